@@ -0,0 +1,324 @@
+package gocb
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// aggregatingMeterSampleSize is the number of values which each value recorder keeps per
+// interval for calculating percentiles.  Beyond this a random sample of the values is kept.
+const aggregatingMeterSampleSize = 1024
+
+// AggregatingMeterOptions specifies options for an AggregatingMeter.
+// UNCOMMITTED: This API may change in the future.
+type AggregatingMeterOptions struct {
+	// EmitInterval is how often a summary is emitted.  The default is 10 minutes.  A summary is
+	// only emitted when something has been recorded.
+	EmitInterval time.Duration
+
+	// Emit receives each summary, encoded as a single JSON document.  It is called from a
+	// background goroutine and should not block for long.  If nil then summaries are logged.
+	Emit func(summary []byte)
+}
+
+// AggregatingMeter is a Meter which aggregates the values recorded to it and periodically
+// emits a summary of them.  Value recorders are summarised by the number of values recorded,
+// along with their minimum, maximum and percentiles.
+// UNCOMMITTED: This API may change in the future.
+type AggregatingMeter struct {
+	interval time.Duration
+	emit     func(summary []byte)
+	clock    clock
+
+	lock           sync.Mutex
+	lastEmit       time.Time
+	counters       map[string]*aggregatingCounter
+	valueRecorders map[string]*aggregatingValueRecorder
+
+	refCount int32
+	killCh   chan struct{}
+	workers  workerGroup
+}
+
+// NewAggregatingMeter returns a new AggregatingMeter.  It begins emitting summaries once it is
+// used by a Cluster, and emits a final summary when the last Cluster using it is closed.
+// UNCOMMITTED: This API may change in the future.
+func NewAggregatingMeter(opts *AggregatingMeterOptions) *AggregatingMeter {
+	if opts == nil {
+		opts = &AggregatingMeterOptions{}
+	}
+
+	interval := opts.EmitInterval
+	if interval == 0 {
+		interval = 10 * time.Minute
+	}
+
+	emit := opts.Emit
+	if emit == nil {
+		emit = func(summary []byte) {
+			logInfof("Operation metrics: %s", summary)
+		}
+	}
+
+	clk := systemClock{}
+	return &AggregatingMeter{
+		interval:       interval,
+		emit:           emit,
+		clock:          clk,
+		lastEmit:       clk.Now(),
+		counters:       make(map[string]*aggregatingCounter),
+		valueRecorders: make(map[string]*aggregatingValueRecorder),
+		killCh:         make(chan struct{}, 1),
+	}
+}
+
+// AddRef is used internally to keep track of the number of Cluster instances referring to it.
+// This is used to correctly shut down the aggregation routines once there are no longer any
+// instances using it.
+func (m *AggregatingMeter) AddRef() int32 {
+	newRefCount := atomic.AddInt32(&m.refCount, 1)
+	if newRefCount == 1 {
+		m.workers.start(m.emitRoutine)
+	}
+	return newRefCount
+}
+
+// DecRef is the counterpart to AddRef (see AddRef for more information).  Releasing the last
+// reference waits for the final summary to be emitted.
+func (m *AggregatingMeter) DecRef() int32 {
+	newRefCount := atomic.AddInt32(&m.refCount, -1)
+	if newRefCount == 0 {
+		m.killCh <- struct{}{}
+		m.workers.wait()
+	}
+	return newRefCount
+}
+
+func (m *AggregatingMeter) emitRoutine() {
+	for {
+		select {
+		case <-m.clock.After(m.interval):
+			m.emitSummary()
+		case <-m.killCh:
+			m.emitSummary()
+			return
+		}
+	}
+}
+
+// Counter returns the counter with the given name and tags.
+func (m *AggregatingMeter) Counter(name string, tags map[string]string) (Counter, error) {
+	key := aggregatingMeterKey(name, tags)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	counter, ok := m.counters[key]
+	if !ok {
+		counter = &aggregatingCounter{name: name, tags: copyMeterTags(tags)}
+		m.counters[key] = counter
+	}
+	return counter, nil
+}
+
+// ValueRecorder returns the value recorder with the given name and tags.
+func (m *AggregatingMeter) ValueRecorder(name string, tags map[string]string) (ValueRecorder, error) {
+	key := aggregatingMeterKey(name, tags)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	recorder, ok := m.valueRecorders[key]
+	if !ok {
+		recorder = &aggregatingValueRecorder{name: name, tags: copyMeterTags(tags)}
+		m.valueRecorders[key] = recorder
+	}
+	return recorder, nil
+}
+
+func copyMeterTags(tags map[string]string) map[string]string {
+	tagsCopy := make(map[string]string, len(tags))
+	for key, value := range tags {
+		tagsCopy[key] = value
+	}
+	return tagsCopy
+}
+
+func aggregatingMeterKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString(name)
+	for _, key := range keys {
+		builder.WriteString("\x00")
+		builder.WriteString(key)
+		builder.WriteString("=")
+		builder.WriteString(tags[key])
+	}
+	return builder.String()
+}
+
+type aggregatingMeterSummary struct {
+	Timestamp      time.Time                      `json:"timestamp"`
+	IntervalMs     int64                          `json:"interval_ms"`
+	Counters       []aggregatingCounterJSON       `json:"counters,omitempty"`
+	ValueRecorders []aggregatingValueRecorderJSON `json:"value_recorders,omitempty"`
+}
+
+type aggregatingCounterJSON struct {
+	Name  string            `json:"name"`
+	Tags  map[string]string `json:"tags,omitempty"`
+	Value uint64            `json:"value"`
+}
+
+type aggregatingValueRecorderJSON struct {
+	Name        string            `json:"name"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Count       uint64            `json:"count"`
+	Min         uint64            `json:"min"`
+	Max         uint64            `json:"max"`
+	Percentiles map[string]uint64 `json:"percentiles"`
+}
+
+// summary builds the summary of the values recorded since the last summary, and resets them.
+func (m *AggregatingMeter) summary() *aggregatingMeterSummary {
+	now := m.clock.Now()
+
+	m.lock.Lock()
+	summary := &aggregatingMeterSummary{
+		Timestamp:  now,
+		IntervalMs: int64(now.Sub(m.lastEmit) / time.Millisecond),
+	}
+	m.lastEmit = now
+
+	for _, counter := range m.counters {
+		if value := counter.reset(); value > 0 {
+			summary.Counters = append(summary.Counters, aggregatingCounterJSON{
+				Name:  counter.name,
+				Tags:  counter.tags,
+				Value: value,
+			})
+		}
+	}
+	for _, recorder := range m.valueRecorders {
+		if recorderJSON, ok := recorder.reset(); ok {
+			summary.ValueRecorders = append(summary.ValueRecorders, recorderJSON)
+		}
+	}
+	m.lock.Unlock()
+
+	sort.Slice(summary.Counters, func(i, j int) bool {
+		return aggregatingMeterKey(summary.Counters[i].Name, summary.Counters[i].Tags) <
+			aggregatingMeterKey(summary.Counters[j].Name, summary.Counters[j].Tags)
+	})
+	sort.Slice(summary.ValueRecorders, func(i, j int) bool {
+		return aggregatingMeterKey(summary.ValueRecorders[i].Name, summary.ValueRecorders[i].Tags) <
+			aggregatingMeterKey(summary.ValueRecorders[j].Name, summary.ValueRecorders[j].Tags)
+	})
+
+	return summary
+}
+
+func (m *AggregatingMeter) emitSummary() {
+	summary := m.summary()
+	if len(summary.Counters) == 0 && len(summary.ValueRecorders) == 0 {
+		return
+	}
+
+	summaryBytes, err := json.Marshal(summary)
+	if err != nil {
+		logDebugf("Failed to generate metrics summary JSON: %s", err)
+		return
+	}
+
+	m.emit(summaryBytes)
+}
+
+type aggregatingCounter struct {
+	name  string
+	tags  map[string]string
+	value uint64
+}
+
+func (c *aggregatingCounter) IncrementBy(num uint64) {
+	atomic.AddUint64(&c.value, num)
+}
+
+func (c *aggregatingCounter) reset() uint64 {
+	return atomic.SwapUint64(&c.value, 0)
+}
+
+type aggregatingValueRecorder struct {
+	name string
+	tags map[string]string
+
+	lock    sync.Mutex
+	count   uint64
+	min     uint64
+	max     uint64
+	samples []uint64
+}
+
+func (r *aggregatingValueRecorder) RecordValue(val uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.count++
+	if r.count == 1 || val < r.min {
+		r.min = val
+	}
+	if val > r.max {
+		r.max = val
+	}
+
+	if len(r.samples) < aggregatingMeterSampleSize {
+		r.samples = append(r.samples, val)
+	} else if idx := rand.Int63n(int64(r.count)); idx < aggregatingMeterSampleSize {
+		r.samples[idx] = val
+	}
+}
+
+func (r *aggregatingValueRecorder) reset() (aggregatingValueRecorderJSON, bool) {
+	r.lock.Lock()
+	count, min, max, samples := r.count, r.min, r.max, r.samples
+	r.count, r.min, r.max, r.samples = 0, 0, 0, nil
+	r.lock.Unlock()
+
+	if count == 0 {
+		return aggregatingValueRecorderJSON{}, false
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+
+	percentiles := make(map[string]uint64)
+	for _, percentile := range []struct {
+		name  string
+		value float64
+	}{{"50.0", 0.5}, {"90.0", 0.9}, {"99.0", 0.99}, {"99.9", 0.999}} {
+		idx := int(percentile.value*float64(len(samples))+0.5) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		percentiles[percentile.name] = samples[idx]
+	}
+
+	return aggregatingValueRecorderJSON{
+		Name:        r.name,
+		Tags:        r.tags,
+		Count:       count,
+		Min:         min,
+		Max:         max,
+		Percentiles: percentiles,
+	}, true
+}
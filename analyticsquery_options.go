@@ -1,6 +1,7 @@
 package gocb
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -17,6 +18,28 @@ const (
 	AnalyticsScanConsistencyRequestPlus = AnalyticsScanConsistency(2)
 )
 
+// AnalyticsPlanFormat indicates the format in which compiled analytics plans are returned.
+type AnalyticsPlanFormat string
+
+const (
+	// AnalyticsPlanFormatJSON indicates that plans should be returned as JSON.
+	AnalyticsPlanFormatJSON = AnalyticsPlanFormat("JSON")
+	// AnalyticsPlanFormatString indicates that plans should be returned as strings.
+	AnalyticsPlanFormatString = AnalyticsPlanFormat("STRING")
+)
+
+// AnalyticsPlanOptions specifies which of the compiled plans for an analytics query
+// should be returned as part of the query meta-data.
+type AnalyticsPlanOptions struct {
+	// Format is the format the plans should be returned in, defaulting to JSON.
+	Format                  AnalyticsPlanFormat
+	LogicalPlan             bool
+	OptimizedLogicalPlan    bool
+	ExpressionTree          bool
+	RewrittenExpressionTree bool
+	Job                     bool
+}
+
 // AnalyticsOptions is the set of options available to an Analytics query.
 type AnalyticsOptions struct {
 	ClientContextID      string
@@ -27,18 +50,30 @@ type AnalyticsOptions struct {
 	ScanConsistency      AnalyticsScanConsistency
 	Raw                  map[string]interface{}
 
+	// Plans, if set, requests that the compiled plans for the query be returned in the
+	// query meta-data, allowing dataset and index selection to be verified.
+	Plans *AnalyticsPlanOptions
+
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
-	parentSpan requestSpanContext
+	// Context, if set, allows the query to be cancelled.  Cancelling the context
+	// will abort the underlying stream and cancel the active request on the
+	// analytics service.
+	Context context.Context
+
+	// Tags are attached to the tracing span and threshold log entry recorded for
+	// this query.
+	Tags map[string]string
+
+	// ParentSpan, if set, is the parent of the span recorded for this query.
+	ParentSpan RequestSpanContext
 }
 
-func (opts *AnalyticsOptions) toMap() (map[string]interface{}, error) {
+func (opts *AnalyticsOptions) toMap(serializer JSONSerializer) (map[string]interface{}, error) {
 	execOpts := make(map[string]interface{})
 
-	if opts.ClientContextID == "" {
-		execOpts["client_context_id"] = uuid.New().String()
-	} else {
+	if opts.ClientContextID != "" {
 		execOpts["client_context_id"] = opts.ClientContextID
 	}
 
@@ -57,7 +92,11 @@ func (opts *AnalyticsOptions) toMap() (map[string]interface{}, error) {
 	}
 
 	if opts.PositionalParameters != nil {
-		execOpts["args"] = opts.PositionalParameters
+		args, err := serializePositionalParameters(serializer, opts.PositionalParameters)
+		if err != nil {
+			return nil, wrapError(err, "failed to serialize positional parameters")
+		}
+		execOpts["args"] = args
 	}
 
 	if opts.NamedParameters != nil {
@@ -65,7 +104,11 @@ func (opts *AnalyticsOptions) toMap() (map[string]interface{}, error) {
 			if !strings.HasPrefix(key, "$") {
 				key = "$" + key
 			}
-			execOpts[key] = value
+			param, err := serializeParameter(serializer, value)
+			if err != nil {
+				return nil, wrapError(err, "failed to serialize named parameter "+key)
+			}
+			execOpts[key] = param
 		}
 	}
 
@@ -73,11 +116,51 @@ func (opts *AnalyticsOptions) toMap() (map[string]interface{}, error) {
 		execOpts["readonly"] = true
 	}
 
+	if opts.Plans != nil {
+		format := opts.Plans.Format
+		if format == "" {
+			format = AnalyticsPlanFormatJSON
+		} else if format != AnalyticsPlanFormatJSON && format != AnalyticsPlanFormatString {
+			return nil, makeInvalidArgumentsError("unexpected plan format option")
+		}
+
+		execOpts["plan-format"] = string(format)
+		if opts.Plans.LogicalPlan {
+			execOpts["logical-plan"] = true
+		}
+		if opts.Plans.OptimizedLogicalPlan {
+			execOpts["optimized-logical-plan"] = true
+		}
+		if opts.Plans.ExpressionTree {
+			execOpts["expression-tree"] = true
+		}
+		if opts.Plans.RewrittenExpressionTree {
+			execOpts["rewritten-expression-tree"] = true
+		}
+		if opts.Plans.Job {
+			execOpts["job"] = true
+		}
+	}
+
 	if opts.Raw != nil {
+		err := checkRawOptions(opts.Raw, execOpts)
+		if err != nil {
+			return nil, err
+		}
 		for k, v := range opts.Raw {
 			execOpts[k] = v
 		}
 	}
 
+	// The query is only retried after ambiguous failures if it is read-only, so this is always
+	// sent to allow the request to be classified.
+	if _, ok := execOpts["readonly"]; !ok {
+		execOpts["readonly"] = false
+	}
+
+	if _, ok := execOpts["client_context_id"]; !ok {
+		execOpts["client_context_id"] = uuid.New().String()
+	}
+
 	return execOpts, nil
 }
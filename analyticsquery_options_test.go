@@ -0,0 +1,130 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAnalyticsOptionsPlans(t *testing.T) {
+	opts := &AnalyticsOptions{
+		Plans: &AnalyticsPlanOptions{
+			OptimizedLogicalPlan: true,
+			Job:                  true,
+		},
+	}
+
+	execOpts, err := opts.toMap(NewDefaultJSONSerializer())
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if execOpts["plan-format"] != "JSON" {
+		t.Fatalf("Expected plan-format to default to JSON but was %v", execOpts["plan-format"])
+	}
+
+	if execOpts["optimized-logical-plan"] != true || execOpts["job"] != true {
+		t.Fatalf("Expected requested plans to be set but were %v", execOpts)
+	}
+
+	if _, ok := execOpts["logical-plan"]; ok {
+		t.Fatalf("Expected logical-plan to not be set")
+	}
+}
+
+func TestAnalyticsOptionsInvalidPlanFormat(t *testing.T) {
+	opts := &AnalyticsOptions{
+		Plans: &AnalyticsPlanOptions{
+			Format: AnalyticsPlanFormat("xml"),
+		},
+	}
+
+	_, err := opts.toMap(NewDefaultJSONSerializer())
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestAnalyticsMetaDataPlans(t *testing.T) {
+	var resp jsonAnalyticsResponse
+	err := json.Unmarshal([]byte(`{"requestID":"1","plans":{"optimizedLogicalPlan":{"operator":"distribute-result"}}}`), &resp)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	var meta AnalyticsMetaData
+	err = meta.fromData(resp)
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if meta.Plans == nil {
+		t.Fatalf("Expected plans to be present")
+	}
+
+	if string(meta.Plans.OptimizedLogicalPlan) != `{"operator":"distribute-result"}` {
+		t.Fatalf("Optimized logical plan was not correct: %s", meta.Plans.OptimizedLogicalPlan)
+	}
+}
+
+func TestAnalyticsMetaDataPlanTrees(t *testing.T) {
+	var resp jsonAnalyticsResponse
+	err := json.Unmarshal([]byte(`{"requestID":"1","plans":{
+		"logicalPlan":"distribute result [$$1]",
+		"optimizedLogicalPlan":{
+			"operator":"distribute-result","operatorId":"1.1","physical-operator":"DISTRIBUTE_RESULT",
+			"execution-mode":"PARTITIONED","inputs":[{
+				"operator":"exchange","operatorId":"1.2","inputs":[{
+					"operator":"data-scan","operatorId":"1.3","data-source":"Default.airports","inputs":[{
+						"operator":"empty-tuple-source","operatorId":"1.4"
+					}]
+				}]
+			}]
+		}
+	}}`), &resp)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	var meta AnalyticsMetaData
+	err = meta.fromData(resp)
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if meta.Plans.LogicalPlanTree != nil {
+		t.Fatalf("Expected a plan in the string format not to be decoded")
+	}
+
+	plan := meta.Plans.OptimizedLogicalPlanTree
+	if plan == nil {
+		t.Fatalf("Expected the optimized logical plan to be decoded")
+	}
+	if plan.Operator != "distribute-result" || plan.PhysicalOperator != "DISTRIBUTE_RESULT" ||
+		plan.ExecutionMode != "PARTITIONED" || len(plan.Inputs) != 1 {
+		t.Fatalf("Unexpected root operator %+v", plan)
+	}
+
+	datasets := plan.DatasetsScanned()
+	if len(datasets) != 1 || datasets[0] != "Default.airports" {
+		t.Fatalf("Expected the airports dataset to be scanned but was %v", datasets)
+	}
+}
+
+func TestAnalyticsOptionsRawConflicts(t *testing.T) {
+	opts := &AnalyticsOptions{
+		NamedParameters: map[string]interface{}{"name": "a"},
+		Raw: map[string]interface{}{
+			"$name":   "b",
+			"timeout": "1s",
+		},
+	}
+	_, err := opts.toMap(NewDefaultJSONSerializer())
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+	if !strings.Contains(err.Error(), "$name, timeout") {
+		t.Fatalf("Expected conflicts to be listed but was %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package gocb
 
 import (
 	"crypto/tls"
+	"os"
+	"sync"
+	"time"
 
 	gocbcore "github.com/couchbase/gocbcore/v8"
 )
@@ -78,6 +81,12 @@ func (ra PasswordAuthenticator) Credentials(req AuthCredsRequest) ([]UserPassPai
 // CertificateAuthenticator implements an Authenticator which can be used with certificate authentication.
 type CertificateAuthenticator struct {
 	ClientCertificate *tls.Certificate
+
+	// CertificateProvider, if set, is used instead of ClientCertificate and is called each time a
+	// connection is established, allowing short-lived certificates to be rotated without
+	// reconnecting the Cluster.  Existing connections continue to use the certificate which they
+	// were established with.
+	CertificateProvider func() (*tls.Certificate, error)
 }
 
 // SupportsTLS returns whether this authenticator can authenticate a TLS connection.
@@ -95,9 +104,78 @@ func (ca CertificateAuthenticator) SupportsNonTLS() bool {
 // Certificate returns the certificate to use when connecting to a specified server.
 // VOLATILE: This API is subject to change at any time.
 func (ca CertificateAuthenticator) Certificate(req AuthCertRequest) (*tls.Certificate, error) {
+	if ca.CertificateProvider != nil {
+		return ca.CertificateProvider()
+	}
+
 	return ca.ClientCertificate, nil
 }
 
+// CertificateFromFiles returns a CertificateProvider which loads a client certificate and key from a
+// pair of PEM encoded files, reloading them whenever either file is modified.  If the files cannot
+// be loaded after they are modified, such as whilst they are being rewritten, then the previously
+// loaded certificate continues to be used.
+// UNCOMMITTED: This API may change in the future.
+func CertificateFromFiles(certFile, keyFile string) func() (*tls.Certificate, error) {
+	loader := &certificateFileLoader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	return loader.certificate
+}
+
+type certificateFileLoader struct {
+	certFile string
+	keyFile  string
+
+	lock        sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func (l *certificateFileLoader) certificate() (*tls.Certificate, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	certInfo, err := os.Stat(l.certFile)
+	if err != nil {
+		return l.fallback(err)
+	}
+	keyInfo, err := os.Stat(l.keyFile)
+	if err != nil {
+		return l.fallback(err)
+	}
+
+	if l.cert != nil && certInfo.ModTime().Equal(l.certModTime) && keyInfo.ModTime().Equal(l.keyModTime) {
+		return l.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return l.fallback(err)
+	}
+
+	if l.cert != nil {
+		logInfof("Reloaded client certificate from %s", l.certFile)
+	}
+
+	l.cert = &cert
+	l.certModTime = certInfo.ModTime()
+	l.keyModTime = keyInfo.ModTime()
+
+	return l.cert, nil
+}
+
+func (l *certificateFileLoader) fallback(err error) (*tls.Certificate, error) {
+	if l.cert == nil {
+		return nil, wrapError(err, "failed to load client certificate")
+	}
+
+	logWarnf("Failed to reload client certificate, using previous certificate: %s", err)
+	return l.cert, nil
+}
+
 // Credentials returns the credentials for a particular service.
 // VOLATILE: This API is subject to change at any time.
 func (ca CertificateAuthenticator) Credentials(req AuthCredsRequest) ([]UserPassPair, error) {
@@ -0,0 +1,121 @@
+package gocb
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestCertificate(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+}
+
+func certificateCommonName(t *testing.T, cert *tls.Certificate) string {
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	return parsed.Subject.CommonName
+}
+
+func TestCertificateAuthenticatorReloadsFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocb-cert")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	modTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	writeTestCertificate(t, certFile, keyFile, "first", modTime)
+
+	auth := CertificateAuthenticator{
+		CertificateProvider: CertificateFromFiles(certFile, keyFile),
+	}
+
+	cert, err := auth.Certificate(AuthCertRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get certificate: %v", err)
+	}
+	if name := certificateCommonName(t, cert); name != "first" {
+		t.Fatalf("Expected first certificate but was %s", name)
+	}
+
+	writeTestCertificate(t, certFile, keyFile, "second", modTime.Add(time.Second))
+
+	cert, err = auth.Certificate(AuthCertRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get certificate: %v", err)
+	}
+	if name := certificateCommonName(t, cert); name != "second" {
+		t.Fatalf("Expected rotated certificate but was %s", name)
+	}
+
+	err = ioutil.WriteFile(keyFile, []byte("partially written"), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	cert, err = auth.Certificate(AuthCertRequest{})
+	if err != nil {
+		t.Fatalf("Expected previous certificate to be used but was %v", err)
+	}
+	if name := certificateCommonName(t, cert); name != "second" {
+		t.Fatalf("Expected previous certificate but was %s", name)
+	}
+}
+
+func TestCertificateFromFilesMissing(t *testing.T) {
+	provider := CertificateFromFiles("/nonexistent/client.pem", "/nonexistent/client.key")
+
+	_, err := provider()
+	if err == nil {
+		t.Fatalf("Expected an error loading missing files")
+	}
+}
@@ -7,6 +7,8 @@ import (
 // Bucket represents a single bucket within a cluster.
 type Bucket struct {
 	sb stateBlock
+
+	cluster *Cluster
 }
 
 func newBucket(sb *stateBlock, bucketName string) *Bucket {
@@ -24,6 +26,7 @@ func newBucket(sb *stateBlock, bucketName string) *Bucket {
 			DuraTimeout:       sb.DuraTimeout,
 			DuraPollTimeout:   sb.DuraPollTimeout,
 			ManagementTimeout: sb.ManagementTimeout,
+			Timeouts:          newTimeouts(sb.Timeouts),
 
 			Transcoder: sb.Transcoder,
 
@@ -33,6 +36,21 @@ func newBucket(sb *stateBlock, bucketName string) *Bucket {
 
 			UseServerDurations: sb.UseServerDurations,
 			UseMutationTokens:  sb.UseMutationTokens,
+
+			OperationLimitsConfig: sb.OperationLimitsConfig,
+			KvLimiter: newOpLimiter(sb.Clock, sb.OperationLimitsConfig.MaxInFlightKV,
+				sb.OperationLimitsConfig.MaxQueuedOperations),
+			HTTPLimiter: sb.HTTPLimiter,
+
+			Telemetry: sb.Telemetry,
+
+			ReadOnly: sb.ReadOnly,
+
+			DeveloperPreview: sb.DeveloperPreview,
+
+			Workers: sb.Workers,
+
+			Clock: sb.Clock,
 		},
 	}
 }
@@ -55,6 +73,13 @@ func (b *Bucket) Name() string {
 	return b.sb.BucketName
 }
 
+// Timeouts returns the timeouts of the bucket, which can be changed to override the timeouts
+// of the Cluster for the operations performed through this bucket.
+// UNCOMMITTED: This API may change in the future.
+func (b *Bucket) Timeouts() *Timeouts {
+	return b.sb.Timeouts
+}
+
 // Scope returns an instance of a Scope.
 // VOLATILE: This API is subject to change at any time.
 func (b *Bucket) Scope(scopeName string) *Scope {
@@ -93,6 +118,12 @@ type bucketHTTPWrapper struct {
 }
 
 func (bw bucketHTTPWrapper) DoHTTPRequest(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+	if bw.b.sb.ReadOnly {
+		if err := checkReadOnlyHTTPRequest(req.Method, req.Path); err != nil {
+			return nil, err
+		}
+	}
+
 	provider, err := bw.b.sb.getCachedClient().getHTTPProvider()
 	if err != nil {
 		return nil, err
@@ -108,7 +139,7 @@ func (b *Bucket) Collections() *CollectionManager {
 	return &CollectionManager{
 		httpClient:           provider,
 		bucketName:           b.Name(),
-		globalTimeout:        b.sb.ManagementTimeout,
+		globalTimeout:        b.sb.managementTimeout(),
 		defaultRetryStrategy: b.sb.RetryStrategyWrapper,
 		tracer:               b.sb.Tracer,
 	}
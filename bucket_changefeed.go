@@ -0,0 +1,595 @@
+package gocb
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+	"github.com/google/uuid"
+)
+
+// ChangeEventType specifies the type of a ChangeEvent.
+type ChangeEventType uint
+
+const (
+	// ChangeEventMutation indicates that a document was created or modified.
+	ChangeEventMutation = ChangeEventType(1)
+
+	// ChangeEventDeletion indicates that a document was removed.
+	ChangeEventDeletion = ChangeEventType(2)
+
+	// ChangeEventExpiration indicates that a document was removed as it had expired.
+	ChangeEventExpiration = ChangeEventType(3)
+
+	// ChangeEventRollback indicates that the server has lost changes from a vbucket, for example
+	// following a failover.  Any state derived from changes to the vbucket with a sequence number
+	// greater than SeqNo must be discarded, as those changes will be received again.
+	ChangeEventRollback = ChangeEventType(4)
+)
+
+// ChangeEvent represents a single change received from a ChangeFeed.
+type ChangeEvent struct {
+	Type         ChangeEventType
+	Key          string
+	Value        []byte
+	Flags        uint32
+	Expiry       uint32
+	Cas          Cas
+	CollectionID uint32
+	VbID         uint16
+	SeqNo        uint64
+
+	revNo      uint64
+	datatype   uint8
+	transcoder Transcoder
+}
+
+// Content assigns the value of a mutation into the value pointer, using the transcoder of the bucket.
+func (e *ChangeEvent) Content(valuePtr interface{}) error {
+	if e.Type != ChangeEventMutation {
+		return ErrNoResult
+	}
+
+	return e.transcoder.Decode(e.Value, e.Flags, valuePtr)
+}
+
+// ChangeFeedVBucketCheckpoint is the position reached within the stream of a single vbucket.
+type ChangeFeedVBucketCheckpoint struct {
+	VbID           uint16 `json:"vb"`
+	VbUUID         uint64 `json:"vbuuid"`
+	SeqNo          uint64 `json:"seqno"`
+	SnapStartSeqNo uint64 `json:"snap_start"`
+	SnapEndSeqNo   uint64 `json:"snap_end"`
+}
+
+// ChangeFeedCheckpoint is the position reached within a ChangeFeed, which can be persisted and
+// then used to resume the feed from the same position.
+type ChangeFeedCheckpoint struct {
+	VBuckets []ChangeFeedVBucketCheckpoint `json:"vbuckets"`
+}
+
+// ChangeFeedOptions are the options available when opening a ChangeFeed.
+type ChangeFeedOptions struct {
+	// Checkpoint is the position to resume the feed from.  If nil the feed starts from the
+	// beginning of the history of the bucket, receiving the current state of every document.
+	Checkpoint *ChangeFeedCheckpoint
+
+	// Name identifies the connection used by the feed on the server.  If not set a unique name
+	// is generated.
+	Name string
+
+	// Timeout is the length of time to wait whilst setting up the feed.
+	Timeout time.Duration
+}
+
+type changeFeedItemKind uint
+
+const (
+	changeFeedItemEvent = changeFeedItemKind(iota)
+	changeFeedItemSnapshot
+	changeFeedItemStreamOpened
+	changeFeedItemStreamEnd
+	changeFeedItemFailoverLog
+)
+
+// changeFeedItem carries the events raised by the underlying streams to the goroutine
+// consuming the feed, which is the only goroutine to act on them.
+type changeFeedItem struct {
+	kind      changeFeedItemKind
+	vbID      uint16
+	event     ChangeEvent
+	snapStart uint64
+	snapEnd   uint64
+	entries   []gocbcore.FailoverEntry
+	err       error
+}
+
+// ChangeFeed is a stream of the changes made to the documents within a bucket, or a collection.
+// Changes to each vbucket are received in order, but there is no ordering between vbuckets.
+// The feed automatically resumes streams which are interrupted by failover or rebalance.
+// UNCOMMITTED: This API may change in the future.
+type ChangeFeed struct {
+	provider    dcpProvider
+	filter      *gocbcore.StreamFilter
+	transcoder  Transcoder
+	observer    *changeFeedObserver
+	streamFlags gocbcore.DcpStreamAddFlag
+
+	items     chan changeFeedItem
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+
+	lock     sync.Mutex
+	vbuckets []ChangeFeedVBucketCheckpoint
+	done     []bool
+
+	numOpen int
+	current *ChangeEvent
+	err     error
+}
+
+// ChangeFeed opens a feed of the changes made to the documents within the bucket.
+// UNCOMMITTED: This API may change in the future.
+func (b *Bucket) ChangeFeed(opts *ChangeFeedOptions) (*ChangeFeed, error) {
+	return openChangeFeed(&b.sb, "", "", opts, 0, 0)
+}
+
+// ChangeFeed opens a feed of the changes made to the documents within the collection.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) ChangeFeed(opts *ChangeFeedOptions) (*ChangeFeed, error) {
+	return openChangeFeed(&c.sb, c.scopeName(), c.name(), opts, 0, 0)
+}
+
+func openChangeFeed(sb *stateBlock, scopeName, collectionName string, opts *ChangeFeedOptions,
+	openFlags gocbcore.DcpOpenFlag, streamFlags gocbcore.DcpStreamAddFlag) (*ChangeFeed, error) {
+	if opts == nil {
+		opts = &ChangeFeedOptions{}
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "gocb-changefeed-" + uuid.New().String()
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = sb.kvTimeout()
+	}
+
+	provider, err := sb.getCachedClient().openDcpProvider(sb.BucketName, name, openFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	clk := clockOrSystem(sb.Clock)
+	feed, err := newChangeFeed(provider, sb.Transcoder, scopeName, collectionName, clk, clk.Now().Add(timeout),
+		opts.Checkpoint, streamFlags)
+	if err != nil {
+		closeErr := provider.Close()
+		if closeErr != nil {
+			logDebugf("Failed to close change feed connection (%s)", closeErr)
+		}
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func newChangeFeed(provider dcpProvider, transcoder Transcoder, scopeName, collectionName string, clk clock,
+	deadline time.Time, checkpoint *ChangeFeedCheckpoint, streamFlags gocbcore.DcpStreamAddFlag) (*ChangeFeed, error) {
+	numVbuckets := provider.NumVbuckets()
+	if numVbuckets == 0 {
+		return nil, wrapError(ErrFeatureNotAvailable, "change feeds require a couchbase bucket")
+	}
+
+	feed := &ChangeFeed{
+		provider:    provider,
+		transcoder:  transcoder,
+		streamFlags: streamFlags,
+		items:       make(chan changeFeedItem, 1024),
+		closeCh:     make(chan struct{}),
+		vbuckets:    make([]ChangeFeedVBucketCheckpoint, numVbuckets),
+		done:        make([]bool, numVbuckets),
+		numOpen:     numVbuckets,
+	}
+	feed.observer = &changeFeedObserver{feed: feed}
+
+	for i := range feed.vbuckets {
+		feed.vbuckets[i].VbID = uint16(i)
+	}
+	if checkpoint != nil {
+		if len(checkpoint.VBuckets) != numVbuckets {
+			return nil, makeInvalidArgumentsError("checkpoint does not match the number of vbuckets in the bucket")
+		}
+		for _, vb := range checkpoint.VBuckets {
+			if int(vb.VbID) >= numVbuckets {
+				return nil, makeInvalidArgumentsError("checkpoint contains an invalid vbucket")
+			}
+			feed.vbuckets[vb.VbID] = vb
+		}
+	}
+
+	if collectionName != "" {
+		collectionID, err := changeFeedCollectionID(provider, scopeName, collectionName, clk, deadline)
+		if err != nil {
+			return nil, err
+		}
+
+		feed.filter = gocbcore.NewStreamFilter()
+		feed.filter.Collections = []uint32{collectionID}
+	}
+
+	for vbID := 0; vbID < numVbuckets; vbID++ {
+		err := feed.openStream(uint16(vbID))
+		if err != nil {
+			closeErr := feed.Close()
+			if closeErr != nil {
+				logDebugf("Failed to close change feed (%s)", closeErr)
+			}
+			return nil, err
+		}
+	}
+
+	return feed, nil
+}
+
+func changeFeedCollectionID(provider dcpProvider, scopeName, collectionName string, clk clock,
+	deadline time.Time) (uint32, error) {
+	type collectionIDResp struct {
+		collectionID uint32
+		err          error
+	}
+
+	respCh := make(chan collectionIDResp, 1)
+	op, err := provider.GetCollectionID(scopeName, collectionName, gocbcore.GetCollectionIDOptions{},
+		func(manifestID uint64, collectionID uint32, err error) {
+			respCh <- collectionIDResp{collectionID, err}
+		})
+	if err != nil {
+		return 0, maybeEnhanceKVErr(err, "", scopeName, collectionName, "")
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.err != nil {
+			return 0, maybeEnhanceKVErr(resp.err, "", scopeName, collectionName, "")
+		}
+		return resp.collectionID, nil
+	case <-clk.After(deadline.Sub(clk.Now())):
+		op.Cancel(ErrUnambiguousTimeout)
+		resp := <-respCh
+		if resp.err != nil {
+			return 0, maybeEnhanceKVErr(resp.err, "", scopeName, collectionName, "")
+		}
+		return resp.collectionID, nil
+	}
+}
+
+// Next waits for the next change to be received, returning false if the feed has been closed,
+// every stream has ended or an error occurred.
+func (f *ChangeFeed) Next() bool {
+	f.current = nil
+
+	for f.err == nil && f.numOpen > 0 {
+		select {
+		case item := <-f.items:
+			f.handleItem(item)
+			if f.current != nil {
+				return true
+			}
+		case <-f.closeCh:
+			return false
+		}
+	}
+
+	return false
+}
+
+// Event returns the change received by the last call to Next.
+func (f *ChangeFeed) Event() *ChangeEvent {
+	return f.current
+}
+
+// Err returns the error which caused the feed to stop, if any.
+func (f *ChangeFeed) Err() error {
+	return f.err
+}
+
+// Checkpoint returns the position reached by the feed, reflecting every change returned by Next.
+func (f *ChangeFeed) Checkpoint() *ChangeFeedCheckpoint {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	vbuckets := make([]ChangeFeedVBucketCheckpoint, len(f.vbuckets))
+	copy(vbuckets, f.vbuckets)
+
+	return &ChangeFeedCheckpoint{
+		VBuckets: vbuckets,
+	}
+}
+
+// Close stops the feed and releases its connections.
+func (f *ChangeFeed) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.closeCh)
+
+		// The streams are marked as done by Next, which may still be running.
+		f.lock.Lock()
+		var open []uint16
+		for vbID, done := range f.done {
+			if !done {
+				open = append(open, uint16(vbID))
+			}
+		}
+		f.lock.Unlock()
+
+		for _, vbID := range open {
+			_, err := f.provider.CloseStream(vbID, func(error) {})
+			if err != nil {
+				logDebugf("Failed to close change feed stream (%s)", err)
+			}
+		}
+
+		f.closeErr = f.provider.Close()
+	})
+
+	return f.closeErr
+}
+
+func (f *ChangeFeed) push(item changeFeedItem) {
+	select {
+	case f.items <- item:
+	case <-f.closeCh:
+	}
+}
+
+func (f *ChangeFeed) openStream(vbID uint16) error {
+	f.lock.Lock()
+	vb := f.vbuckets[vbID]
+	f.lock.Unlock()
+
+	// The start of the stream must fall within the snapshot which we resume from.
+	snapStart, snapEnd := vb.SnapStartSeqNo, vb.SnapEndSeqNo
+	if vb.SeqNo < snapStart || vb.SeqNo >= snapEnd {
+		snapStart, snapEnd = vb.SeqNo, vb.SeqNo
+	}
+
+	_, err := f.provider.OpenStream(vbID, f.streamFlags, gocbcore.VbUUID(vb.VbUUID), gocbcore.SeqNo(vb.SeqNo),
+		gocbcore.SeqNo(0xFFFFFFFFFFFFFFFF), gocbcore.SeqNo(snapStart), gocbcore.SeqNo(snapEnd), f.observer, f.filter,
+		func(entries []gocbcore.FailoverEntry, err error) {
+			f.push(changeFeedItem{
+				kind:    changeFeedItemStreamOpened,
+				vbID:    vbID,
+				entries: entries,
+				err:     err,
+			})
+		})
+	if err != nil {
+		return maybeEnhanceKVErr(err, "", "", "", "")
+	}
+
+	return nil
+}
+
+func (f *ChangeFeed) handleItem(item changeFeedItem) {
+	switch item.kind {
+	case changeFeedItemEvent:
+		f.lock.Lock()
+		f.vbuckets[item.vbID].SeqNo = item.event.SeqNo
+		f.lock.Unlock()
+
+		event := item.event
+		f.current = &event
+	case changeFeedItemSnapshot:
+		f.lock.Lock()
+		f.vbuckets[item.vbID].SnapStartSeqNo = item.snapStart
+		f.vbuckets[item.vbID].SnapEndSeqNo = item.snapEnd
+		f.lock.Unlock()
+	case changeFeedItemStreamOpened:
+		if errors.Is(item.err, gocbcore.ErrMemdRollback) {
+			f.requestFailoverLog(item.vbID)
+			return
+		}
+		if item.err != nil {
+			f.err = maybeEnhanceKVErr(item.err, "", "", "", "")
+			return
+		}
+
+		if len(item.entries) > 0 {
+			f.lock.Lock()
+			f.vbuckets[item.vbID].VbUUID = uint64(item.entries[0].VbUUID)
+			f.lock.Unlock()
+		}
+	case changeFeedItemStreamEnd:
+		switch {
+		case item.err == nil,
+			errors.Is(item.err, gocbcore.ErrDCPStreamClosed),
+			errors.Is(item.err, gocbcore.ErrDCPStreamFilterEmpty):
+			f.lock.Lock()
+			f.done[item.vbID] = true
+			f.lock.Unlock()
+			f.numOpen--
+		case errors.Is(item.err, gocbcore.ErrDCPStreamStateChanged),
+			errors.Is(item.err, gocbcore.ErrDCPStreamDisconnected),
+			errors.Is(item.err, gocbcore.ErrDCPStreamTooSlow):
+			// The stream was interrupted by the server, such as by a rebalance, so we resume it.
+			f.err = f.openStream(item.vbID)
+		default:
+			f.err = maybeEnhanceKVErr(item.err, "", "", "", "")
+		}
+	case changeFeedItemFailoverLog:
+		if item.err != nil {
+			f.err = maybeEnhanceKVErr(item.err, "", "", "", "")
+			return
+		}
+
+		f.lock.Lock()
+		vb := &f.vbuckets[item.vbID]
+		vb.VbUUID, vb.SeqNo = changeFeedRollbackPoint(item.entries, vb.VbUUID, vb.SeqNo)
+		vb.SnapStartSeqNo, vb.SnapEndSeqNo = vb.SeqNo, vb.SeqNo
+		seqNo := vb.SeqNo
+		f.lock.Unlock()
+
+		f.current = &ChangeEvent{
+			Type:  ChangeEventRollback,
+			VbID:  item.vbID,
+			SeqNo: seqNo,
+		}
+
+		f.err = f.openStream(item.vbID)
+	}
+}
+
+func (f *ChangeFeed) requestFailoverLog(vbID uint16) {
+	_, err := f.provider.GetFailoverLog(vbID, func(entries []gocbcore.FailoverEntry, err error) {
+		f.push(changeFeedItem{
+			kind:    changeFeedItemFailoverLog,
+			vbID:    vbID,
+			entries: entries,
+			err:     err,
+		})
+	})
+	if err != nil {
+		f.err = maybeEnhanceKVErr(err, "", "", "", "")
+	}
+}
+
+// changeFeedRollbackPoint determines the position to resume a stream from when the server
+// requires us to roll back.  The failover log lists the history of the vbucket from newest to
+// oldest, each entry being the point at which that branch of history began.  If our branch was
+// superseded before our position we resume from the point at which it was superseded.
+// Otherwise the server rejected a position within our branch, so we walk back through the log
+// to the newest branch start before it.  If our branch is not in the log at all we must start
+// again from the beginning.
+func changeFeedRollbackPoint(entries []gocbcore.FailoverEntry, vbUUID, seqNo uint64) (uint64, uint64) {
+	for i, entry := range entries {
+		if uint64(entry.VbUUID) != vbUUID {
+			continue
+		}
+
+		if i > 0 {
+			branchEnd := uint64(entries[i-1].SeqNo)
+			if seqNo > branchEnd {
+				return vbUUID, branchEnd
+			}
+		}
+
+		for _, older := range entries[i:] {
+			if seqNo > uint64(older.SeqNo) {
+				return uint64(older.VbUUID), uint64(older.SeqNo)
+			}
+		}
+
+		break
+	}
+
+	return 0, 0
+}
+
+// changeFeedObserver receives the events raised by the streams of a ChangeFeed.
+type changeFeedObserver struct {
+	feed *ChangeFeed
+}
+
+func (o *changeFeedObserver) SnapshotMarker(startSeqNo, endSeqNo uint64, vbID uint16, streamID uint16,
+	snapshotType gocbcore.SnapshotState) {
+	o.feed.push(changeFeedItem{
+		kind:      changeFeedItemSnapshot,
+		vbID:      vbID,
+		snapStart: startSeqNo,
+		snapEnd:   endSeqNo,
+	})
+}
+
+func (o *changeFeedObserver) Mutation(seqNo, revNo uint64, flags, expiry, lockTime uint32, cas uint64, datatype uint8,
+	vbID uint16, collectionID uint32, streamID uint16, key, value []byte) {
+	o.feed.push(changeFeedItem{
+		kind: changeFeedItemEvent,
+		vbID: vbID,
+		event: ChangeEvent{
+			Type:         ChangeEventMutation,
+			Key:          string(key),
+			Value:        value,
+			Flags:        flags,
+			Expiry:       expiry,
+			Cas:          Cas(cas),
+			CollectionID: collectionID,
+			VbID:         vbID,
+			SeqNo:        seqNo,
+			revNo:        revNo,
+			datatype:     datatype,
+			transcoder:   o.feed.transcoder,
+		},
+	})
+}
+
+func (o *changeFeedObserver) Deletion(seqNo, revNo, cas uint64, datatype uint8, vbID uint16, collectionID uint32,
+	streamID uint16, key, value []byte) {
+	o.feed.push(changeFeedItem{
+		kind: changeFeedItemEvent,
+		vbID: vbID,
+		event: ChangeEvent{
+			Type:         ChangeEventDeletion,
+			Key:          string(key),
+			Cas:          Cas(cas),
+			CollectionID: collectionID,
+			VbID:         vbID,
+			SeqNo:        seqNo,
+			revNo:        revNo,
+			datatype:     datatype,
+			transcoder:   o.feed.transcoder,
+		},
+	})
+}
+
+func (o *changeFeedObserver) Expiration(seqNo, revNo, cas uint64, vbID uint16, collectionID uint32, streamID uint16,
+	key []byte) {
+	o.feed.push(changeFeedItem{
+		kind: changeFeedItemEvent,
+		vbID: vbID,
+		event: ChangeEvent{
+			Type:         ChangeEventExpiration,
+			Key:          string(key),
+			Cas:          Cas(cas),
+			CollectionID: collectionID,
+			VbID:         vbID,
+			SeqNo:        seqNo,
+			revNo:        revNo,
+			transcoder:   o.feed.transcoder,
+		},
+	})
+}
+
+func (o *changeFeedObserver) End(vbID uint16, streamID uint16, err error) {
+	o.feed.push(changeFeedItem{
+		kind: changeFeedItemStreamEnd,
+		vbID: vbID,
+		err:  err,
+	})
+}
+
+func (o *changeFeedObserver) CreateCollection(seqNo uint64, version uint8, vbID uint16, manifestUID uint64,
+	scopeID uint32, collectionID uint32, ttl uint32, streamID uint16, key []byte) {
+}
+
+func (o *changeFeedObserver) DeleteCollection(seqNo uint64, version uint8, vbID uint16, manifestUID uint64,
+	scopeID uint32, collectionID uint32, streamID uint16) {
+}
+
+func (o *changeFeedObserver) FlushCollection(seqNo uint64, version uint8, vbID uint16, manifestUID uint64,
+	collectionID uint32) {
+}
+
+func (o *changeFeedObserver) CreateScope(seqNo uint64, version uint8, vbID uint16, manifestUID uint64,
+	scopeID uint32, streamID uint16, key []byte) {
+}
+
+func (o *changeFeedObserver) DeleteScope(seqNo uint64, version uint8, vbID uint16, manifestUID uint64,
+	scopeID uint32, streamID uint16) {
+}
+
+func (o *changeFeedObserver) ModifyCollection(seqNo uint64, version uint8, vbID uint16, manifestUID uint64,
+	collectionID uint32, ttl uint32, streamID uint16) {
+}
@@ -0,0 +1,297 @@
+package gocb
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+type mockDcpStreamOpen struct {
+	vbID      uint16
+	vbUUID    gocbcore.VbUUID
+	seqNo     gocbcore.SeqNo
+	snapStart gocbcore.SeqNo
+	snapEnd   gocbcore.SeqNo
+	observer  gocbcore.StreamObserver
+	filter    *gocbcore.StreamFilter
+	cb        gocbcore.OpenStreamCallback
+}
+
+type mockDcpProvider struct {
+	lock         sync.Mutex
+	numVbuckets  int
+	opens        []mockDcpStreamOpen
+	failoverLog  []gocbcore.FailoverEntry
+	collectionID uint32
+	closed       bool
+}
+
+func (mdp *mockDcpProvider) OpenStream(vbID uint16, flags gocbcore.DcpStreamAddFlag, vbUUID gocbcore.VbUUID, startSeqNo,
+	endSeqNo, snapStartSeqNo, snapEndSeqNo gocbcore.SeqNo, evtHandler gocbcore.StreamObserver,
+	filter *gocbcore.StreamFilter, cb gocbcore.OpenStreamCallback) (gocbcore.PendingOp, error) {
+	mdp.lock.Lock()
+	defer mdp.lock.Unlock()
+
+	mdp.opens = append(mdp.opens, mockDcpStreamOpen{
+		vbID:      vbID,
+		vbUUID:    vbUUID,
+		seqNo:     startSeqNo,
+		snapStart: snapStartSeqNo,
+		snapEnd:   snapEndSeqNo,
+		observer:  evtHandler,
+		filter:    filter,
+		cb:        cb,
+	})
+
+	return &mockPendingOp{}, nil
+}
+
+func (mdp *mockDcpProvider) CloseStream(vbID uint16, cb gocbcore.CloseStreamCallback) (gocbcore.PendingOp, error) {
+	return &mockPendingOp{}, nil
+}
+
+func (mdp *mockDcpProvider) GetFailoverLog(vbID uint16, cb gocbcore.GetFailoverLogCallback) (gocbcore.PendingOp, error) {
+	cb(mdp.failoverLog, nil)
+	return &mockPendingOp{}, nil
+}
+
+func (mdp *mockDcpProvider) GetCollectionID(scopeName string, collectionName string, opts gocbcore.GetCollectionIDOptions,
+	cb gocbcore.CollectionIDCallback) (gocbcore.PendingOp, error) {
+	cb(1, mdp.collectionID, nil)
+	return &mockPendingOp{}, nil
+}
+
+func (mdp *mockDcpProvider) NumVbuckets() int {
+	return mdp.numVbuckets
+}
+
+func (mdp *mockDcpProvider) Close() error {
+	mdp.closed = true
+	return nil
+}
+
+func (mdp *mockDcpProvider) lastOpen(vbID uint16) mockDcpStreamOpen {
+	mdp.lock.Lock()
+	defer mdp.lock.Unlock()
+
+	for i := len(mdp.opens) - 1; i >= 0; i-- {
+		if mdp.opens[i].vbID == vbID {
+			return mdp.opens[i]
+		}
+	}
+
+	return mockDcpStreamOpen{}
+}
+
+func testOpenChangeFeed(t *testing.T, provider *mockDcpProvider, checkpoint *ChangeFeedCheckpoint) *ChangeFeed {
+	feed, err := newChangeFeed(provider, NewJSONTranscoder(), "", "", systemClock{}, time.Now().Add(time.Second), checkpoint, 0)
+	if err != nil {
+		t.Fatalf("Failed to open change feed: %v", err)
+	}
+
+	return feed
+}
+
+func TestChangeFeedEvents(t *testing.T) {
+	provider := &mockDcpProvider{numVbuckets: 2}
+	feed := testOpenChangeFeed(t, provider, nil)
+	defer feed.Close()
+
+	open := provider.lastOpen(1)
+	open.cb([]gocbcore.FailoverEntry{{VbUUID: 1234, SeqNo: 0}}, nil)
+	open.observer.SnapshotMarker(0, 10, 1, 0, gocbcore.SnapshotState(0))
+	open.observer.Mutation(4, 1, 2<<24, 0, 0, 99, 0, 1, 0, 0, []byte("key"), []byte(`{"name":"mike"}`))
+	open.observer.Deletion(5, 1, 100, 0, 1, 0, 0, []byte("key"), nil)
+
+	if !feed.Next() {
+		t.Fatalf("Expected a mutation event but feed ended with %v", feed.Err())
+	}
+
+	event := feed.Event()
+	if event.Type != ChangeEventMutation || event.Key != "key" || event.Cas != 99 || event.SeqNo != 4 {
+		t.Fatalf("Unexpected mutation event %v", event)
+	}
+
+	var content map[string]string
+	err := event.Content(&content)
+	if err != nil {
+		t.Fatalf("Content failed: %v", err)
+	}
+	if content["name"] != "mike" {
+		t.Fatalf("Expected content to be decoded but was %v", content)
+	}
+
+	if !feed.Next() {
+		t.Fatalf("Expected a deletion event but feed ended with %v", feed.Err())
+	}
+	if feed.Event().Type != ChangeEventDeletion || feed.Event().SeqNo != 5 {
+		t.Fatalf("Unexpected deletion event %v", feed.Event())
+	}
+
+	vb := feed.Checkpoint().VBuckets[1]
+	if vb.VbUUID != 1234 || vb.SeqNo != 5 || vb.SnapStartSeqNo != 0 || vb.SnapEndSeqNo != 10 {
+		t.Fatalf("Unexpected checkpoint %v", vb)
+	}
+
+	err = feed.Close()
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !provider.closed {
+		t.Fatalf("Expected provider to be closed")
+	}
+	if feed.Next() {
+		t.Fatalf("Expected closed feed to return no events")
+	}
+}
+
+func TestChangeFeedResumesFromCheckpoint(t *testing.T) {
+	provider := &mockDcpProvider{numVbuckets: 2}
+	checkpoint := &ChangeFeedCheckpoint{
+		VBuckets: []ChangeFeedVBucketCheckpoint{
+			{VbID: 0, VbUUID: 11, SeqNo: 20, SnapStartSeqNo: 15, SnapEndSeqNo: 30},
+			{VbID: 1, VbUUID: 12, SeqNo: 30, SnapStartSeqNo: 15, SnapEndSeqNo: 30},
+		},
+	}
+	feed := testOpenChangeFeed(t, provider, checkpoint)
+	defer feed.Close()
+
+	open := provider.lastOpen(0)
+	if open.vbUUID != 11 || open.seqNo != 20 || open.snapStart != 15 || open.snapEnd != 30 {
+		t.Fatalf("Expected stream to resume from checkpoint but was %v", open)
+	}
+
+	// A completed snapshot must be resumed from the point that it ended.
+	open = provider.lastOpen(1)
+	if open.vbUUID != 12 || open.seqNo != 30 || open.snapStart != 30 || open.snapEnd != 30 {
+		t.Fatalf("Expected stream to resume from end of snapshot but was %v", open)
+	}
+}
+
+func TestChangeFeedInvalidCheckpoint(t *testing.T) {
+	provider := &mockDcpProvider{numVbuckets: 2}
+	_, err := newChangeFeed(provider, NewJSONTranscoder(), "", "", systemClock{}, time.Now().Add(time.Second), &ChangeFeedCheckpoint{
+		VBuckets: []ChangeFeedVBucketCheckpoint{{VbID: 0}},
+	}, 0)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestChangeFeedRollback(t *testing.T) {
+	provider := &mockDcpProvider{
+		numVbuckets: 1,
+		failoverLog: []gocbcore.FailoverEntry{
+			{VbUUID: 2, SeqNo: 15},
+			{VbUUID: 1, SeqNo: 0},
+		},
+	}
+	feed := testOpenChangeFeed(t, provider, &ChangeFeedCheckpoint{
+		VBuckets: []ChangeFeedVBucketCheckpoint{{VbID: 0, VbUUID: 1, SeqNo: 20}},
+	})
+	defer feed.Close()
+
+	provider.lastOpen(0).cb(nil, gocbcore.ErrMemdRollback)
+
+	if !feed.Next() {
+		t.Fatalf("Expected a rollback event but feed ended with %v", feed.Err())
+	}
+	if feed.Event().Type != ChangeEventRollback || feed.Event().SeqNo != 15 {
+		t.Fatalf("Unexpected rollback event %v", feed.Event())
+	}
+
+	open := provider.lastOpen(0)
+	if open.vbUUID != 1 || open.seqNo != 15 {
+		t.Fatalf("Expected stream to be reopened from rollback point but was %v", open)
+	}
+}
+
+func TestChangeFeedRollbackPoint(t *testing.T) {
+	entries := []gocbcore.FailoverEntry{
+		{VbUUID: 3, SeqNo: 40},
+		{VbUUID: 2, SeqNo: 15},
+		{VbUUID: 1, SeqNo: 0},
+	}
+
+	vbUUID, seqNo := changeFeedRollbackPoint(entries, 2, 50)
+	if vbUUID != 2 || seqNo != 40 {
+		t.Fatalf("Expected rollback to end of branch but was %d/%d", vbUUID, seqNo)
+	}
+
+	vbUUID, seqNo = changeFeedRollbackPoint(entries, 1, 20)
+	if vbUUID != 1 || seqNo != 15 {
+		t.Fatalf("Expected rollback to end of oldest branch but was %d/%d", vbUUID, seqNo)
+	}
+
+	// A rejected position within the current branch goes back to the start of the branch.
+	vbUUID, seqNo = changeFeedRollbackPoint(entries, 3, 50)
+	if vbUUID != 3 || seqNo != 40 {
+		t.Fatalf("Expected rollback to start of current branch but was %d/%d", vbUUID, seqNo)
+	}
+
+	vbUUID, seqNo = changeFeedRollbackPoint(entries, 2, 30)
+	if vbUUID != 2 || seqNo != 15 {
+		t.Fatalf("Expected rollback to start of branch but was %d/%d", vbUUID, seqNo)
+	}
+
+	// A rejected branch start goes back to the start of the branch before it.
+	vbUUID, seqNo = changeFeedRollbackPoint(entries, 3, 40)
+	if vbUUID != 2 || seqNo != 15 {
+		t.Fatalf("Expected rollback to start of previous branch but was %d/%d", vbUUID, seqNo)
+	}
+
+	vbUUID, seqNo = changeFeedRollbackPoint(entries, 1, 0)
+	if vbUUID != 0 || seqNo != 0 {
+		t.Fatalf("Expected rollback to start but was %d/%d", vbUUID, seqNo)
+	}
+
+	vbUUID, seqNo = changeFeedRollbackPoint(entries, 4, 50)
+	if vbUUID != 0 || seqNo != 0 {
+		t.Fatalf("Expected rollback to start for unknown branch but was %d/%d", vbUUID, seqNo)
+	}
+}
+
+func TestChangeFeedReconnectsInterruptedStream(t *testing.T) {
+	provider := &mockDcpProvider{numVbuckets: 1}
+	feed := testOpenChangeFeed(t, provider, nil)
+	defer feed.Close()
+
+	open := provider.lastOpen(0)
+	open.cb([]gocbcore.FailoverEntry{{VbUUID: 7, SeqNo: 0}}, nil)
+	open.observer.SnapshotMarker(0, 10, 0, 0, gocbcore.SnapshotState(0))
+	open.observer.Mutation(3, 1, 2<<24, 0, 0, 99, 0, 0, 0, 0, []byte("key"), []byte(`{}`))
+	open.observer.End(0, 0, gocbcore.ErrDCPStreamStateChanged)
+	open.observer.End(0, 0, nil)
+
+	if !feed.Next() {
+		t.Fatalf("Expected a mutation event but feed ended with %v", feed.Err())
+	}
+	if feed.Next() {
+		t.Fatalf("Expected feed to end but received %v", feed.Event())
+	}
+	if feed.Err() != nil {
+		t.Fatalf("Expected feed to end without error but was %v", feed.Err())
+	}
+
+	reopen := provider.lastOpen(0)
+	if reopen.vbUUID != 7 || reopen.seqNo != 3 || reopen.snapStart != 0 || reopen.snapEnd != 10 {
+		t.Fatalf("Expected stream to be reopened from last event but was %v", reopen)
+	}
+}
+
+func TestChangeFeedCollectionFilter(t *testing.T) {
+	provider := &mockDcpProvider{numVbuckets: 1, collectionID: 8}
+	feed, err := newChangeFeed(provider, NewJSONTranscoder(), "scope", "collection", systemClock{}, time.Now().Add(time.Second), nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to open change feed: %v", err)
+	}
+	defer feed.Close()
+
+	filter := provider.lastOpen(0).filter
+	if filter == nil || len(filter.Collections) != 1 || filter.Collections[0] != 8 {
+		t.Fatalf("Expected stream to be filtered to collection but was %v", filter)
+	}
+}
@@ -3,6 +3,7 @@ package gocb
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/url"
 	"strings"
 	"time"
@@ -46,7 +47,7 @@ type CollectionManager struct {
 	bucketName           string
 	globalTimeout        time.Duration
 	defaultRetryStrategy *retryStrategyWrapper
-	tracer               requestTracer
+	tracer               RequestTracer
 }
 
 // GetAllScopesOptions is the set of options available to the GetAllScopes operation.
@@ -65,9 +66,11 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := cm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategy := cm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = cm.globalTimeout
 	}
 
 	req := &gocbcore.HTTPRequest{
@@ -75,6 +78,7 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 		Path:          fmt.Sprintf("/pools/default/buckets/%s/collections", cm.bucketName),
 		Method:        "GET",
 		RetryStrategy: retryStrategy,
+		Timeout:       timeout,
 		IsIdempotent:  true,
 		UniqueID:      uuid.New().String(),
 	}
@@ -97,10 +101,14 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 		return nil, makeHTTPBadStatusError("failed to get all scopes", req, resp)
 	}
 
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var scopes []ScopeSpec
 	var mfest gocbcore.Manifest
-	jsonDec := json.NewDecoder(resp.Body)
-	err = jsonDec.Decode(&mfest)
+	err = json.Unmarshal(respBytes, &mfest)
 	if err == nil {
 		for _, scope := range mfest.Scopes {
 			var collections []CollectionSpec
@@ -118,8 +126,7 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 	} else {
 		// Temporary support for older server version
 		var oldMfest jsonManifest
-		jsonDec := json.NewDecoder(resp.Body)
-		err = jsonDec.Decode(&oldMfest)
+		err = json.Unmarshal(respBytes, &oldMfest)
 		if err != nil {
 			return nil, err
 		}
@@ -166,9 +173,11 @@ func (cm *CollectionManager) CreateCollection(spec CollectionSpec, opts *CreateC
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := cm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategy := cm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = cm.globalTimeout
 	}
 
 	posts := url.Values{}
@@ -181,6 +190,7 @@ func (cm *CollectionManager) CreateCollection(spec CollectionSpec, opts *CreateC
 		Body:          []byte(posts.Encode()),
 		ContentType:   "application/x-www-form-urlencoded",
 		RetryStrategy: retryStrategy,
+		Timeout:       timeout,
 		UniqueID:      uuid.New().String(),
 	}
 
@@ -238,9 +248,11 @@ func (cm *CollectionManager) DropCollection(spec CollectionSpec, opts *DropColle
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := cm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategy := cm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = cm.globalTimeout
 	}
 
 	req := &gocbcore.HTTPRequest{
@@ -248,6 +260,7 @@ func (cm *CollectionManager) DropCollection(spec CollectionSpec, opts *DropColle
 		Path:          fmt.Sprintf("/pools/default/buckets/%s/collections/%s/%s", cm.bucketName, spec.ScopeName, spec.Name),
 		Method:        "DELETE",
 		RetryStrategy: retryStrategy,
+		Timeout:       timeout,
 		UniqueID:      uuid.New().String(),
 	}
 
@@ -297,9 +310,11 @@ func (cm *CollectionManager) CreateScope(scopeName string, opts *CreateScopeOpti
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := cm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategy := cm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = cm.globalTimeout
 	}
 
 	posts := url.Values{}
@@ -312,6 +327,7 @@ func (cm *CollectionManager) CreateScope(scopeName string, opts *CreateScopeOpti
 		Body:          []byte(posts.Encode()),
 		ContentType:   "application/x-www-form-urlencoded",
 		RetryStrategy: retryStrategy,
+		Timeout:       timeout,
 		UniqueID:      uuid.New().String(),
 	}
 
@@ -357,9 +373,11 @@ func (cm *CollectionManager) DropScope(scopeName string, opts *DropScopeOptions)
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := cm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategy := cm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = cm.globalTimeout
 	}
 
 	req := &gocbcore.HTTPRequest{
@@ -367,6 +385,7 @@ func (cm *CollectionManager) DropScope(scopeName string, opts *DropScopeOptions)
 		Path:          fmt.Sprintf("/pools/default/buckets/%s/collections/%s", cm.bucketName, scopeName),
 		Method:        "DELETE",
 		RetryStrategy: retryStrategy,
+		Timeout:       timeout,
 		UniqueID:      uuid.New().String(),
 	}
 
@@ -1,8 +1,12 @@
 package gocb
 
 import (
+	"bytes"
 	"errors"
 	"testing"
+	"time"
+
+	"github.com/couchbase/gocbcore/v8"
 )
 
 func TestCollectionManagerCrud(t *testing.T) {
@@ -60,3 +64,44 @@ func TestCollectionManagerCrud(t *testing.T) {
 		t.Fatalf("Expected DropScope to not error but was %v", err)
 	}
 }
+
+func TestCollectionManagerGetAllScopesManifestFormats(t *testing.T) {
+	manifests := []string{
+		`{"uid":"1","scopes":[{"name":"inventory","uid":"8","collections":[{"name":"airline","uid":"9"}]}]}`,
+		`{"uid":1,"scopes":{"inventory":{"uid":8,"collections":{"airline":{"uid":9}}}}}`,
+	}
+
+	for _, manifest := range manifests {
+		var timeout time.Duration
+		provider := &mockHTTPProvider{
+			doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+				timeout = req.Timeout
+				return &gocbcore.HTTPResponse{
+					StatusCode: 200,
+					Body:       &testReadCloser{bytes.NewBufferString(manifest), nil},
+				}, nil
+			},
+		}
+
+		mgr := &CollectionManager{
+			httpClient:    provider,
+			bucketName:    "travel",
+			globalTimeout: 10 * time.Second,
+			tracer:        &noopTracer{},
+		}
+
+		scopes, err := mgr.GetAllScopes(nil)
+		if err != nil {
+			t.Fatalf("Failed to get scopes from %s: %v", manifest, err)
+		}
+
+		if len(scopes) != 1 || scopes[0].Name != "inventory" || len(scopes[0].Collections) != 1 ||
+			scopes[0].Collections[0].Name != "airline" || scopes[0].Collections[0].ScopeName != "inventory" {
+			t.Fatalf("Unexpected scopes from %s: %+v", manifest, scopes)
+		}
+
+		if timeout != 10*time.Second {
+			t.Fatalf("Expected the management timeout to be used but was %s", timeout)
+		}
+	}
+}
@@ -93,13 +93,10 @@ func (b *Bucket) pingKv(provider kvProvider) (pingsOut *gocbcore.PingKvResult, e
 		return nil, err
 	}
 
-	timeoutTmr := gocbcore.AcquireTimer(b.sb.KvTimeout)
 	select {
 	case <-signal:
-		gocbcore.ReleaseTimer(timeoutTmr, false)
 		return
-	case <-timeoutTmr.C:
-		gocbcore.ReleaseTimer(timeoutTmr, true)
+	case <-clockOrSystem(b.sb.Clock).After(b.sb.kvTimeout()):
 		op.Cancel(ErrAmbiguousTimeout)
 		<-signal
 		return
@@ -143,7 +140,8 @@ func (b *Bucket) Ping(opts *PingOptions) (*PingResult, error) {
 	}
 
 	httpReq := func(service ServiceType, url string) (time.Duration, string, error) {
-		startTime := time.Now()
+		clk := clockOrSystem(b.sb.Clock)
+		startTime := clk.Now()
 
 		cli := b.sb.getCachedClient()
 		provider, err := cli.getHTTPProvider()
@@ -153,11 +151,11 @@ func (b *Bucket) Ping(opts *PingOptions) (*PingResult, error) {
 
 		timeout := 60 * time.Second
 		if service == ServiceTypeQuery {
-			timeout = b.sb.QueryTimeout
+			timeout = b.sb.queryTimeout()
 		} else if service == ServiceTypeSearch {
-			timeout = b.sb.SearchTimeout
+			timeout = b.sb.searchTimeout()
 		} else if service == ServiceTypeAnalytics {
-			timeout = b.sb.AnalyticsTimeout
+			timeout = b.sb.analyticsTimeout()
 		}
 
 		req := gocbcore.HTTPRequest{
@@ -177,7 +175,7 @@ func (b *Bucket) Ping(opts *PingOptions) (*PingResult, error) {
 			logDebugf("Failed to close http request: %s", err)
 		}
 
-		pingLatency := time.Now().Sub(startTime)
+		pingLatency := clk.Now().Sub(startTime)
 
 		return pingLatency, req.Endpoint, err
 	}
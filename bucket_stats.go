@@ -0,0 +1,97 @@
+package gocb
+
+import (
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// StatsOptions are the options available to the Stats operation.
+type StatsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// ServerStats contains the statistics returned by a single node.
+type ServerStats struct {
+	Stats map[string]string
+
+	// Error is set if the statistics could not be retrieved from this node.
+	Error error
+}
+
+// StatsResult contains the statistics returned by each node, keyed by the address of the node.
+type StatsResult struct {
+	Servers map[string]ServerStats
+}
+
+// Stats issues the memcached STATS command for the given key group (for example "" for the
+// general statistics, or "timings") against every node hosting the bucket.  There are no
+// guarantees about the consistency of the results across nodes, and a node may be missing
+// from the results if it could not be reached.
+// UNCOMMITTED: This API may change in the future.
+func (b *Bucket) Stats(key string, opts *StatsOptions) (*StatsResult, error) {
+	if opts == nil {
+		opts = &StatsOptions{}
+	}
+
+	provider, err := b.sb.getCachedClient().getKvProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	span := b.sb.Tracer.StartSpan("Stats", nil).
+		SetTag("couchbase.service", "kv")
+	defer span.Finish()
+
+	retryWrapper := b.sb.RetryStrategyWrapper.withStrategy(opts.RetryStrategy)
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = b.sb.kvTimeout()
+	}
+
+	var resultOut *StatsResult
+	var errOut error
+	signal := make(chan bool, 1)
+
+	op, err := provider.StatsEx(gocbcore.StatsOptions{
+		Key:           key,
+		RetryStrategy: retryWrapper,
+		TraceContext:  span.Context(),
+	}, func(result *gocbcore.StatsResult, err error) {
+		if err != nil {
+			errOut = maybeEnhanceKVErr(err, b.Name(), "", "", "")
+			signal <- true
+			return
+		}
+
+		resultOut = &StatsResult{
+			Servers: make(map[string]ServerStats, len(result.Servers)),
+		}
+		for address, server := range result.Servers {
+			var serverErr error
+			if server.Error != nil {
+				serverErr = maybeEnhanceKVErr(server.Error, b.Name(), "", "", "")
+			}
+
+			resultOut.Servers[address] = ServerStats{
+				Stats: server.Stats,
+				Error: serverErr,
+			}
+		}
+		signal <- true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-signal:
+	case <-clockOrSystem(b.sb.Clock).After(timeout):
+		op.Cancel(ErrAmbiguousTimeout)
+		<-signal
+	}
+
+	return resultOut, errOut
+}
@@ -0,0 +1,51 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestBucketStats(t *testing.T) {
+	statsResult := &gocbcore.StatsResult{
+		Servers: map[string]gocbcore.SingleServerStats{
+			"10.0.0.1:11210": {
+				Stats: map[string]string{"get_hits": "10", "get_misses": "2"},
+			},
+			"10.0.0.2:11210": {
+				Error: gocbcore.ErrTimeout,
+			},
+		},
+	}
+
+	b := &Bucket{
+		sb: stateBlock{
+			clientStateBlock: clientStateBlock{
+				BucketName: "mock",
+			},
+			KvTimeout: time.Second,
+			Tracer:    &noopTracer{},
+		},
+	}
+	b.cacheClient(&mockClient{bucketName: "mock", mockKvProvider: &mockKvProvider{value: statsResult}})
+
+	result, err := b.Stats("", nil)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+
+	if len(result.Servers) != 2 {
+		t.Fatalf("Expected 2 servers but was %d", len(result.Servers))
+	}
+
+	server := result.Servers["10.0.0.1:11210"]
+	if server.Error != nil || server.Stats["get_hits"] != "10" || server.Stats["get_misses"] != "2" {
+		t.Fatalf("Unexpected server stats %+v", server)
+	}
+
+	if !errors.Is(result.Servers["10.0.0.2:11210"].Error, ErrTimeout) {
+		t.Fatalf("Expected timeout error but was %v", result.Servers["10.0.0.2:11210"].Error)
+	}
+}
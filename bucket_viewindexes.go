@@ -96,7 +96,7 @@ func (dd *DesignDocument) toData() (jsonDesignDocument, string, error) {
 type ViewIndexManager struct {
 	bucket *Bucket
 
-	tracer requestTracer
+	tracer RequestTracer
 }
 
 func (vm *ViewIndexManager) doMgmtRequest(req mgmtRequest) (*mgmtResponse, error) {
@@ -116,9 +116,7 @@ type GetDesignDocumentOptions struct {
 
 func (vm *ViewIndexManager) ddocName(name string, namespace DesignDocumentNamespace) string {
 	if namespace == DesignDocumentNamespaceProduction {
-		if strings.HasPrefix(name, "dev_") {
-			name = strings.TrimLeft(name, "dev_")
-		}
+		name = strings.TrimPrefix(name, "dev_")
 	} else {
 		if !strings.HasPrefix(name, "dev_") {
 			name = "dev_" + name
@@ -137,10 +135,10 @@ func (vm *ViewIndexManager) GetDesignDocument(name string, namespace DesignDocum
 	span := vm.tracer.StartSpan("GetDesignDocument", nil).SetTag("couchbase.service", "view")
 	defer span.Finish()
 
-	return vm.getDesignDocument(span.Context(), name, namespace, time.Now(), opts)
+	return vm.getDesignDocument(span.Context(), name, namespace, clockOrSystem(vm.bucket.sb.Clock).Now(), opts)
 }
 
-func (vm *ViewIndexManager) getDesignDocument(tracectx requestSpanContext, name string, namespace DesignDocumentNamespace,
+func (vm *ViewIndexManager) getDesignDocument(tracectx RequestSpanContext, name string, namespace DesignDocumentNamespace,
 	startTime time.Time, opts *GetDesignDocumentOptions) (*DesignDocument, error) {
 
 	name = vm.ddocName(name, namespace)
@@ -242,14 +240,21 @@ func (vm *ViewIndexManager) GetAllDesignDocuments(namespace DesignDocumentNamesp
 		logDebugf("Failed to close socket (%s)", err)
 	}
 
-	ddocs := make([]DesignDocument, len(ddocsResp.Rows))
-	for ddocIdx, ddocData := range ddocsResp.Rows {
-		ddocName := strings.TrimPrefix(ddocData.Doc.Meta.ID[8:], "dev_")
+	// The server returns the design documents of both namespaces.
+	ddocs := make([]DesignDocument, 0, len(ddocsResp.Rows))
+	for _, ddocData := range ddocsResp.Rows {
+		ddocName := strings.TrimPrefix(ddocData.Doc.Meta.ID, "_design/")
+		isDevelopment := strings.HasPrefix(ddocName, "dev_")
+		if isDevelopment != (namespace == DesignDocumentNamespaceDevelopment) {
+			continue
+		}
 
-		err := ddocs[ddocIdx].fromData(ddocData.Doc.JSON, ddocName)
+		var ddoc DesignDocument
+		err := ddoc.fromData(ddocData.Doc.JSON, strings.TrimPrefix(ddocName, "dev_"))
 		if err != nil {
 			return nil, err
 		}
+		ddocs = append(ddocs, ddoc)
 	}
 
 	return ddocs, nil
@@ -271,11 +276,11 @@ func (vm *ViewIndexManager) UpsertDesignDocument(ddoc DesignDocument, namespace
 	span := vm.tracer.StartSpan("UpsertDesignDocument", nil).SetTag("couchbase.service", "view")
 	defer span.Finish()
 
-	return vm.upsertDesignDocument(span.Context(), ddoc, namespace, time.Now(), opts)
+	return vm.upsertDesignDocument(span.Context(), ddoc, namespace, clockOrSystem(vm.bucket.sb.Clock).Now(), opts)
 }
 
 func (vm *ViewIndexManager) upsertDesignDocument(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	ddoc DesignDocument,
 	namespace DesignDocumentNamespace,
 	startTime time.Time,
@@ -330,10 +335,10 @@ func (vm *ViewIndexManager) DropDesignDocument(name string, namespace DesignDocu
 	span := vm.tracer.StartSpan("DropDesignDocument", nil).SetTag("couchbase.service", "view")
 	defer span.Finish()
 
-	return vm.dropDesignDocument(span.Context(), name, namespace, time.Now(), opts)
+	return vm.dropDesignDocument(span.Context(), name, namespace, clockOrSystem(vm.bucket.sb.Clock).Now(), opts)
 }
 
-func (vm *ViewIndexManager) dropDesignDocument(tracectx requestSpanContext, name string, namespace DesignDocumentNamespace,
+func (vm *ViewIndexManager) dropDesignDocument(tracectx RequestSpanContext, name string, namespace DesignDocumentNamespace,
 	startTime time.Time, opts *DropDesignDocumentOptions) error {
 
 	name = vm.ddocName(name, namespace)
@@ -369,7 +374,8 @@ type PublishDesignDocumentOptions struct {
 
 // PublishDesignDocument publishes a design document to the given bucket.
 func (vm *ViewIndexManager) PublishDesignDocument(name string, opts *PublishDesignDocumentOptions) error {
-	startTime := time.Now()
+	clk := clockOrSystem(vm.bucket.sb.Clock)
+	startTime := clk.Now()
 	if opts == nil {
 		opts = &PublishDesignDocumentOptions{}
 	}
@@ -378,23 +384,37 @@ func (vm *ViewIndexManager) PublishDesignDocument(name string, opts *PublishDesi
 		SetTag("couchbase.service", "view")
 	defer span.Finish()
 
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = vm.bucket.sb.managementTimeout()
+	}
+
 	devdoc, err := vm.getDesignDocument(
 		span.Context(),
 		name,
 		DesignDocumentNamespaceDevelopment,
 		startTime,
 		&GetDesignDocumentOptions{
+			Timeout:       timeout,
 			RetryStrategy: opts.RetryStrategy,
 		})
 	if err != nil {
 		return err
 	}
 
+	// The upsert only receives what remains of the timeout, so that publishing as a whole
+	// does not exceed it.
+	remaining := timeout - clk.Now().Sub(startTime)
+	if remaining <= 0 {
+		return ErrUnambiguousTimeout
+	}
+
 	err = vm.upsertDesignDocument(span.Context(),
 		*devdoc,
 		DesignDocumentNamespaceProduction,
 		startTime,
 		&UpsertDesignDocumentOptions{
+			Timeout:       remaining,
 			RetryStrategy: opts.RetryStrategy,
 		})
 	if err != nil {
@@ -0,0 +1,65 @@
+package gocb
+
+import (
+	"bytes"
+	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestViewIndexManagerDdocName(t *testing.T) {
+	vm := &ViewIndexManager{}
+
+	tests := []struct {
+		name      string
+		namespace DesignDocumentNamespace
+		expected  string
+	}{
+		{"event", DesignDocumentNamespaceProduction, "event"},
+		{"dev_event", DesignDocumentNamespaceProduction, "event"},
+		{"devices", DesignDocumentNamespaceProduction, "devices"},
+		{"event", DesignDocumentNamespaceDevelopment, "dev_event"},
+		{"dev_event", DesignDocumentNamespaceDevelopment, "dev_event"},
+	}
+	for _, test := range tests {
+		actual := vm.ddocName(test.name, test.namespace)
+		if actual != test.expected {
+			t.Errorf("Expected %s to be %s but was %s", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestViewIndexManagerGetAllDesignDocumentsNamespace(t *testing.T) {
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body: &testReadCloser{bytes.NewBufferString(`{"rows":[
+					{"doc":{"meta":{"id":"_design/dev_one"},"json":{"views":{}}}},
+					{"doc":{"meta":{"id":"_design/two"},"json":{"views":{}}}},
+					{"doc":{"meta":{"id":"_design/devices"},"json":{"views":{}}}}
+				]}`), nil},
+			}, nil
+		},
+	}
+
+	b := &Bucket{}
+	b.sb.Tracer = &noopTracer{}
+	b.cacheClient(&mockClient{bucketName: "mock", mockHTTPProvider: provider})
+
+	ddocs, err := b.ViewIndexes().GetAllDesignDocuments(DesignDocumentNamespaceDevelopment, nil)
+	if err != nil {
+		t.Fatalf("Expected GetAllDesignDocuments to succeed but was %v", err)
+	}
+	if len(ddocs) != 1 || ddocs[0].Name != "one" {
+		t.Fatalf("Expected only the development design document but was %v", ddocs)
+	}
+
+	ddocs, err = b.ViewIndexes().GetAllDesignDocuments(DesignDocumentNamespaceProduction, nil)
+	if err != nil {
+		t.Fatalf("Expected GetAllDesignDocuments to succeed but was %v", err)
+	}
+	if len(ddocs) != 2 || ddocs[0].Name != "two" || ddocs[1].Name != "devices" {
+		t.Fatalf("Expected only the production design documents but was %v", ddocs)
+	}
+}
@@ -3,6 +3,7 @@ package gocb
 import (
 	"encoding/json"
 	"net/url"
+	"reflect"
 	"strings"
 	"time"
 
@@ -51,16 +52,77 @@ func (vr *ViewRow) Value(valuePtr interface{}) error {
 	return json.Unmarshal(vr.valueBytes, valuePtr)
 }
 
+// ViewStats is the value of a row produced by the built-in _stats reduce function.
+type ViewStats struct {
+	Sum    float64 `json:"sum"`
+	Count  uint64  `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	SumSqr float64 `json:"sumsqr"`
+}
+
+// ReduceCount returns the value of a row produced by the built-in _count reduce function.
+func (vr *ViewRow) ReduceCount() (uint64, error) {
+	var count uint64
+	if err := vr.Value(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ReduceSum returns the value of a row produced by the built-in _sum reduce function.
+func (vr *ViewRow) ReduceSum() (float64, error) {
+	var sum float64
+	if err := vr.Value(&sum); err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
+
+// ReduceStats returns the value of a row produced by the built-in _stats reduce function.
+func (vr *ViewRow) ReduceStats() (*ViewStats, error) {
+	var stats ViewStats
+	if err := vr.Value(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GroupKey returns the parts of the key of a row produced by a grouped reduce.  When GroupLevel
+// is set, each row's key holds as many parts of the emitted array key as the group level.  A key
+// which is not an array is returned as a single part.
+func (vr *ViewRow) GroupKey() ([]interface{}, error) {
+	var key interface{}
+	if err := vr.Key(&key); err != nil {
+		return nil, err
+	}
+
+	if parts, ok := key.([]interface{}); ok {
+		return parts, nil
+	}
+	return []interface{}{key}, nil
+}
+
+// ViewGroup is a run of consecutive rows of a view result whose keys share the same leading parts.
+type ViewGroup struct {
+	// Key is the leading parts of the key which every row of the group shares.
+	Key  []interface{}
+	Rows []ViewRow
+}
+
 // ViewResult implements an iterator interface which can be used to iterate over the rows of the query results.
 type ViewResult struct {
-	reader *gocbcore.ViewQueryRowReader
+	reader rowReader
 
-	currentRow ViewRow
+	currentRow   ViewRow
+	currentGroup ViewGroup
+	pendingRow   *ViewRow
+	decodeErr    error
 }
 
-func newViewResult(reader *gocbcore.ViewQueryRowReader) (*ViewResult, error) {
+func newViewResult(reader rowReader) (*ViewResult, error) {
 	return &ViewResult{
-		reader: reader,
+		reader: newCloseOnceRowReader(reader),
 	}, nil
 }
 
@@ -88,12 +150,66 @@ func (r *ViewResult) Row() ViewRow {
 	return r.currentRow
 }
 
-// Err returns any errors that have occurred on the stream
+// NextGroup reads the next group of rows whose keys, as returned by GroupKey, share their first
+// level parts, returning whether a group was read.  Rows are ordered by key, so the rows of a group
+// are adjacent.  A level of zero groups rows by their whole key.  Rows whose keys cannot be decoded
+// are skipped, and the error is returned by Err.  NextGroup should not be mixed with Next on the
+// same result.
+func (r *ViewResult) NextGroup(level int) bool {
+	var group ViewGroup
+	for {
+		var row ViewRow
+		if r.pendingRow != nil {
+			row = *r.pendingRow
+			r.pendingRow = nil
+		} else if r.Next() {
+			row = r.currentRow
+		} else {
+			break
+		}
+
+		key, err := row.GroupKey()
+		if err != nil {
+			if r.decodeErr == nil {
+				r.decodeErr = err
+			}
+			continue
+		}
+		if level > 0 && level < len(key) {
+			key = key[:level]
+		}
+
+		// The row which starts the next group is held until the next call.
+		if len(group.Rows) > 0 && !reflect.DeepEqual(key, group.Key) {
+			r.pendingRow = &row
+			break
+		}
+
+		group.Key = key
+		group.Rows = append(group.Rows, row)
+	}
+
+	r.currentGroup = group
+	return len(group.Rows) > 0
+}
+
+// Group returns the group of rows read by the last call to NextGroup.
+func (r *ViewResult) Group() ViewGroup {
+	return r.currentGroup
+}
+
+// Err returns any errors that have occurred on the stream, or the first row key which NextGroup
+// could not decode.
 func (r *ViewResult) Err() error {
-	return r.reader.Err()
+	if err := r.reader.Err(); err != nil {
+		return err
+	}
+	return r.decodeErr
 }
 
 // Close marks the results as closed, returning any errors that occurred during reading the results.
+// Closing releases the underlying connection even if rows remain unread.  It is safe to call Close
+// more than once, and after an error has occurred, in which case the same result is returned each time.
 func (r *ViewResult) Close() error {
 	return r.reader.Close()
 }
@@ -123,26 +239,35 @@ func (r *ViewResult) MetaData() (*ViewMetaData, error) {
 }
 
 // ViewQuery performs a view query and returns a list of rows or an error.
-func (b *Bucket) ViewQuery(designDoc string, viewName string, opts *ViewOptions) (*ViewResult, error) {
+func (b *Bucket) ViewQuery(designDoc string, viewName string, opts *ViewOptions) (resOut *ViewResult, errOut error) {
 	if opts == nil {
 		opts = &ViewOptions{}
 	}
 
-	span := b.sb.Tracer.StartSpan("ViewQuery", opts.parentSpan).
+	span := b.sb.Tracer.StartSpan("ViewQuery", opts.ParentSpan).
 		SetTag("couchbase.service", "view")
+	span = applyOperationTags(span, opts.Tags)
 	defer span.Finish()
+	defer func() {
+		setSpanOutcome(span, errOut)
+	}()
 
 	designDoc = b.maybePrefixDevDocument(opts.Namespace, designDoc)
 
-	timeout := b.sb.ViewTimeout
+	timeout := b.sb.viewTimeout()
 	if opts.Timeout != 0 && opts.Timeout < timeout {
 		timeout = opts.Timeout
 	}
-	deadline := time.Now().Add(timeout)
+	deadline := contextDeadline(opts.Context, clockOrSystem(b.sb.Clock).Now().Add(timeout))
 
-	retryWrapper := b.sb.RetryStrategyWrapper
-	if opts.RetryStrategy != nil {
-		retryWrapper = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryWrapper := b.sb.RetryStrategyWrapper.withStrategy(opts.RetryStrategy)
+
+	if opts.Context != nil && opts.Context.Err() != nil {
+		return nil, ViewError{
+			InnerError:         opts.Context.Err(),
+			DesignDocumentName: designDoc,
+			ViewName:           viewName,
+		}
 	}
 
 	urlValues, err := opts.toURLValues()
@@ -150,11 +275,28 @@ func (b *Bucket) ViewQuery(designDoc string, viewName string, opts *ViewOptions)
 		return nil, errors.Wrap(err, "could not parse query options")
 	}
 
-	return b.execViewQuery(span.Context(), "_view", designDoc, viewName, *urlValues, deadline, retryWrapper)
+	releaseLimit, err := b.sb.HTTPLimiter.Acquire(deadline)
+	if err != nil {
+		return nil, ViewError{
+			InnerError:         err,
+			DesignDocumentName: designDoc,
+			ViewName:           viewName,
+		}
+	}
+
+	res, err := b.execViewQuery(span.Context(), "_view", designDoc, viewName, *urlValues, deadline, retryWrapper)
+	if err != nil {
+		releaseLimit()
+		return nil, err
+	}
+
+	res.reader = newLimitedRowReader(newCtxRowReader(opts.Context, b.sb.Workers, res.reader, nil), releaseLimit)
+
+	return res, nil
 }
 
 func (b *Bucket) execViewQuery(
-	span requestSpanContext,
+	span RequestSpanContext,
 	viewType, ddoc, viewName string,
 	options url.Values,
 	deadline time.Time,
@@ -179,6 +321,7 @@ func (b *Bucket) execViewQuery(
 		Deadline:           deadline,
 	})
 	if err != nil {
+		b.sb.Telemetry.recordError("views", err)
 		return nil, maybeEnhanceViewError(err)
 	}
 
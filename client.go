@@ -19,6 +19,8 @@ type client interface {
 	getSearchProvider() (searchProvider, error)
 	getHTTPProvider() (httpProvider, error)
 	getDiagnosticsProvider() (diagnosticsProvider, error)
+	getConfigSnapshotProvider() (configSnapshotProvider, error)
+	openDcpProvider(bucketName, streamName string, flags gocbcore.DcpOpenFlag) (dcpProvider, error)
 	close() error
 	setBootstrapError(err error)
 	selectBucket(bucketName string) error
@@ -64,7 +66,7 @@ func (c *stdClient) buildConfig() error {
 	}
 
 	config := &gocbcore.AgentConfig{
-		UserAgent:              Identifier(),
+		UserAgent:              c.cluster.sb.UserAgent,
 		ConnectTimeout:         c.cluster.sb.ConnectTimeout,
 		UseMutationTokens:      c.cluster.sb.UseMutationTokens,
 		KVConnectTimeout:       7000 * time.Millisecond,
@@ -195,12 +197,44 @@ func (c *stdClient) getDiagnosticsProvider() (diagnosticsProvider, error) {
 	return c.agent, nil
 }
 
+func (c *stdClient) getConfigSnapshotProvider() (configSnapshotProvider, error) {
+	if c.bootstrapErr != nil {
+		return nil, c.bootstrapErr
+	}
+
+	if c.agent == nil {
+		return nil, errors.New("cluster not yet connected")
+	}
+	return c.agent, nil
+}
+
+// openDcpProvider creates a new agent, with its own connections, for streaming changes
+// from the specified bucket.  The caller is responsible for closing the agent.
+func (c *stdClient) openDcpProvider(bucketName, streamName string, flags gocbcore.DcpOpenFlag) (dcpProvider, error) {
+	c.lock.Lock()
+	if c.config == nil {
+		c.lock.Unlock()
+		return nil, errors.New("cluster not yet connected")
+	}
+	config := *c.config
+	c.lock.Unlock()
+
+	config.BucketName = bucketName
+
+	agent, err := gocbcore.CreateDcpAgent(&config, streamName, flags)
+	if err != nil {
+		return nil, maybeEnhanceKVErr(err, bucketName, "", "", "")
+	}
+
+	return agent, nil
+}
+
 func (c *stdClient) connected() bool {
 	return c.isConnected
 }
 
 func (c *stdClient) selectBucket(bucketName string) error {
-	return c.agent.SelectBucket(bucketName, time.Now().Add(c.cluster.sb.ConnectTimeout))
+	return c.agent.SelectBucket(bucketName, clockOrSystem(c.cluster.sb.Clock).Now().Add(c.cluster.sb.ConnectTimeout))
 }
 
 func (c *stdClient) supportsGCCCP() bool {
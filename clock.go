@@ -0,0 +1,50 @@
+package gocb
+
+import "time"
+
+// clock provides the current time and timers, so that tests can control the passage of time
+// rather than sleeping.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// afterFunc calls f in its own goroutine once d has elapsed on clk, unless the returned stop
+// function is called first.
+func afterFunc(clk clock, d time.Duration, f func()) (stop func()) {
+	stopCh := make(chan struct{})
+	go func() {
+		select {
+		case <-clk.After(d):
+			f()
+		case <-stopCh:
+		}
+	}()
+
+	return func() {
+		close(stopCh)
+	}
+}
+
+// clockOrSystem returns c, or the system clock if c is nil.
+func clockOrSystem(c clock) clock {
+	if c == nil {
+		return systemClock{}
+	}
+	return c
+}
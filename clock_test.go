@@ -0,0 +1,140 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKvTimeoutUsesClock(t *testing.T) {
+	clk := newFakeClock()
+	col := testGetCollection(t, &mockKvProvider{opWait: time.Hour})
+	col.sb.Clock = clk
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := col.Get("doc", &GetOptions{Timeout: time.Second})
+		errCh <- err
+	}()
+
+	clk.waitForWaiters(1)
+	clk.Advance(999 * time.Millisecond)
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("Expected Get to still be waiting but was %v", err)
+	default:
+	}
+
+	clk.Advance(time.Millisecond)
+
+	err := <-errCh
+	if !errors.Is(err, ErrAmbiguousTimeout) {
+		t.Fatalf("Expected ambiguous timeout but was %v", err)
+	}
+}
+
+func TestThresholdLogSpanUsesClock(t *testing.T) {
+	clk := newFakeClock()
+	tracer := newThresholdLoggingTracer(nil)
+	tracer.clock = clk
+
+	span := tracer.StartSpan("Get", nil)
+	clk.Advance(750 * time.Millisecond)
+	span.Finish()
+
+	if duration := span.(*thresholdLogSpan).duration; duration != 750*time.Millisecond {
+		t.Fatalf("Expected duration of 750ms but was %s", duration)
+	}
+}
+
+func TestMutexLockBackoffUsesClock(t *testing.T) {
+	clk := newFakeClock()
+	col := testGetCollection(t, &mockKvProvider{err: ErrDocumentExists})
+	col.sb.Clock = clk
+
+	start := clk.Now()
+	_, err := col.Mutex("mutex").Lock(10*time.Second, &MutexLockOptions{
+		WaitTimeout: time.Minute,
+	})
+	if !errors.Is(err, ErrMutexLocked) {
+		t.Fatalf("Expected mutex to be locked but error was %v", err)
+	}
+
+	if waited := clk.Now().Sub(start); waited != time.Minute {
+		t.Fatalf("Expected Lock to back off for the whole wait timeout but was %s", waited)
+	}
+}
+
+func TestQueryTimeoutUsesClock(t *testing.T) {
+	clk := newFakeClock()
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	c := testGetQueryCluster(provider)
+	c.sb.Tracer = &noopTracer{}
+	c.sb.Serializer = NewDefaultJSONSerializer()
+	c.sb.QueryTimeout = 5 * time.Second
+	c.sb.Clock = clk
+
+	_, _ = c.Query("SELECT 1", &QueryOptions{Adhoc: true})
+
+	if len(provider.payloads) != 1 {
+		t.Fatalf("Expected 1 request but was %d", len(provider.payloads))
+	}
+	var payload map[string]interface{}
+	err := json.Unmarshal(provider.payloads[0], &payload)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	// No time passes on the clock so the whole timeout remains when the request is sent.
+	if payload["timeout"] != "5s" {
+		t.Fatalf("Expected server timeout of 5s but was %v", payload["timeout"])
+	}
+}
+
+func TestOpLimiterUsesClock(t *testing.T) {
+	clk := newFakeClock()
+	limiter := newOpLimiter(clk, 1, 0)
+
+	release, err := limiter.Acquire(clk.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Expected first acquire to succeed but was %v", err)
+	}
+	defer release()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := limiter.Acquire(clk.Now().Add(time.Second))
+		errCh <- err
+	}()
+
+	clk.waitForWaiters(1)
+	clk.Advance(time.Second)
+
+	err = <-errCh
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout but was %v", err)
+	}
+}
+
+func TestTelemetryReporterUsesClock(t *testing.T) {
+	clk := newFakeClock()
+	sink := &testTelemetrySink{}
+	reporter := newTelemetryReporter(TelemetryConfig{Sink: sink, Interval: time.Minute}, clk)
+	reporter.start()
+	defer reporter.stop()
+
+	reporter.recordError("kv", ErrDocumentNotFound)
+	clk.waitForWaiters(1)
+	clk.Advance(time.Minute)
+
+	for len(sink.decodeReports(t)) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	report := sink.decodeReports(t)[0]
+	if report.IntervalMs != time.Minute.Milliseconds() || !report.Timestamp.Equal(clk.Now()) {
+		t.Fatalf("Expected report to be timed by the clock but was %v after %dms", report.Timestamp, report.IntervalMs)
+	}
+}
@@ -2,7 +2,9 @@ package gocb
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,6 +16,9 @@ import (
 
 // Cluster represents a connection to a specific Couchbase cluster.
 type Cluster struct {
+	// queryCacheStats is accessed atomically so must remain 64-bit aligned.
+	queryCacheStats queryCacheCounters
+
 	cSpec gocbconnstr.ConnSpec
 	auth  Authenticator
 
@@ -68,12 +73,22 @@ type ClusterOptions struct {
 	// Transcoder is used for trancoding data used in KV operations.
 	Transcoder Transcoder
 
+	// Serializer is used for serializing JSON values which are not handled by the
+	// Transcoder, such as query and analytics parameters.
+	Serializer JSONSerializer
+
 	// RetryStrategy is used to automatically retry operations if they fail.
 	RetryStrategy RetryStrategy
 
 	// Tracer specifies the tracer to use for requests.
 	// VOLATILE: This API is subject to change at any time.
-	Tracer requestTracer
+	Tracer RequestTracer
+
+	// Meter, if set, records the latency, number and outcome of operations performed, by service
+	// and operation, and the number of retries.  NewAggregatingMeter provides a Meter which
+	// periodically summarises them, and NewPrometheusMeter one which Prometheus can scrape.
+	// UNCOMMITTED: This API may change in the future.
+	Meter Meter
 
 	// OrphanReporterConfig specifies options for the orphan reporter.
 	OrphanReporterConfig OrphanReporterConfig
@@ -83,11 +98,65 @@ type ClusterOptions struct {
 
 	// IoConfig specifies IO related configuration options.
 	IoConfig IoConfig
+
+	// OperationLimitsConfig specifies limits on the number of outstanding operations.
+	OperationLimitsConfig OperationLimitsConfig
+
+	// TelemetryConfig specifies options for the telemetry report.
+	TelemetryConfig TelemetryConfig
+
+	// DefaultQueryContext, if set, is sent with every query performed by Cluster.Query so
+	// that statements can refer to collections without qualifying them with their bucket and
+	// scope.  It can be overridden for a single query by setting "query_context" in
+	// QueryOptions.Raw.
+	// UNCOMMITTED: This API may change in the future.
+	DefaultQueryContext QueryContext
+
+	// ReadOnly causes every operation which would modify data, indexes or cluster settings to
+	// fail with a ReadOnlyError, without being sent.  This includes KV operations which only
+	// modify the expiry or lock state of a document, and management requests which are not
+	// reads, such as SearchIndexManager.AnalyzeDocument.  Queries and analytics queries are
+	// additionally sent with the readonly option so that the server rejects any statement
+	// which modifies data.
+	// UNCOMMITTED: This API may change in the future.
+	ReadOnly bool
+
+	// StrictConnectionString causes Connect to fail if the connection string contains options
+	// which are not recognised, rather than only logging a warning about them.
+	// UNCOMMITTED: This API may change in the future.
+	StrictConnectionString bool
+
+	// EnableDeveloperPreview allows the use of APIs which depend on server features that are only
+	// available once developer preview mode has been enabled on the cluster, such as
+	// Scope.Query and Scope.SearchQuery.  Without it these APIs fail with a
+	// DeveloperPreviewError, without being sent, rather than returning whatever error the
+	// server responds with.  Cluster.IsDeveloperPreview reports whether the cluster itself is in
+	// developer preview mode.
+	// UNCOMMITTED: This API may change in the future.
+	EnableDeveloperPreview bool
+
+	// ApplicationName and ApplicationVersion identify the application using the SDK.  They are
+	// appended to the user agent which is sent to the server when connecting to the data service
+	// and with every HTTP request, so that server logs can attribute connections to it.  Neither
+	// may contain whitespace.
+	// UNCOMMITTED: This API may change in the future.
+	ApplicationName    string
+	ApplicationVersion string
 }
 
 // ClusterCloseOptions is the set of options available when
 // disconnecting from a Cluster.
 type ClusterCloseOptions struct {
+	// Timeout is the maximum amount of time to wait for all clients to shut down
+	// gracefully.  The default of zero waits for as long as shutdown takes.
+	Timeout time.Duration
+
+	// AbandonOnTimeout causes clients which have not shut down once Timeout has elapsed
+	// to be abandoned rather than reported as failures.  Abandoned clients are not hard
+	// closed, as the underlying agent offers no way to interrupt its shutdown.  Any
+	// operations still outstanding on them have already been failed, and their
+	// connections continue to be torn down in the background.
+	AbandonOnTimeout bool
 }
 
 // Connect creates and returns a Cluster instance created using the
@@ -102,6 +171,11 @@ func Connect(connStr string, opts ClusterOptions) (*Cluster, error) {
 		return nil, errors.New("http scheme is not supported, use couchbase or couchbases instead")
 	}
 
+	err = checkConnStrOptions(connSpec, opts.StrictConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
 	connectTimeout := 10000 * time.Millisecond
 	kvTimeout := 2500 * time.Millisecond
 	viewTimeout := 75000 * time.Millisecond
@@ -133,10 +207,22 @@ func Connect(connStr string, opts ClusterOptions) (*Cluster, error) {
 	if opts.Transcoder == nil {
 		opts.Transcoder = NewJSONTranscoder()
 	}
+	if opts.Serializer == nil {
+		opts.Serializer = NewDefaultJSONSerializer()
+	}
 	if opts.RetryStrategy == nil {
 		opts.RetryStrategy = NewBestEffortRetryStrategy(nil)
 	}
 
+	if err := opts.DefaultQueryContext.validate(); err != nil {
+		return nil, err
+	}
+
+	userAgent, err := buildUserAgent(opts.ApplicationName, opts.ApplicationVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	useMutationTokens := true
 	useServerDurations := true
 	if opts.IoConfig.DisableMutationTokens {
@@ -146,14 +232,31 @@ func Connect(connStr string, opts ClusterOptions) (*Cluster, error) {
 		useServerDurations = false
 	}
 
-	var initialTracer requestTracer
+	// The tracers share the clock of the cluster so that spans are timed consistently with
+	// the deadlines of the operations which they measure.
+	var clk clock = systemClock{}
+
+	telemetry := newTelemetryReporter(opts.TelemetryConfig, clk)
+
+	var initialTracer RequestTracer
 	if opts.Tracer != nil {
 		initialTracer = opts.Tracer
 	} else {
-		initialTracer = newThresholdLoggingTracer(nil)
+		thresholdTracer := newThresholdLoggingTracer(nil)
+		thresholdTracer.reporter = telemetry
+		thresholdTracer.clock = clk
+		initialTracer = thresholdTracer
+	}
+	if opts.Meter != nil {
+		meteringTracer := newMeteringTracer(initialTracer, opts.Meter)
+		meteringTracer.clock = clk
+		initialTracer = meteringTracer
 	}
 	tracerAddRef(initialTracer)
 
+	retryStrategyWrapper := newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategyWrapper.meter = opts.Meter
+
 	cluster := &Cluster{
 		cSpec:       connSpec,
 		auth:        opts.Authenticator,
@@ -168,15 +271,33 @@ func Connect(connStr string, opts ClusterOptions) (*Cluster, error) {
 			DuraTimeout:            40000 * time.Millisecond,
 			DuraPollTimeout:        100 * time.Millisecond,
 			Transcoder:             opts.Transcoder,
+			Serializer:             opts.Serializer,
 			UseMutationTokens:      useMutationTokens,
 			ManagementTimeout:      managementTimeout,
-			RetryStrategyWrapper:   newRetryStrategyWrapper(opts.RetryStrategy),
+			RetryStrategyWrapper:   retryStrategyWrapper,
 			OrphanLoggerEnabled:    !opts.OrphanReporterConfig.Disabled,
 			OrphanLoggerInterval:   opts.OrphanReporterConfig.ReportInterval,
 			OrphanLoggerSampleSize: opts.OrphanReporterConfig.SampleSize,
 			UseServerDurations:     useServerDurations,
 			Tracer:                 initialTracer,
+			Meter:                  opts.Meter,
 			CircuitBreakerConfig:   opts.CircuitBreakerConfig,
+			OperationLimitsConfig:  opts.OperationLimitsConfig,
+			HTTPLimiter: newOpLimiter(clk, opts.OperationLimitsConfig.MaxInFlightHTTP,
+				opts.OperationLimitsConfig.MaxQueuedOperations),
+			Telemetry:    telemetry,
+			QueryContext: opts.DefaultQueryContext.String(),
+			ReadOnly:     opts.ReadOnly,
+
+			DeveloperPreview: opts.EnableDeveloperPreview,
+
+			Workers: newClusterWorkers(),
+
+			UserAgent: userAgent,
+
+			Timeouts: newTimeouts(nil),
+
+			Clock: clk,
 		},
 
 		queryCache: make(map[string]*queryCacheEntry),
@@ -203,9 +324,121 @@ func Connect(connStr string, opts ClusterOptions) (*Cluster, error) {
 	cluster.clusterClient = cli
 	cluster.supportsGCCCP = cli.supportsGCCCP()
 
+	telemetry.start()
+
 	return cluster, nil
 }
 
+// knownConnStrOptions are the connection string options understood by either gocb or gocbcore,
+// along with the type of their value.  Durations are given in milliseconds.
+var knownConnStrOptions = map[string]connStrOptionKind{
+	"analytics_timeout":                     connStrOptionDuration,
+	"bootstrap_on":                          connStrOptionString,
+	"ca_cert_path":                          connStrOptionString,
+	"compression":                           connStrOptionBool,
+	"compression_min_ratio":                 connStrOptionFloat,
+	"compression_min_size":                  connStrOptionInt,
+	"config_poll_interval":                  connStrOptionDuration,
+	"config_poll_timeout":                   connStrOptionDuration,
+	"dcp_priority":                          connStrOptionString,
+	"enable_dcp_expiry":                     connStrOptionBool,
+	"enable_mutation_tokens":                connStrOptionBool,
+	"enable_server_durations":               connStrOptionBool,
+	"http_redial_period":                    connStrOptionDuration,
+	"http_retry_delay":                      connStrOptionDuration,
+	"idle_http_connection_timeout":          connStrOptionDuration,
+	"kv_connect_timeout":                    connStrOptionDuration,
+	"kv_pool_size":                          connStrOptionInt,
+	"max_idle_http_connections":             connStrOptionInt,
+	"max_perhost_idle_http_connections":     connStrOptionInt,
+	"max_queue_size":                        connStrOptionInt,
+	"network":                               connStrOptionString,
+	"orphaned_response_logging":             connStrOptionBool,
+	"orphaned_response_logging_interval":    connStrOptionDuration,
+	"orphaned_response_logging_sample_size": connStrOptionInt,
+	"query_timeout":                         connStrOptionDuration,
+	"search_timeout":                        connStrOptionDuration,
+	"view_timeout":                          connStrOptionDuration,
+}
+
+// checkConnStrOptions reports connection string options which are not recognised, which would
+// otherwise be silently ignored.  They are logged unless strict is set, in which case they are
+// returned as an error.  The values of recognised options are validated when they are parsed.
+func checkConnStrOptions(spec gocbconnstr.ConnSpec, strict bool) error {
+	var unknown []string
+	for name := range spec.Options {
+		if _, ok := knownConnStrOptions[name]; ok {
+			continue
+		}
+
+		if suggestion := closestConnStrOption(name); suggestion != "" {
+			unknown = append(unknown, fmt.Sprintf("%s (did you mean %s?)", name, suggestion))
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	if strict {
+		return makeInvalidArgumentsError("unrecognised connection string options: " + strings.Join(unknown, ", "))
+	}
+
+	logWarnf("Ignoring unrecognised connection string options: %s", strings.Join(unknown, ", "))
+	return nil
+}
+
+// closestConnStrOption returns the known option which a misspelled option was most likely meant
+// to be, or an empty string if none are similar.
+func closestConnStrOption(name string) string {
+	options := make([]string, 0, len(knownConnStrOptions))
+	for option := range knownConnStrOptions {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+
+	best := ""
+	bestDistance := 3
+	for _, option := range options {
+		if distance := editDistance(name, option); distance < bestDistance {
+			best = option
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
 func (c *Cluster) parseExtraConnStrOptions(spec gocbconnstr.ConnSpec) error {
 	fetchOption := func(name string) (string, bool) {
 		optValue := spec.Options[name]
@@ -253,6 +486,7 @@ func (c *Cluster) parseExtraConnStrOptions(spec gocbconnstr.ConnSpec) error {
 // Bucket connects the cluster to server(s) and returns a new Bucket instance.
 func (c *Cluster) Bucket(bucketName string) *Bucket {
 	b := newBucket(&c.sb, bucketName)
+	b.cluster = c
 	cli := c.takeClusterClient()
 	if cli == nil {
 		// We've already taken the cluster client for a different bucket or something like that so
@@ -345,35 +579,129 @@ func (c *Cluster) connSpec() gocbconnstr.ConnSpec {
 	return c.cSpec
 }
 
+// clusterClientName is the name which Close reports the cluster level client under.  It cannot
+// be the name of a bucket.
+const clusterClientName = "<cluster>"
+
 // Close shuts down all buckets in this cluster and invalidates any references this cluster has.
+// If any clients fail to close, or do not close within the timeout, a ClusterCloseError is
+// returned listing them.  Close waits for the goroutines used by the cluster, such as the config
+// pollers of each client and the threshold logging tracer, to exit before returning, unless
+// clients are abandoned by ClusterCloseOptions.AbandonOnTimeout.  Document watchers are closed,
+// and search queries abandoned through their context are waited for, within the timeout.
 func (c *Cluster) Close(opts *ClusterCloseOptions) error {
-	var overallErr error
+	if opts == nil {
+		opts = &ClusterCloseOptions{}
+	}
+
+	clk := clockOrSystem(c.sb.Clock)
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = clk.Now().Add(opts.Timeout)
+	}
+
+	// Watchers are told to stop before the clients are closed, so that they do not report the
+	// failures of their requests as errors.
+	c.sb.Workers.signalClose()
 
+	type namedClient struct {
+		name string
+		cli  client
+	}
+
+	// The cluster client is kept separate from the bucket clients, which are keyed by bucket
+	// name, so that it cannot replace the client of a bucket which happens to share its name.
+	var clients []namedClient
 	c.clusterLock.Lock()
 	for key, conn := range c.connections {
-		err := conn.close()
-		if err != nil {
-			logWarnf("Failed to close a client in cluster close: %s", err)
-			overallErr = err
-		}
-
+		clients = append(clients, namedClient{name: key, cli: conn})
 		delete(c.connections, key)
 	}
 	if c.clusterClient != nil {
-		err := c.clusterClient.close()
-		if err != nil {
-			logWarnf("Failed to close cluster client in cluster close: %s", err)
-			overallErr = err
-		}
+		clients = append(clients, namedClient{name: clusterClientName, cli: c.clusterClient})
 	}
 	c.clusterLock.Unlock()
 
+	type closeResult struct {
+		idx int
+		err error
+	}
+
+	// The clients are closed concurrently so that a single slow client does not hold up the
+	// rest, or consume the whole timeout.
+	resultCh := make(chan closeResult, len(clients))
+	for idx, named := range clients {
+		go func(idx int, cli client) {
+			resultCh <- closeResult{idx: idx, err: cli.close()}
+		}(idx, named.cli)
+	}
+	pending := make(map[int]bool, len(clients))
+	for idx := range clients {
+		pending[idx] = true
+	}
+
+	var timeoutCh <-chan time.Time
+	if !deadline.IsZero() {
+		timeoutCh = clk.After(deadline.Sub(clk.Now()))
+	}
+
+	clientErrs := make(map[string]error)
+WaitLoop:
+	for len(pending) > 0 {
+		select {
+		case res := <-resultCh:
+			delete(pending, res.idx)
+			if res.err != nil {
+				name := clients[res.idx].name
+				logWarnf("Failed to close client %s in cluster close: %s", name, res.err)
+				clientErrs[name] = res.err
+			}
+		case <-timeoutCh:
+			break WaitLoop
+		}
+	}
+
+	for idx := range pending {
+		name := clients[idx].name
+		if opts.AbandonOnTimeout {
+			logWarnf("Client %s did not close within %s, abandoning it", name, opts.Timeout)
+			continue
+		}
+
+		clientErrs[name] = ErrTimeout
+	}
+
 	if c.sb.Tracer != nil {
 		tracerDecRef(c.sb.Tracer)
 		c.sb.Tracer = nil
 	}
 
-	return overallErr
+	// The reporter is left in place, rather than cleared, as operations which are still in
+	// flight may record to it.  Anything recorded once it has stopped is never reported.
+	c.sb.Telemetry.stop()
+
+	workersDoneCh := make(chan struct{})
+	go func() {
+		c.sb.Workers.wait()
+		close(workersDoneCh)
+	}()
+	var workersTimeoutCh <-chan time.Time
+	if !deadline.IsZero() {
+		workersTimeoutCh = clk.After(deadline.Sub(clk.Now()))
+	}
+	select {
+	case <-workersDoneCh:
+	case <-workersTimeoutCh:
+		logWarnf("Cluster workers did not exit within %s, abandoning them", opts.Timeout)
+	}
+
+	if len(clientErrs) > 0 {
+		return ClusterCloseError{
+			ClientErrors: clientErrs,
+		}
+	}
+
+	return nil
 }
 
 func (c *Cluster) clusterOrRandomClient() (client, error) {
@@ -484,6 +812,12 @@ type clusterHTTPWrapper struct {
 }
 
 func (cw clusterHTTPWrapper) DoHTTPRequest(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+	if cw.c.sb.ReadOnly {
+		if err := checkReadOnlyHTTPRequest(req.Method, req.Path); err != nil {
+			return nil, err
+		}
+	}
+
 	provider, err := cw.c.getHTTPProvider()
 	if err != nil {
 		return nil, err
@@ -498,19 +832,52 @@ func (c *Cluster) Users() *UserManager {
 
 	return &UserManager{
 		httpClient:           provider,
-		globalTimeout:        c.sb.ManagementTimeout,
+		globalTimeout:        c.sb.managementTimeout(),
 		defaultRetryStrategy: c.sb.RetryStrategyWrapper,
 		tracer:               c.sb.Tracer,
 	}
 }
 
+// Timeouts returns the timeouts of the cluster, which can be changed without reconnecting.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) Timeouts() *Timeouts {
+	return c.sb.Timeouts
+}
+
 // Buckets returns a BucketManager for managing buckets.
 func (c *Cluster) Buckets() *BucketManager {
 	provider := clusterHTTPWrapper{c}
 
 	return &BucketManager{
 		httpClient:           provider,
-		globalTimeout:        c.sb.ManagementTimeout,
+		globalTimeout:        c.sb.managementTimeout(),
+		defaultRetryStrategy: c.sb.RetryStrategyWrapper,
+		tracer:               c.sb.Tracer,
+		clock:                c.sb.Clock,
+	}
+}
+
+// Settings returns a ClusterSettingsManager for managing cluster level settings.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) Settings() *ClusterSettingsManager {
+	provider := clusterHTTPWrapper{c}
+
+	return &ClusterSettingsManager{
+		httpClient:           provider,
+		globalTimeout:        c.sb.managementTimeout(),
+		defaultRetryStrategy: c.sb.RetryStrategyWrapper,
+		tracer:               c.sb.Tracer,
+	}
+}
+
+// LogCollection returns a LogCollectionManager for collecting cluster logs.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) LogCollection() *LogCollectionManager {
+	provider := clusterHTTPWrapper{c}
+
+	return &LogCollectionManager{
+		httpClient:           provider,
+		globalTimeout:        c.sb.managementTimeout(),
 		defaultRetryStrategy: c.sb.RetryStrategyWrapper,
 		tracer:               c.sb.Tracer,
 	}
@@ -11,12 +11,12 @@ import (
 type AnalyticsIndexManager struct {
 	cluster *Cluster
 
-	tracer requestTracer
+	tracer RequestTracer
 }
 
 func (am *AnalyticsIndexManager) doAnalyticsQuery(q string, opts *AnalyticsOptions) ([][]byte, error) {
-	if opts.Timeout == 0 || opts.Timeout > am.cluster.sb.ManagementTimeout {
-		opts.Timeout = am.cluster.sb.ManagementTimeout
+	if opts.Timeout == 0 || opts.Timeout > am.cluster.sb.managementTimeout() {
+		opts.Timeout = am.cluster.sb.managementTimeout()
 	}
 
 	result, err := am.cluster.AnalyticsQuery(q, opts)
@@ -128,7 +128,7 @@ func (am *AnalyticsIndexManager) CreateDataverse(dataverseName string, opts *Cre
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -164,7 +164,7 @@ func (am *AnalyticsIndexManager) DropDataverse(dataverseName string, opts *DropA
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -222,7 +222,7 @@ func (am *AnalyticsIndexManager) CreateDataset(datasetName, bucketName string, o
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -265,7 +265,7 @@ func (am *AnalyticsIndexManager) DropDataset(datasetName string, opts *DropAnaly
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -294,7 +294,7 @@ func (am *AnalyticsIndexManager) GetAllDatasets(opts *GetAllAnalyticsDatasetsOpt
 	rows, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return nil, err
@@ -367,7 +367,7 @@ func (am *AnalyticsIndexManager) CreateIndex(datasetName, indexName string, fiel
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -410,7 +410,7 @@ func (am *AnalyticsIndexManager) DropIndex(datasetName, indexName string, opts *
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -439,7 +439,7 @@ func (am *AnalyticsIndexManager) GetAllIndexes(opts *GetAllAnalyticsIndexesOptio
 	rows, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return nil, err
@@ -488,7 +488,7 @@ func (am *AnalyticsIndexManager) ConnectLink(opts *ConnectAnalyticsLinkOptions)
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -523,7 +523,7 @@ func (am *AnalyticsIndexManager) DisconnectLink(opts *DisconnectAnalyticsLinkOpt
 	_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,648 @@
+package gocb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AnalyticsLinkType specifies the type of an analytics link.
+type AnalyticsLinkType string
+
+const (
+	// AnalyticsLinkTypeCouchbaseRemote indicates a link to a remote Couchbase cluster.
+	AnalyticsLinkTypeCouchbaseRemote = AnalyticsLinkType("couchbase")
+
+	// AnalyticsLinkTypeS3External indicates a link to Amazon S3.
+	AnalyticsLinkTypeS3External = AnalyticsLinkType("s3")
+
+	// AnalyticsLinkTypeAzureExternal indicates a link to Azure Blob Storage.
+	AnalyticsLinkTypeAzureExternal = AnalyticsLinkType("azureblob")
+)
+
+// AnalyticsEncryptionLevel specifies how traffic over a remote Couchbase link is encrypted.
+type AnalyticsEncryptionLevel uint8
+
+const (
+	// AnalyticsEncryptionLevelNone sends all traffic unencrypted.
+	AnalyticsEncryptionLevelNone AnalyticsEncryptionLevel = iota
+
+	// AnalyticsEncryptionLevelHalf encrypts only the credentials sent when connecting.
+	AnalyticsEncryptionLevelHalf
+
+	// AnalyticsEncryptionLevelFull encrypts all traffic, requiring a certificate to be provided.
+	AnalyticsEncryptionLevelFull
+)
+
+func (level AnalyticsEncryptionLevel) String() string {
+	switch level {
+	case AnalyticsEncryptionLevelHalf:
+		return "half"
+	case AnalyticsEncryptionLevelFull:
+		return "full"
+	default:
+		return "none"
+	}
+}
+
+func analyticsEncryptionLevelFromString(level string) AnalyticsEncryptionLevel {
+	switch level {
+	case "half":
+		return AnalyticsEncryptionLevelHalf
+	case "full":
+		return AnalyticsEncryptionLevelFull
+	default:
+		return AnalyticsEncryptionLevelNone
+	}
+}
+
+// AnalyticsLink is implemented by each of the types of analytics link.
+// UNCOMMITTED: This API may change in the future.
+type AnalyticsLink interface {
+	// Name returns the name of the link.
+	Name() string
+	// DataverseName returns the name of the dataverse which the link belongs to.
+	DataverseName() string
+	// LinkType returns the type of the link.
+	LinkType() AnalyticsLinkType
+	// Validate returns an error if any of the settings required by the link are missing.
+	Validate() error
+	// FormEncode encodes the link for the analytics link REST API.
+	FormEncode() ([]byte, error)
+}
+
+// CouchbaseRemoteAnalyticsEncryptionSettings are the encryption settings of a remote Couchbase
+// link.
+type CouchbaseRemoteAnalyticsEncryptionSettings struct {
+	EncryptionLevel AnalyticsEncryptionLevel
+	// Certificate is the certificate of the remote cluster, required by AnalyticsEncryptionLevelFull.
+	Certificate []byte
+	// ClientCertificate and ClientKey can be provided with AnalyticsEncryptionLevelFull to
+	// authenticate with a certificate rather than a username and password.
+	ClientCertificate []byte
+	ClientKey         []byte
+}
+
+// CouchbaseRemoteAnalyticsLink is an analytics link to a remote Couchbase cluster.
+// UNCOMMITTED: This API may change in the future.
+type CouchbaseRemoteAnalyticsLink struct {
+	Dataverse  string
+	LinkName   string
+	Hostname   string
+	Encryption CouchbaseRemoteAnalyticsEncryptionSettings
+	Username   string
+	// Password is never returned by GetLinks.
+	Password string
+}
+
+// Name returns the name of the link.
+func (link *CouchbaseRemoteAnalyticsLink) Name() string {
+	return link.LinkName
+}
+
+// DataverseName returns the name of the dataverse which the link belongs to.
+func (link *CouchbaseRemoteAnalyticsLink) DataverseName() string {
+	return link.Dataverse
+}
+
+// LinkType returns AnalyticsLinkTypeCouchbaseRemote.
+func (link *CouchbaseRemoteAnalyticsLink) LinkType() AnalyticsLinkType {
+	return AnalyticsLinkTypeCouchbaseRemote
+}
+
+// Validate returns an error if any of the settings required by the link are missing.
+func (link *CouchbaseRemoteAnalyticsLink) Validate() error {
+	if link.Dataverse == "" {
+		return makeInvalidArgumentsError("dataverse must be specified")
+	}
+	if link.LinkName == "" {
+		return makeInvalidArgumentsError("link name must be specified")
+	}
+	if link.Hostname == "" {
+		return makeInvalidArgumentsError("hostname must be specified")
+	}
+	if link.Encryption.EncryptionLevel == AnalyticsEncryptionLevelFull {
+		if len(link.Encryption.Certificate) == 0 {
+			return makeInvalidArgumentsError("certificate must be specified with full encryption")
+		}
+		if (len(link.Encryption.ClientCertificate) == 0) != (len(link.Encryption.ClientKey) == 0) {
+			return makeInvalidArgumentsError("client certificate and client key must be specified together")
+		}
+		if len(link.Encryption.ClientCertificate) > 0 {
+			if link.Username != "" || link.Password != "" {
+				return makeInvalidArgumentsError("username and password cannot be specified with a client certificate")
+			}
+			return nil
+		}
+	}
+	if link.Username == "" || link.Password == "" {
+		return makeInvalidArgumentsError("username and password must be specified")
+	}
+
+	return nil
+}
+
+// FormEncode encodes the link for the analytics link REST API.
+func (link *CouchbaseRemoteAnalyticsLink) FormEncode() ([]byte, error) {
+	data := analyticsLinkForm(link)
+	data.Set("hostname", link.Hostname)
+	data.Set("encryption", link.Encryption.EncryptionLevel.String())
+	if len(link.Encryption.Certificate) > 0 {
+		data.Set("certificate", string(link.Encryption.Certificate))
+	}
+	if len(link.Encryption.ClientCertificate) > 0 {
+		data.Set("clientCertificate", string(link.Encryption.ClientCertificate))
+		data.Set("clientKey", string(link.Encryption.ClientKey))
+	}
+	if link.Username != "" {
+		data.Set("username", link.Username)
+		data.Set("password", link.Password)
+	}
+
+	return []byte(data.Encode()), nil
+}
+
+// S3ExternalAnalyticsLink is an analytics link to Amazon S3.
+// UNCOMMITTED: This API may change in the future.
+type S3ExternalAnalyticsLink struct {
+	Dataverse   string
+	LinkName    string
+	AccessKeyID string
+	// SecretAccessKey is never returned by GetLinks.
+	SecretAccessKey string
+	// SessionToken is optional, and is never returned by GetLinks.
+	SessionToken    string
+	Region          string
+	ServiceEndpoint string
+}
+
+// Name returns the name of the link.
+func (link *S3ExternalAnalyticsLink) Name() string {
+	return link.LinkName
+}
+
+// DataverseName returns the name of the dataverse which the link belongs to.
+func (link *S3ExternalAnalyticsLink) DataverseName() string {
+	return link.Dataverse
+}
+
+// LinkType returns AnalyticsLinkTypeS3External.
+func (link *S3ExternalAnalyticsLink) LinkType() AnalyticsLinkType {
+	return AnalyticsLinkTypeS3External
+}
+
+// Validate returns an error if any of the settings required by the link are missing.
+func (link *S3ExternalAnalyticsLink) Validate() error {
+	if link.Dataverse == "" {
+		return makeInvalidArgumentsError("dataverse must be specified")
+	}
+	if link.LinkName == "" {
+		return makeInvalidArgumentsError("link name must be specified")
+	}
+	if link.AccessKeyID == "" || link.SecretAccessKey == "" {
+		return makeInvalidArgumentsError("access key id and secret access key must be specified")
+	}
+	if link.Region == "" {
+		return makeInvalidArgumentsError("region must be specified")
+	}
+
+	return nil
+}
+
+// FormEncode encodes the link for the analytics link REST API.
+func (link *S3ExternalAnalyticsLink) FormEncode() ([]byte, error) {
+	data := analyticsLinkForm(link)
+	data.Set("accessKeyId", link.AccessKeyID)
+	data.Set("secretAccessKey", link.SecretAccessKey)
+	data.Set("region", link.Region)
+	if link.SessionToken != "" {
+		data.Set("sessionToken", link.SessionToken)
+	}
+	if link.ServiceEndpoint != "" {
+		data.Set("serviceEndpoint", link.ServiceEndpoint)
+	}
+
+	return []byte(data.Encode()), nil
+}
+
+// AzureBlobExternalAnalyticsLink is an analytics link to Azure Blob Storage.  Either
+// ConnectionString, or AccountName with one of AccountKey and SharedAccessSignature, must be
+// specified.
+// UNCOMMITTED: This API may change in the future.
+type AzureBlobExternalAnalyticsLink struct {
+	Dataverse string
+	LinkName  string
+	// ConnectionString is never returned by GetLinks.
+	ConnectionString string
+	AccountName      string
+	// AccountKey is never returned by GetLinks.
+	AccountKey string
+	// SharedAccessSignature is never returned by GetLinks.
+	SharedAccessSignature string
+	BlobEndpoint          string
+	EndpointSuffix        string
+}
+
+// Name returns the name of the link.
+func (link *AzureBlobExternalAnalyticsLink) Name() string {
+	return link.LinkName
+}
+
+// DataverseName returns the name of the dataverse which the link belongs to.
+func (link *AzureBlobExternalAnalyticsLink) DataverseName() string {
+	return link.Dataverse
+}
+
+// LinkType returns AnalyticsLinkTypeAzureExternal.
+func (link *AzureBlobExternalAnalyticsLink) LinkType() AnalyticsLinkType {
+	return AnalyticsLinkTypeAzureExternal
+}
+
+// Validate returns an error if any of the settings required by the link are missing.
+func (link *AzureBlobExternalAnalyticsLink) Validate() error {
+	if link.Dataverse == "" {
+		return makeInvalidArgumentsError("dataverse must be specified")
+	}
+	if link.LinkName == "" {
+		return makeInvalidArgumentsError("link name must be specified")
+	}
+	if link.ConnectionString == "" {
+		if link.AccountName == "" || (link.AccountKey == "") == (link.SharedAccessSignature == "") {
+			return makeInvalidArgumentsError("connection string, or account name with one of account key " +
+				"and shared access signature, must be specified")
+		}
+	}
+
+	return nil
+}
+
+// FormEncode encodes the link for the analytics link REST API.
+func (link *AzureBlobExternalAnalyticsLink) FormEncode() ([]byte, error) {
+	data := analyticsLinkForm(link)
+	if link.ConnectionString != "" {
+		data.Set("connectionString", link.ConnectionString)
+	} else {
+		data.Set("accountName", link.AccountName)
+		if link.AccountKey != "" {
+			data.Set("accountKey", link.AccountKey)
+		} else {
+			data.Set("sharedAccessSignature", link.SharedAccessSignature)
+		}
+	}
+	if link.BlobEndpoint != "" {
+		data.Set("blobEndpoint", link.BlobEndpoint)
+	}
+	if link.EndpointSuffix != "" {
+		data.Set("endpointSuffix", link.EndpointSuffix)
+	}
+
+	return []byte(data.Encode()), nil
+}
+
+// analyticsLinkForm returns the form fields shared by every type of link.  Dataverses which are
+// scopes, named as bucket/scope, identify the link in the path instead.
+func analyticsLinkForm(link AnalyticsLink) url.Values {
+	data := make(url.Values)
+	data.Set("type", string(link.LinkType()))
+	if !strings.Contains(link.DataverseName(), "/") {
+		data.Set("dataverse", link.DataverseName())
+		data.Set("name", link.Name())
+	}
+	return data
+}
+
+func analyticsLinkPath(dataverseName, linkName string) string {
+	if strings.Contains(dataverseName, "/") {
+		return fmt.Sprintf("/analytics/link/%s/%s", url.PathEscape(dataverseName), url.PathEscape(linkName))
+	}
+	return "/analytics/link"
+}
+
+type jsonAnalyticsLink struct {
+	Dataverse string `json:"dataverse"`
+	Scope     string `json:"scope"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+
+	ActiveHostname    string `json:"activeHostname"`
+	Encryption        string `json:"encryption"`
+	Username          string `json:"username"`
+	Certificate       string `json:"certificate"`
+	ClientCertificate string `json:"clientCertificate"`
+
+	AccessKeyID     string `json:"accessKeyId"`
+	Region          string `json:"region"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+
+	AccountName    string `json:"accountName"`
+	BlobEndpoint   string `json:"blobEndpoint"`
+	EndpointSuffix string `json:"endpointSuffix"`
+}
+
+func (data *jsonAnalyticsLink) toLink() AnalyticsLink {
+	// Servers which support scopes as dataverses name the dataverse scope instead.
+	dataverse := data.Dataverse
+	if dataverse == "" {
+		dataverse = data.Scope
+	}
+
+	switch AnalyticsLinkType(data.Type) {
+	case AnalyticsLinkTypeCouchbaseRemote:
+		return &CouchbaseRemoteAnalyticsLink{
+			Dataverse: dataverse,
+			LinkName:  data.Name,
+			Hostname:  data.ActiveHostname,
+			Encryption: CouchbaseRemoteAnalyticsEncryptionSettings{
+				EncryptionLevel:   analyticsEncryptionLevelFromString(data.Encryption),
+				Certificate:       []byte(data.Certificate),
+				ClientCertificate: []byte(data.ClientCertificate),
+			},
+			Username: data.Username,
+		}
+	case AnalyticsLinkTypeS3External:
+		return &S3ExternalAnalyticsLink{
+			Dataverse:       dataverse,
+			LinkName:        data.Name,
+			AccessKeyID:     data.AccessKeyID,
+			Region:          data.Region,
+			ServiceEndpoint: data.ServiceEndpoint,
+		}
+	case AnalyticsLinkTypeAzureExternal:
+		return &AzureBlobExternalAnalyticsLink{
+			Dataverse:      dataverse,
+			LinkName:       data.Name,
+			AccountName:    data.AccountName,
+			BlobEndpoint:   data.BlobEndpoint,
+			EndpointSuffix: data.EndpointSuffix,
+		}
+	default:
+		return nil
+	}
+}
+
+// makeAnalyticsLinkError returns the error for a failed link request, translating the analytics
+// error codes which have a matching error.
+func makeAnalyticsLinkError(message string, req *mgmtRequest, resp *mgmtResponse) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logDebugf("Failed to read response body (%s)", err)
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	bodyStr := string(body)
+	switch {
+	case strings.Contains(bodyStr, "24055"):
+		return makeGenericMgmtError(wrapError(ErrAnalyticsLinkExists, message), req, resp)
+	case strings.Contains(bodyStr, "24006"):
+		return makeGenericMgmtError(wrapError(ErrLinkNotFound, message), req, resp)
+	case strings.Contains(bodyStr, "24034"):
+		return makeGenericMgmtError(wrapError(ErrDataverseNotFound, message), req, resp)
+	}
+
+	if bodyStr != "" {
+		message += ": " + bodyStr
+	}
+	return makeMgmtBadStatusError(message, req, resp)
+}
+
+func (am *AnalyticsIndexManager) sendLink(method string, link AnalyticsLink, timeout time.Duration,
+	retryStrategy RetryStrategy) error {
+	if link == nil {
+		return makeInvalidArgumentsError("link must be specified")
+	}
+
+	err := link.Validate()
+	if err != nil {
+		return err
+	}
+
+	body, err := link.FormEncode()
+	if err != nil {
+		return err
+	}
+
+	req := mgmtRequest{
+		Service:       ServiceTypeAnalytics,
+		Method:        method,
+		Path:          analyticsLinkPath(link.DataverseName(), link.Name()),
+		Body:          body,
+		ContentType:   "application/x-www-form-urlencoded",
+		RetryStrategy: retryStrategy,
+		Timeout:       timeout,
+	}
+	resp, err := am.doMgmtRequest(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 200 {
+		return makeAnalyticsLinkError("failed to "+strings.ToLower(method)+" analytics link", &req, resp)
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	return nil
+}
+
+// CreateAnalyticsLinkOptions is the set of options available to the AnalyticsManager CreateLink operation.
+type CreateAnalyticsLinkOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// CreateLink creates an analytics link.  If a link with the same name already exists in the
+// dataverse then ErrAnalyticsLinkExists is returned.
+// UNCOMMITTED: This API may change in the future.
+func (am *AnalyticsIndexManager) CreateLink(link AnalyticsLink, opts *CreateAnalyticsLinkOptions) error {
+	if opts == nil {
+		opts = &CreateAnalyticsLinkOptions{}
+	}
+
+	span := am.tracer.StartSpan("CreateLink", nil).
+		SetTag("couchbase.service", "analytics")
+	defer span.Finish()
+
+	return am.sendLink("POST", link, opts.Timeout, opts.RetryStrategy)
+}
+
+// ReplaceAnalyticsLinkOptions is the set of options available to the AnalyticsManager ReplaceLink operation.
+type ReplaceAnalyticsLinkOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// ReplaceLink replaces an existing analytics link.  The type of a link cannot be changed.
+// UNCOMMITTED: This API may change in the future.
+func (am *AnalyticsIndexManager) ReplaceLink(link AnalyticsLink, opts *ReplaceAnalyticsLinkOptions) error {
+	if opts == nil {
+		opts = &ReplaceAnalyticsLinkOptions{}
+	}
+
+	span := am.tracer.StartSpan("ReplaceLink", nil).
+		SetTag("couchbase.service", "analytics")
+	defer span.Finish()
+
+	return am.sendLink("PUT", link, opts.Timeout, opts.RetryStrategy)
+}
+
+// DropAnalyticsLinkOptions is the set of options available to the AnalyticsManager DropLink operation.
+type DropAnalyticsLinkOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// DropLink drops an analytics link.  Datasets which use the link must be dropped first.
+// UNCOMMITTED: This API may change in the future.
+func (am *AnalyticsIndexManager) DropLink(linkName, dataverseName string, opts *DropAnalyticsLinkOptions) error {
+	if opts == nil {
+		opts = &DropAnalyticsLinkOptions{}
+	}
+
+	if linkName == "" {
+		return makeInvalidArgumentsError("link name must be specified")
+	}
+	if dataverseName == "" {
+		return makeInvalidArgumentsError("dataverse must be specified")
+	}
+
+	span := am.tracer.StartSpan("DropLink", nil).
+		SetTag("couchbase.service", "analytics")
+	defer span.Finish()
+
+	var body []byte
+	if !strings.Contains(dataverseName, "/") {
+		data := make(url.Values)
+		data.Set("dataverse", dataverseName)
+		data.Set("name", linkName)
+		body = []byte(data.Encode())
+	}
+
+	req := mgmtRequest{
+		Service:       ServiceTypeAnalytics,
+		Method:        "DELETE",
+		Path:          analyticsLinkPath(dataverseName, linkName),
+		Body:          body,
+		ContentType:   "application/x-www-form-urlencoded",
+		RetryStrategy: opts.RetryStrategy,
+		Timeout:       opts.Timeout,
+	}
+	resp, err := am.doMgmtRequest(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 200 {
+		return makeAnalyticsLinkError("failed to drop analytics link", &req, resp)
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	return nil
+}
+
+// GetAnalyticsLinksOptions is the set of options available to the AnalyticsManager GetLinks operation.
+type GetAnalyticsLinksOptions struct {
+	// Dataverse restricts the links returned to those in the dataverse.
+	Dataverse string
+	// LinkType restricts the links returned to those of the type.
+	LinkType AnalyticsLinkType
+	// Name restricts the links returned to the link with the name, and requires Dataverse.
+	Name string
+
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// GetLinks gets analytics links.  Each link is returned as a *CouchbaseRemoteAnalyticsLink,
+// *S3ExternalAnalyticsLink or *AzureBlobExternalAnalyticsLink, without any of its secrets.
+// Links of types which this SDK does not know about are omitted.
+// UNCOMMITTED: This API may change in the future.
+func (am *AnalyticsIndexManager) GetLinks(opts *GetAnalyticsLinksOptions) ([]AnalyticsLink, error) {
+	if opts == nil {
+		opts = &GetAnalyticsLinksOptions{}
+	}
+
+	if opts.Name != "" && opts.Dataverse == "" {
+		return nil, makeInvalidArgumentsError("dataverse must be specified when name is specified")
+	}
+
+	span := am.tracer.StartSpan("GetLinks", nil).
+		SetTag("couchbase.service", "analytics")
+	defer span.Finish()
+
+	path := "/analytics/link"
+	query := make(url.Values)
+	if strings.Contains(opts.Dataverse, "/") {
+		path += "/" + url.PathEscape(opts.Dataverse)
+		if opts.Name != "" {
+			path += "/" + url.PathEscape(opts.Name)
+		}
+	} else {
+		if opts.Dataverse != "" {
+			query.Set("dataverse", opts.Dataverse)
+		}
+		if opts.Name != "" {
+			query.Set("name", opts.Name)
+		}
+	}
+	if opts.LinkType != "" {
+		query.Set("type", string(opts.LinkType))
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req := mgmtRequest{
+		Service:       ServiceTypeAnalytics,
+		Method:        "GET",
+		Path:          path,
+		IsIdempotent:  true,
+		RetryStrategy: opts.RetryStrategy,
+		Timeout:       opts.Timeout,
+	}
+	resp, err := am.doMgmtRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, makeAnalyticsLinkError("failed to get analytics links", &req, resp)
+	}
+
+	var linksData []jsonAnalyticsLink
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&linksData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	links := make([]AnalyticsLink, 0, len(linksData))
+	for i := range linksData {
+		link := linksData[i].toLink()
+		if link == nil {
+			logDebugf("Ignoring analytics link %s of unknown type %s", linksData[i].Name, linksData[i].Type)
+			continue
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
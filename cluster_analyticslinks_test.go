@@ -0,0 +1,195 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func testGetAnalyticsLinksManager(provider *mockHTTPProvider) *AnalyticsIndexManager {
+	clients := make(map[string]client)
+	clients["mock"] = &mockClient{
+		bucketName:       "mock",
+		mockHTTPProvider: provider,
+	}
+
+	return &AnalyticsIndexManager{
+		cluster: &Cluster{connections: clients},
+		tracer:  &noopTracer{},
+	}
+}
+
+func TestAnalyticsLinksCreateLink(t *testing.T) {
+	var method, path, body string
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			method, path, body = req.Method, req.Path, string(req.Body)
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(""), nil},
+			}, nil
+		},
+	}
+	mgr := testGetAnalyticsLinksManager(provider)
+
+	err := mgr.CreateLink(&S3ExternalAnalyticsLink{
+		Dataverse:       "Default",
+		LinkName:        "s3link",
+		AccessKeyID:     "id",
+		SecretAccessKey: "secret",
+		Region:          "us-east-1",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected CreateLink to succeed but was %v", err)
+	}
+	if method != "POST" || path != "/analytics/link" {
+		t.Fatalf("Unexpected request %s %s", method, path)
+	}
+
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		t.Fatalf("Failed to parse body: %v", err)
+	}
+	expected := map[string]string{
+		"type":            "s3",
+		"dataverse":       "Default",
+		"name":            "s3link",
+		"accessKeyId":     "id",
+		"secretAccessKey": "secret",
+		"region":          "us-east-1",
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("Expected %d form values but was %v", len(expected), values)
+	}
+	for key, val := range expected {
+		if values.Get(key) != val {
+			t.Errorf("Expected %s to be %s but was %s", key, val, values.Get(key))
+		}
+	}
+
+	err = mgr.ReplaceLink(&AzureBlobExternalAnalyticsLink{
+		Dataverse:        "travel/inventory",
+		LinkName:         "azlink",
+		ConnectionString: "conn",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected ReplaceLink to succeed but was %v", err)
+	}
+	if method != "PUT" || path != "/analytics/link/travel%2Finventory/azlink" {
+		t.Fatalf("Unexpected request %s %s", method, path)
+	}
+}
+
+func TestAnalyticsLinksValidate(t *testing.T) {
+	mgr := testGetAnalyticsLinksManager(&mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			t.Fatalf("Unexpected request for invalid link")
+			return nil, nil
+		},
+	})
+
+	links := []AnalyticsLink{
+		&CouchbaseRemoteAnalyticsLink{Dataverse: "Default", LinkName: "cb", Hostname: "remote"},
+		&CouchbaseRemoteAnalyticsLink{Dataverse: "Default", LinkName: "cb", Hostname: "remote",
+			Username: "u", Password: "p",
+			Encryption: CouchbaseRemoteAnalyticsEncryptionSettings{EncryptionLevel: AnalyticsEncryptionLevelFull}},
+		&S3ExternalAnalyticsLink{Dataverse: "Default", LinkName: "s3", AccessKeyID: "id", SecretAccessKey: "secret"},
+		&AzureBlobExternalAnalyticsLink{Dataverse: "Default", LinkName: "az", AccountName: "acc"},
+		nil,
+	}
+	for _, link := range links {
+		err := mgr.CreateLink(link, nil)
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Expected invalid argument error for %v but was %v", link, err)
+		}
+	}
+}
+
+func TestAnalyticsLinksErrors(t *testing.T) {
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			code := "24006"
+			if req.Method == "POST" {
+				code = "24055"
+			}
+			return &gocbcore.HTTPResponse{
+				StatusCode: 400,
+				Body: &testReadCloser{bytes.NewBufferString(`{"errors":[{"code":` + code +
+					`,"msg":"link error"}],"status":"fatal"}`), nil},
+			}, nil
+		},
+	}
+	mgr := testGetAnalyticsLinksManager(provider)
+
+	err := mgr.CreateLink(&CouchbaseRemoteAnalyticsLink{
+		Dataverse: "Default",
+		LinkName:  "cb",
+		Hostname:  "remote",
+		Username:  "u",
+		Password:  "p",
+	}, nil)
+	if !errors.Is(err, ErrAnalyticsLinkExists) {
+		t.Fatalf("Expected link exists error but was %v", err)
+	}
+
+	err = mgr.DropLink("cb", "Default", nil)
+	if !errors.Is(err, ErrLinkNotFound) {
+		t.Fatalf("Expected link not found error but was %v", err)
+	}
+}
+
+func TestAnalyticsLinksGetLinks(t *testing.T) {
+	var path string
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			path = req.Path
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body: &testReadCloser{bytes.NewBufferString(`[
+					{"dataverse":"Default","name":"cb","type":"couchbase","activeHostname":"remote",` +
+					`"encryption":"half","username":"u"},
+					{"scope":"travel/inventory","name":"s3","type":"s3","accessKeyId":"id","region":"eu-west-1"},
+					{"dataverse":"Default","name":"az","type":"azureblob","accountName":"acc"},
+					{"dataverse":"Default","name":"gcs","type":"gcs"}
+				]`), nil},
+			}, nil
+		},
+	}
+	mgr := testGetAnalyticsLinksManager(provider)
+
+	links, err := mgr.GetLinks(&GetAnalyticsLinksOptions{
+		Dataverse: "Default",
+		LinkType:  AnalyticsLinkTypeCouchbaseRemote,
+	})
+	if err != nil {
+		t.Fatalf("Expected GetLinks to succeed but was %v", err)
+	}
+	if path != "/analytics/link?dataverse=Default&type=couchbase" {
+		t.Fatalf("Unexpected request path %s", path)
+	}
+	if len(links) != 3 {
+		t.Fatalf("Expected 3 links but was %d", len(links))
+	}
+
+	cbLink, ok := links[0].(*CouchbaseRemoteAnalyticsLink)
+	if !ok || cbLink.Hostname != "remote" || cbLink.Username != "u" ||
+		cbLink.Encryption.EncryptionLevel != AnalyticsEncryptionLevelHalf {
+		t.Errorf("Unexpected couchbase link %#v", links[0])
+	}
+	s3Link, ok := links[1].(*S3ExternalAnalyticsLink)
+	if !ok || s3Link.Dataverse != "travel/inventory" || s3Link.Region != "eu-west-1" {
+		t.Errorf("Unexpected s3 link %#v", links[1])
+	}
+	azLink, ok := links[2].(*AzureBlobExternalAnalyticsLink)
+	if !ok || azLink.AccountName != "acc" {
+		t.Errorf("Unexpected azure link %#v", links[2])
+	}
+
+	_, err = mgr.GetLinks(&GetAnalyticsLinksOptions{Name: "cb"})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package gocb
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"net/url"
 	"time"
 
 	gocbcore "github.com/couchbase/gocbcore/v8"
@@ -24,6 +27,14 @@ type jsonAnalyticsWarning struct {
 	Message string `json:"msg"`
 }
 
+type jsonAnalyticsPlans struct {
+	LogicalPlan             json.RawMessage `json:"logicalPlan"`
+	OptimizedLogicalPlan    json.RawMessage `json:"optimizedLogicalPlan"`
+	ExpressionTree          json.RawMessage `json:"expressionTree"`
+	RewrittenExpressionTree json.RawMessage `json:"rewrittenExpressionTree"`
+	Job                     json.RawMessage `json:"job"`
+}
+
 type jsonAnalyticsResponse struct {
 	RequestID       string                 `json:"requestID"`
 	ClientContextID string                 `json:"clientContextID"`
@@ -31,6 +42,7 @@ type jsonAnalyticsResponse struct {
 	Warnings        []jsonAnalyticsWarning `json:"warnings"`
 	Metrics         jsonAnalyticsMetrics   `json:"metrics"`
 	Signature       interface{}            `json:"signature"`
+	Plans           *jsonAnalyticsPlans    `json:"plans"`
 }
 
 // AnalyticsMetrics encapsulates various metrics gathered during a queries execution.
@@ -83,6 +95,135 @@ func (warning *AnalyticsWarning) fromData(data jsonAnalyticsWarning) error {
 	return nil
 }
 
+type jsonAnalyticsPlanNode struct {
+	Operator         string            `json:"operator"`
+	OperatorID       string            `json:"operatorId"`
+	PhysicalOperator string            `json:"physical-operator"`
+	ExecutionMode    string            `json:"execution-mode"`
+	DataSource       string            `json:"data-source"`
+	Inputs           []json.RawMessage `json:"inputs"`
+}
+
+// AnalyticsPlanNode is a single operator of a logical plan compiled for an analytics query.
+type AnalyticsPlanNode struct {
+	// Operator is the name of the logical operator, e.g. data-scan, unnest-map or select.
+	Operator         string
+	OperatorID       string
+	PhysicalOperator string
+	ExecutionMode    string
+	// DataSource is the dataset scanned by data-scan operators.
+	DataSource string
+	// Inputs are the operators which produce the input of this operator.
+	Inputs []AnalyticsPlanNode
+	// Raw contains the undecoded JSON for this operator, including its expressions.
+	Raw json.RawMessage
+}
+
+func (n *AnalyticsPlanNode) fromData(data json.RawMessage) error {
+	var node jsonAnalyticsPlanNode
+	err := json.Unmarshal(data, &node)
+	if err != nil {
+		return err
+	}
+
+	n.Operator = node.Operator
+	n.OperatorID = node.OperatorID
+	n.PhysicalOperator = node.PhysicalOperator
+	n.ExecutionMode = node.ExecutionMode
+	n.DataSource = node.DataSource
+	n.Raw = data
+
+	for _, inputData := range node.Inputs {
+		var input AnalyticsPlanNode
+		err := input.fromData(inputData)
+		if err != nil {
+			return err
+		}
+
+		n.Inputs = append(n.Inputs, input)
+	}
+
+	return nil
+}
+
+// Walk invokes fn for this operator and each of its inputs, depth first.
+func (n *AnalyticsPlanNode) Walk(fn func(node *AnalyticsPlanNode)) {
+	fn(n)
+	for i := range n.Inputs {
+		n.Inputs[i].Walk(fn)
+	}
+}
+
+// DatasetsScanned returns the data sources of all of the data-scan operators of this plan.
+func (n *AnalyticsPlanNode) DatasetsScanned() []string {
+	var datasets []string
+	n.Walk(func(node *AnalyticsPlanNode) {
+		if node.DataSource != "" {
+			datasets = append(datasets, node.DataSource)
+		}
+	})
+
+	return datasets
+}
+
+// AnalyticsPlans contains the compiled plans returned for an analytics query when
+// requested via AnalyticsOptions.Plans.  The raw fields hold each plan exactly as
+// returned in the requested format, with plans returned in the string format being
+// encoded as JSON strings.  Logical plans returned in the JSON format are also decoded
+// into LogicalPlanTree and OptimizedLogicalPlanTree.
+type AnalyticsPlans struct {
+	LogicalPlan             json.RawMessage
+	OptimizedLogicalPlan    json.RawMessage
+	ExpressionTree          json.RawMessage
+	RewrittenExpressionTree json.RawMessage
+	Job                     json.RawMessage
+
+	// LogicalPlanTree is the decoded LogicalPlan, or nil if it was not requested in the
+	// JSON format.
+	LogicalPlanTree *AnalyticsPlanNode
+	// OptimizedLogicalPlanTree is the decoded OptimizedLogicalPlan, or nil if it was not
+	// requested in the JSON format.  It shows the datasets which the query scans, and the
+	// Raw JSON of its unnest-map operators shows the indexes which it searches.
+	OptimizedLogicalPlanTree *AnalyticsPlanNode
+}
+
+func (plans *AnalyticsPlans) fromData(data jsonAnalyticsPlans) error {
+	plans.LogicalPlan = data.LogicalPlan
+	plans.OptimizedLogicalPlan = data.OptimizedLogicalPlan
+	plans.ExpressionTree = data.ExpressionTree
+	plans.RewrittenExpressionTree = data.RewrittenExpressionTree
+	plans.Job = data.Job
+
+	var err error
+	plans.LogicalPlanTree, err = decodeAnalyticsPlanTree(data.LogicalPlan)
+	if err != nil {
+		return err
+	}
+
+	plans.OptimizedLogicalPlanTree, err = decodeAnalyticsPlanTree(data.OptimizedLogicalPlan)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// decodeAnalyticsPlanTree decodes a logical plan returned in the JSON format, returning nil
+// if the plan is missing or was returned in the string format.
+func decodeAnalyticsPlanTree(data json.RawMessage) (*AnalyticsPlanNode, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, nil
+	}
+
+	node := &AnalyticsPlanNode{}
+	if err := node.fromData(data); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
 // AnalyticsMetaData provides access to the meta-data properties of a query result.
 type AnalyticsMetaData struct {
 	RequestID       string
@@ -90,6 +231,7 @@ type AnalyticsMetaData struct {
 	Metrics         AnalyticsMetrics
 	Signature       interface{}
 	Warnings        []AnalyticsWarning
+	Plans           *AnalyticsPlans
 
 	preparedName string
 }
@@ -111,19 +253,29 @@ func (meta *AnalyticsMetaData) fromData(data jsonAnalyticsResponse) error {
 	meta.Signature = data.Signature
 	meta.Warnings = warnings
 
+	if data.Plans != nil {
+		plans := &AnalyticsPlans{}
+		if err := plans.fromData(*data.Plans); err != nil {
+			return err
+		}
+		meta.Plans = plans
+	}
+
 	return nil
 }
 
 // AnalyticsResult allows access to the results of a query.
 type AnalyticsResult struct {
-	reader *gocbcore.AnalyticsRowReader
+	reader rowReader
 
 	rowBytes []byte
+
+	clientContextID string
 }
 
-func newAnalyticsResult(reader *gocbcore.AnalyticsRowReader) (*AnalyticsResult, error) {
+func newAnalyticsResult(reader rowReader) (*AnalyticsResult, error) {
 	return &AnalyticsResult{
-		reader: reader,
+		reader: newCloseOnceRowReader(reader),
 	}, nil
 }
 
@@ -152,12 +304,21 @@ func (r *AnalyticsResult) Row(valuePtr interface{}) error {
 	return json.Unmarshal(r.rowBytes, valuePtr)
 }
 
+// RawBytes returns the undecoded contents of the current row.  This allows the
+// cost of decoding to be deferred or skipped entirely, for instance when proxying
+// rows elsewhere.  The returned bytes are only valid until the next call to Next.
+func (r *AnalyticsResult) RawBytes() []byte {
+	return r.rowBytes
+}
+
 // Err returns any errors that have occurred on the stream
 func (r *AnalyticsResult) Err() error {
 	return r.reader.Err()
 }
 
 // Close marks the results as closed, returning any errors that occurred during reading the results.
+// Closing releases the underlying connection even if rows remain unread.  It is safe to call Close
+// more than once, and after an error has occurred, in which case the same result is returned each time.
 func (r *AnalyticsResult) Close() error {
 	return r.reader.Close()
 }
@@ -170,6 +331,11 @@ func (r *AnalyticsResult) One(valuePtr interface{}) error {
 	// Read the bytes from the first row
 	valueBytes := r.reader.NextRow()
 	if valueBytes == nil {
+		err := r.Close()
+		if err != nil {
+			return err
+		}
+
 		return ErrNoResult
 	}
 
@@ -178,6 +344,11 @@ func (r *AnalyticsResult) One(valuePtr interface{}) error {
 		// do nothing with the row
 	}
 
+	err := r.Close()
+	if err != nil {
+		return err
+	}
+
 	return json.Unmarshal(valueBytes, valuePtr)
 }
 
@@ -202,31 +373,44 @@ func (r *AnalyticsResult) MetaData() (*AnalyticsMetaData, error) {
 		return nil, err
 	}
 
+	if metaData.ClientContextID == "" {
+		metaData.ClientContextID = r.clientContextID
+	}
+
 	return &metaData, nil
 }
 
 // AnalyticsQuery executes the analytics query statement on the server.
-func (c *Cluster) AnalyticsQuery(statement string, opts *AnalyticsOptions) (*AnalyticsResult, error) {
+func (c *Cluster) AnalyticsQuery(statement string, opts *AnalyticsOptions) (resOut *AnalyticsResult, errOut error) {
 	if opts == nil {
 		opts = &AnalyticsOptions{}
 	}
 
-	span := c.sb.Tracer.StartSpan("Query", opts.parentSpan).
+	span := c.sb.Tracer.StartSpan("Query", opts.ParentSpan).
 		SetTag("couchbase.service", "analytics")
+	span = applyOperationTags(span, opts.Tags)
 	defer span.Finish()
+	defer func() {
+		setSpanOutcome(span, errOut)
+	}()
 
-	timeout := c.sb.QueryTimeout
+	timeout := c.sb.analyticsTimeout()
 	if opts.Timeout != 0 && opts.Timeout < timeout {
 		timeout = opts.Timeout
 	}
-	deadline := time.Now().Add(timeout)
+	deadline := contextDeadline(opts.Context, clockOrSystem(c.sb.Clock).Now().Add(timeout))
+
+	retryStrategy := c.sb.RetryStrategyWrapper.withStrategy(opts.RetryStrategy)
 
-	retryStrategy := c.sb.RetryStrategyWrapper
-	if opts.RetryStrategy != nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	if opts.Context != nil && opts.Context.Err() != nil {
+		return nil, AnalyticsError{
+			InnerError:      opts.Context.Err(),
+			Statement:       statement,
+			ClientContextID: opts.ClientContextID,
+		}
 	}
 
-	queryOpts, err := opts.toMap()
+	queryOpts, err := opts.toMap(c.sb.Serializer)
 	if err != nil {
 		return nil, AnalyticsError{
 			InnerError:      wrapError(err, "failed to generate query options"),
@@ -235,6 +419,19 @@ func (c *Cluster) AnalyticsQuery(statement string, opts *AnalyticsOptions) (*Ana
 		}
 	}
 
+	clientContextID := maybeGetAnalyticsOption(queryOpts, "client_context_id")
+
+	if c.sb.ReadOnly {
+		if err := checkReadOnlyStatement(statement); err != nil {
+			return nil, AnalyticsError{
+				InnerError:      err,
+				Statement:       statement,
+				ClientContextID: clientContextID,
+			}
+		}
+		queryOpts["readonly"] = true
+	}
+
 	var priorityInt int32
 	if opts.Priority {
 		priorityInt = -1
@@ -242,7 +439,99 @@ func (c *Cluster) AnalyticsQuery(statement string, opts *AnalyticsOptions) (*Ana
 
 	queryOpts["statement"] = statement
 
-	return c.execAnalyticsQuery(span, queryOpts, priorityInt, deadline, retryStrategy)
+	// Analytics requests can continue to run server side long after the client has
+	// gone away, so when the context is cancelled we also cancel the active request.
+	var cancelFn func()
+	if opts.Context != nil {
+		cancelFn = func() {
+			c.cancelAnalyticsRequest(clientContextID)
+		}
+	}
+
+	releaseLimit, err := c.sb.HTTPLimiter.Acquire(deadline)
+	if err != nil {
+		return nil, AnalyticsError{
+			InnerError:      err,
+			Statement:       statement,
+			ClientContextID: clientContextID,
+		}
+	}
+
+	stopWatchFn := watchContext(opts.Context, c.sb.Workers, cancelFn)
+	res, err := c.execAnalyticsQuery(span, queryOpts, priorityInt, deadline, retryStrategy)
+	stopWatchFn()
+	if err != nil {
+		releaseLimit()
+		if opts.Context != nil && opts.Context.Err() != nil {
+			return nil, AnalyticsError{
+				InnerError:      opts.Context.Err(),
+				Statement:       statement,
+				ClientContextID: clientContextID,
+			}
+		}
+
+		return nil, err
+	}
+
+	res.clientContextID = clientContextID
+	res.reader = newLimitedRowReader(newCtxRowReader(opts.Context, c.sb.Workers, res.reader, cancelFn), releaseLimit)
+
+	return res, nil
+}
+
+// watchContext invokes onCancel if ctx is cancelled before the returned stop
+// function is called, or the Cluster is closed.
+func watchContext(ctx context.Context, workers *clusterWorkers, onCancel func()) func() {
+	if ctx == nil || ctx.Done() == nil || onCancel == nil {
+		return func() {}
+	}
+
+	stopCh := make(chan struct{})
+	workers.start(func() {
+		select {
+		case <-ctx.Done():
+			onCancel()
+		case <-stopCh:
+		case <-workers.closed():
+		}
+	})
+
+	return func() {
+		close(stopCh)
+	}
+}
+
+// cancelAnalyticsRequest asks the analytics service to cancel the active request
+// with the given client context id.
+func (c *Cluster) cancelAnalyticsRequest(clientContextID string) {
+	posts := url.Values{}
+	posts.Add("client_context_id", clientContextID)
+
+	req := mgmtRequest{
+		Service:      ServiceTypeAnalytics,
+		Method:       "DELETE",
+		Path:         "/analytics/admin/active_requests",
+		Body:         []byte(posts.Encode()),
+		ContentType:  "application/x-www-form-urlencoded",
+		IsIdempotent: true,
+		internal:     true,
+	}
+
+	resp, err := c.executeMgmtRequest(req)
+	if err != nil {
+		logDebugf("Failed to cancel analytics request %s: %s", clientContextID, err)
+		return
+	}
+
+	// A 404 indicates that the request had already completed.
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		logDebugf("Failed to cancel analytics request %s, status code %d", clientContextID, resp.StatusCode)
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
 }
 
 func maybeGetAnalyticsOption(options map[string]interface{}, name string) string {
@@ -253,7 +542,7 @@ func maybeGetAnalyticsOption(options map[string]interface{}, name string) string
 }
 
 func (c *Cluster) execAnalyticsQuery(
-	span requestSpan,
+	span RequestSpan,
 	options map[string]interface{},
 	priority int32,
 	deadline time.Time,
@@ -284,6 +573,7 @@ func (c *Cluster) execAnalyticsQuery(
 		Deadline:      deadline,
 	})
 	if err != nil {
+		c.sb.Telemetry.recordError("analytics", err)
 		return nil, maybeEnhanceAnalyticsError(err)
 	}
 
@@ -0,0 +1,84 @@
+package gocb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// blockingAnalyticsProvider blocks each query until its context is done, as a long running
+// analytics query does.
+type blockingAnalyticsProvider struct {
+	ctx context.Context
+}
+
+func (p *blockingAnalyticsProvider) AnalyticsQuery(opts gocbcore.AnalyticsQueryOptions) (*gocbcore.AnalyticsRowReader, error) {
+	<-p.ctx.Done()
+	return nil, errors.New("request cancelled")
+}
+
+func TestAnalyticsQueryContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancelReqCh := make(chan *gocbcore.HTTPRequest, 1)
+	httpProvider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			cancelReqCh <- req
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBuffer(nil), nil},
+			}, nil
+		},
+	}
+
+	c := &Cluster{
+		connections: map[string]client{
+			"mock": &mockClient{
+				bucketName:            "mock",
+				mockAnalyticsProvider: &blockingAnalyticsProvider{ctx: ctx},
+				mockHTTPProvider:      httpProvider,
+			},
+		},
+	}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.AnalyticsTimeout = 10 * time.Second
+	c.sb.ManagementTimeout = time.Second
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := c.AnalyticsQuery("SELECT * FROM dataset", &AnalyticsOptions{
+		Context:         ctx,
+		ClientContextID: "runaway",
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled but was %v", err)
+	}
+
+	var req *gocbcore.HTTPRequest
+	select {
+	case req = <-cancelReqCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the analytics request to be cancelled")
+	}
+
+	if req.Method != "DELETE" || req.Path != "/analytics/admin/active_requests" {
+		t.Fatalf("Expected DELETE /analytics/admin/active_requests but was %s %s", req.Method, req.Path)
+	}
+	if req.ContentType != "application/x-www-form-urlencoded" {
+		t.Fatalf("Expected a form encoded body but was %s", req.ContentType)
+	}
+
+	form, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		t.Fatalf("Failed to parse body: %v", err)
+	}
+	if form.Get("client_context_id") != "runaway" {
+		t.Fatalf("Expected client_context_id runaway but was %s", form.Get("client_context_id"))
+	}
+}
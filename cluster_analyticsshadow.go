@@ -0,0 +1,167 @@
+package gocb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ShadowCollectionAnalyticsOptions is the set of options available to the AnalyticsManager ShadowCollection operation.
+type ShadowCollectionAnalyticsOptions struct {
+	// DataverseName is the dataverse to create the dataset within, it is created if it does not
+	// exist.  The default is the Default dataverse.
+	DataverseName string
+
+	// DatasetName is the name of the dataset to create.  The default is the name of the collection,
+	// or of the bucket for the default collection.
+	DatasetName string
+
+	// Condition, if set, limits the dataset to the documents which match it.
+	Condition string
+
+	// Timeout is the length of time that the operation as a whole, including waiting for the
+	// existing documents to be ingested, may take.
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// ShadowCollection makes the documents of a collection available to analytics.  It creates the
+// dataverse and dataset which shadow the collection, connects the link which feeds them and then
+// waits until the documents which exist in the collection have been ingested.  It is safe to call
+// ShadowCollection for a collection which is already shadowed.
+// UNCOMMITTED: This API may change in the future.
+func (am *AnalyticsIndexManager) ShadowCollection(collection *Collection, opts *ShadowCollectionAnalyticsOptions) error {
+	if opts == nil {
+		opts = &ShadowCollectionAnalyticsOptions{}
+	}
+
+	if collection == nil {
+		return makeInvalidArgumentsError("collection cannot be nil")
+	}
+
+	span := am.tracer.StartSpan("ShadowCollection", nil).
+		SetTag("couchbase.service", "analytics")
+	defer span.Finish()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = am.cluster.sb.managementTimeout()
+	}
+	clk := clockOrSystem(am.cluster.sb.Clock)
+	deadline := clk.Now().Add(timeout)
+
+	dataverseName := opts.DataverseName
+	if dataverseName == "" {
+		dataverseName = "Default"
+	}
+
+	datasetName := opts.DatasetName
+	if datasetName == "" {
+		datasetName = collection.name()
+		if datasetName == "" || datasetName == "_default" {
+			datasetName = collection.sb.BucketName
+		}
+	}
+
+	for _, q := range analyticsShadowStatements(collection, dataverseName, datasetName, opts.Condition) {
+		if !clk.Now().Before(deadline) {
+			return ErrUnambiguousTimeout
+		}
+
+		_, err := am.doAnalyticsQuery(q, &AnalyticsOptions{
+			Timeout:       deadline.Sub(clk.Now()),
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    span.Context(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return waitForAnalyticsIngestion(clk, func() (map[string]uint64, error) {
+		return am.GetPendingMutations(&GetPendingMutationsAnalyticsOptions{
+			Timeout:       deadline.Sub(clk.Now()),
+			RetryStrategy: opts.RetryStrategy,
+		})
+	}, dataverseName+"."+datasetName, deadline)
+}
+
+func analyticsIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func analyticsShadowStatements(collection *Collection, dataverseName, datasetName, condition string) []string {
+	var statements []string
+
+	if dataverseName != "Default" {
+		statements = append(statements,
+			fmt.Sprintf("CREATE DATAVERSE %s IF NOT EXISTS", analyticsIdentifier(dataverseName)))
+	}
+
+	// The default collection is addressed by the bucket name, which is also understood by servers
+	// which do not support collections.
+	source := analyticsIdentifier(collection.sb.BucketName)
+	if (collection.scopeName() != "" && collection.scopeName() != "_default") ||
+		(collection.name() != "" && collection.name() != "_default") {
+		source = fmt.Sprintf("%s.%s.%s", source, analyticsIdentifier(collection.scopeName()),
+			analyticsIdentifier(collection.name()))
+	}
+
+	var where string
+	if condition != "" {
+		if !strings.HasPrefix(strings.ToUpper(condition), "WHERE") {
+			where = " WHERE "
+		} else {
+			where = " "
+		}
+		where += condition
+	}
+
+	statements = append(statements,
+		fmt.Sprintf("CREATE DATASET IF NOT EXISTS %s.%s ON %s%s", analyticsIdentifier(dataverseName),
+			analyticsIdentifier(datasetName), source, where))
+
+	// The Local link of the Default dataverse is addressed without a dataverse on older servers.
+	link := "Local"
+	if dataverseName != "Default" {
+		link = analyticsIdentifier(dataverseName) + ".Local"
+	}
+	statements = append(statements, "CONNECT LINK "+link)
+
+	return statements
+}
+
+func waitForAnalyticsIngestion(clk clock, getPending func() (map[string]uint64, error), key string,
+	deadline time.Time) error {
+	curInterval := 50 * time.Millisecond
+	for {
+		if !clk.Now().Before(deadline) {
+			return ErrUnambiguousTimeout
+		}
+
+		pending, err := getPending()
+		if err != nil {
+			return err
+		}
+
+		// A dataset which has not yet started ingesting is not listed.
+		if remaining, ok := pending[key]; ok && remaining == 0 {
+			return nil
+		}
+
+		curInterval += 250 * time.Millisecond
+		if curInterval > 1000*time.Millisecond {
+			curInterval = 1000 * time.Millisecond
+		}
+
+		// Make sure we don't sleep past our overall deadline, if we adjust the
+		// deadline then it will be caught at the top of this loop as a timeout.
+		sleepDeadline := clk.Now().Add(curInterval)
+		if sleepDeadline.After(deadline) {
+			sleepDeadline = deadline
+		}
+
+		// wait till our next poll interval
+		clk.Sleep(sleepDeadline.Sub(clk.Now()))
+	}
+}
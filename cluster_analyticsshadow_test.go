@@ -0,0 +1,107 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAnalyticsShadowStatements(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	statements := analyticsShadowStatements(col, "Default", "mock", "")
+	if len(statements) != 2 ||
+		statements[0] != "CREATE DATASET IF NOT EXISTS `Default`.`mock` ON `mock`" ||
+		statements[1] != "CONNECT LINK Local" {
+		t.Fatalf("Unexpected statements %v", statements)
+	}
+
+	col.sb.ScopeName = "inventory"
+	col.sb.CollectionName = "airline"
+	statements = analyticsShadowStatements(col, "travel", "airlines", "country = \"France\"")
+	if len(statements) != 3 ||
+		statements[0] != "CREATE DATAVERSE `travel` IF NOT EXISTS" ||
+		statements[1] != "CREATE DATASET IF NOT EXISTS `travel`.`airlines` ON `mock`.`inventory`.`airline` WHERE country = \"France\"" ||
+		statements[2] != "CONNECT LINK `travel`.Local" {
+		t.Fatalf("Unexpected statements %v", statements)
+	}
+}
+
+func TestWaitForAnalyticsIngestion(t *testing.T) {
+	clk := newFakeClock()
+	polls := 0
+	err := waitForAnalyticsIngestion(clk, func() (map[string]uint64, error) {
+		polls++
+		switch polls {
+		case 1:
+			return map[string]uint64{}, nil
+		case 2:
+			return map[string]uint64{"Default.mock": 10}, nil
+		default:
+			return map[string]uint64{"Default.mock": 0}, nil
+		}
+	}, "Default.mock", clk.Now().Add(5*time.Second))
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	if polls != 3 {
+		t.Fatalf("Expected 3 polls but was %d", polls)
+	}
+}
+
+func TestWaitForAnalyticsIngestionTimeout(t *testing.T) {
+	clk := newFakeClock()
+	start := clk.Now()
+	err := waitForAnalyticsIngestion(clk, func() (map[string]uint64, error) {
+		return map[string]uint64{"Default.mock": 10}, nil
+	}, "Default.mock", start.Add(10*time.Second))
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout but was %v", err)
+	}
+
+	// The backoff never sleeps past the deadline.
+	if waited := clk.Now().Sub(start); waited != 10*time.Second {
+		t.Fatalf("Expected to wait for 10s but was %s", waited)
+	}
+}
+
+func TestShadowCollectionSpanContext(t *testing.T) {
+	tracer := &recordingTracer{}
+	col := testGetCollection(t, &mockKvProvider{})
+
+	c := &Cluster{
+		connections: map[string]client{
+			"mock": &mockClient{
+				bucketName:            "mock",
+				mockAnalyticsProvider: &failingAnalyticsProvider{},
+			},
+		},
+	}
+	c.sb.Tracer = tracer
+	c.sb.AnalyticsTimeout = time.Second
+	c.sb.ManagementTimeout = time.Second
+
+	mgr := &AnalyticsIndexManager{
+		cluster: c,
+		tracer:  tracer,
+	}
+	err := mgr.ShadowCollection(col, nil)
+	if err == nil {
+		t.Fatalf("Expected ShadowCollection to fail")
+	}
+
+	queries := 0
+	for _, start := range tracer.starts {
+		if start.operationName != "Query" {
+			continue
+		}
+		queries++
+		if start.parent != defaultNoopSpanContext {
+			t.Fatalf("Expected query span to have ShadowCollection as its parent but was %v", start.parent)
+		}
+	}
+	if queries == 0 {
+		t.Fatalf("Expected a query span to be started")
+	}
+}
@@ -46,6 +46,14 @@ const (
 
 	// EvictionPolicyTypeValueOnly specifies to use value only eviction for a bucket.
 	EvictionPolicyTypeValueOnly = EvictionPolicyType("valueOnly")
+
+	// EvictionPolicyTypeNotRecentlyUsed specifies to eject the least recently used documents from an
+	// ephemeral bucket once its memory quota is reached.
+	EvictionPolicyTypeNotRecentlyUsed = EvictionPolicyType("nruEviction")
+
+	// EvictionPolicyTypeNoEviction specifies that an ephemeral bucket should reject new data once its
+	// memory quota is reached, rather than ejecting existing documents.
+	EvictionPolicyTypeNoEviction = EvictionPolicyType("noEviction")
 )
 
 // CompressionMode specifies the kind of compression to use for a bucket.
@@ -78,6 +86,7 @@ type jsonBucketSettings struct {
 	EvictionPolicy         string `json:"evictionPolicy"`
 	MaxTTL                 uint32 `json:"maxTTL"`
 	CompressionMode        string `json:"compressionMode"`
+	DurabilityMinLevel     string `json:"durabilityMinLevel"`
 }
 
 // BucketSettings holds information about the settings for a bucket.
@@ -86,7 +95,8 @@ type BucketSettings struct {
 	Name string
 	// FlushEnabled specifies whether or not to enable flush on the bucket.
 	FlushEnabled bool
-	// ReplicaIndexDisabled specifies whether or not to disable replica index.
+	// ReplicaIndexDisabled specifies whether or not to disable replica index.  Replica indexes are
+	// only supported by Couchbase buckets, and cannot be changed once the bucket has been created.
 	ReplicaIndexDisabled bool // inverted so that zero value matches server default.
 	//  is the memory quota to assign to the bucket and is required.
 	RAMQuotaMB uint64
@@ -94,10 +104,17 @@ type BucketSettings struct {
 	// NOTE: If not set this will set 0 replicas.
 	NumReplicas uint32
 	// BucketType is the type of bucket this is. Defaults to CouchbaseBucketType.
-	BucketType      BucketType
+	BucketType BucketType
+	// EvictionPolicy is the eviction policy to use for the bucket.  Couchbase buckets support
+	// EvictionPolicyTypeFull and EvictionPolicyTypeValueOnly, whilst ephemeral buckets support
+	// EvictionPolicyTypeNotRecentlyUsed and EvictionPolicyTypeNoEviction.  Memcached buckets
+	// do not support eviction policies.
 	EvictionPolicy  EvictionPolicyType
 	MaxTTL          time.Duration
 	CompressionMode CompressionMode
+	// MinimumDurabilityLevel is the durability level applied to every mutation on the bucket.  The
+	// zero value leaves the server default, which is to apply no minimum durability level.
+	MinimumDurabilityLevel DurabilityLevel
 }
 
 func (bs *BucketSettings) fromData(data jsonBucketSettings) error {
@@ -110,6 +127,12 @@ func (bs *BucketSettings) fromData(data jsonBucketSettings) error {
 	bs.MaxTTL = time.Duration(data.MaxTTL) * time.Second
 	bs.CompressionMode = CompressionMode(data.CompressionMode)
 
+	minimumDurabilityLevel, err := durabilityLevelFromMgmtString(data.DurabilityMinLevel)
+	if err != nil {
+		return err
+	}
+	bs.MinimumDurabilityLevel = minimumDurabilityLevel
+
 	switch data.BucketType {
 	case "membase":
 		bs.BucketType = CouchbaseBucketType
@@ -124,13 +147,44 @@ func (bs *BucketSettings) fromData(data jsonBucketSettings) error {
 	return nil
 }
 
+func durabilityLevelFromMgmtString(level string) (DurabilityLevel, error) {
+	switch level {
+	case "", "none":
+		return 0, nil
+	case "majority":
+		return DurabilityLevelMajority, nil
+	case "majorityAndPersistActive":
+		return DurabilityLevelMajorityAndPersistOnMaster, nil
+	case "persistToMajority":
+		return DurabilityLevelPersistToMajority, nil
+	default:
+		return 0, errors.New("unrecognized durability level string")
+	}
+}
+
+func durabilityLevelToMgmtString(level DurabilityLevel) (string, error) {
+	switch level {
+	case 0:
+		return "none", nil
+	case DurabilityLevelMajority:
+		return "majority", nil
+	case DurabilityLevelMajorityAndPersistOnMaster:
+		return "majorityAndPersistActive", nil
+	case DurabilityLevelPersistToMajority:
+		return "persistToMajority", nil
+	default:
+		return "", makeInvalidArgumentsError("Unrecognized durability level")
+	}
+}
+
 // BucketManager provides methods for performing bucket management operations.
 // See BucketManager for methods that allow creating and removing buckets themselves.
 type BucketManager struct {
 	httpClient           httpProvider
 	globalTimeout        time.Duration
 	defaultRetryStrategy *retryStrategyWrapper
-	tracer               requestTracer
+	tracer               RequestTracer
+	clock                clock
 }
 
 // GetBucketOptions is the set of options available to the bucket manager GetBucket operation.
@@ -149,15 +203,12 @@ func (bm *BucketManager) GetBucket(bucketName string, opts *GetBucketOptions) (*
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := bm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	return bm.get(span.Context(), bucketName, retryStrategy)
 }
 
-func (bm *BucketManager) get(tracectx requestSpanContext, bucketName string,
+func (bm *BucketManager) get(tracectx RequestSpanContext, bucketName string,
 	strategy *retryStrategyWrapper) (*BucketSettings, error) {
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -216,10 +267,7 @@ func (bm *BucketManager) GetAllBuckets(opts *GetAllBucketsOptions) (map[string]B
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := bm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -271,6 +319,73 @@ func (bm *BucketManager) GetAllBuckets(opts *GetAllBucketsOptions) (map[string]B
 type CreateBucketSettings struct {
 	BucketSettings
 	ConflictResolutionType ConflictResolutionType
+	// NumVBuckets is the number of vBuckets to create the bucket with.  The zero value uses the
+	// server default.  Setting this requires every node in the cluster to be running Couchbase
+	// Server 7.6 or above.
+	NumVBuckets uint32
+}
+
+type jsonPoolsDefaultNodes struct {
+	Nodes []struct {
+		Version string `json:"version"`
+	} `json:"nodes"`
+}
+
+// clusterVersionAtLeast returns whether every node in the cluster is running at least the
+// specified version of Couchbase Server.
+func (bm *BucketManager) clusterVersionAtLeast(tracectx RequestSpanContext, strategy *retryStrategyWrapper,
+	major, minor int) (bool, error) {
+	req := &gocbcore.HTTPRequest{
+		Service:       gocbcore.ServiceType(ServiceTypeManagement),
+		Path:          "/pools/default",
+		Method:        "GET",
+		IsIdempotent:  true,
+		RetryStrategy: strategy,
+		UniqueID:      uuid.New().String(),
+	}
+
+	dspan := bm.tracer.StartSpan("dispatch", tracectx)
+	resp, err := bm.httpClient.DoHTTPRequest(req)
+	dspan.Finish()
+	if err != nil {
+		return false, makeGenericHTTPError(err, req, resp)
+	}
+
+	if resp.StatusCode != 200 {
+		return false, makeHTTPBadStatusError("failed to get cluster nodes", req, resp)
+	}
+
+	var poolData jsonPoolsDefaultNodes
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&poolData)
+	if err != nil {
+		return false, err
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	if len(poolData.Nodes) == 0 {
+		return false, nil
+	}
+
+	for _, node := range poolData.Nodes {
+		// Versions are of the form 7.6.0-1234-enterprise.
+		var nodeMajor, nodeMinor int
+		_, err := fmt.Sscanf(node.Version, "%d.%d", &nodeMajor, &nodeMinor)
+		if err != nil {
+			logDebugf("Failed to parse node version %s (%s)", node.Version, err)
+			return false, nil
+		}
+
+		if nodeMajor < major || (nodeMajor == major && nodeMinor < minor) {
+			return false, nil
+		}
+	}
+
+	return true, nil
 }
 
 // CreateBucketOptions is the set of options available to the bucket manager CreateBucket operation.
@@ -289,11 +404,74 @@ func (bm *BucketManager) CreateBucket(settings CreateBucketSettings, opts *Creat
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := bm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	return bm.createBucket(span.Context(), settings, retryStrategy)
+}
+
+// BucketCreationProgress describes how far a newly created bucket is through being brought online.
+type BucketCreationProgress struct {
+	// NodesReady is the number of nodes on which the bucket is healthy.
+	NodesReady int
+	// NodesTotal is the number of nodes which the bucket is being created on.  This is zero until
+	// the bucket is visible to the node which was asked.
+	NodesTotal int
+}
+
+// Ready returns whether the bucket is healthy on every node.
+func (p BucketCreationProgress) Ready() bool {
+	return p.NodesTotal > 0 && p.NodesReady == p.NodesTotal
+}
+
+// BucketCreation is a handle to a bucket which has been created by CreateBucketAsync, allowing
+// its progress towards being ready for use to be monitored.
+type BucketCreation struct {
+	manager       *BucketManager
+	bucketName    string
+	retryStrategy *retryStrategyWrapper
+}
+
+// Progress returns how far the bucket is through being brought online.
+func (bc *BucketCreation) Progress() (*BucketCreationProgress, error) {
+	span := bc.manager.tracer.StartSpan("BucketCreationProgress", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	return bc.manager.bucketProgress(span.Context(), bc.bucketName, bc.manager.globalTimeout, bc.retryStrategy)
+}
+
+// Wait blocks until the bucket is ready for use, see WaitUntilBucketReady.
+func (bc *BucketCreation) Wait(opts *WaitUntilBucketReadyOptions) error {
+	return bc.manager.WaitUntilBucketReady(bc.bucketName, opts)
+}
+
+// CreateBucketAsync creates a bucket on the cluster, returning a handle which can be used to monitor
+// the progress of the bucket being brought online across the cluster rather than blocking until it is.
+func (bm *BucketManager) CreateBucketAsync(settings CreateBucketSettings, opts *CreateBucketOptions) (*BucketCreation, error) {
+	if opts == nil {
+		opts = &CreateBucketOptions{}
 	}
 
+	span := bm.tracer.StartSpan("CreateBucketAsync", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	err := bm.createBucket(span.Context(), settings, retryStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BucketCreation{
+		manager:       bm,
+		bucketName:    settings.Name,
+		retryStrategy: retryStrategy,
+	}, nil
+}
+
+func (bm *BucketManager) createBucket(tracectx RequestSpanContext, settings CreateBucketSettings,
+	retryStrategy *retryStrategyWrapper) error {
 	posts, err := bm.settingsToPostData(&settings.BucketSettings)
 	if err != nil {
 		return err
@@ -303,6 +481,22 @@ func (bm *BucketManager) CreateBucket(settings CreateBucketSettings, opts *Creat
 		posts.Add("conflictResolutionType", string(settings.ConflictResolutionType))
 	}
 
+	if settings.NumVBuckets > 0 {
+		if settings.BucketType == MemcachedBucketType {
+			return makeInvalidArgumentsError("NumVBuckets cannot be used with memcached buckets")
+		}
+
+		supported, err := bm.clusterVersionAtLeast(tracectx, retryStrategy, 7, 6)
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return wrapError(ErrFeatureNotAvailable, "NumVBuckets requires Couchbase Server 7.6 or above")
+		}
+
+		posts.Add("numVBuckets", fmt.Sprintf("%d", settings.NumVBuckets))
+	}
+
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
 		Path:          "/pools/default/buckets",
@@ -313,7 +507,7 @@ func (bm *BucketManager) CreateBucket(settings CreateBucketSettings, opts *Creat
 		UniqueID:      uuid.New().String(),
 	}
 
-	dspan := bm.tracer.StartSpan("dispatch", span.Context())
+	dspan := bm.tracer.StartSpan("dispatch", tracectx)
 	resp, err := bm.httpClient.DoHTTPRequest(req)
 	dspan.Finish()
 	if err != nil {
@@ -332,13 +526,143 @@ func (bm *BucketManager) CreateBucket(settings CreateBucketSettings, opts *Creat
 	return nil
 }
 
+type jsonBucketNodes struct {
+	Nodes []struct {
+		Hostname string `json:"hostname"`
+		Status   string `json:"status"`
+	} `json:"nodes"`
+}
+
+// WaitUntilBucketReadyOptions is the set of options available to the bucket manager WaitUntilBucketReady operation.
+type WaitUntilBucketReadyOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// WaitUntilBucketReady waits until every node hosting a bucket reports it as healthy.  CreateBucket
+// returns once the cluster has accepted the request, before the bucket can be used, so this can be
+// called afterwards to avoid racing against the bucket being brought online.
+func (bm *BucketManager) WaitUntilBucketReady(bucketName string, opts *WaitUntilBucketReadyOptions) error {
+	if opts == nil {
+		opts = &WaitUntilBucketReadyOptions{}
+	}
+
+	span := bm.tracer.StartSpan("WaitUntilBucketReady", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = bm.globalTimeout
+	}
+	clk := clockOrSystem(bm.clock)
+	deadline := clk.Now().Add(timeout)
+
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	curInterval := 50 * time.Millisecond
+	for {
+		// Each poll is bounded by whatever is left of the overall deadline so that a single
+		// hung request cannot run past it.
+		reqTimeout, err := remainingTimeout(clk, deadline)
+		if err != nil {
+			return err
+		}
+
+		progress, err := bm.bucketProgress(span.Context(), bucketName, reqTimeout, retryStrategy)
+		if err != nil {
+			return err
+		}
+
+		if progress.Ready() {
+			return nil
+		}
+
+		curInterval += 250 * time.Millisecond
+		if curInterval > 1000*time.Millisecond {
+			curInterval = 1000 * time.Millisecond
+		}
+
+		// Make sure we don't sleep past our overall deadline, if we adjust the
+		// deadline then it will be caught at the top of this loop as a timeout.
+		sleepDeadline := clk.Now().Add(curInterval)
+		if sleepDeadline.After(deadline) {
+			sleepDeadline = deadline
+		}
+
+		clk.Sleep(sleepDeadline.Sub(clk.Now()))
+	}
+}
+
+func (bm *BucketManager) bucketProgress(tracectx RequestSpanContext, bucketName string,
+	timeout time.Duration, strategy *retryStrategyWrapper) (*BucketCreationProgress, error) {
+	req := &gocbcore.HTTPRequest{
+		Service:       gocbcore.ServiceType(ServiceTypeManagement),
+		Path:          fmt.Sprintf("/pools/default/buckets/%s", bucketName),
+		Method:        "GET",
+		IsIdempotent:  true,
+		Timeout:       timeout,
+		RetryStrategy: strategy,
+		UniqueID:      uuid.New().String(),
+	}
+
+	dspan := bm.tracer.StartSpan("dispatch", tracectx)
+	resp, err := bm.httpClient.DoHTTPRequest(req)
+	dspan.Finish()
+	if err != nil {
+		return nil, makeGenericHTTPError(err, req, resp)
+	}
+
+	// The bucket may not be visible on the node that we asked yet.
+	if resp.StatusCode == 404 {
+		err = resp.Body.Close()
+		if err != nil {
+			logDebugf("Failed to close socket (%s)", err)
+		}
+
+		return &BucketCreationProgress{}, nil
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, makeHTTPBadStatusError("failed to get bucket", req, resp)
+	}
+
+	var bucketData jsonBucketNodes
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&bucketData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	progress := &BucketCreationProgress{
+		NodesTotal: len(bucketData.Nodes),
+	}
+	for _, node := range bucketData.Nodes {
+		if node.Status == "healthy" {
+			progress.NodesReady++
+		} else {
+			logDebugf("Bucket %s is not yet ready on %s (%s)", bucketName, node.Hostname, node.Status)
+		}
+	}
+
+	return progress, nil
+}
+
 // UpdateBucketOptions is the set of options available to the bucket manager UpdateBucket operation.
 type UpdateBucketOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 }
 
-// UpdateBucket updates a bucket on the cluster.
+// UpdateBucket updates the settings of an existing bucket on the cluster.  The RAM quota,
+// number of replicas, flush, eviction policy, max TTL, compression mode and minimum durability
+// level can all be changed on a live bucket.  Attempting to change the bucket type or replica
+// index returns an error wrapping ErrBucketSettingImmutable.
 func (bm *BucketManager) UpdateBucket(settings BucketSettings, opts *UpdateBucketOptions) error {
 	if opts == nil {
 		opts = &UpdateBucketOptions{}
@@ -348,16 +672,35 @@ func (bm *BucketManager) UpdateBucket(settings BucketSettings, opts *UpdateBucke
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := bm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	posts, err := bm.settingsToPostData(&settings)
 	if err != nil {
 		return err
 	}
 
+	current, err := bm.get(span.Context(), settings.Name, retryStrategy)
+	if err != nil {
+		return err
+	}
+
+	if current.BucketType != settings.BucketType {
+		return makeBucketSettingImmutableError(settings.Name, "bucket type")
+	}
+	if current.BucketType == CouchbaseBucketType && current.ReplicaIndexDisabled != settings.ReplicaIndexDisabled {
+		return makeBucketSettingImmutableError(settings.Name, "replica index")
+	}
+
+	// These can only be specified when the bucket is created, and have been verified
+	// as unchanged above.
+	posts.Del("bucketType")
+	posts.Del("replicaIndex")
+
+	// Clearing the minimum durability level requires it to be explicitly reset.
+	if settings.MinimumDurabilityLevel == 0 && current.MinimumDurabilityLevel != 0 {
+		posts.Add("durabilityMinLevel", "none")
+	}
+
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
 		Path:          fmt.Sprintf("/pools/default/buckets/%s", settings.Name),
@@ -387,6 +730,10 @@ func (bm *BucketManager) UpdateBucket(settings BucketSettings, opts *UpdateBucke
 	return nil
 }
 
+func makeBucketSettingImmutableError(bucketName, setting string) error {
+	return wrapError(ErrBucketSettingImmutable, fmt.Sprintf("cannot change %s of bucket %s", setting, bucketName))
+}
+
 // DropBucketOptions is the set of options available to the bucket manager DropBucket operation.
 type DropBucketOptions struct {
 	Timeout       time.Duration
@@ -403,10 +750,7 @@ func (bm *BucketManager) DropBucket(name string, opts *DropBucketOptions) error
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := bm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -452,10 +796,7 @@ func (bm *BucketManager) FlushBucket(name string, opts *FlushBucketOptions) erro
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := bm.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := bm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -504,16 +845,16 @@ func (bm *BucketManager) settingsToPostData(settings *BucketSettings) (url.Value
 		posts.Add("flushEnabled", "0")
 	}
 
-	if settings.ReplicaIndexDisabled {
-		posts.Add("replicaIndex", "0")
-	} else {
-		posts.Add("replicaIndex", "1")
-	}
-
 	switch settings.BucketType {
 	case CouchbaseBucketType:
 		posts.Add("bucketType", string(settings.BucketType))
 		posts.Add("replicaNumber", fmt.Sprintf("%d", settings.NumReplicas))
+		// Replica indexes are only maintained by buckets which store data on disk.
+		if settings.ReplicaIndexDisabled {
+			posts.Add("replicaIndex", "0")
+		} else {
+			posts.Add("replicaIndex", "1")
+		}
 	case MemcachedBucketType:
 		posts.Add("bucketType", string(settings.BucketType))
 		if settings.NumReplicas > 0 {
@@ -529,6 +870,19 @@ func (bm *BucketManager) settingsToPostData(settings *BucketSettings) (url.Value
 	posts.Add("ramQuotaMB", fmt.Sprintf("%d", settings.RAMQuotaMB))
 
 	if settings.EvictionPolicy != "" {
+		switch settings.BucketType {
+		case CouchbaseBucketType:
+			if settings.EvictionPolicy != EvictionPolicyTypeFull && settings.EvictionPolicy != EvictionPolicyTypeValueOnly {
+				return nil, makeInvalidArgumentsError("eviction policy is not valid for couchbase buckets")
+			}
+		case EphemeralBucketType:
+			if settings.EvictionPolicy != EvictionPolicyTypeNotRecentlyUsed && settings.EvictionPolicy != EvictionPolicyTypeNoEviction {
+				return nil, makeInvalidArgumentsError("eviction policy is not valid for ephemeral buckets")
+			}
+		case MemcachedBucketType:
+			return nil, makeInvalidArgumentsError("eviction policy cannot be used with memcached buckets")
+		}
+
 		posts.Add("evictionPolicy", string(settings.EvictionPolicy))
 	}
 
@@ -540,5 +894,13 @@ func (bm *BucketManager) settingsToPostData(settings *BucketSettings) (url.Value
 		posts.Add("compressionMode", string(settings.CompressionMode))
 	}
 
+	if settings.MinimumDurabilityLevel > 0 {
+		level, err := durabilityLevelToMgmtString(settings.MinimumDurabilityLevel)
+		if err != nil {
+			return nil, err
+		}
+		posts.Add("durabilityMinLevel", level)
+	}
+
 	return posts, nil
 }
@@ -1,6 +1,9 @@
 package gocb
 
 import (
+	"bytes"
+	"errors"
+	"net/url"
 	"testing"
 	"time"
 
@@ -98,3 +101,342 @@ func TestBucketMgrOps(t *testing.T) {
 		t.Fatalf("Failed to drop bucket manager %v", err)
 	}
 }
+
+func TestBucketMgrWaitUntilBucketReady(t *testing.T) {
+	responses := []struct {
+		status int
+		body   string
+	}{
+		{404, `Requested resource not found.`},
+		{200, `{"nodes":[{"hostname":"a","status":"healthy"},{"hostname":"b","status":"warmup"}]}`},
+		{200, `{"nodes":[{"hostname":"a","status":"healthy"},{"hostname":"b","status":"healthy"}]}`},
+	}
+
+	requests := 0
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			if req.Path != "/pools/default/buckets/test" {
+				t.Fatalf("Unexpected request path: %s", req.Path)
+			}
+
+			resp := responses[requests]
+			requests++
+			return &gocbcore.HTTPResponse{
+				StatusCode: resp.status,
+				Body:       &testReadCloser{bytes.NewBufferString(resp.body), nil},
+			}, nil
+		},
+	}
+
+	mgr := &BucketManager{
+		httpClient:    provider,
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+	}
+
+	err := mgr.WaitUntilBucketReady("test", nil)
+	if err != nil {
+		t.Fatalf("Expected bucket to become ready but was %v", err)
+	}
+
+	if requests != len(responses) {
+		t.Fatalf("Expected %d requests but was %d", len(responses), requests)
+	}
+}
+
+func TestBucketMgrWaitUntilBucketReadyTimeout(t *testing.T) {
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(`{"nodes":[{"hostname":"a","status":"warmup"}]}`), nil},
+			}, nil
+		},
+	}
+
+	mgr := &BucketManager{
+		httpClient:    provider,
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+	}
+
+	err := mgr.WaitUntilBucketReady("test", &WaitUntilBucketReadyOptions{
+		Timeout: 200 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected timeout error but was %v", err)
+	}
+}
+
+func TestBucketMgrWaitUntilBucketReadyRequestTimeout(t *testing.T) {
+	clk := newFakeClock()
+
+	var timeouts []time.Duration
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			timeouts = append(timeouts, req.Timeout)
+			// Each request takes a while, eating into the overall deadline.
+			clk.Advance(300 * time.Millisecond)
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(`{"nodes":[{"hostname":"a","status":"warmup"}]}`), nil},
+			}, nil
+		},
+	}
+
+	mgr := &BucketManager{
+		httpClient:    provider,
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+		clock:         clk,
+	}
+
+	err := mgr.WaitUntilBucketReady("test", &WaitUntilBucketReadyOptions{
+		Timeout: time.Second,
+	})
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected timeout error but was %v", err)
+	}
+
+	expected := []time.Duration{time.Second, 400 * time.Millisecond}
+	if len(timeouts) != len(expected) {
+		t.Fatalf("Expected %d requests but was %d", len(expected), len(timeouts))
+	}
+	for i, timeout := range timeouts {
+		if timeout != expected[i] {
+			t.Fatalf("Expected request %d to have timeout %s but was %s", i, expected[i], timeout)
+		}
+	}
+}
+
+func TestBucketMgrUpdateBucket(t *testing.T) {
+	var updateBody string
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			if req.Method == "GET" {
+				return &gocbcore.HTTPResponse{
+					StatusCode: 200,
+					Body: &testReadCloser{bytes.NewBufferString(`{"name":"test","bucketType":"membase",` +
+						`"replicaIndex":true,"replicaNumber":1,"quota":{"rawRAM":104857600},` +
+						`"durabilityMinLevel":"majority"}`), nil},
+				}, nil
+			}
+
+			updateBody = string(req.Body)
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(""), nil},
+			}, nil
+		},
+	}
+
+	mgr := &BucketManager{
+		httpClient:    provider,
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+	}
+
+	err := mgr.UpdateBucket(BucketSettings{
+		Name:            "test",
+		BucketType:      CouchbaseBucketType,
+		RAMQuotaMB:      200,
+		NumReplicas:     2,
+		FlushEnabled:    true,
+		CompressionMode: CompressionModeActive,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected update to succeed but was %v", err)
+	}
+
+	values, err := url.ParseQuery(updateBody)
+	if err != nil {
+		t.Fatalf("Failed to parse update body: %v", err)
+	}
+
+	expected := map[string]string{
+		"ramQuotaMB":         "200",
+		"replicaNumber":      "2",
+		"flushEnabled":       "1",
+		"compressionMode":    "active",
+		"durabilityMinLevel": "none",
+	}
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Fatalf("Expected %s to be %s but was %s", key, value, values.Get(key))
+		}
+	}
+
+	if _, ok := values["bucketType"]; ok {
+		t.Fatalf("Expected bucketType not to be sent on update")
+	}
+	if _, ok := values["replicaIndex"]; ok {
+		t.Fatalf("Expected replicaIndex not to be sent on update")
+	}
+
+	err = mgr.UpdateBucket(BucketSettings{
+		Name:       "test",
+		BucketType: EphemeralBucketType,
+		RAMQuotaMB: 200,
+	}, nil)
+	if !errors.Is(err, ErrBucketSettingImmutable) {
+		t.Fatalf("Expected immutable setting error but was %v", err)
+	}
+}
+
+func TestBucketMgrCreateBucketNumVBuckets(t *testing.T) {
+	var createBody string
+	nodeVersion := "7.6.0-2176-enterprise"
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			if req.Path == "/pools/default" {
+				return &gocbcore.HTTPResponse{
+					StatusCode: 200,
+					Body: &testReadCloser{bytes.NewBufferString(`{"nodes":[{"version":"7.6.1-3200-enterprise"},` +
+						`{"version":"` + nodeVersion + `"}]}`), nil},
+				}, nil
+			}
+
+			createBody = string(req.Body)
+			return &gocbcore.HTTPResponse{
+				StatusCode: 202,
+				Body:       &testReadCloser{bytes.NewBufferString(""), nil},
+			}, nil
+		},
+	}
+
+	mgr := &BucketManager{
+		httpClient:    provider,
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+	}
+
+	settings := CreateBucketSettings{
+		BucketSettings: BucketSettings{
+			Name:       "test",
+			BucketType: EphemeralBucketType,
+			RAMQuotaMB: 100,
+		},
+		NumVBuckets: 128,
+	}
+
+	err := mgr.CreateBucket(settings, nil)
+	if err != nil {
+		t.Fatalf("Expected create to succeed but was %v", err)
+	}
+
+	values, err := url.ParseQuery(createBody)
+	if err != nil {
+		t.Fatalf("Failed to parse create body: %v", err)
+	}
+
+	if values.Get("numVBuckets") != "128" {
+		t.Fatalf("Expected numVBuckets to be 128 but was %s", values.Get("numVBuckets"))
+	}
+
+	if _, ok := values["replicaIndex"]; ok {
+		t.Fatalf("Expected replicaIndex not to be sent for ephemeral buckets")
+	}
+
+	nodeVersion = "7.2.4-7070-enterprise"
+	err = mgr.CreateBucket(settings, nil)
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		t.Fatalf("Expected feature not available error but was %v", err)
+	}
+}
+
+func TestBucketMgrEvictionPolicyValidation(t *testing.T) {
+	type tCase struct {
+		bucketType BucketType
+		policy     EvictionPolicyType
+		valid      bool
+	}
+
+	testCases := []tCase{
+		{CouchbaseBucketType, EvictionPolicyTypeFull, true},
+		{CouchbaseBucketType, EvictionPolicyTypeValueOnly, true},
+		{CouchbaseBucketType, EvictionPolicyTypeNoEviction, false},
+		{EphemeralBucketType, EvictionPolicyTypeNotRecentlyUsed, true},
+		{EphemeralBucketType, EvictionPolicyTypeNoEviction, true},
+		{EphemeralBucketType, EvictionPolicyTypeFull, false},
+		{MemcachedBucketType, EvictionPolicyTypeValueOnly, false},
+		{MemcachedBucketType, "", true},
+	}
+
+	mgr := &BucketManager{}
+	for _, tc := range testCases {
+		posts, err := mgr.settingsToPostData(&BucketSettings{
+			Name:           "test",
+			BucketType:     tc.bucketType,
+			RAMQuotaMB:     100,
+			EvictionPolicy: tc.policy,
+		})
+		if tc.valid {
+			if err != nil {
+				t.Fatalf("Expected %s with %s to be valid but was %v", tc.bucketType, tc.policy, err)
+			}
+
+			if posts.Get("evictionPolicy") != string(tc.policy) {
+				t.Fatalf("Expected evictionPolicy to be %s but was %s", tc.policy, posts.Get("evictionPolicy"))
+			}
+		} else if !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("Expected %s with %s to be invalid but was %v", tc.bucketType, tc.policy, err)
+		}
+	}
+}
+
+func TestBucketMgrCreateBucketAsync(t *testing.T) {
+	statuses := []string{
+		`{"nodes":[{"hostname":"a","status":"healthy"},{"hostname":"b","status":"warmup"}]}`,
+		`{"nodes":[{"hostname":"a","status":"healthy"},{"hostname":"b","status":"healthy"}]}`,
+	}
+
+	gets := 0
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			if req.Method == "POST" {
+				return &gocbcore.HTTPResponse{
+					StatusCode: 202,
+					Body:       &testReadCloser{bytes.NewBufferString(""), nil},
+				}, nil
+			}
+
+			status := statuses[gets]
+			gets++
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(status), nil},
+			}, nil
+		},
+	}
+
+	mgr := &BucketManager{
+		httpClient:    provider,
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+	}
+
+	creation, err := mgr.CreateBucketAsync(CreateBucketSettings{
+		BucketSettings: BucketSettings{
+			Name:       "test",
+			BucketType: CouchbaseBucketType,
+			RAMQuotaMB: 100,
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected create to succeed but was %v", err)
+	}
+
+	progress, err := creation.Progress()
+	if err != nil {
+		t.Fatalf("Expected progress to succeed but was %v", err)
+	}
+
+	if progress.NodesReady != 1 || progress.NodesTotal != 2 || progress.Ready() {
+		t.Fatalf("Expected 1 of 2 nodes to be ready but was %+v", progress)
+	}
+
+	err = creation.Wait(nil)
+	if err != nil {
+		t.Fatalf("Expected wait to succeed but was %v", err)
+	}
+}
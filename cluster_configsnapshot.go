@@ -0,0 +1,190 @@
+package gocb
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// ConfigSnapshotNode describes a node in the cluster configuration which the SDK is using.
+type ConfigSnapshotNode struct {
+	Hostname string
+
+	// Ports maps each service which the SDK uses on this node to the port used to reach it.
+	Ports map[ServiceType]uint16
+}
+
+// ConfigSnapshot describes the cluster configuration which the SDK is currently using to route
+// operations.  It reflects the view of the SDK, which may lag behind changes to the cluster.
+// UNCOMMITTED: This API may change in the future.
+type ConfigSnapshot struct {
+	// Revision is the revision of the configuration.
+	Revision int64
+
+	// NumReplicas is the number of replicas configured for the bucket.  It is zero for a
+	// snapshot taken from a Cluster which is not associated with a bucket.
+	NumReplicas int
+
+	// Nodes lists the nodes in the configuration, ordered by hostname.  Key-value ports are
+	// only included for nodes which the SDK has a connection to.
+	Nodes []ConfigSnapshotNode
+}
+
+// ConfigSnapshot returns the cluster configuration which the cluster level connection is using.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) ConfigSnapshot() (*ConfigSnapshot, error) {
+	cli, err := c.clusterOrRandomClient()
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := cli.getConfigSnapshotProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConfigSnapshot(provider)
+}
+
+// ConfigSnapshot returns the cluster configuration which the bucket is using.
+// UNCOMMITTED: This API may change in the future.
+func (b *Bucket) ConfigSnapshot() (*ConfigSnapshot, error) {
+	provider, err := b.sb.getCachedClient().getConfigSnapshotProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConfigSnapshot(provider)
+}
+
+func newConfigSnapshot(provider configSnapshotProvider) (*ConfigSnapshot, error) {
+	diag, err := provider.Diagnostics()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*ConfigSnapshotNode)
+	addNode := func(service ServiceType, address string) {
+		host, portStr, err := net.SplitHostPort(address)
+		if err != nil {
+			logDebugf("Failed to parse endpoint address %s: %s", address, err)
+			return
+		}
+
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			logDebugf("Failed to parse endpoint port %s: %s", address, err)
+			return
+		}
+
+		node, ok := nodes[host]
+		if !ok {
+			node = &ConfigSnapshotNode{
+				Hostname: host,
+				Ports:    make(map[ServiceType]uint16),
+			}
+			nodes[host] = node
+		}
+		node.Ports[service] = uint16(port)
+	}
+
+	for _, conn := range diag.MemdConns {
+		addNode(ServiceTypeKeyValue, conn.RemoteAddr)
+	}
+
+	httpEps := map[ServiceType][]string{
+		ServiceTypeManagement: provider.MgmtEps(),
+		ServiceTypeViews:      provider.CapiEps(),
+		ServiceTypeQuery:      provider.N1qlEps(),
+		ServiceTypeSearch:     provider.FtsEps(),
+		ServiceTypeAnalytics:  provider.CbasEps(),
+	}
+	for service, eps := range httpEps {
+		for _, ep := range eps {
+			epURL, err := url.Parse(ep)
+			if err != nil {
+				logDebugf("Failed to parse endpoint %s: %s", ep, err)
+				continue
+			}
+			addNode(service, epURL.Host)
+		}
+	}
+
+	snapshot := &ConfigSnapshot{
+		Revision:    diag.ConfigRev,
+		NumReplicas: provider.NumReplicas(),
+		Nodes:       make([]ConfigSnapshotNode, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		snapshot.Nodes = append(snapshot.Nodes, *node)
+	}
+	sort.Slice(snapshot.Nodes, func(i, j int) bool {
+		return snapshot.Nodes[i].Hostname < snapshot.Nodes[j].Hostname
+	})
+
+	return snapshot, nil
+}
+
+// VbucketMapping describes where a document is stored.
+// UNCOMMITTED: This API may change in the future.
+type VbucketMapping struct {
+	VbucketID uint16
+
+	// Active is the key-value address, as host:port, of the node holding the active copy of
+	// the vbucket.
+	Active string
+
+	// Replicas lists the key-value addresses of the nodes holding each replica of the
+	// vbucket.  An address is empty if the replica is not currently assigned to a node.
+	Replicas []string
+}
+
+// VbucketMapping returns the vbucket which a document ID maps to, and the nodes which the
+// SDK currently routes operations on that vbucket to.  This is intended for debugging data
+// locality, and the mapping may change at any time.
+// UNCOMMITTED: This API may change in the future.
+func (b *Bucket) VbucketMapping(id string) (*VbucketMapping, error) {
+	provider, err := b.sb.getCachedClient().getConfigSnapshotProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.NumVbuckets() == 0 {
+		return nil, makeInvalidArgumentsError("bucket does not use vbuckets")
+	}
+
+	diag, err := provider.Diagnostics()
+	if err != nil {
+		return nil, err
+	}
+
+	// Diagnostics lists the connections of each server in turn, with the same number of
+	// connections for every server.
+	serverAddress := func(serverIdx int) string {
+		numServers := provider.NumServers()
+		if serverIdx < 0 || numServers == 0 {
+			return ""
+		}
+
+		poolSize := len(diag.MemdConns) / numServers
+		for i := serverIdx * poolSize; i < (serverIdx+1)*poolSize && i < len(diag.MemdConns); i++ {
+			if diag.MemdConns[i].RemoteAddr != "" {
+				return diag.MemdConns[i].RemoteAddr
+			}
+		}
+		return ""
+	}
+
+	vbID := provider.KeyToVbucket([]byte(id))
+	mapping := &VbucketMapping{
+		VbucketID: vbID,
+		Active:    serverAddress(provider.VbucketToServer(vbID, 0)),
+		Replicas:  make([]string, provider.NumReplicas()),
+	}
+	for i := range mapping.Replicas {
+		mapping.Replicas[i] = serverAddress(provider.VbucketToServer(vbID, uint32(i+1)))
+	}
+
+	return mapping, nil
+}
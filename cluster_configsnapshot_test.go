@@ -0,0 +1,145 @@
+package gocb
+
+import (
+	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+type mockConfigSnapshotProvider struct {
+	diag        gocbcore.DiagnosticInfo
+	numReplicas int
+	mgmtEps     []string
+	n1qlEps     []string
+	vbMap       [][]int
+}
+
+func (p *mockConfigSnapshotProvider) Diagnostics() (*gocbcore.DiagnosticInfo, error) {
+	return &p.diag, nil
+}
+
+func (p *mockConfigSnapshotProvider) NumReplicas() int {
+	return p.numReplicas
+}
+
+func (p *mockConfigSnapshotProvider) NumServers() int {
+	return len(p.mgmtEps)
+}
+
+func (p *mockConfigSnapshotProvider) NumVbuckets() int {
+	return len(p.vbMap)
+}
+
+func (p *mockConfigSnapshotProvider) KeyToVbucket(key []byte) uint16 {
+	return uint16(len(key) % len(p.vbMap))
+}
+
+func (p *mockConfigSnapshotProvider) VbucketToServer(vbID uint16, replicaIdx uint32) int {
+	return p.vbMap[vbID][replicaIdx]
+}
+
+func (p *mockConfigSnapshotProvider) MgmtEps() []string {
+	return p.mgmtEps
+}
+
+func (p *mockConfigSnapshotProvider) CapiEps() []string {
+	return nil
+}
+
+func (p *mockConfigSnapshotProvider) N1qlEps() []string {
+	return p.n1qlEps
+}
+
+func (p *mockConfigSnapshotProvider) FtsEps() []string {
+	return nil
+}
+
+func (p *mockConfigSnapshotProvider) CbasEps() []string {
+	return nil
+}
+
+func TestBucketConfigSnapshot(t *testing.T) {
+	provider := &mockConfigSnapshotProvider{
+		diag: gocbcore.DiagnosticInfo{
+			ConfigRev: 1234,
+			MemdConns: []gocbcore.MemdConnInfo{
+				{RemoteAddr: "10.0.0.2:11210"},
+				{RemoteAddr: "10.0.0.1:11210"},
+				{RemoteAddr: "10.0.0.1:11210"},
+			},
+		},
+		numReplicas: 2,
+		mgmtEps:     []string{"http://10.0.0.1:8091", "http://10.0.0.2:8091"},
+		n1qlEps:     []string{"http://10.0.0.2:8093"},
+	}
+
+	b := &Bucket{}
+	b.cacheClient(&mockClient{bucketName: "default", mockConfigProvider: provider})
+
+	snapshot, err := b.ConfigSnapshot()
+	if err != nil {
+		t.Fatalf("ConfigSnapshot failed: %v", err)
+	}
+
+	if snapshot.Revision != 1234 || snapshot.NumReplicas != 2 {
+		t.Fatalf("Unexpected snapshot %+v", snapshot)
+	}
+
+	if len(snapshot.Nodes) != 2 || snapshot.Nodes[0].Hostname != "10.0.0.1" || snapshot.Nodes[1].Hostname != "10.0.0.2" {
+		t.Fatalf("Unexpected nodes %+v", snapshot.Nodes)
+	}
+
+	expected := map[ServiceType]uint16{
+		ServiceTypeKeyValue:   11210,
+		ServiceTypeManagement: 8091,
+		ServiceTypeQuery:      8093,
+	}
+	ports := snapshot.Nodes[1].Ports
+	if len(ports) != len(expected) {
+		t.Fatalf("Unexpected ports %v", ports)
+	}
+	for service, port := range expected {
+		if ports[service] != port {
+			t.Fatalf("Unexpected ports %v", ports)
+		}
+	}
+}
+
+func TestBucketVbucketMapping(t *testing.T) {
+	provider := &mockConfigSnapshotProvider{
+		diag: gocbcore.DiagnosticInfo{
+			MemdConns: []gocbcore.MemdConnInfo{
+				{RemoteAddr: "10.0.0.1:11210"},
+				{RemoteAddr: ""},
+				{RemoteAddr: ""},
+				{RemoteAddr: "10.0.0.2:11210"},
+			},
+		},
+		numReplicas: 1,
+		mgmtEps:     []string{"http://10.0.0.1:8091", "http://10.0.0.2:8091"},
+		vbMap:       [][]int{{0, 1}, {1, 0}, {1, -1}},
+	}
+
+	b := &Bucket{}
+	b.cacheClient(&mockClient{bucketName: "default", mockConfigProvider: provider})
+
+	mapping, err := b.VbucketMapping("a")
+	if err != nil {
+		t.Fatalf("VbucketMapping failed: %v", err)
+	}
+
+	if mapping.VbucketID != 1 || mapping.Active != "10.0.0.2:11210" ||
+		len(mapping.Replicas) != 1 || mapping.Replicas[0] != "10.0.0.1:11210" {
+		t.Fatalf("Unexpected mapping %+v", mapping)
+	}
+
+	mapping, err = b.VbucketMapping("ab")
+	if err != nil {
+		t.Fatalf("VbucketMapping failed: %v", err)
+	}
+
+	if mapping.VbucketID != 2 || mapping.Active != "10.0.0.2:11210" ||
+		len(mapping.Replicas) != 1 || mapping.Replicas[0] != "" {
+		t.Fatalf("Unexpected mapping %+v", mapping)
+	}
+}
@@ -0,0 +1,88 @@
+package gocb
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeveloperPreviewError is returned when an API which depends on developer preview server
+// features is used by a Cluster connected without ClusterOptions.EnableDeveloperPreview set.
+// No request is sent to the server.
+type DeveloperPreviewError struct {
+	Feature string
+}
+
+func (e DeveloperPreviewError) Error() string {
+	return e.Feature + " requires developer preview mode, set ClusterOptions.EnableDeveloperPreview to use it"
+}
+
+// Unwrap returns ErrDeveloperPreviewNotEnabled.
+func (e DeveloperPreviewError) Unwrap() error {
+	return ErrDeveloperPreviewNotEnabled
+}
+
+// checkDeveloperPreview is called by preview-only APIs before sending any requests.
+func (sb *stateBlock) checkDeveloperPreview(feature string) error {
+	if sb.DeveloperPreview {
+		return nil
+	}
+
+	return DeveloperPreviewError{Feature: feature}
+}
+
+// IsDeveloperPreviewOptions is the set of options available to the IsDeveloperPreview operation.
+type IsDeveloperPreviewOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+type jsonPoolsDeveloperPreview struct {
+	IsDeveloperPreview bool `json:"isDeveloperPreview"`
+}
+
+// IsDeveloperPreview returns whether developer preview mode has been enabled on the cluster.
+// Developer preview mode cannot be disabled on a cluster once enabled, and is not supported
+// for production use.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) IsDeveloperPreview(opts *IsDeveloperPreviewOptions) (bool, error) {
+	if opts == nil {
+		opts = &IsDeveloperPreviewOptions{}
+	}
+
+	span := c.sb.Tracer.StartSpan("IsDeveloperPreview", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	req := mgmtRequest{
+		Service:       ServiceTypeManagement,
+		Method:        "GET",
+		Path:          "/pools",
+		IsIdempotent:  true,
+		RetryStrategy: opts.RetryStrategy,
+		Timeout:       opts.Timeout,
+		parentSpan:    span.Context(),
+	}
+
+	resp, err := c.executeMgmtRequest(req)
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode != 200 {
+		return false, makeMgmtBadStatusError("failed to get cluster information", &req, resp)
+	}
+
+	var poolsData jsonPoolsDeveloperPreview
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&poolsData)
+	if err != nil {
+		return false, err
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	return poolsData.IsDeveloperPreview, nil
+}
@@ -0,0 +1,124 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	cbsearch "github.com/couchbase/gocb/v2/search"
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestIsDeveloperPreview(t *testing.T) {
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			if req.Path != "/pools" {
+				t.Fatalf("Unexpected request path: %s", req.Path)
+			}
+
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(`{"isDeveloperPreview":true,"pools":[]}`), nil},
+			}, nil
+		},
+	}
+
+	c := &Cluster{
+		connections: map[string]client{
+			"mock": &mockClient{bucketName: "mock", mockHTTPProvider: provider},
+		},
+	}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.ManagementTimeout = time.Second
+
+	enabled, err := c.IsDeveloperPreview(nil)
+	if err != nil {
+		t.Fatalf("IsDeveloperPreview failed: %v", err)
+	}
+
+	if !enabled {
+		t.Fatalf("Expected developer preview to be enabled")
+	}
+}
+
+func TestCollectionManagerWithoutDeveloperPreview(t *testing.T) {
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(`{"uid":"0","scopes":[]}`), nil},
+			}, nil
+		},
+	}
+
+	b := &Bucket{}
+	b.sb.Tracer = &noopTracer{}
+	b.cacheClient(&mockClient{bucketName: "mock", mockHTTPProvider: provider})
+
+	_, err := b.Collections().GetAllScopes(nil)
+	if err != nil {
+		t.Fatalf("Expected GetAllScopes to succeed without developer preview but was %v", err)
+	}
+}
+
+func TestScopeQueryRequiresDeveloperPreview(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	c := testGetQueryCluster(provider)
+	c.sb.Tracer = &noopTracer{}
+	c.sb.QueryTimeout = time.Second
+
+	scope := &Scope{cluster: c}
+	scope.sb.BucketName = "travel"
+	scope.sb.ScopeName = "inventory"
+
+	_, err := scope.Query("SELECT * FROM airline", nil)
+	var previewErr DeveloperPreviewError
+	if !errors.As(err, &previewErr) {
+		t.Fatalf("Expected developer preview error but was %v", err)
+	}
+	if !errors.Is(err, ErrDeveloperPreviewNotEnabled) {
+		t.Fatalf("Expected error to wrap ErrDeveloperPreviewNotEnabled")
+	}
+	if previewErr.Feature != "Scope.Query" {
+		t.Fatalf("Expected feature to be Scope.Query but was %s", previewErr.Feature)
+	}
+	if len(provider.payloads) != 0 {
+		t.Fatalf("Expected no requests to be sent but was %d", len(provider.payloads))
+	}
+}
+
+func TestScopeSearchQueryRequiresDeveloperPreview(t *testing.T) {
+	provider := &mockSearchProvider{err: errors.New("no results")}
+	c := &Cluster{connections: map[string]client{
+		"mock": &mockClient{bucketName: "mock", mockSearchProvider: provider},
+	}}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.SearchTimeout = time.Second
+
+	scope := &Scope{cluster: c}
+	scope.sb.BucketName = "travel"
+	scope.sb.ScopeName = "inventory"
+
+	_, err := scope.SearchQuery("hotels", cbsearch.NewMatchQuery("hotel"), nil)
+	var previewErr DeveloperPreviewError
+	if !errors.As(err, &previewErr) {
+		t.Fatalf("Expected developer preview error but was %v", err)
+	}
+	if previewErr.Feature != "Scope.SearchQuery" {
+		t.Fatalf("Expected feature to be Scope.SearchQuery but was %s", previewErr.Feature)
+	}
+	if len(provider.payloads) != 0 {
+		t.Fatalf("Expected no requests to be sent but was %d", len(provider.payloads))
+	}
+}
+
+func TestDeveloperPreviewPassedToScopes(t *testing.T) {
+	c := &Cluster{}
+	c.sb.DeveloperPreview = true
+
+	scope := newBucket(&c.sb, "travel").Scope("inventory")
+	if err := scope.sb.checkDeveloperPreview("feature"); err != nil {
+		t.Fatalf("Expected developer preview to be enabled but was %v", err)
+	}
+}
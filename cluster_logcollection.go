@@ -0,0 +1,309 @@
+package gocb
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// LogRedactionLevel specifies how much redaction is applied to collected logs.
+type LogRedactionLevel string
+
+const (
+	// LogRedactionLevelNone indicates that collected logs are not redacted.
+	LogRedactionLevelNone = LogRedactionLevel("none")
+
+	// LogRedactionLevelPartial indicates that user data is redacted in an additional copy of
+	// the collected logs.
+	LogRedactionLevelPartial = LogRedactionLevel("partial")
+)
+
+// LoggingCollectionState specifies the state of a log collection task.
+type LoggingCollectionState string
+
+const (
+	// LoggingCollectionStateIdle indicates that no log collection has been run.
+	LoggingCollectionStateIdle = LoggingCollectionState("idle")
+
+	// LoggingCollectionStateRunning indicates that log collection is in progress.
+	LoggingCollectionStateRunning = LoggingCollectionState("running")
+
+	// LoggingCollectionStateCompleted indicates that the last log collection has finished.
+	LoggingCollectionStateCompleted = LoggingCollectionState("completed")
+
+	// LoggingCollectionStateCancelled indicates that the last log collection was cancelled.
+	LoggingCollectionStateCancelled = LoggingCollectionState("cancelled")
+)
+
+// LogCollectionManager provides methods for collecting the logs of the cluster nodes into
+// support bundles, and optionally uploading them to Couchbase support.
+// UNCOMMITTED: This API may change in the future.
+type LogCollectionManager struct {
+	httpClient           httpProvider
+	globalTimeout        time.Duration
+	defaultRetryStrategy *retryStrategyWrapper
+	tracer               RequestTracer
+}
+
+// LogCollectionUploadSettings specifies where collected logs are uploaded to.
+type LogCollectionUploadSettings struct {
+	// Host is the host to upload the logs to, for example uploads.couchbase.com.
+	Host string
+	// Customer is the name of the customer the logs are uploaded for.  It is required.
+	Customer string
+	// Ticket is the support ticket number the logs relate to.
+	Ticket string
+	// Proxy is the proxy to upload the logs through.
+	Proxy string
+}
+
+// StartLoggingCollectionOptions is the set of options available to the log collection manager
+// StartLoggingCollection operation.
+type StartLoggingCollectionOptions struct {
+	// Nodes are the OTP names of the nodes to collect logs from, for example ns_1@10.0.0.1.
+	// If empty then logs are collected from every node.
+	Nodes []string
+	// Upload, if set, uploads the collected logs once collection completes.
+	Upload *LogCollectionUploadSettings
+	// RedactionLevel is the redaction applied to the collected logs.  The server default is
+	// used if empty.
+	RedactionLevel LogRedactionLevel
+	// LogDir is the directory on each node in which to store the collected logs.
+	LogDir string
+	// TmpDir is the directory on each node used for temporary files during collection.
+	TmpDir string
+
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// StartLoggingCollection starts collecting logs on the cluster nodes.  Collection runs in the
+// background; use GetLoggingCollectionStatus to poll for its progress.
+func (lm *LogCollectionManager) StartLoggingCollection(opts *StartLoggingCollectionOptions) error {
+	if opts == nil {
+		opts = &StartLoggingCollectionOptions{}
+	}
+
+	span := lm.tracer.StartSpan("StartLoggingCollection", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	posts := url.Values{}
+	if len(opts.Nodes) == 0 {
+		posts.Add("nodes", "*")
+	} else {
+		posts.Add("nodes", strings.Join(opts.Nodes, ","))
+	}
+
+	if opts.Upload != nil {
+		if opts.Upload.Host == "" || opts.Upload.Customer == "" {
+			return makeInvalidArgumentsError("upload host and customer must be specified to upload logs")
+		}
+
+		posts.Add("uploadHost", opts.Upload.Host)
+		posts.Add("customer", opts.Upload.Customer)
+		if opts.Upload.Ticket != "" {
+			posts.Add("ticket", opts.Upload.Ticket)
+		}
+		if opts.Upload.Proxy != "" {
+			posts.Add("uploadProxy", opts.Upload.Proxy)
+		}
+	}
+
+	if opts.RedactionLevel != "" {
+		posts.Add("logRedactionLevel", string(opts.RedactionLevel))
+	}
+	if opts.LogDir != "" {
+		posts.Add("logDir", opts.LogDir)
+	}
+	if opts.TmpDir != "" {
+		posts.Add("tmpDir", opts.TmpDir)
+	}
+
+	return lm.doControllerRequest(span.Context(), "/controller/startLogsCollection", posts, opts.Timeout,
+		opts.RetryStrategy, "failed to start log collection")
+}
+
+// CancelLoggingCollectionOptions is the set of options available to the log collection manager
+// CancelLoggingCollection operation.
+type CancelLoggingCollectionOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// CancelLoggingCollection cancels the log collection which is in progress.
+func (lm *LogCollectionManager) CancelLoggingCollection(opts *CancelLoggingCollectionOptions) error {
+	if opts == nil {
+		opts = &CancelLoggingCollectionOptions{}
+	}
+
+	span := lm.tracer.StartSpan("CancelLoggingCollection", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	return lm.doControllerRequest(span.Context(), "/controller/cancelLogsCollection", url.Values{}, opts.Timeout,
+		opts.RetryStrategy, "failed to cancel log collection")
+}
+
+func (lm *LogCollectionManager) doControllerRequest(tracectx RequestSpanContext, path string, posts url.Values,
+	timeout time.Duration, strategy RetryStrategy, errMsg string) error {
+	if timeout == 0 {
+		timeout = lm.globalTimeout
+	}
+
+	retryStrategy := lm.defaultRetryStrategy.withStrategy(strategy)
+
+	req := &gocbcore.HTTPRequest{
+		Service:       gocbcore.ServiceType(ServiceTypeManagement),
+		Path:          path,
+		Method:        "POST",
+		Body:          []byte(posts.Encode()),
+		ContentType:   "application/x-www-form-urlencoded",
+		Timeout:       timeout,
+		RetryStrategy: retryStrategy,
+		UniqueID:      uuid.New().String(),
+	}
+
+	dspan := lm.tracer.StartSpan("dispatch", tracectx)
+	resp, err := lm.httpClient.DoHTTPRequest(req)
+	dspan.Finish()
+	if err != nil {
+		return makeGenericHTTPError(err, req, resp)
+	}
+
+	if resp.StatusCode != 200 {
+		return makeHTTPBadStatusError(errMsg, req, resp)
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	return nil
+}
+
+// LoggingCollectionNodeStatus is the log collection status of a single node.
+type LoggingCollectionNodeStatus struct {
+	// Status is the state of collection on the node, for example collecting, collected,
+	// uploading, uploaded or failed.
+	Status string
+	// Path is the path on the node of the collected logs.
+	Path string
+	// URL is the location the logs were uploaded to, if they were uploaded.
+	URL string
+}
+
+// LoggingCollectionStatus is the status of the most recent log collection.
+type LoggingCollectionStatus struct {
+	State LoggingCollectionState
+	// Progress is the completion of the collection as a percentage.
+	Progress int
+	// Nodes maps the OTP name of each node to its collection status.
+	Nodes map[string]LoggingCollectionNodeStatus
+}
+
+type jsonLoggingCollectionNodeStatus struct {
+	Status string `json:"status"`
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+}
+
+type jsonClusterTask struct {
+	Type     string                                     `json:"type"`
+	Status   string                                     `json:"status"`
+	Progress json.Number                                `json:"progress"`
+	PerNode  map[string]jsonLoggingCollectionNodeStatus `json:"perNode"`
+}
+
+// GetLoggingCollectionStatusOptions is the set of options available to the log collection
+// manager GetLoggingCollectionStatus operation.
+type GetLoggingCollectionStatusOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// GetLoggingCollectionStatus returns the status of the most recent log collection.
+func (lm *LogCollectionManager) GetLoggingCollectionStatus(opts *GetLoggingCollectionStatusOptions) (*LoggingCollectionStatus, error) {
+	if opts == nil {
+		opts = &GetLoggingCollectionStatusOptions{}
+	}
+
+	span := lm.tracer.StartSpan("GetLoggingCollectionStatus", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = lm.globalTimeout
+	}
+
+	retryStrategy := lm.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
+
+	req := &gocbcore.HTTPRequest{
+		Service:       gocbcore.ServiceType(ServiceTypeManagement),
+		Path:          "/pools/default/tasks",
+		Method:        "GET",
+		IsIdempotent:  true,
+		Timeout:       timeout,
+		RetryStrategy: retryStrategy,
+		UniqueID:      uuid.New().String(),
+	}
+
+	dspan := lm.tracer.StartSpan("dispatch", span.Context())
+	resp, err := lm.httpClient.DoHTTPRequest(req)
+	dspan.Finish()
+	if err != nil {
+		return nil, makeGenericHTTPError(err, req, resp)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, makeHTTPBadStatusError("failed to get log collection status", req, resp)
+	}
+
+	var tasksData []jsonClusterTask
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&tasksData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	status := &LoggingCollectionStatus{
+		State: LoggingCollectionStateIdle,
+		Nodes: make(map[string]LoggingCollectionNodeStatus),
+	}
+	for _, task := range tasksData {
+		if task.Type != "clusterLogsCollection" {
+			continue
+		}
+
+		status.State = LoggingCollectionState(task.Status)
+		if task.Progress != "" {
+			progress, err := strconv.ParseFloat(string(task.Progress), 64)
+			if err != nil {
+				return nil, err
+			}
+			status.Progress = int(progress)
+		}
+		for node, nodeData := range task.PerNode {
+			status.Nodes[node] = LoggingCollectionNodeStatus{
+				Status: nodeData.Status,
+				Path:   nodeData.Path,
+				URL:    nodeData.URL,
+			}
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,114 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func testLogCollectionManager(doFn func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error)) *LogCollectionManager {
+	return &LogCollectionManager{
+		httpClient:    &mockHTTPProvider{doFn: doFn},
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+	}
+}
+
+func TestLogCollectionMgrStart(t *testing.T) {
+	var reqs []*gocbcore.HTTPRequest
+	mgr := testLogCollectionManager(func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+		reqs = append(reqs, req)
+		return &gocbcore.HTTPResponse{
+			StatusCode: 200,
+			Body:       &testReadCloser{bytes.NewBufferString(""), nil},
+		}, nil
+	})
+
+	err := mgr.StartLoggingCollection(&StartLoggingCollectionOptions{
+		Nodes: []string{"ns_1@10.0.0.1", "ns_1@10.0.0.2"},
+		Upload: &LogCollectionUploadSettings{
+			Host:     "uploads.couchbase.com",
+			Customer: "acme",
+			Ticket:   "1234",
+		},
+		RedactionLevel: LogRedactionLevelPartial,
+	})
+	if err != nil {
+		t.Fatalf("StartLoggingCollection failed: %v", err)
+	}
+
+	if len(reqs) != 1 || reqs[0].Method != "POST" || reqs[0].Path != "/controller/startLogsCollection" {
+		t.Fatalf("Unexpected requests %+v", reqs)
+	}
+
+	form, err := url.ParseQuery(string(reqs[0].Body))
+	if err != nil {
+		t.Fatalf("Failed to parse request body: %v", err)
+	}
+
+	expected := map[string]string{
+		"nodes":             "ns_1@10.0.0.1,ns_1@10.0.0.2",
+		"uploadHost":        "uploads.couchbase.com",
+		"customer":          "acme",
+		"ticket":            "1234",
+		"logRedactionLevel": "partial",
+	}
+	if len(form) != len(expected) {
+		t.Fatalf("Unexpected request body %v", form)
+	}
+	for k, v := range expected {
+		if form.Get(k) != v {
+			t.Fatalf("Expected %s to be %s but was %s", k, v, form.Get(k))
+		}
+	}
+
+	err = mgr.StartLoggingCollection(&StartLoggingCollectionOptions{
+		Upload: &LogCollectionUploadSettings{Host: "uploads.couchbase.com"},
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	err = mgr.CancelLoggingCollection(nil)
+	if err != nil {
+		t.Fatalf("CancelLoggingCollection failed: %v", err)
+	}
+
+	if len(reqs) != 2 || reqs[1].Path != "/controller/cancelLogsCollection" {
+		t.Fatalf("Unexpected requests %+v", reqs)
+	}
+}
+
+func TestLogCollectionMgrStatus(t *testing.T) {
+	mgr := testLogCollectionManager(func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+		if req.Path != "/pools/default/tasks" {
+			t.Fatalf("Unexpected request path: %s", req.Path)
+		}
+
+		return &gocbcore.HTTPResponse{
+			StatusCode: 200,
+			Body: &testReadCloser{bytes.NewBufferString(`[{"type":"rebalance","status":"notRunning"},` +
+				`{"type":"clusterLogsCollection","status":"running","progress":55.5,"perNode":{` +
+				`"ns_1@10.0.0.1":{"status":"collected","path":"/tmp/a.zip"},` +
+				`"ns_1@10.0.0.2":{"status":"uploaded","path":"/tmp/b.zip","url":"https://u/b.zip"}}}]`), nil},
+		}, nil
+	})
+
+	status, err := mgr.GetLoggingCollectionStatus(nil)
+	if err != nil {
+		t.Fatalf("GetLoggingCollectionStatus failed: %v", err)
+	}
+
+	if status.State != LoggingCollectionStateRunning || status.Progress != 55 || len(status.Nodes) != 2 {
+		t.Fatalf("Unexpected status %+v", status)
+	}
+
+	node := status.Nodes["ns_1@10.0.0.2"]
+	if node.Status != "uploaded" || node.Path != "/tmp/b.zip" || node.URL != "https://u/b.zip" {
+		t.Fatalf("Unexpected node status %+v", node)
+	}
+}
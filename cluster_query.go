@@ -2,6 +2,8 @@ package gocb
 
 import (
 	"encoding/json"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	gocbcore "github.com/couchbase/gocbcore/v8"
@@ -13,6 +15,116 @@ type queryCacheEntry struct {
 	encodedPlan string
 }
 
+type queryCacheCounters struct {
+	hits            uint64
+	misses          uint64
+	prepares        uint64
+	prepareTimeNano uint64
+}
+
+// QueryCacheStats provides statistics about the usage of the prepared statement cache
+// used when executing queries with Adhoc set to false.
+type QueryCacheStats struct {
+	// Hits is the number of queries executed using a cached prepared statement.
+	Hits uint64
+	// Misses is the number of queries which required a statement to be prepared.
+	Misses uint64
+	// Prepares is the number of PREPARE requests successfully sent to the server.
+	Prepares uint64
+	// TotalPrepareTime is the total time spent preparing statements.
+	TotalPrepareTime time.Duration
+	// Entries is the number of statements currently held in the cache.
+	Entries int
+}
+
+// QueryCacheStats returns statistics about the usage of the prepared statement cache.
+func (c *Cluster) QueryCacheStats() QueryCacheStats {
+	c.clusterLock.RLock()
+	entries := len(c.queryCache)
+	c.clusterLock.RUnlock()
+
+	return QueryCacheStats{
+		Hits:             atomic.LoadUint64(&c.queryCacheStats.hits),
+		Misses:           atomic.LoadUint64(&c.queryCacheStats.misses),
+		Prepares:         atomic.LoadUint64(&c.queryCacheStats.prepares),
+		TotalPrepareTime: time.Duration(atomic.LoadUint64(&c.queryCacheStats.prepareTimeNano)),
+		Entries:          entries,
+	}
+}
+
+// QueryCacheEntry is an exported representation of a prepared statement held in the
+// prepared statement cache.  It can be serialized and later imported via
+// ImportQueryCache to avoid having to re-prepare statements, for instance after a
+// restart.
+type QueryCacheEntry struct {
+	Statement string `json:"statement"`
+	// QueryContext is the query context which the statement was prepared against, if any.
+	QueryContext string `json:"query_context,omitempty"`
+	Name         string `json:"name"`
+	EncodedPlan  string `json:"encoded_plan,omitempty"`
+	Enhanced     bool   `json:"enhanced,omitempty"`
+}
+
+// queryCacheKey returns the key of a statement in the prepared statement cache.  A statement
+// is prepared against its query context, so the same statement used with different query
+// contexts is cached separately.
+func queryCacheKey(statement, queryContext string) string {
+	if queryContext == "" {
+		return statement
+	}
+	return queryContext + "\x00" + statement
+}
+
+func splitQueryCacheKey(key string) (string, string) {
+	if idx := strings.IndexByte(key, 0); idx >= 0 {
+		return key[idx+1:], key[:idx]
+	}
+	return key, ""
+}
+
+// ExportQueryCache returns the current contents of the prepared statement cache.
+func (c *Cluster) ExportQueryCache() []QueryCacheEntry {
+	c.clusterLock.RLock()
+	defer c.clusterLock.RUnlock()
+
+	entries := make([]QueryCacheEntry, 0, len(c.queryCache))
+	for key, entry := range c.queryCache {
+		statement, queryContext := splitQueryCacheKey(key)
+		entries = append(entries, QueryCacheEntry{
+			Statement:    statement,
+			QueryContext: queryContext,
+			Name:         entry.name,
+			EncodedPlan:  entry.encodedPlan,
+			Enhanced:     entry.enhanced,
+		})
+	}
+
+	return entries
+}
+
+// ImportQueryCache adds previously exported entries to the prepared statement cache,
+// replacing any existing entries for the same statements.  Entries which are no longer
+// valid on the server are transparently re-prepared when they are next used.
+func (c *Cluster) ImportQueryCache(entries []QueryCacheEntry) error {
+	for _, entry := range entries {
+		if entry.Statement == "" || entry.Name == "" {
+			return makeInvalidArgumentsError("query cache entries must have a statement and name")
+		}
+	}
+
+	c.clusterLock.Lock()
+	for _, entry := range entries {
+		c.queryCache[queryCacheKey(entry.Statement, entry.QueryContext)] = &queryCacheEntry{
+			enhanced:    entry.Enhanced,
+			name:        entry.Name,
+			encodedPlan: entry.EncodedPlan,
+		}
+	}
+	c.clusterLock.Unlock()
+
+	return nil
+}
+
 type jsonQueryMetrics struct {
 	ElapsedTime   string `json:"elapsedTime"`
 	ExecutionTime string `json:"executionTime"`
@@ -131,14 +243,16 @@ func (meta *QueryMetaData) fromData(data jsonQueryResponse) error {
 
 // QueryResult allows access to the results of a query.
 type QueryResult struct {
-	reader *gocbcore.N1QLRowReader
+	reader rowReader
 
 	rowBytes []byte
+
+	clientContextID string
 }
 
-func newQueryResult(reader *gocbcore.N1QLRowReader) (*QueryResult, error) {
+func newQueryResult(reader rowReader) (*QueryResult, error) {
 	return &QueryResult{
-		reader: reader,
+		reader: newCloseOnceRowReader(reader),
 	}, nil
 }
 
@@ -167,12 +281,21 @@ func (r *QueryResult) Row(valuePtr interface{}) error {
 	return json.Unmarshal(r.rowBytes, valuePtr)
 }
 
+// RawBytes returns the undecoded contents of the current row.  This allows the
+// cost of decoding to be deferred or skipped entirely, for instance when proxying
+// rows elsewhere.  The returned bytes are only valid until the next call to Next.
+func (r *QueryResult) RawBytes() []byte {
+	return r.rowBytes
+}
+
 // Err returns any errors that have occurred on the stream
 func (r *QueryResult) Err() error {
 	return r.reader.Err()
 }
 
 // Close marks the results as closed, returning any errors that occurred during reading the results.
+// Closing releases the underlying connection even if rows remain unread.  It is safe to call Close
+// more than once, and after an error has occurred, in which case the same result is returned each time.
 func (r *QueryResult) Close() error {
 	return r.reader.Close()
 }
@@ -185,6 +308,11 @@ func (r *QueryResult) One(valuePtr interface{}) error {
 	// Read the bytes from the first row
 	valueBytes := r.reader.NextRow()
 	if valueBytes == nil {
+		err := r.Close()
+		if err != nil {
+			return err
+		}
+
 		return ErrNoResult
 	}
 
@@ -193,6 +321,11 @@ func (r *QueryResult) One(valuePtr interface{}) error {
 		// do nothing with the row
 	}
 
+	err := r.Close()
+	if err != nil {
+		return err
+	}
+
 	return json.Unmarshal(valueBytes, valuePtr)
 }
 
@@ -217,31 +350,50 @@ func (r *QueryResult) MetaData() (*QueryMetaData, error) {
 		return nil, err
 	}
 
+	if metaData.ClientContextID == "" {
+		metaData.ClientContextID = r.clientContextID
+	}
+
 	return &metaData, nil
 }
 
 // Query executes the query statement on the server.
 func (c *Cluster) Query(statement string, opts *QueryOptions) (*QueryResult, error) {
+	return c.query(statement, c.sb.QueryContext, opts)
+}
+
+// query executes the query statement against the query context given, unless the options
+// specify a different query context using Raw.
+func (c *Cluster) query(statement, queryContext string, opts *QueryOptions) (resOut *QueryResult, errOut error) {
 	if opts == nil {
 		opts = &QueryOptions{}
 	}
 
-	span := c.sb.Tracer.StartSpan("Query", opts.parentSpan).
+	span := c.sb.Tracer.StartSpan("Query", opts.ParentSpan).
 		SetTag("couchbase.service", "query")
+	span = applyOperationTags(span, opts.Tags)
 	defer span.Finish()
+	defer func() {
+		setSpanOutcome(span, errOut)
+	}()
 
-	timeout := c.sb.QueryTimeout
+	timeout := c.sb.queryTimeout()
 	if opts.Timeout != 0 && opts.Timeout < timeout {
 		timeout = opts.Timeout
 	}
-	deadline := time.Now().Add(timeout)
+	deadline := contextDeadline(opts.Context, clockOrSystem(c.sb.Clock).Now().Add(timeout))
+
+	retryStrategy := c.sb.RetryStrategyWrapper.withStrategy(opts.RetryStrategy)
 
-	retryStrategy := c.sb.RetryStrategyWrapper
-	if opts.RetryStrategy != nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	if opts.Context != nil && opts.Context.Err() != nil {
+		return nil, QueryError{
+			InnerError:      opts.Context.Err(),
+			Statement:       statement,
+			ClientContextID: opts.ClientContextID,
+		}
 	}
 
-	queryOpts, err := opts.toMap()
+	queryOpts, err := opts.toMap(c.sb.Serializer)
 	if err != nil {
 		return nil, QueryError{
 			InnerError:      wrapError(err, "failed to generate query options"),
@@ -250,13 +402,82 @@ func (c *Cluster) Query(statement string, opts *QueryOptions) (*QueryResult, err
 		}
 	}
 
+	clientContextID := maybeGetQueryOption(queryOpts, "client_context_id")
+
+	if c.sb.ReadOnly {
+		if err := checkReadOnlyStatement(statement); err != nil {
+			return nil, QueryError{
+				InnerError:      err,
+				Statement:       statement,
+				ClientContextID: clientContextID,
+			}
+		}
+		queryOpts["readonly"] = true
+	}
+
 	queryOpts["statement"] = statement
+	if _, ok := queryOpts["query_context"]; !ok && queryContext != "" {
+		queryOpts["query_context"] = queryContext
+	}
 
-	if !opts.Adhoc {
-		return c.execPreparedN1qlQuery(span, queryOpts, deadline, retryStrategy)
+	if opts.RetryOnStreamReset {
+		if err := checkReadOnlyStatement(statement); err != nil {
+			return nil, QueryError{
+				InnerError:      makeInvalidArgumentsError("RetryOnStreamReset cannot be used with statements which modify data"),
+				Statement:       statement,
+				ClientContextID: clientContextID,
+			}
+		}
+		// The statement is not fully parsed, so the server must reject anything which would
+		// modify data, otherwise it could be run again when the stream is reset.
+		queryOpts["readonly"] = true
 	}
 
-	return c.execN1qlQuery(span, queryOpts, deadline, retryStrategy)
+	releaseLimit, err := c.sb.HTTPLimiter.Acquire(deadline)
+	if err != nil {
+		return nil, QueryError{
+			InnerError:      err,
+			Statement:       statement,
+			ClientContextID: clientContextID,
+		}
+	}
+
+	// Preparing the statement modifies the options, so each dispatch is given its own copy
+	// of them.  The copies share the client_context_id, so a re-dispatch can be correlated
+	// with the original request.
+	dispatch := func() (*QueryResult, error) {
+		dispatchOpts := make(map[string]interface{}, len(queryOpts))
+		for k, v := range queryOpts {
+			dispatchOpts[k] = v
+		}
+
+		if !opts.Adhoc {
+			return c.execPreparedN1qlQuery(span, dispatchOpts, deadline, retryStrategy)
+		}
+		return c.execN1qlQuery(span, dispatchOpts, deadline, retryStrategy)
+	}
+
+	res, err := dispatch()
+	if err != nil {
+		releaseLimit()
+		return nil, err
+	}
+
+	res.clientContextID = clientContextID
+
+	if opts.RetryOnStreamReset {
+		res.reader = newResumingRowReader(res.reader, func() (rowReader, error) {
+			res, err := dispatch()
+			if err != nil {
+				return nil, err
+			}
+			return res.reader, nil
+		})
+	}
+
+	res.reader = newLimitedRowReader(newCtxRowReader(opts.Context, c.sb.Workers, res.reader, nil), releaseLimit)
+
+	return res, nil
 }
 
 func maybeGetQueryOption(options map[string]interface{}, name string) string {
@@ -267,7 +488,7 @@ func maybeGetQueryOption(options map[string]interface{}, name string) string {
 }
 
 func (c *Cluster) execN1qlQuery(
-	span requestSpan,
+	span RequestSpan,
 	options map[string]interface{},
 	deadline time.Time,
 	retryStrategy *retryStrategyWrapper,
@@ -281,6 +502,18 @@ func (c *Cluster) execN1qlQuery(
 		}
 	}
 
+	// Each request only receives what remains of the overall budget, so that the
+	// steps of a prepared query never exceed the timeout of the query as a whole.
+	remaining := deadline.Sub(clockOrSystem(c.sb.Clock).Now())
+	if remaining <= 0 {
+		return nil, QueryError{
+			InnerError:      ErrUnambiguousTimeout,
+			Statement:       maybeGetQueryOption(options, "statement"),
+			ClientContextID: maybeGetQueryOption(options, "client_context_id"),
+		}
+	}
+	options["timeout"] = remaining.String()
+
 	reqBytes, err := json.Marshal(options)
 	if err != nil {
 		return nil, QueryError{
@@ -296,6 +529,7 @@ func (c *Cluster) execN1qlQuery(
 		Deadline:      deadline,
 	})
 	if err != nil {
+		c.sb.Telemetry.recordError("query", err)
 		return nil, maybeEnhanceQueryError(err)
 	}
 
@@ -304,7 +538,7 @@ func (c *Cluster) execN1qlQuery(
 }
 
 func (c *Cluster) execPreparedN1qlQuery(
-	span requestSpan,
+	span RequestSpan,
 	options map[string]interface{},
 	deadline time.Time,
 	retryStrategy *retryStrategyWrapper,
@@ -313,7 +547,7 @@ func (c *Cluster) execPreparedN1qlQuery(
 }
 
 func (c *Cluster) execEnhPreparedN1qlQuery(
-	span requestSpan,
+	span RequestSpan,
 	options map[string]interface{},
 	deadline time.Time,
 	retryStrategy *retryStrategyWrapper,
@@ -330,7 +564,7 @@ func (c *Cluster) execEnhPreparedN1qlQuery(
 }
 
 func (c *Cluster) execOldPreparedN1qlQuery(
-	span requestSpan,
+	span RequestSpan,
 	options map[string]interface{},
 	deadline time.Time,
 	retryStrategy *retryStrategyWrapper,
@@ -340,8 +574,10 @@ func (c *Cluster) execOldPreparedN1qlQuery(
 		return nil, newCliInternalError("statement was not a string")
 	}
 
+	cacheKey := queryCacheKey(statement, maybeGetQueryOption(options, "query_context"))
+
 	c.clusterLock.RLock()
-	cachedStmt := c.queryCache[statement]
+	cachedStmt := c.queryCache[cacheKey]
 	c.clusterLock.RUnlock()
 
 	// Try to execute the cached query
@@ -353,16 +589,23 @@ func (c *Cluster) execOldPreparedN1qlQuery(
 
 		results, err := c.execN1qlQuery(span, options, deadline, retryStrategy)
 		if err == nil {
+			atomic.AddUint64(&c.queryCacheStats.hits, 1)
+			meterIncrement(c.sb.Meter, meterNameQueryCache, map[string]string{meterTagCacheResult: cacheResultHit})
 			return results, nil
 		}
 	}
 
+	atomic.AddUint64(&c.queryCacheStats.misses, 1)
+	meterIncrement(c.sb.Meter, meterNameQueryCache, map[string]string{meterTagCacheResult: cacheResultMiss})
+
 	// Try to prepare the query
 	delete(options, "prepared")
 	delete(options, "encoded_plan")
 	delete(options, "auto_execute")
 	options["statement"] = "PREPARE " + statement
 
+	clk := clockOrSystem(c.sb.Clock)
+	prepareStart := clk.Now()
 	cacheRes, err := c.execN1qlQuery(span, options, deadline, retryStrategy)
 	if err != nil {
 		return nil, err
@@ -374,12 +617,17 @@ func (c *Cluster) execOldPreparedN1qlQuery(
 		return nil, err
 	}
 
+	atomic.AddUint64(&c.queryCacheStats.prepares, 1)
+	prepareTime := clk.Now().Sub(prepareStart)
+	atomic.AddUint64(&c.queryCacheStats.prepareTimeNano, uint64(prepareTime))
+	meterRecordDuration(c.sb.Meter, meterNameQueryPrepares, nil, prepareTime)
+
 	cachedStmt = &queryCacheEntry{}
 	cachedStmt.name = prepData.Name
 	cachedStmt.encodedPlan = prepData.EncodedPlan
 
 	c.clusterLock.Lock()
-	c.queryCache[statement] = cachedStmt
+	c.queryCache[cacheKey] = cachedStmt
 	c.clusterLock.Unlock()
 
 	// Attempt to execute our cached query plan
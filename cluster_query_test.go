@@ -0,0 +1,408 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+type mockQueryProvider struct {
+	payloads [][]byte
+	err      error
+}
+
+func (p *mockQueryProvider) N1QLQuery(opts gocbcore.N1QLQueryOptions) (*gocbcore.N1QLRowReader, error) {
+	p.payloads = append(p.payloads, opts.Payload)
+	return nil, p.err
+}
+
+func testGetQueryCluster(provider queryProvider) *Cluster {
+	clients := make(map[string]client)
+	clients["mock"] = &mockClient{
+		bucketName:        "mock",
+		mockQueryProvider: provider,
+	}
+
+	return &Cluster{
+		connections: clients,
+		queryCache:  make(map[string]*queryCacheEntry),
+	}
+}
+
+func TestQueryCacheExportImport(t *testing.T) {
+	c := &Cluster{
+		queryCache: map[string]*queryCacheEntry{
+			"SELECT 1": {
+				name:        "p1",
+				encodedPlan: "plan1",
+			},
+		},
+	}
+
+	entries := c.ExportQueryCache()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry but had %d", len(entries))
+	}
+
+	if entries[0].Statement != "SELECT 1" || entries[0].Name != "p1" || entries[0].EncodedPlan != "plan1" {
+		t.Fatalf("Exported entry was not correct: %+v", entries[0])
+	}
+
+	imported := &Cluster{
+		queryCache: make(map[string]*queryCacheEntry),
+	}
+	err := imported.ImportQueryCache(entries)
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	entry := imported.queryCache["SELECT 1"]
+	if entry == nil {
+		t.Fatalf("Expected entry to have been imported")
+	}
+
+	if entry.name != "p1" || entry.encodedPlan != "plan1" {
+		t.Fatalf("Imported entry was not correct: %+v", entry)
+	}
+
+	if imported.QueryCacheStats().Entries != 1 {
+		t.Fatalf("Expected cache stats to report 1 entry but was %d", imported.QueryCacheStats().Entries)
+	}
+}
+
+func TestQueryCacheImportInvalid(t *testing.T) {
+	c := &Cluster{
+		queryCache: make(map[string]*queryCacheEntry),
+	}
+
+	err := c.ImportQueryCache([]QueryCacheEntry{{Statement: "SELECT 1"}})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestQueryPlanFromData(t *testing.T) {
+	var explain jsonQueryExplain
+	err := loadJSONTestDataset("query_explain_covered", &explain)
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	var plan QueryPlan
+	err = plan.fromData(explain.Plan)
+	if err != nil {
+		t.Fatalf("Failed to parse plan: %v", err)
+	}
+
+	if plan.Operator != "Sequence" {
+		t.Fatalf("Expected root operator to be Sequence but was %s", plan.Operator)
+	}
+
+	if len(plan.Children) != 2 {
+		t.Fatalf("Expected root to have 2 children but had %d", len(plan.Children))
+	}
+
+	scan := plan.Children[0]
+	if scan.Operator != "IndexScan3" || scan.Keyspace != "travel-sample" || len(scan.Spans) != 1 {
+		t.Fatalf("Index scan was not parsed correctly: %+v", scan)
+	}
+
+	indexes := plan.IndexesUsed()
+	if len(indexes) != 1 || indexes[0] != "def_type" {
+		t.Fatalf("Expected def_type to be the only index used but was %v", indexes)
+	}
+
+	if !plan.IsCovered() {
+		t.Fatalf("Expected plan to be covered")
+	}
+
+	parallel := plan.Children[1]
+	if len(parallel.Children) != 1 || len(parallel.Children[0].Children) != 2 {
+		t.Fatalf("Parallel operator children were not parsed correctly: %+v", parallel)
+	}
+}
+
+func TestQueryServerTimeoutFromDeadline(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	c := testGetQueryCluster(provider)
+
+	options := map[string]interface{}{"statement": "SELECT 1"}
+	_, err := c.execN1qlQuery(nil, options, time.Now().Add(2*time.Second), nil)
+	if err == nil {
+		t.Fatalf("Expected the provider error to be returned")
+	}
+
+	if len(provider.payloads) != 1 {
+		t.Fatalf("Expected 1 request but was %d", len(provider.payloads))
+	}
+
+	var payload map[string]interface{}
+	err = json.Unmarshal(provider.payloads[0], &payload)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	timeoutStr, ok := payload["timeout"].(string)
+	if !ok {
+		t.Fatalf("Expected payload to contain a timeout but was %v", payload["timeout"])
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		t.Fatalf("Failed to parse timeout: %v", err)
+	}
+
+	if timeout <= 0 || timeout > 2*time.Second {
+		t.Fatalf("Expected timeout to be within the remaining budget but was %s", timeout)
+	}
+}
+
+func TestQueryDeadlineExhausted(t *testing.T) {
+	provider := &mockQueryProvider{}
+	c := testGetQueryCluster(provider)
+
+	options := map[string]interface{}{"statement": "SELECT 1"}
+	_, err := c.execOldPreparedN1qlQuery(nil, options, time.Now().Add(-time.Millisecond), nil)
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout but was %v", err)
+	}
+
+	if len(provider.payloads) != 0 {
+		t.Fatalf("Expected no requests to be sent but was %d", len(provider.payloads))
+	}
+}
+
+func TestQueryDefaultQueryContext(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	c := testGetQueryCluster(provider)
+	c.sb.Tracer = &noopTracer{}
+	c.sb.Serializer = NewDefaultJSONSerializer()
+	c.sb.QueryTimeout = time.Second
+	c.sb.QueryContext = QueryContext{BucketName: "travel", ScopeName: "inventory"}.String()
+
+	_, _ = c.Query("SELECT * FROM airline", &QueryOptions{Adhoc: true})
+	_, _ = c.Query("SELECT * FROM airline", &QueryOptions{
+		Adhoc: true,
+		Raw:   map[string]interface{}{"query_context": "default:`other`"},
+	})
+
+	if len(provider.payloads) != 2 {
+		t.Fatalf("Expected 2 requests but was %d", len(provider.payloads))
+	}
+
+	expected := []string{"default:`travel`.`inventory`", "default:`other`"}
+	for i, payloadBytes := range provider.payloads {
+		var payload map[string]interface{}
+		err := json.Unmarshal(payloadBytes, &payload)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal payload: %v", err)
+		}
+
+		if payload["query_context"] != expected[i] {
+			t.Fatalf("Expected query context %s but was %v", expected[i], payload["query_context"])
+		}
+	}
+}
+
+func TestQueryContextString(t *testing.T) {
+	if (QueryContext{}).String() != "" {
+		t.Fatalf("Expected empty query context to be empty")
+	}
+
+	bucketContext := QueryContext{BucketName: "travel"}
+	if bucketContext.String() != "default:`travel`" {
+		t.Fatalf("Unexpected query context %s", bucketContext.String())
+	}
+
+	escapedContext := QueryContext{BucketName: "tra`vel", ScopeName: "in`vent`ory"}
+	if escapedContext.String() != "default:`tra``vel`.`in``vent``ory`" {
+		t.Fatalf("Unexpected query context %s", escapedContext.String())
+	}
+
+	scopeContext := QueryContext{ScopeName: "inventory"}
+	if !errors.Is(scopeContext.validate(), ErrInvalidArgument) {
+		t.Fatalf("Expected query context without a bucket to be invalid")
+	}
+}
+
+func TestQueryRetryOnStreamResetRejectsMutations(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	c := testGetQueryCluster(provider)
+	c.sb.Tracer = &noopTracer{}
+	c.sb.Serializer = NewDefaultJSONSerializer()
+	c.sb.QueryTimeout = time.Second
+
+	_, err := c.Query("DELETE FROM airline", &QueryOptions{RetryOnStreamReset: true})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	if len(provider.payloads) != 0 {
+		t.Fatalf("Expected no requests to be sent but was %d", len(provider.payloads))
+	}
+}
+
+func TestQueryRetryOnStreamResetSendsReadOnly(t *testing.T) {
+	statements := []string{"SELECT * FROM airline ORDER BY id", "EXECUTE p1", "/* unterminated DELETE FROM airline"}
+	for _, statement := range statements {
+		provider := &mockQueryProvider{err: errors.New("no results")}
+		c := testGetQueryCluster(provider)
+		c.sb.Tracer = &noopTracer{}
+		c.sb.Serializer = NewDefaultJSONSerializer()
+		c.sb.QueryTimeout = time.Second
+
+		_, _ = c.Query(statement, &QueryOptions{RetryOnStreamReset: true, Adhoc: true})
+		if len(provider.payloads) != 1 {
+			t.Fatalf("Expected 1 request for %q but was %d", statement, len(provider.payloads))
+		}
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(provider.payloads[0], &payload); err != nil {
+			t.Fatalf("Failed to unmarshal payload: %v", err)
+		}
+
+		if payload["readonly"] != true {
+			t.Fatalf("Expected %q to be sent as readonly but was %v", statement, payload["readonly"])
+		}
+	}
+}
+
+func TestQueryGeneratedClientContextID(t *testing.T) {
+	opts := &QueryOptions{}
+	execOpts, err := opts.toMap(NewDefaultJSONSerializer())
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if clientContextID, ok := execOpts["client_context_id"].(string); !ok || clientContextID == "" {
+		t.Fatalf("Expected a generated client context id but was %v", execOpts["client_context_id"])
+	}
+
+	c := testGetQueryCluster(&mockQueryProvider{})
+	c.sb.Tracer = &noopTracer{}
+	c.sb.Serializer = NewDefaultJSONSerializer()
+	c.sb.QueryTimeout = time.Second
+	c.sb.ReadOnly = true
+
+	_, err = c.Query("DELETE FROM travel", nil)
+	var queryErr QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("Expected query error but was %v", err)
+	}
+	if queryErr.ClientContextID == "" {
+		t.Fatalf("Expected the generated client context id to be reported")
+	}
+}
+
+func TestScopeQuery(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	c := testGetQueryCluster(provider)
+	c.sb.Tracer = &noopTracer{}
+	c.sb.Serializer = NewDefaultJSONSerializer()
+	c.sb.QueryTimeout = time.Second
+	c.sb.QueryContext = QueryContext{BucketName: "other"}.String()
+
+	scope := &Scope{cluster: c}
+	scope.sb.BucketName = "travel"
+	scope.sb.ScopeName = "inventory"
+	scope.sb.DeveloperPreview = true
+
+	_, _ = scope.Query("SELECT * FROM airline", &QueryOptions{Adhoc: true})
+	_, _ = scope.Query("SELECT * FROM airline", nil)
+
+	if len(provider.payloads) != 2 {
+		t.Fatalf("Expected 2 requests but was %d", len(provider.payloads))
+	}
+
+	expected := []string{"SELECT * FROM airline", "PREPARE SELECT * FROM airline"}
+	for i, payloadBytes := range provider.payloads {
+		var payload map[string]interface{}
+		err := json.Unmarshal(payloadBytes, &payload)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal payload: %v", err)
+		}
+
+		if payload["statement"] != expected[i] {
+			t.Fatalf("Expected statement %s but was %v", expected[i], payload["statement"])
+		}
+		if payload["query_context"] != "default:`travel`.`inventory`" {
+			t.Fatalf("Expected scope query context but was %v", payload["query_context"])
+		}
+	}
+}
+
+func TestQueryCacheQueryContext(t *testing.T) {
+	c := &Cluster{
+		queryCache: make(map[string]*queryCacheEntry),
+	}
+
+	err := c.ImportQueryCache([]QueryCacheEntry{
+		{Statement: "SELECT * FROM airline", Name: "p1"},
+		{Statement: "SELECT * FROM airline", QueryContext: "default:`travel`.`inventory`", Name: "p2"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	entries := c.ExportQueryCache()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries but had %d", len(entries))
+	}
+
+	for _, entry := range entries {
+		if entry.Statement != "SELECT * FROM airline" {
+			t.Fatalf("Exported entry was not correct: %+v", entry)
+		}
+		if (entry.Name == "p1") != (entry.QueryContext == "") {
+			t.Fatalf("Exported entry had the wrong query context: %+v", entry)
+		}
+	}
+}
+
+func TestQueryCacheMetrics(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("prepare failed")}
+	c := testGetQueryCluster(provider)
+	c.sb.Tracer = &noopTracer{}
+	c.sb.Serializer = NewDefaultJSONSerializer()
+	c.sb.QueryTimeout = time.Second
+	meter := NewAggregatingMeter(nil)
+	c.sb.Meter = meter
+
+	// Nothing is cached so the statement must be prepared, which fails.
+	_, err := c.Query("SELECT * FROM airline", nil)
+	if err == nil {
+		t.Fatalf("Expected the prepare to fail")
+	}
+
+	err = c.ImportQueryCache([]QueryCacheEntry{
+		{Statement: "SELECT * FROM airline", Name: "p1", EncodedPlan: "plan1"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	provider.err = nil
+	_, err = c.Query("SELECT * FROM airline", nil)
+	if err != nil {
+		t.Fatalf("Expected the cached statement to be executed but was %v", err)
+	}
+
+	stats := c.QueryCacheStats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Prepares != 0 {
+		t.Fatalf("Unexpected cache stats %+v", stats)
+	}
+
+	results := make(map[string]uint64)
+	for _, counter := range meter.summary().Counters {
+		if counter.Name != meterNameQueryCache {
+			t.Fatalf("Unexpected counter %+v", counter)
+		}
+		results[counter.Tags[meterTagCacheResult]] += counter.Value
+	}
+	if results[cacheResultHit] != 1 || results[cacheResultMiss] != 1 {
+		t.Fatalf("Expected one hit and one miss but was %v", results)
+	}
+}
@@ -0,0 +1,139 @@
+package gocb
+
+import (
+	"encoding/json"
+)
+
+type jsonQueryExplain struct {
+	Plan json.RawMessage `json:"plan"`
+	Text string          `json:"text"`
+}
+
+type jsonQueryPlanNode struct {
+	Operator string            `json:"#operator"`
+	Index    string            `json:"index"`
+	Keyspace string            `json:"keyspace"`
+	Spans    []json.RawMessage `json:"spans"`
+	Covers   []string          `json:"covers"`
+	Children []json.RawMessage `json:"~children"`
+	Child    json.RawMessage   `json:"~child"`
+}
+
+// QueryPlan represents a single operator within the plan produced by an EXPLAIN query.
+// Plans form a tree, with operators such as Sequence and Parallel holding the operators
+// that they execute as children.
+type QueryPlan struct {
+	// Operator is the name of the operator, e.g. IndexScan3, Fetch or Filter.
+	Operator string
+	// Index is the name of the index used by index scan operators.
+	Index string
+	// Keyspace is the keyspace that the operator acts upon, if any.
+	Keyspace string
+	// Spans are the raw index spans scanned by index scan operators.
+	Spans []json.RawMessage
+	// Covers are the expressions covered by the index when the scan is covering.
+	Covers []string
+	// Children are the operators executed by this operator.
+	Children []QueryPlan
+	// Raw contains the undecoded JSON for this operator.
+	Raw json.RawMessage
+}
+
+func (p *QueryPlan) fromData(data json.RawMessage) error {
+	var node jsonQueryPlanNode
+	err := json.Unmarshal(data, &node)
+	if err != nil {
+		return err
+	}
+
+	p.Operator = node.Operator
+	p.Index = node.Index
+	p.Keyspace = node.Keyspace
+	p.Spans = node.Spans
+	p.Covers = node.Covers
+	p.Raw = data
+
+	children := node.Children
+	if len(node.Child) > 0 {
+		children = append(children, node.Child)
+	}
+
+	for _, childData := range children {
+		var child QueryPlan
+		err := child.fromData(childData)
+		if err != nil {
+			return err
+		}
+
+		p.Children = append(p.Children, child)
+	}
+
+	return nil
+}
+
+// Walk invokes fn for this operator and each of its descendants, depth first.
+func (p *QueryPlan) Walk(fn func(plan *QueryPlan)) {
+	fn(p)
+	for i := range p.Children {
+		p.Children[i].Walk(fn)
+	}
+}
+
+// IndexesUsed returns the names of all of the indexes scanned by this plan.
+func (p *QueryPlan) IndexesUsed() []string {
+	var indexes []string
+	p.Walk(func(plan *QueryPlan) {
+		if plan.Index != "" {
+			indexes = append(indexes, plan.Index)
+		}
+	})
+
+	return indexes
+}
+
+// IsCovered returns whether this plan is satisfied entirely by covering index scans,
+// without needing to fetch any documents.
+func (p *QueryPlan) IsCovered() bool {
+	covered := false
+	fetches := false
+	p.Walk(func(plan *QueryPlan) {
+		if len(plan.Covers) > 0 {
+			covered = true
+		}
+		if plan.Operator == "Fetch" {
+			fetches = true
+		}
+	})
+
+	return covered && !fetches
+}
+
+// ExplainQuery executes an EXPLAIN for the query statement on the server and returns
+// the resulting plan.  The statement should not itself be prefixed with EXPLAIN.
+func (c *Cluster) ExplainQuery(statement string, opts *QueryOptions) (*QueryPlan, error) {
+	var queryOpts QueryOptions
+	if opts != nil {
+		queryOpts = *opts
+	}
+	// There is no value in preparing an EXPLAIN statement.
+	queryOpts.Adhoc = true
+
+	result, err := c.Query("EXPLAIN "+statement, &queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var explain jsonQueryExplain
+	err = result.One(&explain)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan QueryPlan
+	err = plan.fromData(explain.Plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
@@ -9,12 +9,12 @@ import (
 type QueryIndexManager struct {
 	cluster *Cluster
 
-	tracer requestTracer
+	tracer RequestTracer
 }
 
 func (qm *QueryIndexManager) doQuery(q string, opts *QueryOptions) ([][]byte, error) {
-	if opts.Timeout == 0 || opts.Timeout > qm.cluster.sb.ManagementTimeout {
-		opts.Timeout = qm.cluster.sb.ManagementTimeout
+	if opts.Timeout == 0 || opts.Timeout > qm.cluster.sb.managementTimeout() {
+		opts.Timeout = qm.cluster.sb.managementTimeout()
 	}
 
 	result, err := qm.cluster.Query(q, opts)
@@ -81,7 +81,7 @@ type createQueryIndexOptions struct {
 }
 
 func (qm *QueryIndexManager) createIndex(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	bucketName, indexName string,
 	fields []string,
 	opts createQueryIndexOptions,
@@ -114,7 +114,7 @@ func (qm *QueryIndexManager) createIndex(
 	_, err := qm.doQuery(qs, &QueryOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    tracectx,
+		ParentSpan:    tracectx,
 	})
 	if err != nil {
 		return err
@@ -202,7 +202,7 @@ type dropQueryIndexOptions struct {
 }
 
 func (qm *QueryIndexManager) dropIndex(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	bucketName, indexName string,
 	opts dropQueryIndexOptions,
 ) error {
@@ -217,7 +217,7 @@ func (qm *QueryIndexManager) dropIndex(
 	_, err := qm.doQuery(qs, &QueryOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    tracectx,
+		ParentSpan:    tracectx,
 	})
 	if err != nil {
 		return err
@@ -311,7 +311,7 @@ func (qm *QueryIndexManager) GetAllIndexes(bucketName string, opts *GetAllQueryI
 }
 
 func (qm *QueryIndexManager) getAllIndexes(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	bucketName string,
 	opts *GetAllQueryIndexesOptions,
 ) ([]QueryIndex, error) {
@@ -321,7 +321,7 @@ func (qm *QueryIndexManager) getAllIndexes(
 		Readonly:             true,
 		Timeout:              opts.Timeout,
 		RetryStrategy:        opts.RetryStrategy,
-		parentSpan:           tracectx,
+		ParentSpan:           tracectx,
 	})
 	if err != nil {
 		return nil, err
@@ -400,7 +400,7 @@ func (qm *QueryIndexManager) BuildDeferredIndexes(bucketName string, opts *Build
 	_, err = qm.doQuery(qs, &QueryOptions{
 		Timeout:       opts.Timeout,
 		RetryStrategy: opts.RetryStrategy,
-		parentSpan:    span,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return nil, err
@@ -455,11 +455,12 @@ func (qm *QueryIndexManager) WatchIndexes(bucketName string, watchList []string,
 		watchList = append(watchList, "#primary")
 	}
 
-	deadline := time.Now().Add(timeout)
+	clk := clockOrSystem(qm.cluster.sb.Clock)
+	deadline := clk.Now().Add(timeout)
 
 	curInterval := 50 * time.Millisecond
 	for {
-		if !deadline.Before(time.Now()) {
+		if !clk.Now().Before(deadline) {
 			return ErrUnambiguousTimeout
 		}
 
@@ -467,7 +468,7 @@ func (qm *QueryIndexManager) WatchIndexes(bucketName string, watchList []string,
 			span.Context(),
 			bucketName,
 			&GetAllQueryIndexesOptions{
-				Timeout:       deadline.Sub(time.Now()),
+				Timeout:       deadline.Sub(clk.Now()),
 				RetryStrategy: opts.RetryStrategy,
 			})
 		if err != nil {
@@ -484,19 +485,19 @@ func (qm *QueryIndexManager) WatchIndexes(bucketName string, watchList []string,
 		}
 
 		curInterval += 500 * time.Millisecond
-		if curInterval > 1000 {
-			curInterval = 1000
+		if curInterval > 1000*time.Millisecond {
+			curInterval = 1000 * time.Millisecond
 		}
 
 		// Make sure we don't sleep past our overall deadline, if we adjust the
 		// deadline then it will be caught at the top of this loop as a timeout.
-		sleepDeadline := time.Now().Add(curInterval)
+		sleepDeadline := clk.Now().Add(curInterval)
 		if sleepDeadline.After(deadline) {
 			sleepDeadline = deadline
 		}
 
 		// wait till our next poll interval
-		time.Sleep(sleepDeadline.Sub(time.Now()))
+		clk.Sleep(sleepDeadline.Sub(clk.Now()))
 	}
 
 	return nil
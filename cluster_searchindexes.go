@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"strings"
 	"time"
 )
 
@@ -88,11 +90,117 @@ func (si *SearchIndex) toData() (jsonSearchIndex, error) {
 	return data, nil
 }
 
+// SearchVectorSimilarity specifies the metric used to compare the vectors of a vector field.
+type SearchVectorSimilarity string
+
+const (
+	// SearchVectorSimilarityL2Norm compares vectors by their euclidean distance.
+	SearchVectorSimilarityL2Norm = SearchVectorSimilarity("l2_norm")
+
+	// SearchVectorSimilarityDotProduct compares vectors by their dot product.
+	SearchVectorSimilarityDotProduct = SearchVectorSimilarity("dot_product")
+)
+
+// SearchVectorField describes a vector field of a search index.
+// UNCOMMITTED: This API may change in the future.
+type SearchVectorField struct {
+	// Dims is the number of dimensions of the vectors held in the field.
+	Dims int
+
+	// Similarity is the metric used to compare vectors.  The default is SearchVectorSimilarityL2Norm.
+	Similarity SearchVectorSimilarity
+}
+
+// AddVectorField adds a vector field to the default mapping of the index, so that the vectors
+// held at the document path given can be searched using SearchOptions.VectorQueries.  Paths
+// into nested objects are separated by dots, for instance "details.embedding".
+// UNCOMMITTED: This API may change in the future.
+func (si *SearchIndex) AddVectorField(path string, field SearchVectorField) error {
+	if path == "" {
+		return makeInvalidArgumentsError("vector field path cannot be empty")
+	}
+	if field.Dims <= 0 {
+		return makeInvalidArgumentsError("vector field dims must be greater than 0")
+	}
+
+	similarity := field.Similarity
+	if similarity == "" {
+		similarity = SearchVectorSimilarityL2Norm
+	} else if similarity != SearchVectorSimilarityL2Norm && similarity != SearchVectorSimilarityDotProduct {
+		return makeInvalidArgumentsError("unexpected vector similarity option")
+	}
+
+	if si.Params == nil {
+		si.Params = make(map[string]interface{})
+	}
+
+	mapping, err := searchIndexChildMapping(si.Params, "mapping")
+	if err != nil {
+		return err
+	}
+	docMapping, err := searchIndexChildMapping(mapping, "default_mapping")
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(path, ".")
+	for _, part := range parts {
+		properties, err := searchIndexChildMapping(docMapping, "properties")
+		if err != nil {
+			return err
+		}
+
+		_, exists := properties[part]
+		docMapping, err = searchIndexChildMapping(properties, part)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			docMapping["enabled"] = true
+			docMapping["dynamic"] = false
+		}
+	}
+
+	var fields []interface{}
+	if existing, ok := docMapping["fields"]; ok {
+		fields, ok = existing.([]interface{})
+		if !ok {
+			return makeInvalidArgumentsError("index mapping fields for " + path + " are not a list")
+		}
+	}
+
+	docMapping["fields"] = append(fields, map[string]interface{}{
+		"name":       parts[len(parts)-1],
+		"type":       "vector",
+		"dims":       field.Dims,
+		"similarity": string(similarity),
+		"index":      true,
+	})
+
+	return nil
+}
+
+// searchIndexChildMapping returns the object held in parent under key, creating it if needed.
+func searchIndexChildMapping(parent map[string]interface{}, key string) (map[string]interface{}, error) {
+	existing, ok := parent[key]
+	if !ok {
+		child := make(map[string]interface{})
+		parent[key] = child
+		return child, nil
+	}
+
+	child, ok := existing.(map[string]interface{})
+	if !ok {
+		return nil, makeInvalidArgumentsError("index mapping " + key + " is not an object")
+	}
+	return child, nil
+}
+
 // SearchIndexManager provides methods for performing Couchbase search index management.
 type SearchIndexManager struct {
 	cluster *Cluster
 
-	tracer requestTracer
+	tracer RequestTracer
 }
 
 func (sm *SearchIndexManager) doMgmtRequest(req mgmtRequest) (*mgmtResponse, error) {
@@ -271,6 +379,15 @@ func (sm *SearchIndexManager) UpsertIndex(indexDefinition SearchIndex, opts *Ups
 	}
 
 	if resp.StatusCode != 200 {
+		if resp.StatusCode == 400 {
+			errText, err := ioutil.ReadAll(resp.Body)
+			if err == nil {
+				if limitErr := translateSearchLimitErr(int(resp.StatusCode), string(errText), nil); limitErr != nil {
+					return makeGenericMgmtError(wrapError(limitErr, "failed to upsert the index"), &req, resp)
+				}
+			}
+		}
+
 		return makeMgmtBadStatusError("failed to upsert the index", &req, resp)
 	}
 
@@ -351,6 +468,7 @@ func (sm *SearchIndexManager) AnalyzeDocument(indexName string, doc interface{},
 		IsIdempotent:  true,
 		RetryStrategy: opts.RetryStrategy,
 		Timeout:       opts.Timeout,
+		readOnly:      true,
 	}
 	resp, err := sm.doMgmtRequest(req)
 	if err != nil {
@@ -434,8 +552,112 @@ func (sm *SearchIndexManager) GetIndexedDocumentsCount(indexName string, opts *G
 	return count.Count, nil
 }
 
+// SearchIndexStats contains statistics about a search index, which can be used to
+// determine whether a newly created index has finished building.
+type SearchIndexStats struct {
+	// DocCount is the number of documents currently indexed.
+	DocCount uint64
+	// NumPartitionsActual is the number of index partitions currently active.
+	NumPartitionsActual uint64
+	// NumPartitionsTarget is the number of index partitions which the index should have.
+	NumPartitionsTarget uint64
+	// NumMutationsToIndex is the number of mutations which are yet to be indexed,
+	// indicating how far the index lags behind its source.
+	NumMutationsToIndex uint64
+	// TotalSeqReceived is the total number of sequence numbers processed by the index.
+	TotalSeqReceived uint64
+	// Raw contains all of the statistics returned for the index, keyed by stat name.
+	Raw map[string]interface{}
+}
+
+func (ss *SearchIndexStats) fromData(data map[string]interface{}) error {
+	ss.Raw = make(map[string]interface{}, len(data))
+	for key, value := range data {
+		// Some server versions prefix stat names with the bucket and index name.
+		if idx := strings.LastIndex(key, ":"); idx >= 0 {
+			key = key[idx+1:]
+		}
+		ss.Raw[key] = value
+	}
+
+	statValue := func(name string) uint64 {
+		if value, ok := ss.Raw[name].(float64); ok && value > 0 {
+			return uint64(value)
+		}
+		return 0
+	}
+
+	ss.DocCount = statValue("doc_count")
+	ss.NumPartitionsActual = statValue("num_pindexes_actual")
+	ss.NumPartitionsTarget = statValue("num_pindexes_target")
+	ss.NumMutationsToIndex = statValue("num_mutations_to_index")
+	ss.TotalSeqReceived = statValue("total_seq_received")
+
+	return nil
+}
+
+// GetSearchIndexStatsOptions is the set of options available to the search index GetIndexStats operation.
+type GetSearchIndexStatsOptions struct {
+	Timeout       time.Duration
+	Context       context.Context
+	RetryStrategy RetryStrategy
+}
+
+// GetIndexStats retrieves statistics about a search index, such as its partition
+// status and ingest lag.
+func (sm *SearchIndexManager) GetIndexStats(indexName string, opts *GetSearchIndexStatsOptions) (*SearchIndexStats, error) {
+	if opts == nil {
+		opts = &GetSearchIndexStatsOptions{}
+	}
+
+	if indexName == "" {
+		return nil, invalidArgumentsError{"indexName cannot be empty"}
+	}
+
+	span := sm.tracer.StartSpan("GetIndexStats", nil).
+		SetTag("couchbase.service", "search")
+	defer span.Finish()
+
+	req := mgmtRequest{
+		Service:       ServiceTypeSearch,
+		Method:        "GET",
+		Path:          fmt.Sprintf("/api/stats/index/%s", indexName),
+		IsIdempotent:  true,
+		RetryStrategy: opts.RetryStrategy,
+		Timeout:       opts.Timeout,
+	}
+	resp, err := sm.doMgmtRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, makeMgmtBadStatusError("failed to get the index stats", &req, resp)
+	}
+
+	var statsData map[string]interface{}
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&statsData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	var stats SearchIndexStats
+	err = stats.fromData(statsData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
 func (sm *SearchIndexManager) performControlRequest(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	method, uri string,
 	timeout time.Duration,
 	retryStrategy RetryStrategy,
@@ -633,3 +855,107 @@ func (sm *SearchIndexManager) UnfreezePlan(indexName string, opts *AllowQuerying
 		opts.Timeout,
 		opts.RetryStrategy)
 }
+
+// SearchIndexAlias is used to define a search index alias.  An alias directs queries
+// to one or more target indexes, allowing indexes to be swapped without downtime by
+// updating the alias targets.
+type SearchIndexAlias struct {
+	// UUID is required for updates. It must match the UUID value for the alias on the server.
+	UUID string
+	// Name represents the name of this alias.
+	Name string
+	// Targets are the names of the indexes which this alias directs queries to.
+	Targets []string
+}
+
+func (sa *SearchIndexAlias) fromIndex(index SearchIndex) error {
+	if index.Type != "fulltext-alias" {
+		return invalidArgumentsError{fmt.Sprintf("index %s is not an alias", index.Name)}
+	}
+
+	sa.UUID = index.UUID
+	sa.Name = index.Name
+	sa.Targets = nil
+
+	targets, _ := index.Params["targets"].(map[string]interface{})
+	for target := range targets {
+		sa.Targets = append(sa.Targets, target)
+	}
+
+	return nil
+}
+
+func (sa *SearchIndexAlias) toIndex() SearchIndex {
+	targets := make(map[string]interface{}, len(sa.Targets))
+	for _, target := range sa.Targets {
+		targets[target] = map[string]interface{}{}
+	}
+
+	return SearchIndex{
+		UUID:       sa.UUID,
+		Name:       sa.Name,
+		Type:       "fulltext-alias",
+		SourceType: "nil",
+		Params: map[string]interface{}{
+			"targets": targets,
+		},
+	}
+}
+
+// UpsertSearchIndexAliasOptions is the set of options available to the search index manager UpsertIndexAlias operation.
+type UpsertSearchIndexAliasOptions struct {
+	Timeout       time.Duration
+	Context       context.Context
+	RetryStrategy RetryStrategy
+}
+
+// UpsertIndexAlias creates or updates a search index alias.
+func (sm *SearchIndexManager) UpsertIndexAlias(alias SearchIndexAlias, opts *UpsertSearchIndexAliasOptions) error {
+	if opts == nil {
+		opts = &UpsertSearchIndexAliasOptions{}
+	}
+
+	if alias.Name == "" {
+		return invalidArgumentsError{"alias name cannot be empty"}
+	}
+	if len(alias.Targets) == 0 {
+		return invalidArgumentsError{"alias must have at least one target"}
+	}
+
+	return sm.UpsertIndex(alias.toIndex(), &UpsertSearchIndexOptions{
+		Timeout:       opts.Timeout,
+		Context:       opts.Context,
+		RetryStrategy: opts.RetryStrategy,
+	})
+}
+
+// GetSearchIndexAliasOptions is the set of options available to the search index manager GetIndexAlias operation.
+type GetSearchIndexAliasOptions struct {
+	Timeout       time.Duration
+	Context       context.Context
+	RetryStrategy RetryStrategy
+}
+
+// GetIndexAlias retrieves a specific search index alias by name.
+func (sm *SearchIndexManager) GetIndexAlias(aliasName string, opts *GetSearchIndexAliasOptions) (*SearchIndexAlias, error) {
+	if opts == nil {
+		opts = &GetSearchIndexAliasOptions{}
+	}
+
+	index, err := sm.GetIndex(aliasName, &GetSearchIndexOptions{
+		Timeout:       opts.Timeout,
+		Context:       opts.Context,
+		RetryStrategy: opts.RetryStrategy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var alias SearchIndexAlias
+	err = alias.fromIndex(*index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alias, nil
+}
@@ -1,6 +1,7 @@
 package gocb
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 )
@@ -242,3 +243,100 @@ func TestSearchIndexesPartitionControl(t *testing.T) {
 		t.Fatalf("Expected ResumeIngest err to be nil but was %v", err)
 	}
 }
+
+func TestSearchIndexStatsFromData(t *testing.T) {
+	var stats SearchIndexStats
+	err := stats.fromData(map[string]interface{}{
+		"default:test:doc_count":              float64(100),
+		"default:test:num_pindexes_actual":    float64(6),
+		"default:test:num_pindexes_target":    float64(6),
+		"default:test:num_mutations_to_index": float64(12),
+		"total_seq_received":                  float64(250),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if stats.DocCount != 100 {
+		t.Fatalf("Expected doc count to be 100 but was %d", stats.DocCount)
+	}
+
+	if stats.NumPartitionsActual != 6 || stats.NumPartitionsTarget != 6 {
+		t.Fatalf("Expected 6 partitions but was %d/%d", stats.NumPartitionsActual, stats.NumPartitionsTarget)
+	}
+
+	if stats.NumMutationsToIndex != 12 {
+		t.Fatalf("Expected 12 mutations to index but was %d", stats.NumMutationsToIndex)
+	}
+
+	if stats.TotalSeqReceived != 250 {
+		t.Fatalf("Expected 250 seqs received but was %d", stats.TotalSeqReceived)
+	}
+}
+
+func TestSearchIndexAliasToIndex(t *testing.T) {
+	alias := SearchIndexAlias{
+		Name:    "alias",
+		Targets: []string{"test", "test2"},
+	}
+
+	index := alias.toIndex()
+	if index.Type != "fulltext-alias" {
+		t.Fatalf("Expected index type to be fulltext-alias but was %s", index.Type)
+	}
+
+	var roundTripped SearchIndexAlias
+	err := roundTripped.fromIndex(index)
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if roundTripped.Name != "alias" || len(roundTripped.Targets) != 2 {
+		t.Fatalf("Alias was not round tripped correctly: %+v", roundTripped)
+	}
+}
+
+func TestSearchIndexAliasFromNonAlias(t *testing.T) {
+	var alias SearchIndexAlias
+	err := alias.fromIndex(SearchIndex{Name: "test", Type: "fulltext-index"})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestSearchIndexAddVectorField(t *testing.T) {
+	index := SearchIndex{
+		Name: "test",
+		Type: "fulltext-index",
+		Params: map[string]interface{}{
+			"mapping": map[string]interface{}{
+				"default_mapping": map[string]interface{}{
+					"enabled": true,
+					"dynamic": true,
+				},
+			},
+		},
+	}
+
+	err := index.AddVectorField("details.embedding", SearchVectorField{Dims: 3})
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	paramsBytes, err := json.Marshal(index.Params)
+	if err != nil {
+		t.Fatalf("Failed to marshal params: %v", err)
+	}
+
+	expected := `{"mapping":{"default_mapping":{"dynamic":true,"enabled":true,"properties":{"details":{` +
+		`"dynamic":false,"enabled":true,"properties":{"embedding":{"dynamic":false,"enabled":true,"fields":[` +
+		`{"dims":3,"index":true,"name":"embedding","similarity":"l2_norm","type":"vector"}]}}}}}}}`
+	if string(paramsBytes) != expected {
+		t.Fatalf("Expected params %s but was %s", expected, paramsBytes)
+	}
+
+	err = index.AddVectorField("details.embedding", SearchVectorField{Dims: 0})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
@@ -31,10 +31,11 @@ type jsonSearchRow struct {
 	Index       string                 `json:"index"`
 	ID          string                 `json:"id"`
 	Score       float64                `json:"score"`
-	Explanation interface{}            `json:"explanation"`
+	Explanation json.RawMessage        `json:"explanation"`
 	Locations   jsonSearchRowLocations `json:"locations"`
 	Fragments   map[string][]string    `json:"fragments"`
 	Fields      json.RawMessage        `json:"fields"`
+	Sort        []string               `json:"sort"`
 }
 
 type jsonSearchResponse struct {
@@ -108,13 +109,35 @@ type SearchRowLocation struct {
 	ArrayPositions []uint32
 }
 
-func (rl *SearchRowLocation) fromData(data jsonRowLocation) error {
+func (rl *SearchRowLocation) fromData(data jsonRowLocation) {
 	rl.Position = data.Position
 	rl.Start = data.Start
 	rl.End = data.End
 	rl.ArrayPositions = data.ArrayPositions
+}
 
-	return nil
+// SearchExplanation is a node of the tree explaining how the score of a search hit was
+// calculated.  The value of each node is derived from the values of its children.
+type SearchExplanation struct {
+	Value    float64
+	Message  string
+	Children []SearchExplanation
+}
+
+// fromData builds the explanation from its generic form, as held in SearchRow.Explanation, so
+// that the explanation only has to be decoded once.
+func (se *SearchExplanation) fromData(data map[string]interface{}) {
+	se.Value, _ = data["value"].(float64)
+	se.Message, _ = data["message"].(string)
+
+	children, _ := data["children"].([]interface{})
+	if len(children) > 0 {
+		se.Children = make([]SearchExplanation, len(children))
+		for i, child := range children {
+			childData, _ := child.(map[string]interface{})
+			se.Children[i].fromData(childData)
+		}
+	}
 }
 
 // SearchRow represents a single hit returned from a search query.
@@ -123,8 +146,15 @@ type SearchRow struct {
 	ID          string
 	Score       float64
 	Explanation interface{}
-	Locations   map[string]map[string][]SearchRowLocation
-	Fragments   map[string][]string
+	// ExplanationTree is the parsed form of Explanation.  It is only populated when
+	// SearchOptions.Explain is set.
+	ExplanationTree *SearchExplanation
+	Locations       map[string]map[string][]SearchRowLocation
+	Fragments       map[string][]string
+	// SortKeys are the values which the hit was sorted by, in the order of SearchOptions.Sort.
+	// They can be passed as SearchOptions.SearchAfter or SearchOptions.SearchBefore to fetch
+	// the next or previous page of hits.
+	SortKeys    []string
 	fieldsBytes []byte
 }
 
@@ -135,14 +165,16 @@ func (sr *SearchRow) Fields(valuePtr interface{}) error {
 
 // SearchResult allows access to the results of a search query.
 type SearchResult struct {
-	reader *gocbcore.SearchRowReader
+	reader rowReader
 
-	currentRow SearchRow
+	rowBytes   []byte
+	currentRow *SearchRow
+	decodeErr  error
 }
 
-func newSearchResult(reader *gocbcore.SearchRowReader) (*SearchResult, error) {
+func newSearchResult(reader rowReader) (*SearchResult, error) {
 	return &SearchResult{
-		reader: reader,
+		reader: newCloseOnceRowReader(reader),
 	}, nil
 }
 
@@ -153,46 +185,87 @@ func (r *SearchResult) Next() bool {
 		return false
 	}
 
-	r.currentRow = SearchRow{}
+	r.rowBytes = rowBytes
+	r.currentRow = nil
 
-	var rowData jsonSearchRow
-	if err := json.Unmarshal(rowBytes, &rowData); err == nil {
-		r.currentRow.Index = rowData.Index
-		r.currentRow.ID = rowData.ID
-		r.currentRow.Score = rowData.Score
-		r.currentRow.Explanation = rowData.Explanation
-		r.currentRow.Fragments = rowData.Fragments
-		r.currentRow.fieldsBytes = rowData.Fields
-
-		locations := make(map[string]map[string][]SearchRowLocation)
-		for fieldName, fieldData := range rowData.Locations {
-			terms := make(map[string][]SearchRowLocation)
-			for termName, termData := range fieldData {
-				locations := make([]SearchRowLocation, len(termData))
-				for locIdx, locData := range termData {
-					locations[locIdx].fromData(locData)
-				}
-				terms[termName] = locations
+	return true
+}
+
+// Row returns the contents of the current row.  If the row cannot be decoded then an empty row
+// is returned, and the error is returned by Err.
+func (r *SearchResult) Row() SearchRow {
+	if r.currentRow == nil {
+		r.currentRow = &SearchRow{}
+		err := r.currentRow.fromBytes(r.rowBytes)
+		if err != nil {
+			*r.currentRow = SearchRow{}
+			if r.decodeErr == nil {
+				r.decodeErr = err
 			}
-			locations[fieldName] = terms
 		}
-		r.currentRow.Locations = locations
 	}
 
-	return true
+	return *r.currentRow
 }
 
-// Row returns the contents of the current row.
-func (r *SearchResult) Row() SearchRow {
-	return r.currentRow
+// RawBytes returns the undecoded contents of the current row.  This allows the
+// cost of decoding to be deferred or skipped entirely, for instance when proxying
+// rows elsewhere.  The returned bytes are only valid until the next call to Next.
+func (r *SearchResult) RawBytes() []byte {
+	return r.rowBytes
+}
+
+func (sr *SearchRow) fromBytes(rowBytes []byte) error {
+	var rowData jsonSearchRow
+	if err := json.Unmarshal(rowBytes, &rowData); err != nil {
+		return err
+	}
+
+	sr.Index = rowData.Index
+	sr.ID = rowData.ID
+	sr.Score = rowData.Score
+	if len(rowData.Explanation) > 0 && string(rowData.Explanation) != "null" {
+		if err := json.Unmarshal(rowData.Explanation, &sr.Explanation); err != nil {
+			return err
+		}
+		if explanationData, ok := sr.Explanation.(map[string]interface{}); ok {
+			sr.ExplanationTree = &SearchExplanation{}
+			sr.ExplanationTree.fromData(explanationData)
+		}
+	}
+	sr.Fragments = rowData.Fragments
+	sr.SortKeys = rowData.Sort
+	sr.fieldsBytes = rowData.Fields
+
+	locations := make(map[string]map[string][]SearchRowLocation)
+	for fieldName, fieldData := range rowData.Locations {
+		terms := make(map[string][]SearchRowLocation)
+		for termName, termData := range fieldData {
+			locations := make([]SearchRowLocation, len(termData))
+			for locIdx, locData := range termData {
+				locations[locIdx].fromData(locData)
+			}
+			terms[termName] = locations
+		}
+		locations[fieldName] = terms
+	}
+	sr.Locations = locations
+
+	return nil
 }
 
-// Err returns any errors that have occurred on the stream
+// Err returns any errors that have occurred on the stream, or in decoding a row.
 func (r *SearchResult) Err() error {
-	return r.reader.Err()
+	if err := r.reader.Err(); err != nil {
+		return err
+	}
+
+	return r.decodeErr
 }
 
 // Close marks the results as closed, returning any errors that occurred during reading the results.
+// Closing releases the underlying connection even if rows remain unread.  It is safe to call Close
+// more than once, and after an error has occurred, in which case the same result is returned each time.
 func (r *SearchResult) Close() error {
 	return r.reader.Close()
 }
@@ -254,24 +327,33 @@ func (r *SearchResult) Facets() (map[string]SearchFacetResult, error) {
 }
 
 // SearchQuery executes the analytics query statement on the server.
-func (c *Cluster) SearchQuery(indexName string, query cbsearch.Query, opts *SearchOptions) (*SearchResult, error) {
+func (c *Cluster) SearchQuery(indexName string, query cbsearch.Query, opts *SearchOptions) (resOut *SearchResult, errOut error) {
 	if opts == nil {
 		opts = &SearchOptions{}
 	}
 
-	span := c.sb.Tracer.StartSpan("SearchQuery", opts.parentSpan).
+	span := c.sb.Tracer.StartSpan("SearchQuery", opts.ParentSpan).
 		SetTag("couchbase.service", "search")
+	span = applyOperationTags(span, opts.Tags)
 	defer span.Finish()
+	defer func() {
+		setSpanOutcome(span, errOut)
+	}()
 
-	timeout := c.sb.QueryTimeout
+	timeout := c.sb.searchTimeout()
 	if opts.Timeout != 0 && opts.Timeout < timeout {
 		timeout = opts.Timeout
 	}
-	deadline := time.Now().Add(timeout)
+	clk := clockOrSystem(c.sb.Clock)
+	deadline := contextDeadline(opts.Context, clk.Now().Add(timeout))
 
-	retryStrategy := c.sb.RetryStrategyWrapper
-	if opts.RetryStrategy != nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	retryStrategy := c.sb.RetryStrategyWrapper.withStrategy(opts.RetryStrategy)
+
+	if opts.Context != nil && opts.Context.Err() != nil {
+		return nil, SearchError{
+			InnerError: opts.Context.Err(),
+			Query:      query,
+		}
 	}
 
 	searchOpts, err := opts.toMap()
@@ -284,7 +366,91 @@ func (c *Cluster) SearchQuery(indexName string, query cbsearch.Query, opts *Sear
 
 	searchOpts["query"] = query
 
-	return c.execSearchQuery(span, indexName, searchOpts, deadline, retryStrategy)
+	// Propagate our timeout to the server so that it does not continue to process
+	// the query after we have given up on it.  Any ctl given in the raw options is
+	// copied, as it belongs to the caller.
+	ctl := make(map[string]interface{})
+	if rawCtl, ok := searchOpts["ctl"].(map[string]interface{}); ok {
+		for k, v := range rawCtl {
+			ctl[k] = v
+		}
+	}
+	searchOpts["ctl"] = ctl
+
+	dispatch := func() (*SearchResult, error) {
+		// A query whose time has already run out is not sent, as there would be no
+		// timeout left to give the server.
+		remaining := int64(deadline.Sub(clk.Now()) / time.Millisecond)
+		if remaining <= 0 {
+			return nil, SearchError{
+				InnerError: ErrUnambiguousTimeout,
+				Query:      query,
+			}
+		}
+		ctl["timeout"] = remaining
+		return c.execSearchQuery(span, indexName, searchOpts, deadline, retryStrategy)
+	}
+	if opts.RetryOnStreamReset {
+		dispatchOnce := dispatch
+		dispatch = func() (*SearchResult, error) {
+			res, err := dispatchOnce()
+			if err != nil {
+				return nil, err
+			}
+
+			res.reader = newResumingRowReader(res.reader, func() (rowReader, error) {
+				res, err := dispatchOnce()
+				if err != nil {
+					return nil, err
+				}
+				return res.reader, nil
+			})
+			return res, nil
+		}
+	}
+
+	if opts.Context == nil {
+		return dispatch()
+	}
+
+	type searchQueryResp struct {
+		res *SearchResult
+		err error
+	}
+
+	respCh := make(chan searchQueryResp, 1)
+	c.sb.Workers.start(func() {
+		res, err := dispatch()
+		respCh <- searchQueryResp{res, err}
+	})
+
+	select {
+	case resp := <-respCh:
+		if resp.err != nil {
+			return nil, resp.err
+		}
+
+		resp.res.reader = newCtxRowReader(opts.Context, c.sb.Workers, resp.res.reader, nil)
+
+		return resp.res, nil
+	case <-opts.Context.Done():
+		// The request may still complete after we have returned, in which case
+		// we need to make sure that its stream gets released.
+		c.sb.Workers.start(func() {
+			resp := <-respCh
+			if resp.res != nil {
+				err := resp.res.Close()
+				if err != nil {
+					logDebugf("Failed to close search result after cancellation: %s", err)
+				}
+			}
+		})
+
+		return nil, SearchError{
+			InnerError: opts.Context.Err(),
+			Query:      query,
+		}
+	}
 }
 
 func maybeGetSearchOptionQuery(options map[string]interface{}) interface{} {
@@ -295,7 +461,7 @@ func maybeGetSearchOptionQuery(options map[string]interface{}) interface{} {
 }
 
 func (c *Cluster) execSearchQuery(
-	span requestSpan,
+	span RequestSpan,
 	indexName string,
 	options map[string]interface{},
 	deadline time.Time,
@@ -317,6 +483,14 @@ func (c *Cluster) execSearchQuery(
 		}
 	}
 
+	releaseLimit, err := c.sb.HTTPLimiter.Acquire(deadline)
+	if err != nil {
+		return nil, SearchError{
+			InnerError: err,
+			Query:      maybeGetSearchOptionQuery(options),
+		}
+	}
+
 	res, err := provider.SearchQuery(gocbcore.SearchQueryOptions{
 		IndexName:     indexName,
 		Payload:       reqBytes,
@@ -324,8 +498,10 @@ func (c *Cluster) execSearchQuery(
 		Deadline:      deadline,
 	})
 	if err != nil {
+		releaseLimit()
+		c.sb.Telemetry.recordError("search", err)
 		return nil, maybeEnhanceSearchError(err)
 	}
 
-	return newSearchResult(res)
+	return newSearchResult(newLimitedRowReader(res, releaseLimit))
 }
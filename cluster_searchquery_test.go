@@ -0,0 +1,162 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	cbsearch "github.com/couchbase/gocb/v2/search"
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+type mockSearchProvider struct {
+	indexNames []string
+	payloads   [][]byte
+	err        error
+}
+
+func (p *mockSearchProvider) SearchQuery(opts gocbcore.SearchQueryOptions) (*gocbcore.SearchRowReader, error) {
+	p.indexNames = append(p.indexNames, opts.IndexName)
+	p.payloads = append(p.payloads, opts.Payload)
+	return nil, p.err
+}
+
+// blockingSearchProvider does not respond to a query until it is released, as a search node
+// which has stopped responding does.
+type blockingSearchProvider struct {
+	releaseCh chan struct{}
+}
+
+func (p *blockingSearchProvider) SearchQuery(opts gocbcore.SearchQueryOptions) (*gocbcore.SearchRowReader, error) {
+	<-p.releaseCh
+	return nil, errors.New("no results")
+}
+
+func TestSearchQueryContextCancelled(t *testing.T) {
+	provider := &blockingSearchProvider{releaseCh: make(chan struct{})}
+	defer close(provider.releaseCh)
+
+	c := &Cluster{connections: map[string]client{
+		"mock": &mockClient{bucketName: "mock", mockSearchProvider: provider},
+	}}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.SearchTimeout = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := c.SearchQuery("hotels", cbsearch.NewMatchQuery("hotel"), &SearchOptions{
+			Context: ctx,
+		})
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled but was %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("SearchQuery did not return after the context was cancelled")
+	}
+}
+
+func TestScopeSearchQuery(t *testing.T) {
+	provider := &mockSearchProvider{err: errors.New("no results")}
+	clients := make(map[string]client)
+	clients["mock"] = &mockClient{
+		bucketName:         "mock",
+		mockSearchProvider: provider,
+	}
+	c := &Cluster{connections: clients}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.SearchTimeout = time.Second
+
+	scope := &Scope{cluster: c}
+	scope.sb.BucketName = "travel"
+	scope.sb.ScopeName = "inventory"
+	scope.sb.DeveloperPreview = true
+
+	_, _ = scope.SearchQuery("hotels", cbsearch.NewMatchQuery("hotel"), &SearchOptions{
+		Collections: []string{"hotel", "landmark"},
+	})
+
+	if len(provider.payloads) != 1 {
+		t.Fatalf("Expected 1 request but was %d", len(provider.payloads))
+	}
+	if provider.indexNames[0] != "travel.inventory.hotels" {
+		t.Fatalf("Expected fully qualified index name but was %s", provider.indexNames[0])
+	}
+
+	var payload struct {
+		Collections []string `json:"collections"`
+	}
+	err := json.Unmarshal(provider.payloads[0], &payload)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+	if !reflect.DeepEqual(payload.Collections, []string{"hotel", "landmark"}) {
+		t.Fatalf("Expected collections to be sent but was %v", payload.Collections)
+	}
+}
+
+func TestSearchQueryRawCtlNotModified(t *testing.T) {
+	provider := &mockSearchProvider{err: errors.New("no results")}
+	clients := make(map[string]client)
+	clients["mock"] = &mockClient{
+		bucketName:         "mock",
+		mockSearchProvider: provider,
+	}
+	c := &Cluster{connections: clients}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.SearchTimeout = time.Second
+
+	ctl := map[string]interface{}{"consistency": map[string]interface{}{"level": "at_plus"}}
+	_, _ = c.SearchQuery("hotels", cbsearch.NewMatchQuery("hotel"), &SearchOptions{
+		Raw: map[string]interface{}{"ctl": ctl},
+	})
+
+	if len(ctl) != 1 {
+		t.Fatalf("Expected the raw ctl to be unchanged but was %v", ctl)
+	}
+
+	var payload struct {
+		Ctl map[string]interface{} `json:"ctl"`
+	}
+	err := json.Unmarshal(provider.payloads[0], &payload)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+	if payload.Ctl["consistency"] == nil || payload.Ctl["timeout"] == nil {
+		t.Fatalf("Expected the raw ctl and the timeout to be sent but was %v", payload.Ctl)
+	}
+}
+
+func TestSearchQueryDeadlineExhausted(t *testing.T) {
+	provider := &mockSearchProvider{}
+	clients := make(map[string]client)
+	clients["mock"] = &mockClient{
+		bucketName:         "mock",
+		mockSearchProvider: provider,
+	}
+	c := &Cluster{connections: clients}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.SearchTimeout = time.Second
+
+	// Less than a millisecond leaves no timeout to give the server.
+	_, err := c.SearchQuery("hotels", cbsearch.NewMatchQuery("hotel"), &SearchOptions{
+		Timeout: 500 * time.Microsecond,
+	})
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout but was %v", err)
+	}
+
+	if len(provider.payloads) != 0 {
+		t.Fatalf("Expected no requests to be sent but was %d", len(provider.payloads))
+	}
+}
@@ -0,0 +1,366 @@
+package gocb
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// ClusterSettingsManager provides methods for managing cluster level settings.
+// UNCOMMITTED: This API may change in the future.
+type ClusterSettingsManager struct {
+	httpClient           httpProvider
+	globalTimeout        time.Duration
+	defaultRetryStrategy *retryStrategyWrapper
+	tracer               RequestTracer
+}
+
+func (sm *ClusterSettingsManager) doRequest(tracectx RequestSpanContext, method, path string, posts url.Values,
+	timeout time.Duration, strategy RetryStrategy, errMsg string, valueOut interface{}) error {
+	if timeout == 0 {
+		timeout = sm.globalTimeout
+	}
+
+	retryStrategy := sm.defaultRetryStrategy.withStrategy(strategy)
+
+	req := &gocbcore.HTTPRequest{
+		Service:       gocbcore.ServiceType(ServiceTypeManagement),
+		Path:          path,
+		Method:        method,
+		Timeout:       timeout,
+		RetryStrategy: retryStrategy,
+		UniqueID:      uuid.New().String(),
+	}
+	if method == "GET" {
+		req.IsIdempotent = true
+	} else {
+		req.Body = []byte(posts.Encode())
+		req.ContentType = "application/x-www-form-urlencoded"
+	}
+
+	dspan := sm.tracer.StartSpan("dispatch", tracectx)
+	resp, err := sm.httpClient.DoHTTPRequest(req)
+	dspan.Finish()
+	if err != nil {
+		return makeGenericHTTPError(err, req, resp)
+	}
+
+	if resp.StatusCode != 200 {
+		return makeHTTPBadStatusError(errMsg, req, resp)
+	}
+
+	if valueOut != nil {
+		jsonDec := json.NewDecoder(resp.Body)
+		err = jsonDec.Decode(valueOut)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		logDebugf("Failed to close socket (%s)", err)
+	}
+
+	return nil
+}
+
+// AutoFailoverSettings are the settings controlling automatic failover of unresponsive nodes.
+type AutoFailoverSettings struct {
+	Enabled bool
+	// Timeout is how long a node must be unresponsive before it is failed over.  It is
+	// rounded down to whole seconds.
+	Timeout time.Duration
+	// MaxCount is the number of nodes which can be automatically failed over before an
+	// administrator must intervene.
+	MaxCount int
+	// Count is the number of nodes which have been automatically failed over.  It is ignored
+	// when updating the settings.
+	Count int
+}
+
+type jsonAutoFailoverSettings struct {
+	Enabled  bool `json:"enabled"`
+	Timeout  int  `json:"timeout"`
+	MaxCount int  `json:"maxCount"`
+	Count    int  `json:"count"`
+}
+
+// GetAutoFailoverSettingsOptions is the set of options available to the settings manager
+// GetAutoFailoverSettings operation.
+type GetAutoFailoverSettingsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// GetAutoFailoverSettings returns the auto-failover settings of the cluster.
+func (sm *ClusterSettingsManager) GetAutoFailoverSettings(opts *GetAutoFailoverSettingsOptions) (*AutoFailoverSettings, error) {
+	if opts == nil {
+		opts = &GetAutoFailoverSettingsOptions{}
+	}
+
+	span := sm.tracer.StartSpan("GetAutoFailoverSettings", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	var settingsData jsonAutoFailoverSettings
+	err := sm.doRequest(span.Context(), "GET", "/settings/autoFailover", nil, opts.Timeout, opts.RetryStrategy,
+		"failed to get auto-failover settings", &settingsData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AutoFailoverSettings{
+		Enabled:  settingsData.Enabled,
+		Timeout:  time.Duration(settingsData.Timeout) * time.Second,
+		MaxCount: settingsData.MaxCount,
+		Count:    settingsData.Count,
+	}, nil
+}
+
+// UpdateAutoFailoverSettingsOptions is the set of options available to the settings manager
+// UpdateAutoFailoverSettings operation.
+type UpdateAutoFailoverSettingsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// UpdateAutoFailoverSettings updates the auto-failover settings of the cluster.
+func (sm *ClusterSettingsManager) UpdateAutoFailoverSettings(settings AutoFailoverSettings,
+	opts *UpdateAutoFailoverSettingsOptions) error {
+	if opts == nil {
+		opts = &UpdateAutoFailoverSettingsOptions{}
+	}
+
+	span := sm.tracer.StartSpan("UpdateAutoFailoverSettings", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	posts := url.Values{}
+	posts.Add("enabled", strconv.FormatBool(settings.Enabled))
+	if settings.Enabled {
+		if settings.Timeout < time.Second {
+			return makeInvalidArgumentsError("auto-failover timeout must be at least one second")
+		}
+
+		posts.Add("timeout", strconv.Itoa(int(settings.Timeout/time.Second)))
+		if settings.MaxCount > 0 {
+			posts.Add("maxCount", strconv.Itoa(settings.MaxCount))
+		}
+	}
+
+	return sm.doRequest(span.Context(), "POST", "/settings/autoFailover", posts, opts.Timeout, opts.RetryStrategy,
+		"failed to update auto-failover settings", nil)
+}
+
+// EmailAlertSettings are the settings controlling the email alerts sent by the cluster.
+type EmailAlertSettings struct {
+	Enabled    bool
+	Sender     string
+	Recipients []string
+
+	Host    string
+	Port    int
+	Encrypt bool
+	// Username is the user to authenticate to the email server with.
+	Username string
+	// Password is the password to authenticate to the email server with.  It is never
+	// returned by the server, and is left unchanged on update if empty.
+	Password string
+
+	// Alerts are the names of the alerts which are enabled, for example auto_failover_node.
+	// The server default is used on update if empty.
+	Alerts []string
+}
+
+type jsonEmailServerSettings struct {
+	User    string `json:"user"`
+	Host    string `json:"host"`
+	Port    int    `json:"port"`
+	Encrypt bool   `json:"encrypt"`
+}
+
+type jsonEmailAlertSettings struct {
+	Enabled     bool                    `json:"enabled"`
+	Sender      string                  `json:"sender"`
+	Recipients  []string                `json:"recipients"`
+	EmailServer jsonEmailServerSettings `json:"emailServer"`
+	Alerts      []string                `json:"alerts"`
+}
+
+// GetEmailAlertSettingsOptions is the set of options available to the settings manager
+// GetEmailAlertSettings operation.
+type GetEmailAlertSettingsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// GetEmailAlertSettings returns the email alert settings of the cluster.
+func (sm *ClusterSettingsManager) GetEmailAlertSettings(opts *GetEmailAlertSettingsOptions) (*EmailAlertSettings, error) {
+	if opts == nil {
+		opts = &GetEmailAlertSettingsOptions{}
+	}
+
+	span := sm.tracer.StartSpan("GetEmailAlertSettings", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	var settingsData jsonEmailAlertSettings
+	err := sm.doRequest(span.Context(), "GET", "/settings/alerts", nil, opts.Timeout, opts.RetryStrategy,
+		"failed to get email alert settings", &settingsData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailAlertSettings{
+		Enabled:    settingsData.Enabled,
+		Sender:     settingsData.Sender,
+		Recipients: settingsData.Recipients,
+		Host:       settingsData.EmailServer.Host,
+		Port:       settingsData.EmailServer.Port,
+		Encrypt:    settingsData.EmailServer.Encrypt,
+		Username:   settingsData.EmailServer.User,
+		Alerts:     settingsData.Alerts,
+	}, nil
+}
+
+// UpdateEmailAlertSettingsOptions is the set of options available to the settings manager
+// UpdateEmailAlertSettings operation.
+type UpdateEmailAlertSettingsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// UpdateEmailAlertSettings updates the email alert settings of the cluster.
+func (sm *ClusterSettingsManager) UpdateEmailAlertSettings(settings EmailAlertSettings,
+	opts *UpdateEmailAlertSettingsOptions) error {
+	if opts == nil {
+		opts = &UpdateEmailAlertSettingsOptions{}
+	}
+
+	span := sm.tracer.StartSpan("UpdateEmailAlertSettings", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	posts := url.Values{}
+	posts.Add("enabled", strconv.FormatBool(settings.Enabled))
+	if settings.Enabled {
+		if settings.Sender == "" || len(settings.Recipients) == 0 {
+			return makeInvalidArgumentsError("sender and recipients must be specified to enable email alerts")
+		}
+
+		posts.Add("sender", settings.Sender)
+		posts.Add("recipients", strings.Join(settings.Recipients, ","))
+		posts.Add("emailHost", settings.Host)
+		if settings.Port > 0 {
+			posts.Add("emailPort", strconv.Itoa(settings.Port))
+		}
+		posts.Add("emailEncrypt", strconv.FormatBool(settings.Encrypt))
+		if settings.Username != "" {
+			posts.Add("emailUser", settings.Username)
+		}
+		if settings.Password != "" {
+			posts.Add("emailPass", settings.Password)
+		}
+		if len(settings.Alerts) > 0 {
+			posts.Add("alerts", strings.Join(settings.Alerts, ","))
+		}
+	}
+
+	return sm.doRequest(span.Context(), "POST", "/settings/alerts", posts, opts.Timeout, opts.RetryStrategy,
+		"failed to update email alert settings", nil)
+}
+
+// MemoryQuotas are the memory quotas, in megabytes, assigned to each service on every node.
+// A zero quota is left unchanged on update.
+type MemoryQuotas struct {
+	KeyValueMB  uint64
+	IndexMB     uint64
+	SearchMB    uint64
+	AnalyticsMB uint64
+	EventingMB  uint64
+}
+
+type jsonMemoryQuotas struct {
+	MemoryQuota         uint64 `json:"memoryQuota"`
+	IndexMemoryQuota    uint64 `json:"indexMemoryQuota"`
+	FtsMemoryQuota      uint64 `json:"ftsMemoryQuota"`
+	CbasMemoryQuota     uint64 `json:"cbasMemoryQuota"`
+	EventingMemoryQuota uint64 `json:"eventingMemoryQuota"`
+}
+
+// GetMemoryQuotasOptions is the set of options available to the settings manager
+// GetMemoryQuotas operation.
+type GetMemoryQuotasOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// GetMemoryQuotas returns the per service memory quotas of the cluster.
+func (sm *ClusterSettingsManager) GetMemoryQuotas(opts *GetMemoryQuotasOptions) (*MemoryQuotas, error) {
+	if opts == nil {
+		opts = &GetMemoryQuotasOptions{}
+	}
+
+	span := sm.tracer.StartSpan("GetMemoryQuotas", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	var quotasData jsonMemoryQuotas
+	err := sm.doRequest(span.Context(), "GET", "/pools/default", nil, opts.Timeout, opts.RetryStrategy,
+		"failed to get memory quotas", &quotasData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryQuotas{
+		KeyValueMB:  quotasData.MemoryQuota,
+		IndexMB:     quotasData.IndexMemoryQuota,
+		SearchMB:    quotasData.FtsMemoryQuota,
+		AnalyticsMB: quotasData.CbasMemoryQuota,
+		EventingMB:  quotasData.EventingMemoryQuota,
+	}, nil
+}
+
+// UpdateMemoryQuotasOptions is the set of options available to the settings manager
+// UpdateMemoryQuotas operation.
+type UpdateMemoryQuotasOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// UpdateMemoryQuotas updates the per service memory quotas of the cluster.
+func (sm *ClusterSettingsManager) UpdateMemoryQuotas(quotas MemoryQuotas, opts *UpdateMemoryQuotasOptions) error {
+	if opts == nil {
+		opts = &UpdateMemoryQuotasOptions{}
+	}
+
+	span := sm.tracer.StartSpan("UpdateMemoryQuotas", nil).
+		SetTag("couchbase.service", "mgmt")
+	defer span.Finish()
+
+	posts := url.Values{}
+	addQuota := func(name string, quota uint64) {
+		if quota > 0 {
+			posts.Add(name, strconv.FormatUint(quota, 10))
+		}
+	}
+	addQuota("memoryQuota", quotas.KeyValueMB)
+	addQuota("indexMemoryQuota", quotas.IndexMB)
+	addQuota("ftsMemoryQuota", quotas.SearchMB)
+	addQuota("cbasMemoryQuota", quotas.AnalyticsMB)
+	addQuota("eventingMemoryQuota", quotas.EventingMB)
+
+	if len(posts) == 0 {
+		return makeInvalidArgumentsError("at least one memory quota must be specified")
+	}
+
+	return sm.doRequest(span.Context(), "POST", "/pools/default", posts, opts.Timeout, opts.RetryStrategy,
+		"failed to update memory quotas", nil)
+}
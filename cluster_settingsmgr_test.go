@@ -0,0 +1,156 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func testSettingsManager(doFn func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error)) *ClusterSettingsManager {
+	return &ClusterSettingsManager{
+		httpClient:    &mockHTTPProvider{doFn: doFn},
+		globalTimeout: 10 * time.Second,
+		tracer:        &noopTracer{},
+	}
+}
+
+func TestSettingsMgrAutoFailover(t *testing.T) {
+	var updateBody string
+	mgr := testSettingsManager(func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+		if req.Path != "/settings/autoFailover" {
+			t.Fatalf("Unexpected request path: %s", req.Path)
+		}
+
+		body := ""
+		if req.Method == "GET" {
+			body = `{"enabled":true,"timeout":120,"count":1,"maxCount":3}`
+		} else {
+			updateBody = string(req.Body)
+		}
+
+		return &gocbcore.HTTPResponse{
+			StatusCode: 200,
+			Body:       &testReadCloser{bytes.NewBufferString(body), nil},
+		}, nil
+	})
+
+	settings, err := mgr.GetAutoFailoverSettings(nil)
+	if err != nil {
+		t.Fatalf("GetAutoFailoverSettings failed: %v", err)
+	}
+
+	expected := AutoFailoverSettings{Enabled: true, Timeout: 2 * time.Minute, MaxCount: 3, Count: 1}
+	if *settings != expected {
+		t.Fatalf("Unexpected settings %+v", settings)
+	}
+
+	err = mgr.UpdateAutoFailoverSettings(AutoFailoverSettings{Enabled: true, Timeout: 30 * time.Second, MaxCount: 2}, nil)
+	if err != nil {
+		t.Fatalf("UpdateAutoFailoverSettings failed: %v", err)
+	}
+
+	form, _ := url.ParseQuery(updateBody)
+	if form.Get("enabled") != "true" || form.Get("timeout") != "30" || form.Get("maxCount") != "2" {
+		t.Fatalf("Unexpected update body %s", updateBody)
+	}
+
+	err = mgr.UpdateAutoFailoverSettings(AutoFailoverSettings{Enabled: true}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestSettingsMgrEmailAlerts(t *testing.T) {
+	var updateBody string
+	mgr := testSettingsManager(func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+		if req.Path != "/settings/alerts" {
+			t.Fatalf("Unexpected request path: %s", req.Path)
+		}
+
+		body := ""
+		if req.Method == "GET" {
+			body = `{"enabled":true,"sender":"cb@example.com","recipients":["ops@example.com"],` +
+				`"emailServer":{"user":"cb","host":"smtp.example.com","port":587,"encrypt":true},` +
+				`"alerts":["auto_failover_node","disk"]}`
+		} else {
+			updateBody = string(req.Body)
+		}
+
+		return &gocbcore.HTTPResponse{
+			StatusCode: 200,
+			Body:       &testReadCloser{bytes.NewBufferString(body), nil},
+		}, nil
+	})
+
+	settings, err := mgr.GetEmailAlertSettings(nil)
+	if err != nil {
+		t.Fatalf("GetEmailAlertSettings failed: %v", err)
+	}
+
+	if !settings.Enabled || settings.Sender != "cb@example.com" || len(settings.Recipients) != 1 ||
+		settings.Host != "smtp.example.com" || settings.Port != 587 || !settings.Encrypt ||
+		settings.Username != "cb" || len(settings.Alerts) != 2 {
+		t.Fatalf("Unexpected settings %+v", settings)
+	}
+
+	settings.Recipients = append(settings.Recipients, "dev@example.com")
+	err = mgr.UpdateEmailAlertSettings(*settings, nil)
+	if err != nil {
+		t.Fatalf("UpdateEmailAlertSettings failed: %v", err)
+	}
+
+	form, _ := url.ParseQuery(updateBody)
+	if form.Get("recipients") != "ops@example.com,dev@example.com" || form.Get("emailPort") != "587" ||
+		form.Get("alerts") != "auto_failover_node,disk" || form.Get("emailPass") != "" {
+		t.Fatalf("Unexpected update body %s", updateBody)
+	}
+}
+
+func TestSettingsMgrMemoryQuotas(t *testing.T) {
+	var updateBody string
+	mgr := testSettingsManager(func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+		if req.Path != "/pools/default" {
+			t.Fatalf("Unexpected request path: %s", req.Path)
+		}
+
+		body := ""
+		if req.Method == "GET" {
+			body = `{"memoryQuota":2048,"indexMemoryQuota":512,"ftsMemoryQuota":256,"cbasMemoryQuota":1024}`
+		} else {
+			updateBody = string(req.Body)
+		}
+
+		return &gocbcore.HTTPResponse{
+			StatusCode: 200,
+			Body:       &testReadCloser{bytes.NewBufferString(body), nil},
+		}, nil
+	})
+
+	quotas, err := mgr.GetMemoryQuotas(nil)
+	if err != nil {
+		t.Fatalf("GetMemoryQuotas failed: %v", err)
+	}
+
+	expected := MemoryQuotas{KeyValueMB: 2048, IndexMB: 512, SearchMB: 256, AnalyticsMB: 1024}
+	if *quotas != expected {
+		t.Fatalf("Unexpected quotas %+v", quotas)
+	}
+
+	err = mgr.UpdateMemoryQuotas(MemoryQuotas{IndexMB: 1024}, nil)
+	if err != nil {
+		t.Fatalf("UpdateMemoryQuotas failed: %v", err)
+	}
+
+	if updateBody != "indexMemoryQuota=1024" {
+		t.Fatalf("Unexpected update body %s", updateBody)
+	}
+
+	err = mgr.UpdateMemoryQuotas(MemoryQuotas{}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package gocb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/gocbconnstr"
+)
+
+func TestClusterCloseClientErrors(t *testing.T) {
+	closeErr := errors.New("close failed")
+	c := &Cluster{
+		connections: map[string]client{
+			"good": &mockClient{bucketName: "good"},
+			"bad":  &mockClient{bucketName: "bad", closeErr: closeErr},
+		},
+	}
+
+	err := c.Close(nil)
+	var clusterCloseErr ClusterCloseError
+	if !errors.As(err, &clusterCloseErr) {
+		t.Fatalf("Expected a cluster close error but was %v", err)
+	}
+
+	if len(clusterCloseErr.ClientErrors) != 1 || clusterCloseErr.ClientErrors["bad"] != closeErr {
+		t.Fatalf("Expected only the bad client to be reported but was %v", clusterCloseErr.ClientErrors)
+	}
+
+	if !errors.Is(err, closeErr) {
+		t.Fatalf("Expected error to match the client error")
+	}
+
+	if len(c.connections) != 0 {
+		t.Fatalf("Expected all connections to be removed but had %d", len(c.connections))
+	}
+}
+
+func TestClusterCloseTimeout(t *testing.T) {
+	c := &Cluster{
+		connections: map[string]client{
+			"fast": &mockClient{bucketName: "fast"},
+			"slow": &mockClient{bucketName: "slow", closeWait: time.Second},
+		},
+	}
+
+	start := time.Now()
+	err := c.Close(&ClusterCloseOptions{
+		Timeout: 50 * time.Millisecond,
+	})
+	if time.Since(start) >= time.Second {
+		t.Fatalf("Close should have returned once the timeout elapsed")
+	}
+
+	var clusterCloseErr ClusterCloseError
+	if !errors.As(err, &clusterCloseErr) {
+		t.Fatalf("Expected a cluster close error but was %v", err)
+	}
+
+	if len(clusterCloseErr.ClientErrors) != 1 || !errors.Is(clusterCloseErr.ClientErrors["slow"], ErrTimeout) {
+		t.Fatalf("Expected only the slow client to time out but was %v", clusterCloseErr.ClientErrors)
+	}
+}
+
+func TestClusterCloseBucketNamedCluster(t *testing.T) {
+	bucketErr := errors.New("bucket close failed")
+	clusterErr := errors.New("cluster close failed")
+	c := &Cluster{
+		connections: map[string]client{
+			"cluster": &mockClient{bucketName: "cluster", closeErr: bucketErr},
+		},
+		clusterClient: &mockClient{closeErr: clusterErr},
+	}
+
+	err := c.Close(nil)
+
+	var clusterCloseErr ClusterCloseError
+	if !errors.As(err, &clusterCloseErr) {
+		t.Fatalf("Expected a cluster close error but was %v", err)
+	}
+
+	if clusterCloseErr.ClientErrors["cluster"] != bucketErr || clusterCloseErr.ClientErrors[clusterClientName] != clusterErr {
+		t.Fatalf("Expected both the bucket and cluster clients to be closed but was %v", clusterCloseErr.ClientErrors)
+	}
+}
+
+func TestClusterCloseAbandonOnTimeout(t *testing.T) {
+	c := &Cluster{
+		connections: map[string]client{
+			"slow": &mockClient{bucketName: "slow", closeWait: time.Second},
+		},
+	}
+
+	start := time.Now()
+	err := c.Close(&ClusterCloseOptions{
+		Timeout:          50 * time.Millisecond,
+		AbandonOnTimeout: true,
+	})
+	if err != nil {
+		t.Fatalf("Expected abandoning close not to error but was %v", err)
+	}
+
+	if time.Since(start) >= time.Second {
+		t.Fatalf("Close should have returned once the timeout elapsed")
+	}
+}
+
+func TestCheckConnStrOptions(t *testing.T) {
+	spec, err := gocbconnstr.Parse("couchbase://localhost?query_timeout=100&kv_pool_size=2")
+	if err != nil {
+		t.Fatalf("Failed to parse connection string: %v", err)
+	}
+
+	if err := checkConnStrOptions(spec, true); err != nil {
+		t.Fatalf("Expected known options to be accepted but was %v", err)
+	}
+
+	spec, err = gocbconnstr.Parse("couchbase://localhost?query_timout=100&frobnicate=true")
+	if err != nil {
+		t.Fatalf("Failed to parse connection string: %v", err)
+	}
+
+	if err := checkConnStrOptions(spec, false); err != nil {
+		t.Fatalf("Expected unknown options to only be logged but was %v", err)
+	}
+
+	err = checkConnStrOptions(spec, true)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "frobnicate, query_timout (did you mean query_timeout?)") {
+		t.Fatalf("Expected error to name the unknown options but was %v", err)
+	}
+}
+
+func TestConnectStrictConnectionString(t *testing.T) {
+	_, err := Connect("couchbase://localhost?kv_pool_sise=2", ClusterOptions{StrictConnectionString: true})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+}
+
+func TestBuildUserAgent(t *testing.T) {
+	userAgent, err := buildUserAgent("", "")
+	if err != nil || userAgent != Identifier() {
+		t.Fatalf("Expected default user agent but was %s, %v", userAgent, err)
+	}
+
+	userAgent, err = buildUserAgent("billing-service", "1.4.2")
+	if err != nil || userAgent != Identifier()+" billing-service/1.4.2" {
+		t.Fatalf("Unexpected user agent %s, %v", userAgent, err)
+	}
+
+	_, err = buildUserAgent("", "1.4.2")
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+
+	_, err = Connect("couchbase://localhost", ClusterOptions{ApplicationName: "billing service"})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+}
@@ -229,7 +229,7 @@ type UserManager struct {
 	httpClient           httpProvider
 	globalTimeout        time.Duration
 	defaultRetryStrategy *retryStrategyWrapper
-	tracer               requestTracer
+	tracer               RequestTracer
 }
 
 // GetAllUsersOptions is the set of options available to the user manager GetAll operation.
@@ -254,10 +254,7 @@ func (um *UserManager) GetAllUsers(opts *GetAllUsersOptions) ([]UserAndMetadata,
 		opts.DomainName = string(LocalDomain)
 	}
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -324,10 +321,7 @@ func (um *UserManager) GetUser(name string, opts *GetUserOptions) (*UserAndMetad
 		opts.DomainName = string(LocalDomain)
 	}
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -392,10 +386,7 @@ func (um *UserManager) UpsertUser(user User, opts *UpsertUserOptions) error {
 		opts.DomainName = string(LocalDomain)
 	}
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	var reqRoleStrs []string
 	for _, roleData := range user.Roles {
@@ -458,10 +449,7 @@ func (um *UserManager) DropUser(name string, opts *DropUserOptions) error {
 		opts.DomainName = string(LocalDomain)
 	}
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -501,10 +489,7 @@ func (um *UserManager) GetRoles(opts *GetRolesOptions) ([]RoleAndDescription, er
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -568,10 +553,7 @@ func (um *UserManager) GetGroup(groupName string, opts *GetGroupOptions) (*Group
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -630,10 +612,7 @@ func (um *UserManager) GetAllGroups(opts *GetAllGroupsOptions) ([]Group, error)
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
@@ -697,10 +676,7 @@ func (um *UserManager) UpsertGroup(group Group, opts *UpsertGroupOptions) error
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	var reqRoleStrs []string
 	for _, roleData := range group.Roles {
@@ -760,10 +736,7 @@ func (um *UserManager) DropGroup(groupName string, opts *DropGroupOptions) error
 		SetTag("couchbase.service", "mgmt")
 	defer span.Finish()
 
-	retryStrategy := um.defaultRetryStrategy
-	if opts.RetryStrategy == nil {
-		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryStrategy := um.defaultRetryStrategy.withStrategy(opts.RetryStrategy)
 
 	req := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(ServiceTypeManagement),
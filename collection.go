@@ -3,11 +3,14 @@ package gocb
 // Collection represents a single collection.
 type Collection struct {
 	sb stateBlock
+
+	cluster *Cluster
 }
 
 func newCollection(scope *Scope, collectionName string) *Collection {
 	collection := &Collection{
-		sb: scope.stateBlock(),
+		sb:      scope.stateBlock(),
+		cluster: scope.cluster,
 	}
 	collection.sb.CollectionName = collectionName
 
@@ -34,6 +37,10 @@ func (c *Collection) getKvProvider() (kvProvider, error) {
 		return nil, err
 	}
 
+	if c.sb.ReadOnly {
+		return readOnlyKvProvider{agent}, nil
+	}
+
 	return agent, nil
 }
 
@@ -42,7 +49,7 @@ func (c *Collection) Name() string {
 	return c.sb.CollectionName
 }
 
-func (c *Collection) startKvOpTrace(operationName string, tracectx requestSpanContext) requestSpan {
+func (c *Collection) startKvOpTrace(operationName string, tracectx RequestSpanContext) RequestSpan {
 	return c.sb.Tracer.StartSpan(operationName, tracectx).
 		SetTag("couchbase.bucket", c.sb.BucketName).
 		SetTag("couchbase.collection", c.sb.CollectionName).
@@ -0,0 +1,177 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// MutationOutcome describes whether the effect of a mutation is visible on a document.
+type MutationOutcome uint
+
+const (
+	// MutationOutcomeUnknown indicates that it could not be determined whether the mutation was applied.
+	MutationOutcomeUnknown = MutationOutcome(0)
+
+	// MutationOutcomeApplied indicates that the document currently reflects the mutation.
+	MutationOutcomeApplied = MutationOutcome(1)
+
+	// MutationOutcomeNotApplied indicates that the document does not currently reflect the mutation.
+	MutationOutcomeNotApplied = MutationOutcome(2)
+)
+
+// ResolveAmbiguousMutationOptions are the options available to the ResolveAmbiguousMutation operation.
+// At least one of ExpectedValue, IdempotencyToken or PreviousCas must be set, or the mutation must be
+// a Remove.
+type ResolveAmbiguousMutationOptions struct {
+	// Remove indicates that the ambiguous mutation was a Remove.
+	Remove bool
+
+	// ExpectedValue is the value which the ambiguous mutation attempted to store.  When set the current
+	// content of the document is compared against it.
+	ExpectedValue interface{}
+
+	// IdempotencyTokenField is the name of a top-level field within the document which the ambiguous
+	// mutation set to IdempotencyToken.  Writing a unique token with each mutation allows the outcome
+	// to be resolved even when the rest of the document is unchanged by the mutation.
+	IdempotencyTokenField string
+	IdempotencyToken      interface{}
+
+	// PreviousCas is the CAS of the document before the ambiguous mutation was attempted.  If the
+	// document still has this CAS then the mutation was not applied.
+	PreviousCas Cas
+
+	Transcoder    Transcoder
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// ResolveAmbiguousMutation re-reads a document after a mutation failed with ErrDurabilityAmbiguous,
+// or an ambiguous timeout, to determine whether the mutation was applied.  The outcome reflects
+// the current state of the document, so a concurrent writer may have since overwritten or removed
+// the mutation.  MutationOutcomeUnknown is returned if only PreviousCas was provided and the
+// document has since changed, as the change may have been made by another writer.
+//
+// Note that an applied mutation may still not have met its durability requirements, in which case
+// the mutation can be retried with the same value to ensure that it becomes durable.
+func (c *Collection) ResolveAmbiguousMutation(id string, opts *ResolveAmbiguousMutationOptions) (MutationOutcome, *GetResult, error) {
+	if opts == nil {
+		opts = &ResolveAmbiguousMutationOptions{}
+	}
+
+	if !opts.Remove && opts.ExpectedValue == nil && opts.IdempotencyTokenField == "" && opts.PreviousCas == 0 {
+		return MutationOutcomeUnknown, nil, makeInvalidArgumentsError(
+			"one of ExpectedValue, IdempotencyTokenField or PreviousCas must be set")
+	}
+	if opts.IdempotencyTokenField != "" && opts.IdempotencyToken == nil {
+		return MutationOutcomeUnknown, nil, makeInvalidArgumentsError(
+			"IdempotencyToken must be set when IdempotencyTokenField is set")
+	}
+
+	transcoder := opts.Transcoder
+	if transcoder == nil {
+		transcoder = c.sb.Transcoder
+	}
+
+	doc, err := c.Get(id, &GetOptions{
+		Transcoder:    transcoder,
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+	})
+	if err != nil {
+		if errors.Is(err, ErrDocumentNotFound) {
+			if opts.Remove {
+				return MutationOutcomeApplied, nil, nil
+			}
+
+			return MutationOutcomeNotApplied, nil, nil
+		}
+
+		return MutationOutcomeUnknown, nil, err
+	}
+
+	if opts.Remove {
+		return MutationOutcomeNotApplied, doc, nil
+	}
+
+	if opts.PreviousCas != 0 && doc.Cas() == opts.PreviousCas {
+		return MutationOutcomeNotApplied, doc, nil
+	}
+
+	if opts.IdempotencyTokenField != "" {
+		matches, err := doc.fieldMatches(opts.IdempotencyTokenField, opts.IdempotencyToken)
+		if err != nil {
+			return MutationOutcomeUnknown, doc, err
+		}
+		if !matches {
+			return MutationOutcomeNotApplied, doc, nil
+		}
+		if opts.ExpectedValue == nil {
+			return MutationOutcomeApplied, doc, nil
+		}
+	}
+
+	if opts.ExpectedValue != nil {
+		matches, err := doc.contentMatches(opts.ExpectedValue)
+		if err != nil {
+			return MutationOutcomeUnknown, doc, err
+		}
+		if matches {
+			return MutationOutcomeApplied, doc, nil
+		}
+
+		return MutationOutcomeNotApplied, doc, nil
+	}
+
+	return MutationOutcomeUnknown, doc, nil
+}
+
+// contentMatches compares the contents of the document against the encoded form of value.
+func (d *GetResult) contentMatches(value interface{}) (bool, error) {
+	expectedBytes, flags, err := d.transcoder.Encode(value)
+	if err != nil {
+		return false, err
+	}
+
+	if bytes.Equal(expectedBytes, d.contents) {
+		return true, nil
+	}
+
+	// The stored representation may differ from ours, e.g. by whitespace or field order, so
+	// fall back to comparing the decoded forms.
+	var expected, actual interface{}
+	if err := d.transcoder.Decode(expectedBytes, flags, &expected); err != nil {
+		return false, nil
+	}
+	if err := d.transcoder.Decode(d.contents, d.flags, &actual); err != nil {
+		return false, nil
+	}
+
+	return reflect.DeepEqual(expected, actual), nil
+}
+
+// fieldMatches compares a top-level field of the document against token.
+func (d *GetResult) fieldMatches(field string, token interface{}) (bool, error) {
+	var content map[string]interface{}
+	if err := d.Content(&content); err != nil {
+		return false, err
+	}
+
+	actual, ok := content[field]
+	if !ok {
+		return false, nil
+	}
+
+	tokenBytes, flags, err := d.transcoder.Encode(token)
+	if err != nil {
+		return false, err
+	}
+
+	var expected interface{}
+	if err := d.transcoder.Decode(tokenBytes, flags, &expected); err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(expected, actual), nil
+}
@@ -0,0 +1,155 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveAmbiguousMutationMatchingValue(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{ "name": "mike", "age": 32 }`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	outcome, doc, err := col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		ExpectedValue: map[string]interface{}{"age": 32, "name": "mike"},
+		PreviousCas:   5,
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeApplied {
+		t.Fatalf("Expected outcome to be applied but was %d", outcome)
+	}
+
+	if doc == nil || doc.Cas() != 10 {
+		t.Fatalf("Expected document with cas 10 to be returned but was %v", doc)
+	}
+}
+
+func TestResolveAmbiguousMutationDifferentValue(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"name":"bob"}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	outcome, _, err := col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		ExpectedValue: map[string]interface{}{"name": "mike"},
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeNotApplied {
+		t.Fatalf("Expected outcome to be not applied but was %d", outcome)
+	}
+}
+
+func TestResolveAmbiguousMutationUnchangedCas(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   5,
+		value: []byte(`{"name":"mike"}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	outcome, _, err := col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		ExpectedValue: map[string]interface{}{"name": "mike"},
+		PreviousCas:   5,
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeNotApplied {
+		t.Fatalf("Expected outcome to be not applied but was %d", outcome)
+	}
+
+	outcome, _, err = col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		PreviousCas: 4,
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeUnknown {
+		t.Fatalf("Expected outcome to be unknown but was %d", outcome)
+	}
+}
+
+func TestResolveAmbiguousMutationIdempotencyToken(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"name":"mike","txnToken":"abc123"}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	outcome, _, err := col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		IdempotencyTokenField: "txnToken",
+		IdempotencyToken:      "abc123",
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeApplied {
+		t.Fatalf("Expected outcome to be applied but was %d", outcome)
+	}
+
+	outcome, _, err = col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		IdempotencyTokenField: "txnToken",
+		IdempotencyToken:      "def456",
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeNotApplied {
+		t.Fatalf("Expected outcome to be not applied but was %d", outcome)
+	}
+}
+
+func TestResolveAmbiguousMutationDocumentNotFound(t *testing.T) {
+	provider := &mockKvProvider{
+		err:   ErrDocumentNotFound,
+		value: make([]byte, 0),
+	}
+	col := testGetCollection(t, provider)
+
+	outcome, _, err := col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		Remove: true,
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeApplied {
+		t.Fatalf("Expected remove outcome to be applied but was %d", outcome)
+	}
+
+	outcome, _, err = col.ResolveAmbiguousMutation("ambiguous", &ResolveAmbiguousMutationOptions{
+		ExpectedValue: "value",
+	})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if outcome != MutationOutcomeNotApplied {
+		t.Fatalf("Expected store outcome to be not applied but was %d", outcome)
+	}
+}
+
+func TestResolveAmbiguousMutationNoCriteria(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	_, _, err := col.ResolveAmbiguousMutation("ambiguous", nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
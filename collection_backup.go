@@ -0,0 +1,363 @@
+package gocb
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+	"github.com/google/uuid"
+)
+
+const backupArchiveVersion = 1
+
+// These are the options understood by the server for SetWithMeta and DelWithMeta.
+const (
+	withMetaSkipConflictResolution = uint32(0x01)
+	withMetaForceAccept            = uint32(0x02)
+)
+
+// backupHeader is the first line of a backup archive.
+type backupHeader struct {
+	Version    int    `json:"version"`
+	Bucket     string `json:"bucket"`
+	Scope      string `json:"scope"`
+	Collection string `json:"collection"`
+}
+
+// backupRecord is a single document within a backup archive.  The value, and datatype, are stored
+// exactly as they were received from the server so that any xattrs are preserved.
+type backupRecord struct {
+	Key      string `json:"key"`
+	Value    []byte `json:"value,omitempty"`
+	Flags    uint32 `json:"flags,omitempty"`
+	Expiry   uint32 `json:"expiry,omitempty"`
+	Cas      uint64 `json:"cas"`
+	RevNo    uint64 `json:"revno"`
+	Datatype uint8  `json:"datatype,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+}
+
+// BackupOptions are the options available to the Backup operation.
+type BackupOptions struct {
+	// IncludeXattrs includes the extended attributes of each document in the backup.
+	IncludeXattrs bool
+
+	// IncludeTombstones includes documents which have been removed in the backup, so that restoring
+	// the backup also removes them.
+	IncludeTombstones bool
+
+	// Timeout is the length of time to wait whilst setting up the backup.
+	Timeout time.Duration
+}
+
+// BackupResult is the outcome of a Backup operation.
+type BackupResult struct {
+	Documents  int
+	Tombstones int
+}
+
+// Backup writes every document in the collection to w, in an archive which can be restored with
+// Restore.  The documents are read from the change stream of the collection, and so the backup is
+// a consistent copy of each vbucket at the point that the backup started, but is not consistent
+// across vbuckets.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) Backup(w io.Writer, opts *BackupOptions) (*BackupResult, error) {
+	if opts == nil {
+		opts = &BackupOptions{}
+	}
+
+	openFlags := gocbcore.DcpOpenFlag(0)
+	if opts.IncludeXattrs {
+		openFlags |= gocbcore.DcpOpenFlagIncludeXattrs
+	}
+
+	feed, err := openChangeFeed(&c.sb, c.scopeName(), c.name(), &ChangeFeedOptions{
+		Name:    "gocb-backup-" + uuid.New().String(),
+		Timeout: opts.Timeout,
+	}, openFlags, gocbcore.DcpStreamAddFlagLatest)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := writeBackup(feed, w, &backupHeader{
+		Version:    backupArchiveVersion,
+		Bucket:     c.sb.BucketName,
+		Scope:      c.scopeName(),
+		Collection: c.name(),
+	}, opts.IncludeTombstones)
+
+	closeErr := feed.Close()
+	if closeErr != nil {
+		logDebugf("Failed to close backup change feed (%s)", closeErr)
+	}
+
+	return result, err
+}
+
+func writeBackup(feed *ChangeFeed, w io.Writer, header *backupHeader, includeTombstones bool) (*BackupResult, error) {
+	result := &BackupResult{}
+	encoder := json.NewEncoder(w)
+
+	err := encoder.Encode(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for feed.Next() {
+		event := feed.Event()
+
+		record := backupRecord{
+			Key:      event.Key,
+			Value:    event.Value,
+			Flags:    event.Flags,
+			Expiry:   event.Expiry,
+			Cas:      uint64(event.Cas),
+			RevNo:    event.revNo,
+			Datatype: event.datatype,
+		}
+
+		switch event.Type {
+		case ChangeEventMutation:
+			result.Documents++
+		case ChangeEventDeletion, ChangeEventExpiration:
+			if !includeTombstones {
+				continue
+			}
+			record.Deleted = true
+			result.Tombstones++
+		default:
+			continue
+		}
+
+		err := encoder.Encode(&record)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if feed.Err() != nil {
+		return result, feed.Err()
+	}
+
+	return result, nil
+}
+
+// RestoreConflictResolution specifies how a restore handles documents which already exist.
+type RestoreConflictResolution uint
+
+const (
+	// RestoreConflictResolutionServer uses the conflict resolution of the bucket, as used by XDCR, to
+	// decide whether the backed up or existing version of each document is kept.  This is the default.
+	RestoreConflictResolutionServer = RestoreConflictResolution(1)
+
+	// RestoreConflictResolutionOverwrite always replaces existing documents with the backed up version.
+	RestoreConflictResolutionOverwrite = RestoreConflictResolution(2)
+)
+
+// RestoreOptions are the options available to the Restore operation.
+type RestoreOptions struct {
+	ConflictResolution RestoreConflictResolution
+
+	// MaxInFlight is the maximum number of documents being written at once.  The default is 128.
+	MaxInFlight int
+
+	// OnProgress, if set, is called each time that a document has been written.
+	OnProgress func(progress BulkProgress)
+
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// RestoreFailure describes a document which could not be restored.
+type RestoreFailure struct {
+	ID  string
+	Err error
+}
+
+// RestoreResult is the outcome of a Restore operation.
+type RestoreResult struct {
+	Restored int
+
+	// Skipped is the number of documents where the existing version was kept, as it won conflict
+	// resolution.
+	Skipped int
+
+	Failures []RestoreFailure
+}
+
+// Restore writes the documents from a backup archive, created by Backup, into the collection.  The
+// documents are restored along with their metadata, such as CAS and expiry.  The collection does not
+// need to be the collection which was backed up.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) Restore(r io.Reader, opts *RestoreOptions) (*RestoreResult, error) {
+	if opts == nil {
+		opts = &RestoreOptions{}
+	}
+
+	options := withMetaForceAccept
+	switch opts.ConflictResolution {
+	case 0, RestoreConflictResolutionServer:
+	case RestoreConflictResolutionOverwrite:
+		options |= withMetaSkipConflictResolution
+	default:
+		return nil, makeInvalidArgumentsError("unexpected conflict resolution")
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	var header backupHeader
+	if !scanner.Scan() {
+		if scanner.Err() != nil {
+			return nil, scanner.Err()
+		}
+		return nil, makeInvalidArgumentsError("backup archive is empty")
+	}
+	err := json.Unmarshal(scanner.Bytes(), &header)
+	if err != nil || header.Version != backupArchiveVersion {
+		return nil, makeInvalidArgumentsError("backup archive is invalid")
+	}
+
+	var skipped uint32
+	var readErr error
+	line := 1
+
+	opsCh := make(chan BulkOp)
+	go func() {
+		defer close(opsCh)
+
+		for scanner.Scan() {
+			line++
+
+			op := &restoreOp{
+				options: options,
+				skipped: &skipped,
+			}
+			err := json.Unmarshal(scanner.Bytes(), &op.record)
+			if err != nil {
+				readErr = wrapError(err, fmt.Sprintf("failed to read line %d of backup archive", line))
+				return
+			}
+
+			opsCh <- op
+		}
+
+		readErr = scanner.Err()
+	}()
+
+	res, err := c.BulkExecutor(&BulkExecutorOptions{
+		MaxInFlight:   opts.MaxInFlight,
+		Timeout:       opts.Timeout,
+		OnProgress:    opts.OnProgress,
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	}).ExecuteStream(opsCh)
+	if err != nil {
+		for range opsCh {
+		}
+		return nil, err
+	}
+
+	result := &RestoreResult{
+		Restored: res.Succeeded - int(skipped),
+		Skipped:  int(skipped),
+	}
+	for _, failure := range res.Failures {
+		result.Failures = append(result.Failures, RestoreFailure{
+			ID:  failure.Op.(*restoreOp).record.Key,
+			Err: failure.Err,
+		})
+	}
+
+	if readErr != nil {
+		return result, readErr
+	}
+
+	return result, nil
+}
+
+// restoreOp writes a single document from a backup archive, along with its metadata.
+type restoreOp struct {
+	bulkOp
+
+	record  backupRecord
+	options uint32
+	skipped *uint32
+	Err     error
+}
+
+func (item *restoreOp) markError(err error) {
+	item.Err = err
+}
+
+func (item *restoreOp) err() error {
+	return item.Err
+}
+
+func (item *restoreOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder,
+	signal chan BulkOp, retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
+	span := startSpanFunc("RestoreOp", tracectx)
+	item.bulkOp.span = span
+
+	handler := func(err error) {
+		// Losing conflict resolution is reported as the document existing, in which case we have
+		// kept the existing version rather than failed.
+		if errors.Is(err, ErrDocumentExists) {
+			atomic.AddUint32(item.skipped, 1)
+			err = nil
+		}
+
+		item.Err = maybeEnhanceCollKVErr(err, provider, c, item.record.Key)
+		signal <- item
+	}
+
+	var op gocbcore.PendingOp
+	var err error
+	if item.record.Deleted {
+		op, err = provider.DeleteMetaEx(gocbcore.DeleteMetaOptions{
+			Key:            []byte(item.record.Key),
+			Value:          item.record.Value,
+			Datatype:       item.record.Datatype,
+			Options:        item.options,
+			Flags:          item.record.Flags,
+			Expiry:         item.record.Expiry,
+			Cas:            gocbcore.Cas(item.record.Cas),
+			RevNo:          item.record.RevNo,
+			CollectionName: c.name(),
+			ScopeName:      c.scopeName(),
+			RetryStrategy:  retryWrapper,
+			TraceContext:   span.Context(),
+		}, func(res *gocbcore.DeleteMetaResult, err error) {
+			handler(err)
+		})
+	} else {
+		op, err = provider.SetMetaEx(gocbcore.SetMetaOptions{
+			Key:            []byte(item.record.Key),
+			Value:          item.record.Value,
+			Datatype:       item.record.Datatype,
+			Options:        item.options,
+			Flags:          item.record.Flags,
+			Expiry:         item.record.Expiry,
+			Cas:            gocbcore.Cas(item.record.Cas),
+			RevNo:          item.record.RevNo,
+			CollectionName: c.name(),
+			ScopeName:      c.scopeName(),
+			RetryStrategy:  retryWrapper,
+			TraceContext:   span.Context(),
+		}, func(res *gocbcore.SetMetaResult, err error) {
+			handler(err)
+		})
+	}
+	if err != nil {
+		item.Err = err
+		signal <- item
+	} else {
+		item.bulkOp.pendop = op
+	}
+}
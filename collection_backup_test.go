@@ -0,0 +1,127 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func testWriteBackup(t *testing.T, includeTombstones bool) (*BackupResult, string) {
+	provider := &mockDcpProvider{numVbuckets: 1}
+	feed, err := newChangeFeed(provider, NewJSONTranscoder(), "", "", systemClock{}, time.Now().Add(time.Second), nil,
+		gocbcore.DcpStreamAddFlagLatest)
+	if err != nil {
+		t.Fatalf("Failed to open change feed: %v", err)
+	}
+	defer feed.Close()
+
+	observer := provider.lastOpen(0).observer
+	observer.Mutation(1, 3, 2<<24, 0, 0, 99, 0x05, 0, 0, 0, []byte("doc-1"), []byte("xattrs-and-body"))
+	observer.Deletion(2, 4, 100, 0, 0, 0, 0, []byte("doc-2"), nil)
+	observer.End(0, 0, nil)
+
+	var out bytes.Buffer
+	res, err := writeBackup(feed, &out, &backupHeader{
+		Version: backupArchiveVersion,
+		Bucket:  "mock",
+	}, includeTombstones)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	return res, out.String()
+}
+
+func TestWriteBackup(t *testing.T) {
+	res, archive := testWriteBackup(t, true)
+	if res.Documents != 1 || res.Tombstones != 1 {
+		t.Fatalf("Unexpected result %v", res)
+	}
+
+	lines := strings.Split(strings.TrimSpace(archive), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 records but was %q", archive)
+	}
+
+	expected := `{"key":"doc-1","value":"eGF0dHJzLWFuZC1ib2R5","flags":33554432,"cas":99,"revno":3,"datatype":5}`
+	if lines[1] != expected {
+		t.Fatalf("Unexpected record %s", lines[1])
+	}
+
+	if !strings.Contains(lines[2], `"deleted":true`) {
+		t.Fatalf("Expected tombstone record but was %s", lines[2])
+	}
+
+	res, archive = testWriteBackup(t, false)
+	if res.Documents != 1 || res.Tombstones != 0 || strings.Count(archive, "\n") != 2 {
+		t.Fatalf("Expected tombstones to be excluded but was %v", res)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	_, archive := testWriteBackup(t, true)
+
+	provider := &mockKvProvider{cas: 10}
+	col := testGetCollection(t, provider)
+
+	res, err := col.Restore(strings.NewReader(archive), nil)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if res.Restored != 2 || res.Skipped != 0 || len(res.Failures) != 0 {
+		t.Fatalf("Unexpected result %v", res)
+	}
+}
+
+func TestRestoreConflictLost(t *testing.T) {
+	_, archive := testWriteBackup(t, false)
+
+	provider := &mockKvProvider{err: ErrDocumentExists}
+	col := testGetCollection(t, provider)
+
+	res, err := col.Restore(strings.NewReader(archive), &RestoreOptions{
+		ConflictResolution: RestoreConflictResolutionServer,
+	})
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if res.Restored != 0 || res.Skipped != 1 || len(res.Failures) != 0 {
+		t.Fatalf("Expected document to be skipped but was %v", res)
+	}
+}
+
+func TestRestoreFailures(t *testing.T) {
+	_, archive := testWriteBackup(t, false)
+
+	provider := &mockKvProvider{err: ErrTemporaryFailure}
+	col := testGetCollection(t, provider)
+
+	res, err := col.Restore(strings.NewReader(archive), nil)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if len(res.Failures) != 1 || res.Failures[0].ID != "doc-1" || !errors.Is(res.Failures[0].Err, ErrTemporaryFailure) {
+		t.Fatalf("Expected restore of doc-1 to fail but was %v", res.Failures)
+	}
+}
+
+func TestRestoreInvalidArchive(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	_, err := col.Restore(strings.NewReader("not an archive\n"), nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	_, err = col.Restore(strings.NewReader("{\"version\":1}\n{broken\n"), nil)
+	if err == nil {
+		t.Fatalf("Expected invalid record to fail")
+	}
+}
@@ -19,6 +19,8 @@ type AppendOptions struct {
 	ReplicateTo     uint
 	Cas             Cas
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 func (c *Collection) binaryAppend(id string, val []byte, opts *AppendOptions) (mutOut *MutationResult, errOut error) {
@@ -26,18 +28,23 @@ func (c *Collection) binaryAppend(id string, val []byte, opts *AppendOptions) (m
 		opts = &AppendOptions{}
 	}
 
-	opm := c.newKvOpManager("Append", nil)
+	opm := c.newKvOpManager("Append", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
 
+	if err := c.checkValueSize(id, len(val)); err != nil {
+		return nil, err
+	}
+
 	agent, err := c.getKvProvider()
 	if err != nil {
 		return nil, err
@@ -51,7 +58,7 @@ func (c *Collection) binaryAppend(id string, val []byte, opts *AppendOptions) (m
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		Cas:                    gocbcore.Cas(opts.Cas),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.AdjoinResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -61,6 +68,7 @@ func (c *Collection) binaryAppend(id string, val []byte, opts *AppendOptions) (m
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
@@ -84,6 +92,8 @@ type PrependOptions struct {
 	ReplicateTo     uint
 	Cas             Cas
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 func (c *Collection) binaryPrepend(id string, val []byte, opts *PrependOptions) (mutOut *MutationResult, errOut error) {
@@ -91,18 +101,23 @@ func (c *Collection) binaryPrepend(id string, val []byte, opts *PrependOptions)
 		opts = &PrependOptions{}
 	}
 
-	opm := c.newKvOpManager("Prepend", nil)
+	opm := c.newKvOpManager("Prepend", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
 
+	if err := c.checkValueSize(id, len(val)); err != nil {
+		return nil, err
+	}
+
 	agent, err := c.getKvProvider()
 	if err != nil {
 		return nil, err
@@ -116,7 +131,7 @@ func (c *Collection) binaryPrepend(id string, val []byte, opts *PrependOptions)
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		Cas:                    gocbcore.Cas(opts.Cas),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.AdjoinResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -126,6 +141,7 @@ func (c *Collection) binaryPrepend(id string, val []byte, opts *PrependOptions)
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
@@ -141,22 +157,39 @@ func (c *BinaryCollection) Prepend(id string, val []byte, opts *PrependOptions)
 	return c.collection.binaryPrepend(id, val, opts)
 }
 
+// CounterFailIfAbsent can be used as the Initial value of an Increment or Decrement operation
+// to prevent the document from being created if it does not already exist.
+const CounterFailIfAbsent = int64(-1)
+
+// counterInitial converts an Initial option to the form expected by the server, where an
+// initial value of all ones indicates that the document should not be created.
+func counterInitial(initial int64) uint64 {
+	if initial < 0 {
+		return 0xFFFFFFFFFFFFFFFF
+	}
+
+	return uint64(initial)
+}
+
 // IncrementOptions are the options available to the Increment operation.
 type IncrementOptions struct {
 	Timeout time.Duration
-	// Expiry is the length of time that the document will be stored in Couchbase.
-	// A value of 0 will set the document to never expire.
+	// Expiry is the length of time that the document will be stored in Couchbase if it is
+	// created by this operation.  A value of 0 will set the document to never expire.
 	Expiry time.Duration
 	// Initial, if non-negative, is the `initial` value to use for the document if it does not exist.
-	// If present, this is the value that will be returned by a successful operation.
+	// If present, this is the value that will be returned by a successful operation.  Setting this
+	// to CounterFailIfAbsent causes the operation to fail with ErrDocumentNotFound instead.
 	Initial int64
-	// Delta is the value to use for incrementing/decrementing if Initial is not present.
+	// Delta is the amount to adjust the value of the document by if it already exists.
 	Delta           uint64
 	DurabilityLevel DurabilityLevel
 	PersistTo       uint
 	ReplicateTo     uint
 	Cas             Cas
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 func (c *Collection) binaryIncrement(id string, opts *IncrementOptions) (countOut *CounterResult, errOut error) {
@@ -164,19 +197,15 @@ func (c *Collection) binaryIncrement(id string, opts *IncrementOptions) (countOu
 		opts = &IncrementOptions{}
 	}
 
-	opm := c.newKvOpManager("Increment", nil)
+	opm := c.newKvOpManager("Increment", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
-	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
-	if opts.Initial >= 0 {
-		realInitial = uint64(opts.Initial)
-	}
-
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
@@ -188,7 +217,7 @@ func (c *Collection) binaryIncrement(id string, opts *IncrementOptions) (countOu
 	err = opm.Wait(agent.IncrementEx(gocbcore.CounterOptions{
 		Key:                    opm.DocumentID(),
 		Delta:                  opts.Delta,
-		Initial:                realInitial,
+		Initial:                counterInitial(opts.Initial),
 		Expiry:                 durationToExpiry(opts.Expiry),
 		CollectionName:         opm.CollectionName(),
 		ScopeName:              opm.ScopeName(),
@@ -196,7 +225,7 @@ func (c *Collection) binaryIncrement(id string, opts *IncrementOptions) (countOu
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		Cas:                    gocbcore.Cas(opts.Cas),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.CounterResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -206,6 +235,7 @@ func (c *Collection) binaryIncrement(id string, opts *IncrementOptions) (countOu
 
 		countOut = &CounterResult{}
 		countOut.cas = Cas(res.Cas)
+		countOut.serverDuration = opm.ServerDuration()
 		countOut.mt = opm.EnhanceMt(res.MutationToken)
 		countOut.content = res.Value
 
@@ -227,19 +257,22 @@ func (c *BinaryCollection) Increment(id string, opts *IncrementOptions) (countOu
 // DecrementOptions are the options available to the Decrement operation.
 type DecrementOptions struct {
 	Timeout time.Duration
-	// Expiry is the length of time that the document will be stored in Couchbase.
-	// A value of 0 will set the document to never expire.
+	// Expiry is the length of time that the document will be stored in Couchbase if it is
+	// created by this operation.  A value of 0 will set the document to never expire.
 	Expiry time.Duration
 	// Initial, if non-negative, is the `initial` value to use for the document if it does not exist.
-	// If present, this is the value that will be returned by a successful operation.
+	// If present, this is the value that will be returned by a successful operation.  Setting this
+	// to CounterFailIfAbsent causes the operation to fail with ErrDocumentNotFound instead.
 	Initial int64
-	// Delta is the value to use for incrementing/decrementing if Initial is not present.
+	// Delta is the amount to adjust the value of the document by if it already exists.
 	Delta           uint64
 	DurabilityLevel DurabilityLevel
 	PersistTo       uint
 	ReplicateTo     uint
 	Cas             Cas
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 func (c *Collection) binaryDecrement(id string, opts *DecrementOptions) (countOut *CounterResult, errOut error) {
@@ -247,19 +280,15 @@ func (c *Collection) binaryDecrement(id string, opts *DecrementOptions) (countOu
 		opts = &DecrementOptions{}
 	}
 
-	opm := c.newKvOpManager("Decrement", nil)
+	opm := c.newKvOpManager("Decrement", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
-	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
-	if opts.Initial >= 0 {
-		realInitial = uint64(opts.Initial)
-	}
-
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
@@ -271,7 +300,7 @@ func (c *Collection) binaryDecrement(id string, opts *DecrementOptions) (countOu
 	err = opm.Wait(agent.DecrementEx(gocbcore.CounterOptions{
 		Key:                    opm.DocumentID(),
 		Delta:                  opts.Delta,
-		Initial:                realInitial,
+		Initial:                counterInitial(opts.Initial),
 		Expiry:                 durationToExpiry(opts.Expiry),
 		CollectionName:         opm.CollectionName(),
 		ScopeName:              opm.ScopeName(),
@@ -279,7 +308,7 @@ func (c *Collection) binaryDecrement(id string, opts *DecrementOptions) (countOu
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		Cas:                    gocbcore.Cas(opts.Cas),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.CounterResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -289,6 +318,7 @@ func (c *Collection) binaryDecrement(id string, opts *DecrementOptions) (countOu
 
 		countOut = &CounterResult{}
 		countOut.cas = Cas(res.Cas)
+		countOut.serverDuration = opm.ServerDuration()
 		countOut.mt = opm.EnhanceMt(res.MutationToken)
 		countOut.content = res.Value
 
@@ -1,6 +1,9 @@
 package gocb
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestBinaryAppend(t *testing.T) {
 	if !globalCluster.SupportsFeature(AdjoinFeature) {
@@ -220,3 +223,53 @@ func TestBinaryDecrement(t *testing.T) {
 		t.Fatalf("Expected counter value to be 80 but was %d", res.Content())
 	}
 }
+
+func TestBinaryIncrementInitial(t *testing.T) {
+	colBinary := globalCollection.Binary()
+
+	_, err := colBinary.Increment("binaryIncrementInitial", &IncrementOptions{
+		Delta:   10,
+		Initial: CounterFailIfAbsent,
+	})
+	if !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("Expected Increment to fail with document not found but was %v", err)
+	}
+
+	res, err := colBinary.Increment("binaryIncrementInitial", &IncrementOptions{
+		Delta:   10,
+		Initial: 5,
+	})
+	if err != nil {
+		t.Fatalf("Failed to Increment, err: %v", err)
+	}
+
+	if res.Content() != 5 {
+		t.Fatalf("Expected counter value to be 5 but was %d", res.Content())
+	}
+
+	res, err = colBinary.Increment("binaryIncrementInitial", &IncrementOptions{
+		Delta:   10,
+		Initial: CounterFailIfAbsent,
+	})
+	if err != nil {
+		t.Fatalf("Failed to Increment, err: %v", err)
+	}
+
+	if res.Content() != 15 {
+		t.Fatalf("Expected counter value to be 15 but was %d", res.Content())
+	}
+}
+
+func TestCounterInitial(t *testing.T) {
+	if counterInitial(CounterFailIfAbsent) != 0xFFFFFFFFFFFFFFFF {
+		t.Fatalf("Expected CounterFailIfAbsent to disable document creation")
+	}
+
+	if counterInitial(0) != 0 {
+		t.Fatalf("Expected an initial value of 0 to be retained")
+	}
+
+	if counterInitial(42) != 42 {
+		t.Fatalf("Expected an initial value of 42 to be retained")
+	}
+}
@@ -1,6 +1,7 @@
 package gocb
 
 import (
+	"errors"
 	"time"
 
 	"github.com/couchbase/gocbcore/v8"
@@ -8,10 +9,14 @@ import (
 
 type bulkOp struct {
 	pendop gocbcore.PendingOp
-	span   requestSpan
+	span   RequestSpan
 }
 
 func (op *bulkOp) cancel(err error) {
+	if op.pendop == nil {
+		return
+	}
+
 	op.pendop.Cancel(err)
 }
 
@@ -24,9 +29,10 @@ func (op *bulkOp) finish() {
 // such as GetOp, UpsertOp, ReplaceOp, and more.
 // UNCOMMITTED: This API may change in the future.
 type BulkOp interface {
-	execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-		retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan)
+	execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+		retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan)
 	markError(err error)
+	err() error
 	cancel(err error)
 	finish()
 }
@@ -39,6 +45,8 @@ type BulkOpOptions struct {
 	// operations that fetch values. It does not apply to all BulkOp operations.
 	Transcoder    Transcoder
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // Do execute one or more `BulkOp` items in parallel.
@@ -48,17 +56,14 @@ func (c *Collection) Do(ops []BulkOp, opts *BulkOpOptions) error {
 		opts = &BulkOpOptions{}
 	}
 
-	span := c.startKvOpTrace("Do", nil)
+	span := applyOperationTags(c.startKvOpTrace("Do", opts.ParentSpan), opts.Tags)
 
-	timeout := c.sb.KvTimeout * time.Duration(len(ops))
+	timeout := c.sb.kvTimeout() * time.Duration(len(ops))
 	if opts.Timeout != 0 {
 		timeout = opts.Timeout
 	}
 
-	retryWrapper := c.sb.RetryStrategyWrapper
-	if opts.RetryStrategy != nil {
-		retryWrapper = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	retryWrapper := c.sb.RetryStrategyWrapper.withStrategy(opts.RetryStrategy)
 
 	if opts.Transcoder == nil {
 		opts.Transcoder = c.sb.Transcoder
@@ -69,6 +74,14 @@ func (c *Collection) Do(ops []BulkOp, opts *BulkOpOptions) error {
 		return err
 	}
 
+	clk := clockOrSystem(c.sb.Clock)
+	deadline := clk.Now().Add(timeout)
+
+	// Each op counts towards MaxInFlightKV, waiting for a slot for no longer than the batch.
+	agent = newLimitedKvProvider(agent, c.sb.KvLimiter, func() time.Time {
+		return deadline
+	})
+
 	// Make the channel big enough to hold all our ops in case
 	//   we get delayed inside execute (don't want to block the
 	//   individual op handlers when they dispatch their signal).
@@ -77,14 +90,13 @@ func (c *Collection) Do(ops []BulkOp, opts *BulkOpOptions) error {
 		item.execute(span.Context(), c, agent, opts.Transcoder, signal, retryWrapper, c.startKvOpTrace)
 	}
 
-	deadline := time.Now().Add(timeout)
 	for range ops {
 		select {
 		case item := <-signal:
 			// We're really just clearing the pendop from this thread,
 			//   since it already completed, no cancel actually occurs
 			item.finish()
-		case <-time.After(deadline.Sub(time.Now())):
+		case <-clk.After(deadline.Sub(clk.Now())):
 			// cancel everything
 			for _, item := range ops {
 				item.cancel(ErrAmbiguousTimeout)
@@ -112,11 +124,21 @@ func (item *GetOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *GetOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *GetOp) err() error {
+	return item.Err
+}
+
+func (item *GetOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("GetOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.GetEx(gocbcore.GetOptions{
 		Key:            []byte(item.ID),
 		CollectionName: c.name(),
@@ -133,6 +155,7 @@ func (item *GetOp) execute(tracectx requestSpanContext, c *Collection, provider
 				transcoder: transcoder,
 				contents:   res.Value,
 				flags:      res.Flags,
+				datatype:   DocumentDatatype(res.Datatype),
 			}
 		}
 		signal <- item
@@ -160,11 +183,21 @@ func (item *GetAndTouchOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *GetAndTouchOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *GetAndTouchOp) err() error {
+	return item.Err
+}
+
+func (item *GetAndTouchOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("GetAndTouchOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.GetAndTouchEx(gocbcore.GetAndTouchOptions{
 		Key:            []byte(item.ID),
 		Expiry:         durationToExpiry(item.Expiry),
@@ -182,6 +215,7 @@ func (item *GetAndTouchOp) execute(tracectx requestSpanContext, c *Collection, p
 				transcoder: transcoder,
 				contents:   res.Value,
 				flags:      res.Flags,
+				datatype:   DocumentDatatype(res.Datatype),
 			}
 		}
 		signal <- item
@@ -209,11 +243,21 @@ func (item *TouchOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *TouchOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *TouchOp) err() error {
+	return item.Err
+}
+
+func (item *TouchOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("TouchOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.TouchEx(gocbcore.TouchOptions{
 		Key:            []byte(item.ID),
 		Expiry:         durationToExpiry(item.Expiry),
@@ -248,6 +292,69 @@ func (item *TouchOp) execute(tracectx requestSpanContext, c *Collection, provide
 	}
 }
 
+// ExistsOp represents a type of `BulkOp` used for Exists operations. See BulkOp.
+// UNCOMMITTED: This API may change in the future.
+type ExistsOp struct {
+	bulkOp
+
+	ID     string
+	Result *ExistsResult
+	Err    error
+}
+
+func (item *ExistsOp) markError(err error) {
+	item.Err = err
+}
+
+func (item *ExistsOp) err() error {
+	return item.Err
+}
+
+func (item *ExistsOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
+	span := startSpanFunc("ExistsOp", tracectx)
+	item.bulkOp.span = span
+
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
+	op, err := provider.GetMetaEx(gocbcore.GetMetaOptions{
+		Key:            []byte(item.ID),
+		CollectionName: c.name(),
+		ScopeName:      c.scopeName(),
+		RetryStrategy:  retryWrapper,
+		TraceContext:   span.Context(),
+	}, func(res *gocbcore.GetMetaResult, err error) {
+		if errors.Is(err, ErrDocumentNotFound) {
+			item.Result = &ExistsResult{
+				docExists: false,
+			}
+			signal <- item
+			return
+		}
+
+		item.Err = maybeEnhanceCollKVErr(err, provider, c, item.ID)
+		if item.Err == nil {
+			item.Result = &ExistsResult{
+				Result: Result{
+					cas: Cas(res.Cas),
+				},
+				docExists: true,
+			}
+		}
+		signal <- item
+	})
+	if err != nil {
+		item.Err = err
+		signal <- item
+	} else {
+		item.bulkOp.pendop = op
+	}
+}
+
 // RemoveOp represents a type of `BulkOp` used for Remove operations. See BulkOp.
 // UNCOMMITTED: This API may change in the future.
 type RemoveOp struct {
@@ -263,11 +370,21 @@ func (item *RemoveOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *RemoveOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *RemoveOp) err() error {
+	return item.Err
+}
+
+func (item *RemoveOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("RemoveOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.DeleteEx(gocbcore.DeleteOptions{
 		Key:            []byte(item.ID),
 		Cas:            gocbcore.Cas(item.Cas),
@@ -319,14 +436,27 @@ func (item *UpsertOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *UpsertOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder,
-	signal chan BulkOp, retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *UpsertOp) err() error {
+	return item.Err
+}
+
+func (item *UpsertOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder,
+	signal chan BulkOp, retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("UpsertOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	etrace := c.startKvOpTrace("encode", span.Context())
 	bytes, flags, err := transcoder.Encode(item.Value)
 	etrace.Finish()
+	if err == nil {
+		err = c.checkValueSize(item.ID, len(bytes))
+	}
 	if err != nil {
 		item.Err = err
 		signal <- item
@@ -386,11 +516,21 @@ func (item *InsertOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *InsertOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *InsertOp) err() error {
+	return item.Err
+}
+
+func (item *InsertOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("InsertOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	etrace := c.startKvOpTrace("encode", span.Context())
 	bytes, flags, err := transcoder.Encode(item.Value)
 	if err != nil {
@@ -401,6 +541,13 @@ func (item *InsertOp) execute(tracectx requestSpanContext, c *Collection, provid
 	}
 	etrace.Finish()
 
+	err = c.checkValueSize(item.ID, len(bytes))
+	if err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.AddEx(gocbcore.AddOptions{
 		Key:            []byte(item.ID),
 		Value:          bytes,
@@ -454,11 +601,21 @@ func (item *ReplaceOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *ReplaceOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *ReplaceOp) err() error {
+	return item.Err
+}
+
+func (item *ReplaceOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("ReplaceOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	etrace := c.startKvOpTrace("encode", span.Context())
 	bytes, flags, err := transcoder.Encode(item.Value)
 	if err != nil {
@@ -469,6 +626,13 @@ func (item *ReplaceOp) execute(tracectx requestSpanContext, c *Collection, provi
 	}
 	etrace.Finish()
 
+	err = c.checkValueSize(item.ID, len(bytes))
+	if err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.ReplaceEx(gocbcore.ReplaceOptions{
 		Key:            []byte(item.ID),
 		Value:          bytes,
@@ -521,11 +685,27 @@ func (item *AppendOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *AppendOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *AppendOp) err() error {
+	return item.Err
+}
+
+func (item *AppendOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("AppendOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
+	if err := c.checkValueSize(item.ID, len(item.Value)); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.AppendEx(gocbcore.AdjoinOptions{
 		Key:            []byte(item.ID),
 		Value:          []byte(item.Value),
@@ -575,11 +755,27 @@ func (item *PrependOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *PrependOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *PrependOp) err() error {
+	return item.Err
+}
+
+func (item *PrependOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("PrependOp", tracectx)
 	item.bulkOp.span = span
 
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
+	if err := c.checkValueSize(item.ID, len(item.Value)); err != nil {
+		item.Err = err
+		signal <- item
+		return
+	}
+
 	op, err := provider.PrependEx(gocbcore.AdjoinOptions{
 		Key:            []byte(item.ID),
 		Value:          []byte(item.Value),
@@ -619,8 +815,10 @@ func (item *PrependOp) execute(tracectx requestSpanContext, c *Collection, provi
 type IncrementOp struct {
 	bulkOp
 
-	ID      string
-	Delta   int64
+	ID    string
+	Delta int64
+	// Initial is the value to create the document with if it does not exist.  Setting this to
+	// CounterFailIfAbsent causes the operation to fail with ErrDocumentNotFound instead.
 	Initial int64
 	Expiry  time.Duration
 
@@ -632,20 +830,25 @@ func (item *IncrementOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *IncrementOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *IncrementOp) err() error {
+	return item.Err
+}
+
+func (item *IncrementOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("IncrementOp", tracectx)
 	item.bulkOp.span = span
 
-	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
-	if item.Initial > 0 {
-		realInitial = uint64(item.Initial)
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
 	}
 
 	op, err := provider.IncrementEx(gocbcore.CounterOptions{
 		Key:            []byte(item.ID),
 		Delta:          uint64(item.Delta),
-		Initial:        realInitial,
+		Initial:        counterInitial(item.Initial),
 		Expiry:         durationToExpiry(item.Expiry),
 		CollectionName: c.name(),
 		ScopeName:      c.scopeName(),
@@ -686,8 +889,10 @@ func (item *IncrementOp) execute(tracectx requestSpanContext, c *Collection, pro
 type DecrementOp struct {
 	bulkOp
 
-	ID      string
-	Delta   int64
+	ID    string
+	Delta int64
+	// Initial is the value to create the document with if it does not exist.  Setting this to
+	// CounterFailIfAbsent causes the operation to fail with ErrDocumentNotFound instead.
 	Initial int64
 	Expiry  time.Duration
 
@@ -699,20 +904,25 @@ func (item *DecrementOp) markError(err error) {
 	item.Err = err
 }
 
-func (item *DecrementOp) execute(tracectx requestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
-	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, requestSpanContext) requestSpan) {
+func (item *DecrementOp) err() error {
+	return item.Err
+}
+
+func (item *DecrementOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, startSpanFunc func(string, RequestSpanContext) RequestSpan) {
 	span := startSpanFunc("DecrementOp", tracectx)
 	item.bulkOp.span = span
 
-	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
-	if item.Initial > 0 {
-		realInitial = uint64(item.Initial)
+	if err := validateDocumentID(item.ID); err != nil {
+		item.Err = err
+		signal <- item
+		return
 	}
 
 	op, err := provider.DecrementEx(gocbcore.CounterOptions{
 		Key:            []byte(item.ID),
 		Delta:          uint64(item.Delta),
-		Initial:        realInitial,
+		Initial:        counterInitial(item.Initial),
 		Expiry:         durationToExpiry(item.Expiry),
 		CollectionName: c.name(),
 		ScopeName:      c.scopeName(),
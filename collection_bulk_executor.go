@@ -0,0 +1,189 @@
+package gocb
+
+import (
+	"time"
+)
+
+// BulkProgress describes how many of the operations submitted to a BulkExecutor have completed.
+type BulkProgress struct {
+	Succeeded int
+	Failed    int
+}
+
+// BulkFailure describes an operation executed by a BulkExecutor which failed.
+type BulkFailure struct {
+	Op  BulkOp
+	Err error
+}
+
+// BulkExecutorResult is the outcome of executing a set of operations with a BulkExecutor.
+type BulkExecutorResult struct {
+	Succeeded int
+	Failures  []BulkFailure
+}
+
+// BulkExecutorOptions are the set of options available when creating a BulkExecutor.
+type BulkExecutorOptions struct {
+	// MaxInFlight is the maximum number of operations which will be outstanding at once.
+	// The default is 128.
+	MaxInFlight int
+
+	// Timeout is the length of time that each operation may take, rather than the operations
+	// as a whole.  The default is the KV timeout.
+	Timeout time.Duration
+
+	// OnProgress, if set, is called each time that an operation completes.  It is never called
+	// concurrently, and operations are not dispatched whilst it is running.
+	OnProgress func(progress BulkProgress)
+
+	// Transcoder is used to encode values for operations that perform mutations and to decode values for
+	// operations that fetch values. It does not apply to all BulkOp operations.
+	Transcoder    Transcoder
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// BulkExecutor executes large numbers of BulkOp operations, keeping a bounded number of them
+// in flight at once and collecting any which fail.  Unlike Do, the operations do not need to be
+// known up front, and a failure of one operation does not affect any other.
+// UNCOMMITTED: This API may change in the future.
+type BulkExecutor struct {
+	collection *Collection
+	opts       BulkExecutorOptions
+
+	// onComplete, if set, is called with each operation once it has completed.
+	onComplete func(op BulkOp)
+}
+
+// BulkExecutor returns a new BulkExecutor for executing operations against this collection.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) BulkExecutor(opts *BulkExecutorOptions) *BulkExecutor {
+	if opts == nil {
+		opts = &BulkExecutorOptions{}
+	}
+
+	executor := &BulkExecutor{
+		collection: c,
+		opts:       *opts,
+	}
+	if executor.opts.MaxInFlight <= 0 {
+		executor.opts.MaxInFlight = 128
+	}
+	if executor.opts.Timeout == 0 {
+		executor.opts.Timeout = c.sb.kvTimeout()
+	}
+	if executor.opts.Transcoder == nil {
+		executor.opts.Transcoder = c.sb.Transcoder
+	}
+
+	return executor
+}
+
+// Execute executes each of ops, returning once they have all completed.
+func (e *BulkExecutor) Execute(ops []BulkOp) (*BulkExecutorResult, error) {
+	opsCh := make(chan BulkOp, len(ops))
+	for _, op := range ops {
+		opsCh <- op
+	}
+	close(opsCh)
+
+	return e.ExecuteStream(opsCh)
+}
+
+// ExecuteStream executes each operation received from ops, returning once ops has been closed
+// and every operation received from it has completed.
+func (e *BulkExecutor) ExecuteStream(ops <-chan BulkOp) (*BulkExecutorResult, error) {
+	c := e.collection
+
+	span := applyOperationTags(c.startKvOpTrace("BulkExecute", nil), e.opts.Tags)
+	defer span.Finish()
+
+	retryWrapper := c.sb.RetryStrategyWrapper.withStrategy(e.opts.RetryStrategy)
+
+	agent, err := c.getKvProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkExecutorResult{}
+
+	// The signal channel is big enough to hold every operation which can be in flight so
+	// that the individual op handlers never block when they dispatch their signal.
+	signal := make(chan BulkOp, e.opts.MaxInFlight)
+	timers := make(map[BulkOp]func())
+	clk := clockOrSystem(c.sb.Clock)
+
+	// Each op also counts towards MaxInFlightKV, waiting for a slot for no longer than its
+	// own timeout.
+	agent = newLimitedKvProvider(agent, c.sb.KvLimiter, func() time.Time {
+		return clk.Now().Add(e.opts.Timeout)
+	})
+
+	record := func(item BulkOp, err error) {
+		if err != nil {
+			result.Failures = append(result.Failures, BulkFailure{
+				Op:  item,
+				Err: err,
+			})
+		} else {
+			result.Succeeded++
+		}
+
+		if e.onComplete != nil {
+			e.onComplete(item)
+		}
+
+		if e.opts.OnProgress != nil {
+			e.opts.OnProgress(BulkProgress{
+				Succeeded: result.Succeeded,
+				Failed:    len(result.Failures),
+			})
+		}
+	}
+
+	complete := func(item BulkOp) {
+		timers[item]()
+		delete(timers, item)
+		item.finish()
+		record(item, item.err())
+	}
+
+	for op := range ops {
+		item := op
+		// Each operation holds its own result, so it cannot be executed again until it has
+		// completed.
+		if _, ok := timers[item]; ok {
+			record(item, makeInvalidArgumentsError("operation is already being executed"))
+			continue
+		}
+
+		if len(timers) >= e.opts.MaxInFlight {
+			complete(<-signal)
+		}
+
+		timeoutErr := ErrAmbiguousTimeout
+		if isBulkReadOp(item) {
+			timeoutErr = ErrUnambiguousTimeout
+		}
+
+		item.execute(span.Context(), c, agent, e.opts.Transcoder, signal, retryWrapper, c.startKvOpTrace)
+		timers[item] = afterFunc(clk, e.opts.Timeout, func() {
+			item.cancel(timeoutErr)
+		})
+	}
+
+	for len(timers) > 0 {
+		complete(<-signal)
+	}
+
+	return result, nil
+}
+
+// isBulkReadOp returns whether an operation only reads, so that it has no effect if it times out.
+func isBulkReadOp(op BulkOp) bool {
+	switch op.(type) {
+	case *GetOp, *ExistsOp:
+		return true
+	}
+	return false
+}
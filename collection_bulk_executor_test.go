@@ -0,0 +1,168 @@
+package gocb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBulkExecutorExecute(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"name":"mike"}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	var ops []BulkOp
+	for i := 0; i < 20; i++ {
+		ops = append(ops, &GetOp{ID: fmt.Sprintf("bulk-%d", i)})
+	}
+
+	var progress []BulkProgress
+	res, err := col.BulkExecutor(&BulkExecutorOptions{
+		MaxInFlight: 4,
+		OnProgress: func(p BulkProgress) {
+			progress = append(progress, p)
+		},
+	}).Execute(ops)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if res.Succeeded != 20 || len(res.Failures) != 0 {
+		t.Fatalf("Expected 20 operations to succeed but was %d with %d failures", res.Succeeded, len(res.Failures))
+	}
+
+	if len(progress) != 20 || progress[19].Succeeded != 20 {
+		t.Fatalf("Expected progress to be reported for each operation but was %v", progress)
+	}
+
+	for _, op := range ops {
+		getOp := op.(*GetOp)
+		if getOp.Err != nil || getOp.Result == nil || getOp.Result.Cas() != 10 {
+			t.Fatalf("Expected %s to have a result but was %v", getOp.ID, getOp.Err)
+		}
+	}
+}
+
+func TestBulkExecutorBoundsInFlight(t *testing.T) {
+	provider := &mockKvProvider{
+		opWait: 20 * time.Millisecond,
+		cas:    10,
+		value:  []byte(`{"name":"mike"}`),
+		flags:  2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	opsCh := make(chan BulkOp)
+	go func() {
+		for i := 0; i < 10; i++ {
+			opsCh <- &GetOp{ID: fmt.Sprintf("bulk-%d", i)}
+		}
+		close(opsCh)
+	}()
+
+	start := time.Now()
+	res, err := col.BulkExecutor(&BulkExecutorOptions{
+		MaxInFlight: 2,
+	}).ExecuteStream(opsCh)
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+
+	if res.Succeeded != 10 {
+		t.Fatalf("Expected 10 operations to succeed but was %d", res.Succeeded)
+	}
+
+	if time.Since(start) < 100*time.Millisecond {
+		t.Fatalf("Expected no more than 2 operations to be in flight at once")
+	}
+}
+
+func TestBulkExecutorFailures(t *testing.T) {
+	provider := &mockKvProvider{
+		err: ErrDocumentNotFound,
+	}
+	col := testGetCollection(t, provider)
+
+	ops := []BulkOp{&GetOp{ID: "missing-1"}, &GetOp{ID: "missing-2"}}
+	res, err := col.BulkExecutor(nil).Execute(ops)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if res.Succeeded != 0 || len(res.Failures) != 2 {
+		t.Fatalf("Expected 2 operations to fail but was %d with %d failures", res.Succeeded, len(res.Failures))
+	}
+
+	for _, failure := range res.Failures {
+		if !errors.Is(failure.Err, ErrDocumentNotFound) {
+			t.Fatalf("Expected document not found error but was %v", failure.Err)
+		}
+	}
+}
+
+func TestBulkExecutorTimeout(t *testing.T) {
+	provider := &mockKvProvider{
+		opWait: 500 * time.Millisecond,
+		cas:    10,
+		value:  []byte(`{"name":"mike"}`),
+		flags:  2 << 24,
+	}
+	col := testGetCollection(t, provider)
+	clk := newFakeClock()
+	col.sb.Clock = clk
+
+	getOp := &GetOp{ID: "slow"}
+	upsertOp := &UpsertOp{ID: "slow", Value: "value"}
+
+	resCh := make(chan *BulkExecutorResult, 1)
+	go func() {
+		res, err := col.BulkExecutor(&BulkExecutorOptions{
+			Timeout: 50 * time.Millisecond,
+		}).Execute([]BulkOp{getOp, upsertOp})
+		if err != nil {
+			t.Errorf("Execute failed: %v", err)
+		}
+		resCh <- res
+	}()
+
+	clk.waitForWaiters(2)
+	clk.Advance(50 * time.Millisecond)
+	res := <-resCh
+
+	if len(res.Failures) != 2 {
+		t.Fatalf("Expected both operations to time out but was %v", res.Failures)
+	}
+	if !errors.Is(getOp.Err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected get to time out unambiguously but was %v", getOp.Err)
+	}
+	if !errors.Is(upsertOp.Err, ErrAmbiguousTimeout) {
+		t.Fatalf("Expected upsert to time out ambiguously but was %v", upsertOp.Err)
+	}
+}
+
+func TestBulkExecutorDuplicateOp(t *testing.T) {
+	provider := &mockKvProvider{
+		opWait: 20 * time.Millisecond,
+		cas:    10,
+		value:  []byte(`{"name":"mike"}`),
+		flags:  2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	op := &GetOp{ID: "dup"}
+	res, err := col.BulkExecutor(nil).Execute([]BulkOp{op, op})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if res.Succeeded != 1 || len(res.Failures) != 1 || !errors.Is(res.Failures[0].Err, ErrInvalidArgument) {
+		t.Fatalf("Expected the duplicate to be rejected but was %d succeeded, failures %v", res.Succeeded, res.Failures)
+	}
+	if op.Err != nil || op.Result == nil {
+		t.Fatalf("Expected the operation to have a result but was %v", op.Err)
+	}
+}
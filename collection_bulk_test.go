@@ -1,8 +1,11 @@
 package gocb
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
 )
 
 func TestUpsertGetBulk(t *testing.T) {
@@ -72,3 +75,93 @@ func TestUpsertGetBulk(t *testing.T) {
 		}
 	}
 }
+
+func TestExistsBulk(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{cas: 42})
+	missingCol := testGetCollection(t, &mockKvProvider{err: ErrDocumentNotFound})
+	failingCol := testGetCollection(t, &mockKvProvider{err: ErrTemporaryFailure})
+
+	tests := []struct {
+		name       string
+		col        *Collection
+		expectErr  error
+		expectCas  Cas
+		expectBool bool
+	}{
+		{"exists", col, nil, 42, true},
+		{"missing", missingCol, nil, 0, false},
+		{"failing", failingCol, ErrTemporaryFailure, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := &ExistsOp{ID: "key"}
+			err := test.col.Do([]BulkOp{op}, nil)
+			if err != nil {
+				t.Fatalf("Expected Do to not error but was %v", err)
+			}
+
+			if test.expectErr != nil {
+				if !errors.Is(op.Err, test.expectErr) {
+					t.Fatalf("Expected ExistsOp Err to be %v but was %v", test.expectErr, op.Err)
+				}
+				return
+			}
+
+			if op.Err != nil {
+				t.Fatalf("Expected ExistsOp Err to be nil but was %v", op.Err)
+			}
+			if op.Result.Exists() != test.expectBool {
+				t.Fatalf("Expected Exists to be %t", test.expectBool)
+			}
+			if op.Result.Cas() != test.expectCas {
+				t.Fatalf("Expected Cas to be %d but was %d", test.expectCas, op.Result.Cas())
+			}
+		})
+	}
+}
+
+// counterKvProvider records the initial values sent by counter operations.
+type counterKvProvider struct {
+	*mockKvProvider
+
+	initials []uint64
+}
+
+func (p *counterKvProvider) IncrementEx(opts gocbcore.CounterOptions, cb gocbcore.CounterExCallback) (gocbcore.PendingOp, error) {
+	p.initials = append(p.initials, opts.Initial)
+	return p.mockKvProvider.IncrementEx(opts, cb)
+}
+
+func (p *counterKvProvider) DecrementEx(opts gocbcore.CounterOptions, cb gocbcore.CounterExCallback) (gocbcore.PendingOp, error) {
+	p.initials = append(p.initials, opts.Initial)
+	return p.mockKvProvider.DecrementEx(opts, cb)
+}
+
+func TestCounterBulkInitial(t *testing.T) {
+	provider := &counterKvProvider{mockKvProvider: &mockKvProvider{value: uint64(1)}}
+	col := testGetCollection(t, provider.mockKvProvider)
+	col.sb.getCachedClient().(*mockClient).mockKvProvider = provider
+
+	ops := []BulkOp{
+		&IncrementOp{ID: "a", Delta: 1},
+		&IncrementOp{ID: "b", Delta: 1, Initial: CounterFailIfAbsent},
+		&DecrementOp{ID: "c", Delta: 1},
+		&DecrementOp{ID: "d", Delta: 1, Initial: CounterFailIfAbsent},
+	}
+	err := col.Do(ops, nil)
+	if err != nil {
+		t.Fatalf("Expected Do to not error but was %v", err)
+	}
+
+	// Initial values must be treated as they are by Increment and Decrement.
+	expected := []uint64{0, 0xFFFFFFFFFFFFFFFF, 0, 0xFFFFFFFFFFFFFFFF}
+	if len(provider.initials) != len(expected) {
+		t.Fatalf("Expected %d counter operations but was %d", len(expected), len(provider.initials))
+	}
+	for i, initial := range provider.initials {
+		if initial != expected[i] {
+			t.Fatalf("Expected initial values %v but was %v", expected, provider.initials)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+	"github.com/google/uuid"
+)
+
+const defaultChunkSize = 1024 * 1024
+
+// chunkedManifest is stored in place of a document whose value has been split into chunks.
+type chunkedManifest struct {
+	Chunked *chunkedManifestInfo `json:"gocbChunkedDocument"`
+}
+
+type chunkedManifestInfo struct {
+	Version    int    `json:"version"`
+	Generation string `json:"generation"`
+	Chunks     int    `json:"chunks"`
+	Size       int    `json:"size"`
+	Flags      uint32 `json:"flags"`
+}
+
+func chunkID(id, generation string, idx int) string {
+	return fmt.Sprintf("%s::chunk::%s::%d", id, generation, idx)
+}
+
+func (m *chunkedManifestInfo) chunkIDs(id string) []string {
+	ids := make([]string, m.Chunks)
+	for i := range ids {
+		ids[i] = chunkID(id, m.Generation, i)
+	}
+	return ids
+}
+
+// remainingTimeout returns the time left before the deadline of an operation which sends several
+// requests, or zero if the operation has no deadline so that the default timeout is used.
+func remainingTimeout(clk clock, deadline time.Time) (time.Duration, error) {
+	if deadline.IsZero() {
+		return 0, nil
+	}
+
+	remaining := deadline.Sub(clk.Now())
+	if remaining <= 0 {
+		return 0, ErrUnambiguousTimeout
+	}
+	return remaining, nil
+}
+
+// parseChunkedManifest returns the manifest stored in a document, or nil if the document
+// is not a chunked document.
+func parseChunkedManifest(doc *GetResult) *chunkedManifestInfo {
+	valueType, _ := gocbcore.DecodeCommonFlags(doc.flags)
+	if valueType != gocbcore.JSONType {
+		return nil
+	}
+
+	var manifest chunkedManifest
+	if err := json.Unmarshal(doc.contents, &manifest); err != nil {
+		return nil
+	}
+
+	return manifest.Chunked
+}
+
+// UpsertChunkedOptions are the options available to the UpsertChunked operation.
+type UpsertChunkedOptions struct {
+	// ChunkSize is the largest value, in bytes, which is stored as a single document.  Larger
+	// values are split into chunks of this size.  The default is 1MiB.
+	ChunkSize uint32
+
+	// Cas, if set, causes the operation to fail with ErrCasMismatch unless the document,
+	// chunked or otherwise, currently has this CAS.
+	Cas Cas
+
+	Expiry        time.Duration
+	Transcoder    Transcoder
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// GetChunkedOptions are the options available to the GetChunked operation.
+type GetChunkedOptions struct {
+	Transcoder    Transcoder
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// RemoveChunkedOptions are the options available to the RemoveChunked operation.
+type RemoveChunkedOptions struct {
+	Cas           Cas
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// UpsertChunked stores a document which may be larger than the server allows.  Values larger than
+// ChunkSize are split into chunk documents, named after the document ID, and the document itself
+// holds a manifest of the chunks.  The manifest is written last, using the CAS of the previous
+// version of the document, so readers using GetChunked never see a partially written value and
+// concurrent writers cannot both succeed.  Chunks of the previous version are removed once the
+// manifest has been written.
+//
+// Documents written by UpsertChunked must be read using GetChunked and removed using
+// RemoveChunked.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) UpsertChunked(id string, val interface{}, opts *UpsertChunkedOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &UpsertChunkedOptions{}
+	}
+
+	chunkSize := int(opts.ChunkSize)
+	if chunkSize == 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	transcoder := opts.Transcoder
+	if transcoder == nil {
+		transcoder = c.sb.Transcoder
+	}
+
+	clk := clockOrSystem(c.sb.Clock)
+	var deadline time.Time
+	if opts.Timeout != 0 {
+		deadline = clk.Now().Add(opts.Timeout)
+	}
+
+	bytes, flags, err := transcoder.Encode(val)
+	if err != nil {
+		return nil, err
+	}
+
+	// Small values are stored as an ordinary document.
+	var manifest *chunkedManifestInfo
+	if len(bytes) > chunkSize {
+		manifest = &chunkedManifestInfo{
+			Version:    1,
+			Generation: uuid.New().String()[:8],
+			Chunks:     (len(bytes) + chunkSize - 1) / chunkSize,
+			Size:       len(bytes),
+			Flags:      flags,
+		}
+
+		if lastID := chunkID(id, manifest.Generation, manifest.Chunks-1); len(lastID) > maxDocumentIDLength {
+			return nil, makeInvalidArgumentsError(fmt.Sprintf(
+				"document ID %q is too long to be chunked, chunk IDs would be up to %d bytes, longer than the maximum of %d bytes",
+				id, len(lastID), maxDocumentIDLength))
+		}
+	}
+
+	timeout, err := remainingTimeout(clk, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingCas Cas
+	var oldManifest *chunkedManifestInfo
+	existing, err := c.Get(id, &GetOptions{
+		Timeout:       timeout,
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	})
+	if err == nil {
+		existingCas = existing.Cas()
+		oldManifest = parseChunkedManifest(existing)
+	} else if !errors.Is(err, ErrDocumentNotFound) {
+		return nil, err
+	}
+
+	if opts.Cas != 0 {
+		if existingCas == 0 {
+			return nil, ErrDocumentNotFound
+		}
+		if opts.Cas != existingCas {
+			return nil, ErrCasMismatch
+		}
+	}
+
+	var newChunkIDs []string
+	doc := val
+	docTranscoder := transcoder
+	if manifest != nil {
+		newChunkIDs = manifest.chunkIDs(id)
+
+		ops := make([]BulkOp, len(newChunkIDs))
+		for i, chunkID := range newChunkIDs {
+			end := (i + 1) * chunkSize
+			if end > len(bytes) {
+				end = len(bytes)
+			}
+			ops[i] = &UpsertOp{
+				ID:     chunkID,
+				Value:  bytes[i*chunkSize : end],
+				Expiry: opts.Expiry,
+			}
+		}
+
+		timeout, err := remainingTimeout(clk, deadline)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.Do(ops, &BulkOpOptions{
+			Timeout:       timeout,
+			Transcoder:    NewRawBinaryTranscoder(),
+			RetryStrategy: opts.RetryStrategy,
+			Tags:          opts.Tags,
+		})
+		if err == nil {
+			for _, op := range ops {
+				if op.err() != nil {
+					err = op.err()
+					break
+				}
+			}
+		}
+		if err != nil {
+			c.removeChunks(newChunkIDs, clk, deadline)
+			return nil, err
+		}
+
+		manifestBytes, err := json.Marshal(chunkedManifest{Chunked: manifest})
+		if err != nil {
+			c.removeChunks(newChunkIDs, clk, deadline)
+			return nil, err
+		}
+		doc = manifestBytes
+		docTranscoder = NewRawJSONTranscoder()
+	}
+
+	timeout, err = remainingTimeout(clk, deadline)
+	if err != nil {
+		c.removeChunks(newChunkIDs, clk, deadline)
+		return nil, err
+	}
+
+	var res *MutationResult
+	if existingCas == 0 {
+		res, err = c.Insert(id, doc, &InsertOptions{
+			Expiry:        opts.Expiry,
+			Transcoder:    docTranscoder,
+			Timeout:       timeout,
+			RetryStrategy: opts.RetryStrategy,
+			Tags:          opts.Tags,
+		})
+	} else {
+		res, err = c.Replace(id, doc, &ReplaceOptions{
+			Cas:           existingCas,
+			Expiry:        opts.Expiry,
+			Transcoder:    docTranscoder,
+			Timeout:       timeout,
+			RetryStrategy: opts.RetryStrategy,
+			Tags:          opts.Tags,
+		})
+	}
+	if err != nil {
+		c.removeChunks(newChunkIDs, clk, deadline)
+		return nil, err
+	}
+
+	if oldManifest != nil {
+		c.removeChunks(oldManifest.chunkIDs(id), clk, deadline)
+	}
+
+	return res, nil
+}
+
+// GetChunked fetches a document written by UpsertChunked, reassembling it from its chunks if
+// required.  The CAS of the result is that of the manifest, so it can be passed to UpsertChunked
+// and RemoveChunked.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) GetChunked(id string, opts *GetChunkedOptions) (*GetResult, error) {
+	if opts == nil {
+		opts = &GetChunkedOptions{}
+	}
+
+	transcoder := opts.Transcoder
+	if transcoder == nil {
+		transcoder = c.sb.Transcoder
+	}
+
+	clk := clockOrSystem(c.sb.Clock)
+	var deadline time.Time
+	if opts.Timeout != 0 {
+		deadline = clk.Now().Add(opts.Timeout)
+	}
+
+	var lastCas Cas
+	for {
+		timeout, err := remainingTimeout(clk, deadline)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := c.Get(id, &GetOptions{
+			Timeout:       timeout,
+			RetryStrategy: opts.RetryStrategy,
+			Tags:          opts.Tags,
+		})
+		if err != nil {
+			return nil, err
+		}
+		doc.transcoder = transcoder
+
+		manifest := parseChunkedManifest(doc)
+		if manifest == nil {
+			return doc, nil
+		}
+
+		// If the manifest has not changed since we last failed to read a chunk then the document
+		// is broken rather than concurrently being replaced.
+		if lastCas != 0 && doc.Cas() == lastCas {
+			return nil, wrapError(ErrDocumentNotFound, "chunk of document "+id+" is missing")
+		}
+
+		chunkIDs := manifest.chunkIDs(id)
+		ops := make([]BulkOp, len(chunkIDs))
+		for i, chunkID := range chunkIDs {
+			ops[i] = &GetOp{ID: chunkID}
+		}
+
+		timeout, err = remainingTimeout(clk, deadline)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.Do(ops, &BulkOpOptions{
+			Timeout:       timeout,
+			Transcoder:    NewRawBinaryTranscoder(),
+			RetryStrategy: opts.RetryStrategy,
+			Tags:          opts.Tags,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		contents := make([]byte, 0, manifest.Size)
+		missing := false
+		for _, op := range ops {
+			getOp := op.(*GetOp)
+			if errors.Is(getOp.Err, ErrDocumentNotFound) {
+				missing = true
+				break
+			} else if getOp.Err != nil {
+				return nil, getOp.Err
+			}
+			contents = append(contents, getOp.Result.contents...)
+		}
+
+		if missing {
+			// The chunks may have been removed by a concurrent writer replacing the manifest.
+			lastCas = doc.Cas()
+			continue
+		}
+
+		if len(contents) != manifest.Size {
+			return nil, fmt.Errorf("document %s was %d bytes but expected %d bytes", id, len(contents), manifest.Size)
+		}
+
+		return &GetResult{
+			Result:     Result{cas: doc.Cas()},
+			transcoder: transcoder,
+			flags:      manifest.Flags,
+			contents:   contents,
+		}, nil
+	}
+}
+
+// RemoveChunked removes a document written by UpsertChunked, along with its chunks.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) RemoveChunked(id string, opts *RemoveChunkedOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &RemoveChunkedOptions{}
+	}
+
+	clk := clockOrSystem(c.sb.Clock)
+	var deadline time.Time
+	if opts.Timeout != 0 {
+		deadline = clk.Now().Add(opts.Timeout)
+	}
+
+	doc, err := c.Get(id, &GetOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cas := opts.Cas
+	if cas == 0 {
+		cas = doc.Cas()
+	}
+
+	timeout, err := remainingTimeout(clk, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.Remove(id, &RemoveOptions{
+		Cas:           cas,
+		Timeout:       timeout,
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest := parseChunkedManifest(doc); manifest != nil {
+		c.removeChunks(manifest.chunkIDs(id), clk, deadline)
+	}
+
+	return res, nil
+}
+
+// removeChunks removes chunks which are no longer referenced by a manifest.  Failures are only
+// logged, as the chunks will never be read.  The chunks are left in place if the deadline of the
+// operation has already passed.
+func (c *Collection) removeChunks(ids []string, clk clock, deadline time.Time) {
+	if len(ids) == 0 {
+		return
+	}
+
+	timeout, err := remainingTimeout(clk, deadline)
+	if err != nil {
+		logDebugf("Failed to remove chunks (%s)", err)
+		return
+	}
+
+	ops := make([]BulkOp, len(ids))
+	for i, id := range ids {
+		ops[i] = &RemoveOp{ID: id}
+	}
+
+	err = c.Do(ops, &BulkOpOptions{Timeout: timeout})
+	if err != nil {
+		logDebugf("Failed to remove chunks (%s)", err)
+		return
+	}
+
+	for _, op := range ops {
+		if err := op.err(); err != nil && !errors.Is(err, ErrDocumentNotFound) {
+			logDebugf("Failed to remove chunk %s (%s)", op.(*RemoveOp).ID, err)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+type mockMapDoc struct {
+	value []byte
+	flags uint32
+	cas   gocbcore.Cas
+}
+
+// mockMapKvProvider stores documents in memory, so that operations on different documents
+// can be tested together.
+type mockMapKvProvider struct {
+	*mockKvProvider
+
+	lock    sync.Mutex
+	docs    map[string]mockMapDoc
+	lastCas gocbcore.Cas
+}
+
+func testMapCollection(t *testing.T) (*Collection, *mockMapKvProvider) {
+	provider := &mockMapKvProvider{
+		mockKvProvider: &mockKvProvider{},
+		docs:           make(map[string]mockMapDoc),
+	}
+	col := testGetCollection(t, provider.mockKvProvider)
+	col.sb.getCachedClient().(*mockClient).mockKvProvider = provider
+
+	return col, provider
+}
+
+func (p *mockMapKvProvider) store(key []byte, value []byte, flags uint32, cas gocbcore.Cas, mustExist, mustNotExist bool) (gocbcore.Cas, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	doc, exists := p.docs[string(key)]
+	if mustNotExist && exists {
+		return 0, gocbcore.ErrDocumentExists
+	}
+	if mustExist && !exists {
+		return 0, gocbcore.ErrDocumentNotFound
+	}
+	if cas != 0 && doc.cas != cas {
+		return 0, gocbcore.ErrCasMismatch
+	}
+
+	p.lastCas++
+	p.docs[string(key)] = mockMapDoc{value: append([]byte(nil), value...), flags: flags, cas: p.lastCas}
+	return p.lastCas, nil
+}
+
+func (p *mockMapKvProvider) AddEx(opts gocbcore.AddOptions, cb gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	cas, err := p.store(opts.Key, opts.Value, opts.Flags, 0, false, true)
+	if err != nil {
+		cb(nil, err)
+	} else {
+		cb(&gocbcore.StoreResult{Cas: cas}, nil)
+	}
+	return &mockPendingOp{}, nil
+}
+
+func (p *mockMapKvProvider) SetEx(opts gocbcore.SetOptions, cb gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	cas, err := p.store(opts.Key, opts.Value, opts.Flags, 0, false, false)
+	if err != nil {
+		cb(nil, err)
+	} else {
+		cb(&gocbcore.StoreResult{Cas: cas}, nil)
+	}
+	return &mockPendingOp{}, nil
+}
+
+func (p *mockMapKvProvider) ReplaceEx(opts gocbcore.ReplaceOptions, cb gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	cas, err := p.store(opts.Key, opts.Value, opts.Flags, opts.Cas, true, false)
+	if err != nil {
+		cb(nil, err)
+	} else {
+		cb(&gocbcore.StoreResult{Cas: cas}, nil)
+	}
+	return &mockPendingOp{}, nil
+}
+
+func (p *mockMapKvProvider) GetEx(opts gocbcore.GetOptions, cb gocbcore.GetExCallback) (gocbcore.PendingOp, error) {
+	p.lock.Lock()
+	doc, exists := p.docs[string(opts.Key)]
+	p.lock.Unlock()
+
+	if !exists {
+		cb(nil, gocbcore.ErrDocumentNotFound)
+	} else {
+		cb(&gocbcore.GetResult{Value: doc.value, Flags: doc.flags, Cas: doc.cas}, nil)
+	}
+	return &mockPendingOp{}, nil
+}
+
+func (p *mockMapKvProvider) DeleteEx(opts gocbcore.DeleteOptions, cb gocbcore.DeleteExCallback) (gocbcore.PendingOp, error) {
+	p.lock.Lock()
+	doc, exists := p.docs[string(opts.Key)]
+	var err error
+	if !exists {
+		err = gocbcore.ErrDocumentNotFound
+	} else if opts.Cas != 0 && opts.Cas != doc.cas {
+		err = gocbcore.ErrCasMismatch
+	} else {
+		delete(p.docs, string(opts.Key))
+		p.lastCas++
+	}
+	cas := p.lastCas
+	p.lock.Unlock()
+
+	if err != nil {
+		cb(nil, err)
+	} else {
+		cb(&gocbcore.DeleteResult{Cas: cas}, nil)
+	}
+	return &mockPendingOp{}, nil
+}
+
+func (p *mockMapKvProvider) numChunks() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	count := 0
+	for key := range p.docs {
+		if strings.Contains(key, "::chunk::") {
+			count++
+		}
+	}
+	return count
+}
+
+func TestChunkedDocument(t *testing.T) {
+	col, provider := testMapCollection(t)
+
+	large := strings.Repeat("abcdefghij", 100)
+	_, err := col.UpsertChunked("large", large, &UpsertChunkedOptions{ChunkSize: 64})
+	if err != nil {
+		t.Fatalf("UpsertChunked failed: %v", err)
+	}
+
+	// The JSON encoded string is 1002 bytes, so requires 16 chunks of 64 bytes.
+	if provider.numChunks() != 16 {
+		t.Fatalf("Expected 16 chunks but was %d", provider.numChunks())
+	}
+
+	res, err := col.GetChunked("large", nil)
+	if err != nil {
+		t.Fatalf("GetChunked failed: %v", err)
+	}
+
+	var content string
+	err = res.Content(&content)
+	if err != nil || content != large {
+		t.Fatalf("Unexpected content %s, error %v", content, err)
+	}
+
+	// Replacing the document must remove the chunks of the previous version.
+	_, err = col.UpsertChunked("large", "small", &UpsertChunkedOptions{ChunkSize: 64, Cas: res.Cas()})
+	if err != nil {
+		t.Fatalf("UpsertChunked failed: %v", err)
+	}
+
+	if provider.numChunks() != 0 {
+		t.Fatalf("Expected no chunks but was %d", provider.numChunks())
+	}
+
+	res, err = col.GetChunked("large", nil)
+	if err != nil {
+		t.Fatalf("GetChunked failed: %v", err)
+	}
+
+	err = res.Content(&content)
+	if err != nil || content != "small" {
+		t.Fatalf("Unexpected content %s, error %v", content, err)
+	}
+}
+
+func TestChunkedDocumentCasMismatch(t *testing.T) {
+	col, provider := testMapCollection(t)
+
+	_, err := col.UpsertChunked("doc", []byte("value"), &UpsertChunkedOptions{Transcoder: NewRawBinaryTranscoder()})
+	if err != nil {
+		t.Fatalf("UpsertChunked failed: %v", err)
+	}
+
+	_, err = col.UpsertChunked("doc", bytes.Repeat([]byte("x"), 100), &UpsertChunkedOptions{
+		ChunkSize:  10,
+		Cas:        12345,
+		Transcoder: NewRawBinaryTranscoder(),
+	})
+	if !errors.Is(err, ErrCasMismatch) {
+		t.Fatalf("Expected cas mismatch but was %v", err)
+	}
+
+	if provider.numChunks() != 0 {
+		t.Fatalf("Expected no chunks but was %d", provider.numChunks())
+	}
+}
+
+func TestRemoveChunked(t *testing.T) {
+	col, provider := testMapCollection(t)
+
+	_, err := col.UpsertChunked("doc", bytes.Repeat([]byte("x"), 100), &UpsertChunkedOptions{
+		ChunkSize:  10,
+		Transcoder: NewRawBinaryTranscoder(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertChunked failed: %v", err)
+	}
+
+	res, err := col.GetChunked("doc", &GetChunkedOptions{Transcoder: NewRawBinaryTranscoder()})
+	if err != nil {
+		t.Fatalf("GetChunked failed: %v", err)
+	}
+
+	var content []byte
+	err = res.Content(&content)
+	if err != nil || !bytes.Equal(content, bytes.Repeat([]byte("x"), 100)) {
+		t.Fatalf("Unexpected content %s, error %v", content, err)
+	}
+
+	_, err = col.RemoveChunked("doc", nil)
+	if err != nil {
+		t.Fatalf("RemoveChunked failed: %v", err)
+	}
+
+	if provider.numChunks() != 0 || len(provider.docs) != 0 {
+		t.Fatalf("Expected no documents but was %d", len(provider.docs))
+	}
+}
+
+func TestGetChunkedMissingChunk(t *testing.T) {
+	col, provider := testMapCollection(t)
+
+	_, err := col.UpsertChunked("doc", bytes.Repeat([]byte("x"), 100), &UpsertChunkedOptions{
+		ChunkSize:  10,
+		Transcoder: NewRawBinaryTranscoder(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertChunked failed: %v", err)
+	}
+
+	provider.lock.Lock()
+	for key := range provider.docs {
+		if strings.HasSuffix(key, "::3") {
+			delete(provider.docs, key)
+		}
+	}
+	provider.lock.Unlock()
+
+	_, err = col.GetChunked("doc", nil)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("Expected document not found but was %v", err)
+	}
+}
+
+func TestUpsertChunkedLongID(t *testing.T) {
+	col, provider := testMapCollection(t)
+
+	id := strings.Repeat("k", 240)
+	_, err := col.UpsertChunked(id, strings.Repeat("abcdefghij", 100), &UpsertChunkedOptions{ChunkSize: 64})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	if len(provider.docs) != 0 {
+		t.Fatalf("Expected nothing to be written but was %d documents", len(provider.docs))
+	}
+
+	// Values which are not chunked only need the document ID itself to be valid.
+	_, err = col.UpsertChunked(id, "small", &UpsertChunkedOptions{ChunkSize: 64})
+	if err != nil {
+		t.Fatalf("UpsertChunked failed: %v", err)
+	}
+}
+
+// slowMapKvProvider takes a while to fetch documents and never completes adds, so that
+// the deadline of a chunked operation runs out part way through it.
+type slowMapKvProvider struct {
+	*mockMapKvProvider
+
+	clk     *fakeClock
+	getTime time.Duration
+	addCh   chan struct{}
+}
+
+func (p *slowMapKvProvider) GetEx(opts gocbcore.GetOptions, cb gocbcore.GetExCallback) (gocbcore.PendingOp, error) {
+	p.clk.Advance(p.getTime)
+	return p.mockMapKvProvider.GetEx(opts, cb)
+}
+
+func (p *slowMapKvProvider) AddEx(opts gocbcore.AddOptions, cb gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	p.addCh <- struct{}{}
+	return &mockPendingOp{
+		handler: func(err error) {
+			cb(nil, err)
+		},
+	}, nil
+}
+
+func TestUpsertChunkedAppliesDeadlineToEveryStep(t *testing.T) {
+	col, mapProvider := testMapCollection(t)
+	clk := newFakeClock()
+	col.sb.Clock = clk
+
+	provider := &slowMapKvProvider{
+		mockMapKvProvider: mapProvider,
+		clk:               clk,
+		getTime:           600 * time.Millisecond,
+		addCh:             make(chan struct{}, 1),
+	}
+	col.sb.getCachedClient().(*mockClient).mockKvProvider = provider
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := col.UpsertChunked("doc", []byte("value"), &UpsertChunkedOptions{
+			Timeout:    time.Second,
+			Transcoder: NewRawBinaryTranscoder(),
+		})
+		errCh <- err
+	}()
+
+	<-provider.addCh
+
+	// The fetch of the existing document used 600ms of the 1s timeout, so the insert of the
+	// document must time out once the remaining 400ms have passed.
+	clk.Advance(400 * time.Millisecond)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("Expected a timeout but was %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("UpsertChunked did not time out at its deadline")
+	}
+}
+
+func TestRemainingTimeout(t *testing.T) {
+	clk := newFakeClock()
+
+	timeout, err := remainingTimeout(clk, time.Time{})
+	if err != nil || timeout != 0 {
+		t.Fatalf("Expected no timeout without a deadline but was %s, %v", timeout, err)
+	}
+
+	deadline := clk.Now().Add(time.Second)
+	clk.Advance(300 * time.Millisecond)
+	timeout, err = remainingTimeout(clk, deadline)
+	if err != nil || timeout != 700*time.Millisecond {
+		t.Fatalf("Expected 700ms remaining but was %s, %v", timeout, err)
+	}
+
+	clk.Advance(time.Second)
+	_, err = remainingTimeout(clk, deadline)
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout but was %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// CompareReplicasOptions are the options available to the CompareReplicas command.
+type CompareReplicasOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
+}
+
+// DocumentCopy describes one copy of a document, as read by CompareReplicas.
+type DocumentCopy struct {
+	// ReplicaIndex identifies the copy, 0 being the active copy and 1 onwards the replicas.
+	ReplicaIndex int
+	// Found is false if the document does not exist on this copy.
+	Found bool
+	Cas   Cas
+	Flags uint32
+	// Content is the raw content of the document on this copy.
+	Content []byte
+	// VbSeqNo is the sequence number which the vbucket holding the document had reached on this
+	// copy, as reported by observe_seqno.  The server does not report the sequence number of
+	// a document held by a replica, so this is the sequence number of the latest mutation to
+	// any document in the vbucket, which tells whether this copy is behind the others.
+	VbSeqNo uint64
+	// Err is set if the copy could not be read, in which case it is excluded from the comparison.
+	Err error
+}
+
+// ReplicaComparison is the result of comparing the copies of a document.
+type ReplicaComparison struct {
+	// Copies holds each copy of the document, ordered by replica index.
+	Copies []DocumentCopy
+	// ExistenceDiverged is true if the document exists on some copies but not others.
+	ExistenceDiverged bool
+	// CasDiverged is true if the copies which hold the document do not all have the same CAS.
+	CasDiverged bool
+	// ContentDiverged is true if the copies which hold the document do not all have the same
+	// content and flags.
+	ContentDiverged bool
+	// SeqNoDiverged is true if the vbucket holding the document has not reached the same
+	// sequence number on every copy which could be read, such as when a replica is behind.
+	// Unlike the other checks this may be set even though the document itself has not diverged.
+	SeqNoDiverged bool
+}
+
+// Diverged returns whether any of the copies which could be read differ from each other.
+func (r *ReplicaComparison) Diverged() bool {
+	return r.ExistenceDiverged || r.CasDiverged || r.ContentDiverged || r.SeqNoDiverged
+}
+
+// CompareReplicas reads a document, and the sequence number of the vbucket holding it, from the
+// active copy and from every replica, and reports whether the copies have diverged from each
+// other.  This is useful when investigating
+// consistency issues, such as after a failover.  Copies which cannot be read are reported
+// with their error rather than failing the comparison.
+func (c *Collection) CompareReplicas(id string, opts *CompareReplicasOptions) (*ReplicaComparison, error) {
+	if opts == nil {
+		opts = &CompareReplicasOptions{}
+	}
+
+	span := applyOperationTags(c.startKvOpTrace("CompareReplicas", opts.ParentSpan), opts.Tags)
+	defer span.Finish()
+
+	timeout := opts.Timeout
+	if timeout == 0 || timeout > c.sb.kvTimeout() {
+		timeout = c.sb.kvTimeout()
+	}
+	deadline := clockOrSystem(c.sb.Clock).Now().Add(timeout)
+
+	agent, err := c.getKvProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	copies := make([]DocumentCopy, agent.NumReplicas()+1)
+	waitCh := make(chan struct{}, len(copies))
+	for replicaIdx := range copies {
+		go func(replicaIdx int) {
+			docCopy := DocumentCopy{ReplicaIndex: replicaIdx}
+
+			res, err := c.getOneReplica(span.Context(), id, replicaIdx, nil, opts.RetryStrategy, deadline, nil)
+			if err == nil {
+				docCopy.Found = true
+				docCopy.Cas = res.cas
+				docCopy.Flags = res.flags
+				docCopy.Content = res.contents
+			} else if !errors.Is(err, ErrDocumentNotFound) {
+				docCopy.Err = err
+			}
+
+			if docCopy.Err == nil {
+				docCopy.VbSeqNo, docCopy.Err = c.observeVbSeqNo(span.Context(), id, replicaIdx, deadline)
+			}
+
+			copies[replicaIdx] = docCopy
+			waitCh <- struct{}{}
+		}(replicaIdx)
+	}
+
+	for range copies {
+		<-waitCh
+	}
+
+	comparison := &ReplicaComparison{
+		Copies: copies,
+	}
+	comparison.compare()
+
+	return comparison, nil
+}
+
+// observeVbSeqNo returns the current sequence number of the vbucket holding id on one copy.
+func (c *Collection) observeVbSeqNo(
+	tracectx RequestSpanContext,
+	id string,
+	replicaIdx int,
+	deadline time.Time,
+) (seqNoOut uint64, errOut error) {
+	opm := c.newKvOpManager("observeVbSeqNo", tracectx)
+	defer opm.Finish()
+
+	opm.SetDocumentID(id)
+	opm.SetDeadline(deadline)
+
+	if err := opm.CheckReadyForOp(); err != nil {
+		return 0, err
+	}
+
+	agent, err := c.getKvProvider()
+	if err != nil {
+		return 0, err
+	}
+	// The server reports the current sequence number whether or not the vbucket uuid matches,
+	// so there is no need to know it.
+	err = opm.Wait(agent.ObserveVbEx(gocbcore.ObserveVbOptions{
+		VbID:         agent.KeyToVbucket([]byte(id)),
+		ReplicaIdx:   replicaIdx,
+		TraceContext: opm.TraceContext(),
+	}, func(res *gocbcore.ObserveVbResult, err error) {
+		if err != nil || res == nil {
+			errOut = opm.EnhanceErr(err)
+			return
+		}
+
+		seqNoOut = uint64(res.CurrentSeqNo)
+
+		opm.Resolve(nil)
+	}))
+	if err != nil {
+		errOut = err
+	}
+	return
+}
+
+func (r *ReplicaComparison) compare() {
+	var readable, found []*DocumentCopy
+	for i := range r.Copies {
+		docCopy := &r.Copies[i]
+		if docCopy.Err != nil {
+			continue
+		}
+
+		readable = append(readable, docCopy)
+		if docCopy.Found {
+			found = append(found, docCopy)
+		}
+	}
+
+	r.ExistenceDiverged = len(found) > 0 && len(found) < len(readable)
+
+	for i := 1; i < len(readable); i++ {
+		if readable[i].VbSeqNo != readable[0].VbSeqNo {
+			r.SeqNoDiverged = true
+		}
+	}
+
+	for i := 1; i < len(found); i++ {
+		if found[i].Cas != found[0].Cas {
+			r.CasDiverged = true
+		}
+		if found[i].Flags != found[0].Flags || !bytes.Equal(found[i].Content, found[0].Content) {
+			r.ContentDiverged = true
+		}
+	}
+}
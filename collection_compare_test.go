@@ -0,0 +1,59 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareReplicas(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:     5,
+		value:   []byte(`{"name":"active"}`),
+		vbSeqNo: 12,
+	}
+	col := testGetCollection(t, provider)
+
+	res, err := col.CompareReplicas("compareReplicasDoc", nil)
+	if err != nil {
+		t.Fatalf("CompareReplicas failed: %v", err)
+	}
+
+	if len(res.Copies) != 1 || !res.Copies[0].Found || res.Copies[0].Cas != Cas(5) || res.Copies[0].VbSeqNo != 12 {
+		t.Fatalf("Unexpected copies %+v", res.Copies)
+	}
+	if res.Diverged() {
+		t.Fatalf("Expected a single copy to not diverge")
+	}
+}
+
+func TestReplicaComparisonCompare(t *testing.T) {
+	active := DocumentCopy{ReplicaIndex: 0, Found: true, Cas: 5, Content: []byte(`{"a":1}`), VbSeqNo: 10}
+
+	testCases := []struct {
+		name      string
+		replica   DocumentCopy
+		existence bool
+		cas       bool
+		content   bool
+		seqNo     bool
+	}{
+		{"same", DocumentCopy{ReplicaIndex: 1, Found: true, Cas: 5, Content: []byte(`{"a":1}`), VbSeqNo: 10}, false, false, false, false},
+		{"cas", DocumentCopy{ReplicaIndex: 1, Found: true, Cas: 4, Content: []byte(`{"a":1}`), VbSeqNo: 10}, false, true, false, false},
+		{"content", DocumentCopy{ReplicaIndex: 1, Found: true, Cas: 4, Content: []byte(`{"a":2}`), VbSeqNo: 10}, false, true, true, false},
+		{"seqno", DocumentCopy{ReplicaIndex: 1, Found: true, Cas: 5, Content: []byte(`{"a":1}`), VbSeqNo: 8}, false, false, false, true},
+		{"missing", DocumentCopy{ReplicaIndex: 1, VbSeqNo: 10}, true, false, false, false},
+		{"unreadable", DocumentCopy{ReplicaIndex: 1, Err: errors.New("timeout")}, false, false, false, false},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			comparison := &ReplicaComparison{Copies: []DocumentCopy{active, tCase.replica}}
+			comparison.compare()
+
+			if comparison.ExistenceDiverged != tCase.existence || comparison.CasDiverged != tCase.cas ||
+				comparison.ContentDiverged != tCase.content || comparison.SeqNoDiverged != tCase.seqNo {
+				t.Fatalf("Unexpected comparison %+v", comparison)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package gocb
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -17,6 +18,8 @@ type kvProvider interface {
 	ObserveEx(opts gocbcore.ObserveOptions, cb gocbcore.ObserveExCallback) (gocbcore.PendingOp, error)
 	ObserveVbEx(opts gocbcore.ObserveVbOptions, cb gocbcore.ObserveVbExCallback) (gocbcore.PendingOp, error)
 	GetMetaEx(opts gocbcore.GetMetaOptions, cb gocbcore.GetMetaExCallback) (gocbcore.PendingOp, error)
+	SetMetaEx(opts gocbcore.SetMetaOptions, cb gocbcore.SetMetaExCallback) (gocbcore.PendingOp, error)
+	DeleteMetaEx(opts gocbcore.DeleteMetaOptions, cb gocbcore.DeleteMetaExCallback) (gocbcore.PendingOp, error)
 	DeleteEx(opts gocbcore.DeleteOptions, cb gocbcore.DeleteExCallback) (gocbcore.PendingOp, error)
 	LookupInEx(opts gocbcore.LookupInOptions, cb gocbcore.LookupInExCallback) (gocbcore.PendingOp, error)
 	MutateInEx(opts gocbcore.MutateInOptions, cb gocbcore.MutateInExCallback) (gocbcore.PendingOp, error)
@@ -29,7 +32,9 @@ type kvProvider interface {
 	AppendEx(opts gocbcore.AdjoinOptions, cb gocbcore.AdjoinExCallback) (gocbcore.PendingOp, error)
 	PrependEx(opts gocbcore.AdjoinOptions, cb gocbcore.AdjoinExCallback) (gocbcore.PendingOp, error)
 	PingKvEx(opts gocbcore.PingKvOptions, cb gocbcore.PingKvExCallback) (gocbcore.PendingOp, error)
+	StatsEx(opts gocbcore.StatsOptions, cb gocbcore.StatsExCallback) (gocbcore.PendingOp, error)
 	NumReplicas() int
+	KeyToVbucket(key []byte) uint16
 }
 
 // Cas represents the specific state of a document on the cluster.
@@ -46,6 +51,8 @@ type InsertOptions struct {
 	Transcoder      Transcoder
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 // Insert creates a new document in the Collection.
@@ -54,7 +61,7 @@ func (c *Collection) Insert(id string, val interface{}, opts *InsertOptions) (mu
 		opts = &InsertOptions{}
 	}
 
-	opm := c.newKvOpManager("Insert", nil)
+	opm := c.newKvOpManager("Insert", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
@@ -62,6 +69,7 @@ func (c *Collection) Insert(id string, val interface{}, opts *InsertOptions) (mu
 	opm.SetValue(val)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -82,7 +90,7 @@ func (c *Collection) Insert(id string, val interface{}, opts *InsertOptions) (mu
 		DurabilityLevel:        opm.DurabilityLevel(),
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.StoreResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -92,6 +100,7 @@ func (c *Collection) Insert(id string, val interface{}, opts *InsertOptions) (mu
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
@@ -111,6 +120,8 @@ type UpsertOptions struct {
 	Transcoder      Transcoder
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 // Upsert creates a new document in the Collection if it does not exist, if it does exist then it updates it.
@@ -119,7 +130,7 @@ func (c *Collection) Upsert(id string, val interface{}, opts *UpsertOptions) (mu
 		opts = &UpsertOptions{}
 	}
 
-	opm := c.newKvOpManager("Upsert", nil)
+	opm := c.newKvOpManager("Upsert", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
@@ -127,6 +138,7 @@ func (c *Collection) Upsert(id string, val interface{}, opts *UpsertOptions) (mu
 	opm.SetValue(val)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -147,7 +159,7 @@ func (c *Collection) Upsert(id string, val interface{}, opts *UpsertOptions) (mu
 		DurabilityLevel:        opm.DurabilityLevel(),
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.StoreResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -157,6 +169,7 @@ func (c *Collection) Upsert(id string, val interface{}, opts *UpsertOptions) (mu
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
@@ -177,6 +190,8 @@ type ReplaceOptions struct {
 	Transcoder      Transcoder
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 // Replace updates a document in the collection.
@@ -185,7 +200,7 @@ func (c *Collection) Replace(id string, val interface{}, opts *ReplaceOptions) (
 		opts = &ReplaceOptions{}
 	}
 
-	opm := c.newKvOpManager("Replace", nil)
+	opm := c.newKvOpManager("Replace", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
@@ -193,6 +208,7 @@ func (c *Collection) Replace(id string, val interface{}, opts *ReplaceOptions) (
 	opm.SetValue(val)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -214,7 +230,7 @@ func (c *Collection) Replace(id string, val interface{}, opts *ReplaceOptions) (
 		DurabilityLevel:        opm.DurabilityLevel(),
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.StoreResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -224,6 +240,7 @@ func (c *Collection) Replace(id string, val interface{}, opts *ReplaceOptions) (
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
@@ -244,6 +261,8 @@ type GetOptions struct {
 	Transcoder    Transcoder
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // Get performs a fetch operation against the collection. This can take 3 paths, a standard full document
@@ -266,12 +285,13 @@ func (c *Collection) getDirect(id string, opts *GetOptions) (docOut *GetResult,
 		opts = &GetOptions{}
 	}
 
-	opm := c.newKvOpManager("Get", nil)
+	opm := c.newKvOpManager("Get", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetTranscoder(opts.Transcoder)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -287,7 +307,7 @@ func (c *Collection) getDirect(id string, opts *GetOptions) (docOut *GetResult,
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan(),
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.GetResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -297,11 +317,13 @@ func (c *Collection) getDirect(id string, opts *GetOptions) (docOut *GetResult,
 
 		doc := &GetResult{
 			Result: Result{
-				cas: Cas(res.Cas),
+				cas:            Cas(res.Cas),
+				serverDuration: opm.ServerDuration(),
 			},
 			transcoder: opm.Transcoder(),
 			contents:   res.Value,
 			flags:      res.Flags,
+			datatype:   DocumentDatatype(res.Datatype),
 		}
 
 		docOut = doc
@@ -319,12 +341,13 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 		opts = &GetOptions{}
 	}
 
-	opm := c.newKvOpManager("Get", nil)
+	opm := c.newKvOpManager("Get", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetTranscoder(opts.Transcoder)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if opts.Transcoder != nil {
@@ -377,6 +400,7 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 
 	doc.transcoder = opm.Transcoder()
 	doc.cas = result.cas
+	doc.serverDuration = result.serverDuration
 	if projections == nil {
 		err = doc.fromFullProjection(ops, result, opts.Project)
 		if err != nil {
@@ -396,6 +420,8 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 type ExistsOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // Exists checks if a document exists for the given id.
@@ -404,11 +430,12 @@ func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResul
 		opts = &ExistsOptions{}
 	}
 
-	opm := c.newKvOpManager("Exists", nil)
+	opm := c.newKvOpManager("Exists", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -424,7 +451,7 @@ func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResul
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan,
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.GetMetaResult, err error) {
 		if errors.Is(err, ErrDocumentNotFound) {
 			docOut = &ExistsResult{
@@ -446,7 +473,8 @@ func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResul
 		if res != nil {
 			docOut = &ExistsResult{
 				Result: Result{
-					cas: Cas(res.Cas),
+					cas:            Cas(res.Cas),
+					serverDuration: opm.ServerDuration(),
 				},
 				docExists: true,
 			}
@@ -461,11 +489,12 @@ func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResul
 }
 
 func (c *Collection) getOneReplica(
-	span requestSpanContext,
+	span RequestSpanContext,
 	id string,
 	replicaIdx int,
 	transcoder Transcoder,
 	retryStrategy RetryStrategy,
+	deadline time.Time,
 	cancelCh chan struct{},
 ) (docOut *GetReplicaResult, errOut error) {
 	opm := c.newKvOpManager("getOneReplica", span)
@@ -474,8 +503,13 @@ func (c *Collection) getOneReplica(
 	opm.SetDocumentID(id)
 	opm.SetTranscoder(transcoder)
 	opm.SetRetryStrategy(retryStrategy)
+	opm.SetDeadline(deadline)
 	opm.SetCancelCh(cancelCh)
 
+	if err := opm.CheckReadyForOp(); err != nil {
+		return nil, err
+	}
+
 	agent, err := c.getKvProvider()
 	if err != nil {
 		return nil, err
@@ -486,7 +520,7 @@ func (c *Collection) getOneReplica(
 			CollectionName: opm.CollectionName(),
 			ScopeName:      opm.ScopeName(),
 			RetryStrategy:  opm.RetryStrategy(),
-			TraceContext:   opm.TraceSpan(),
+			TraceContext:   opm.TraceContext(),
 		}, func(res *gocbcore.GetResult, err error) {
 			if err != nil {
 				errOut = opm.EnhanceErr(err)
@@ -496,9 +530,11 @@ func (c *Collection) getOneReplica(
 
 			docOut = &GetReplicaResult{}
 			docOut.cas = Cas(res.Cas)
+			docOut.serverDuration = opm.ServerDuration()
 			docOut.transcoder = opm.Transcoder()
 			docOut.contents = res.Value
 			docOut.flags = res.Flags
+			docOut.datatype = DocumentDatatype(res.Datatype)
 			docOut.isReplica = false
 
 			opm.Resolve(nil)
@@ -515,7 +551,7 @@ func (c *Collection) getOneReplica(
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan(),
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.GetReplicaResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -525,9 +561,11 @@ func (c *Collection) getOneReplica(
 
 		docOut = &GetReplicaResult{}
 		docOut.cas = Cas(res.Cas)
+		docOut.serverDuration = opm.ServerDuration()
 		docOut.transcoder = opm.Transcoder()
 		docOut.contents = res.Value
 		docOut.flags = res.Flags
+		docOut.datatype = DocumentDatatype(res.Datatype)
 		docOut.isReplica = true
 
 		opm.Resolve(nil)
@@ -543,6 +581,8 @@ type GetAllReplicaOptions struct {
 	Transcoder    Transcoder
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // GetAllReplicasResult represents the results of a GetAllReplicas operation.
@@ -607,18 +647,19 @@ func (c *Collection) GetAllReplicas(id string, opts *GetAllReplicaOptions) (docO
 		opts = &GetAllReplicaOptions{}
 	}
 
-	span := c.startKvOpTrace("GetAllReplicas", nil)
+	span := applyOperationTags(c.startKvOpTrace("GetAllReplicas", opts.ParentSpan), opts.Tags)
 	defer span.Finish()
 
 	// Timeout needs to be adjusted here, since we use it at the bottom of this
 	// function, but the remaining options are all passed downwards and get handled
 	// by those functions rather than us.
 	timeout := opts.Timeout
-	if timeout == 0 || timeout > c.sb.KvTimeout {
-		timeout = c.sb.KvTimeout
+	if timeout == 0 || timeout > c.sb.kvTimeout() {
+		timeout = c.sb.kvTimeout()
 	}
 
-	deadline := time.Now().Add(timeout)
+	clk := clockOrSystem(c.sb.Clock)
+	deadline := clk.Now().Add(timeout)
 	transcoder := opts.Transcoder
 	retryStrategy := opts.RetryStrategy
 
@@ -640,7 +681,7 @@ func (c *Collection) GetAllReplicas(id string, opts *GetAllReplicaOptions) (docO
 	// Loop all the servers and populate the result object
 	for replicaIdx := 0; replicaIdx < numServers; replicaIdx++ {
 		go func(replicaIdx int) {
-			res, err := c.getOneReplica(span, id, replicaIdx, transcoder, retryStrategy, cancelCh)
+			res, err := c.getOneReplica(span.Context(), id, replicaIdx, transcoder, retryStrategy, deadline, cancelCh)
 			if err != nil {
 				logDebugf("Failed to fetch replica from replica %d: %s", replicaIdx, err)
 			} else {
@@ -652,7 +693,7 @@ func (c *Collection) GetAllReplicas(id string, opts *GetAllReplicaOptions) (docO
 	// Start a timer to close it after the deadline
 	go func() {
 		select {
-		case <-time.After(deadline.Sub(time.Now())):
+		case <-clk.After(deadline.Sub(clk.Now())):
 			// If we timeout, we should close the result
 			repRes.Close()
 			return
@@ -670,6 +711,8 @@ type GetAnyReplicaOptions struct {
 	Transcoder    Transcoder
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // GetAnyReplica returns the value of a particular document from a replica server.
@@ -678,13 +721,15 @@ func (c *Collection) GetAnyReplica(id string, opts *GetAnyReplicaOptions) (docOu
 		opts = &GetAnyReplicaOptions{}
 	}
 
-	span := c.startKvOpTrace("GetAnyReplica", nil)
+	span := applyOperationTags(c.startKvOpTrace("GetAnyReplica", opts.ParentSpan), opts.Tags)
 	defer span.Finish()
 
 	repRes, err := c.GetAllReplicas(id, &GetAllReplicaOptions{
 		Timeout:       opts.Timeout,
 		Transcoder:    opts.Transcoder,
 		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+		ParentSpan:    span.Context(),
 	})
 	if err != nil {
 		return nil, err
@@ -708,6 +753,49 @@ func (c *Collection) GetAnyReplica(id string, opts *GetAnyReplicaOptions) (docOu
 	return res, nil
 }
 
+// GetReplicaOptions are the options available to the GetReplica command.
+type GetReplicaOptions struct {
+	Transcoder    Transcoder
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
+}
+
+// GetReplica returns the value of a particular document from a specific copy of it, allowing
+// the copies to be compared when diagnosing divergence between them.  A replicaIdx of 0 reads
+// the active copy, and 1 up to the number of replicas configured for the bucket read the
+// replicas.  The number of replicas is only known once the cluster config has been received, so
+// an index beyond it fails with ErrInvalidArgument once the read has been routed.
+func (c *Collection) GetReplica(id string, replicaIdx int, opts *GetReplicaOptions) (*GetReplicaResult, error) {
+	if opts == nil {
+		opts = &GetReplicaOptions{}
+	}
+
+	if err := validateDocumentID(id); err != nil {
+		return nil, err
+	}
+	if replicaIdx < 0 {
+		return nil, makeInvalidArgumentsError("replica index must not be negative")
+	}
+
+	span := applyOperationTags(c.startKvOpTrace("GetReplica", opts.ParentSpan), opts.Tags)
+	defer span.Finish()
+
+	timeout := opts.Timeout
+	if timeout == 0 || timeout > c.sb.kvTimeout() {
+		timeout = c.sb.kvTimeout()
+	}
+
+	res, err := c.getOneReplica(span.Context(), id, replicaIdx, opts.Transcoder, opts.RetryStrategy,
+		clockOrSystem(c.sb.Clock).Now().Add(timeout), nil)
+	if errors.Is(err, gocbcore.ErrInvalidReplica) {
+		return nil, makeInvalidArgumentsError(
+			fmt.Sprintf("replica index %d is greater than the number of replicas of the bucket", replicaIdx))
+	}
+	return res, err
+}
+
 // RemoveOptions are the options available to the Remove command.
 type RemoveOptions struct {
 	Cas             Cas
@@ -716,6 +804,8 @@ type RemoveOptions struct {
 	DurabilityLevel DurabilityLevel
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 // Remove removes a document from the collection.
@@ -724,12 +814,13 @@ func (c *Collection) Remove(id string, opts *RemoveOptions) (mutOut *MutationRes
 		opts = &RemoveOptions{}
 	}
 
-	opm := c.newKvOpManager("Remove", nil)
+	opm := c.newKvOpManager("Remove", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -748,7 +839,7 @@ func (c *Collection) Remove(id string, opts *RemoveOptions) (mutOut *MutationRes
 		DurabilityLevel:        opm.DurabilityLevel(),
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.DeleteResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -758,6 +849,7 @@ func (c *Collection) Remove(id string, opts *RemoveOptions) (mutOut *MutationRes
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
@@ -773,6 +865,8 @@ type GetAndTouchOptions struct {
 	Transcoder    Transcoder
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // GetAndTouch retrieves a document and simultaneously updates its expiry time.
@@ -781,12 +875,13 @@ func (c *Collection) GetAndTouch(id string, expiry time.Duration, opts *GetAndTo
 		opts = &GetAndTouchOptions{}
 	}
 
-	opm := c.newKvOpManager("GetAndTouch", nil)
+	opm := c.newKvOpManager("GetAndTouch", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetTranscoder(opts.Transcoder)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -803,7 +898,7 @@ func (c *Collection) GetAndTouch(id string, expiry time.Duration, opts *GetAndTo
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan(),
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.GetAndTouchResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -814,11 +909,13 @@ func (c *Collection) GetAndTouch(id string, expiry time.Duration, opts *GetAndTo
 		if res != nil {
 			doc := &GetResult{
 				Result: Result{
-					cas: Cas(res.Cas),
+					cas:            Cas(res.Cas),
+					serverDuration: opm.ServerDuration(),
 				},
 				transcoder: opm.Transcoder(),
 				contents:   res.Value,
 				flags:      res.Flags,
+				datatype:   DocumentDatatype(res.Datatype),
 			}
 
 			docOut = doc
@@ -837,6 +934,8 @@ type GetAndLockOptions struct {
 	Transcoder    Transcoder
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // GetAndLock locks a document for a period of time, providing exclusive RW access to it.
@@ -847,12 +946,13 @@ func (c *Collection) GetAndLock(id string, lockTime time.Duration, opts *GetAndL
 		opts = &GetAndLockOptions{}
 	}
 
-	opm := c.newKvOpManager("GetAndLock", nil)
+	opm := c.newKvOpManager("GetAndLock", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetTranscoder(opts.Transcoder)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -869,7 +969,7 @@ func (c *Collection) GetAndLock(id string, lockTime time.Duration, opts *GetAndL
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan(),
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.GetAndLockResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -880,11 +980,13 @@ func (c *Collection) GetAndLock(id string, lockTime time.Duration, opts *GetAndL
 		if res != nil {
 			doc := &GetResult{
 				Result: Result{
-					cas: Cas(res.Cas),
+					cas:            Cas(res.Cas),
+					serverDuration: opm.ServerDuration(),
 				},
 				transcoder: opm.Transcoder(),
 				contents:   res.Value,
 				flags:      res.Flags,
+				datatype:   DocumentDatatype(res.Datatype),
 			}
 
 			docOut = doc
@@ -902,6 +1004,8 @@ func (c *Collection) GetAndLock(id string, lockTime time.Duration, opts *GetAndL
 type UnlockOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // Unlock unlocks a document which was locked with GetAndLock.
@@ -910,11 +1014,12 @@ func (c *Collection) Unlock(id string, cas Cas, opts *UnlockOptions) (errOut err
 		opts = &UnlockOptions{}
 	}
 
-	opm := c.newKvOpManager("Unlock", nil)
+	opm := c.newKvOpManager("Unlock", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -931,7 +1036,7 @@ func (c *Collection) Unlock(id string, cas Cas, opts *UnlockOptions) (errOut err
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan(),
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.UnlockResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -952,6 +1057,8 @@ func (c *Collection) Unlock(id string, cas Cas, opts *UnlockOptions) (errOut err
 type TouchOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // Touch touches a document, specifying a new expiry time for it.
@@ -960,11 +1067,12 @@ func (c *Collection) Touch(id string, expiry time.Duration, opts *TouchOptions)
 		opts = &TouchOptions{}
 	}
 
-	opm := c.newKvOpManager("Touch", nil)
+	opm := c.newKvOpManager("Touch", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -981,7 +1089,7 @@ func (c *Collection) Touch(id string, expiry time.Duration, opts *TouchOptions)
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan(),
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.TouchResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
@@ -991,6 +1099,7 @@ func (c *Collection) Touch(id string, expiry time.Duration, opts *TouchOptions)
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
@@ -1440,3 +1440,49 @@ func TestGetErrorCollectionUnknown(t *testing.T) {
 		t.Fatalf("Error should have been collection missing but was %v", err)
 	}
 }
+
+func TestGetReplica(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   5,
+		value: []byte(`{"name":"active"}`),
+	}
+	col := testGetCollection(t, provider)
+
+	res, err := col.GetReplica("getReplicaDoc", 0, nil)
+	if err != nil {
+		t.Fatalf("GetReplica failed: %v", err)
+	}
+
+	if res.IsReplica() {
+		t.Fatalf("Expected replica index 0 to read the active copy")
+	}
+	if res.Cas() != Cas(5) {
+		t.Fatalf("Expected cas 5 but was %d", res.Cas())
+	}
+
+	_, err = col.GetReplica("getReplicaDoc", 1, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error for a replica which does not exist but was %v", err)
+	}
+
+	_, err = col.GetReplica("getReplicaDoc", -1, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error for a negative replica index but was %v", err)
+	}
+
+	_, err = col.GetReplica("", 0, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error for an empty document ID but was %v", err)
+	}
+
+	// The number of replicas is not known before the config arrives, so the index must not
+	// be checked against it up front.
+	provider.numReplicas = 2
+	res, err = col.GetReplica("getReplicaDoc", 2, nil)
+	if err != nil {
+		t.Fatalf("GetReplica failed: %v", err)
+	}
+	if !res.IsReplica() {
+		t.Fatalf("Expected replica index 2 to read a replica")
+	}
+}
@@ -3,6 +3,7 @@ package gocb
 import (
 	"errors"
 	"fmt"
+	"math/rand"
 )
 
 // CouchbaseList represents a list document.
@@ -105,6 +106,16 @@ func (cl *CouchbaseList) IndexOf(val interface{}) (int, error) {
 	return -1, nil
 }
 
+// Contains verifies whether or not a value exists within the list.
+func (cl *CouchbaseList) Contains(val interface{}) (bool, error) {
+	index, err := cl.IndexOf(val)
+	if err != nil {
+		return false, err
+	}
+
+	return index >= 0, nil
+}
+
 // Size returns the size of the list.
 func (cl *CouchbaseList) Size() (int, error) {
 	ops := make([]LookupInSpec, 1)
@@ -474,3 +485,99 @@ func (cs *CouchbaseQueue) Clear() error {
 
 	return nil
 }
+
+// CouchbaseCounter represents a counter document.  A sharded counter spreads its value across a
+// number of documents, each of which holds part of the total, so that extremely hot counters do
+// not contend on a single document.  The parts are aggregated when the counter is read.
+type CouchbaseCounter struct {
+	collection *Collection
+	id         string
+	shards     int
+}
+
+// Counter returns a new CouchbaseCounter for the document specified by id.
+func (c *Collection) Counter(id string) *CouchbaseCounter {
+	return c.ShardedCounter(id, 1)
+}
+
+// ShardedCounter returns a new CouchbaseCounter whose value is spread across the specified number
+// of documents, named by suffixing id with the index of each shard.  The same number of shards must
+// be used every time that the counter is accessed.
+func (c *Collection) ShardedCounter(id string, shards int) *CouchbaseCounter {
+	if shards < 1 {
+		shards = 1
+	}
+
+	return &CouchbaseCounter{
+		collection: c,
+		id:         id,
+		shards:     shards,
+	}
+}
+
+func (cc *CouchbaseCounter) shardID(index int) string {
+	if cc.shards == 1 {
+		return cc.id
+	}
+
+	return fmt.Sprintf("%s::%d", cc.id, index)
+}
+
+// Increment adds delta to the counter, creating it if it does not exist.
+func (cc *CouchbaseCounter) Increment(delta int64) error {
+	ops := make([]MutateInSpec, 1)
+	ops[0] = IncrementSpec("count", delta, &CounterSpecOptions{CreatePath: true})
+	_, err := cc.collection.MutateIn(cc.shardID(rand.Intn(cc.shards)), ops, &MutateInOptions{StoreSemantic: StoreSemanticsUpsert})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Decrement subtracts delta from the counter, creating it if it does not exist.  The value of the
+// counter may become negative.
+func (cc *CouchbaseCounter) Decrement(delta int64) error {
+	return cc.Increment(-delta)
+}
+
+// Get returns the current value of the counter, which is zero if it does not exist.
+func (cc *CouchbaseCounter) Get() (int64, error) {
+	var total int64
+	for i := 0; i < cc.shards; i++ {
+		ops := make([]LookupInSpec, 1)
+		ops[0] = GetSpec("count", nil)
+		result, err := cc.collection.LookupIn(cc.shardID(i), ops, nil)
+		if errors.Is(err, ErrDocumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		var count int64
+		err = result.ContentAt(0, &count)
+		if errors.Is(err, ErrPathNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		total += count
+	}
+
+	return total, nil
+}
+
+// Clear clears a counter, also removing it.
+func (cc *CouchbaseCounter) Clear() error {
+	for i := 0; i < cc.shards; i++ {
+		_, err := cc.collection.Remove(cc.shardID(i), nil)
+		if err != nil && !errors.Is(err, ErrDocumentNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
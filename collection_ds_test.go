@@ -39,6 +39,24 @@ func TestListCrud(t *testing.T) {
 		t.Fatalf("Expected list index to be 2 but was %d", size)
 	}
 
+	contains, err := list.Contains("test2")
+	if err != nil {
+		t.Fatalf("Failed to check list contents %v", err)
+	}
+
+	if !contains {
+		t.Fatalf("Expected list to contain test2")
+	}
+
+	contains, err = list.Contains("test5")
+	if err != nil {
+		t.Fatalf("Failed to check list contents %v", err)
+	}
+
+	if contains {
+		t.Fatalf("Expected list to not contain test5")
+	}
+
 	var index2 string
 	err = list.At(2, &index2)
 	if err != nil {
@@ -386,3 +404,57 @@ func TestMapCrud(t *testing.T) {
 		t.Fatalf("Failed to clear map %v", err)
 	}
 }
+
+func TestCounterCrud(t *testing.T) {
+	testCounterCrud(t, globalCollection.Counter("testCounter"))
+}
+
+func TestShardedCounterCrud(t *testing.T) {
+	testCounterCrud(t, globalCollection.ShardedCounter("testShardedCounter", 4))
+}
+
+func testCounterCrud(t *testing.T, counter *CouchbaseCounter) {
+	value, err := counter.Get()
+	if err != nil {
+		t.Fatalf("Failed to get counter %v", err)
+	}
+
+	if value != 0 {
+		t.Fatalf("Expected missing counter to be 0 but was %d", value)
+	}
+
+	for i := 0; i < 10; i++ {
+		err = counter.Increment(5)
+		if err != nil {
+			t.Fatalf("Failed to increment counter %v", err)
+		}
+	}
+
+	err = counter.Decrement(60)
+	if err != nil {
+		t.Fatalf("Failed to decrement counter %v", err)
+	}
+
+	value, err = counter.Get()
+	if err != nil {
+		t.Fatalf("Failed to get counter %v", err)
+	}
+
+	if value != -10 {
+		t.Fatalf("Expected counter to be -10 but was %d", value)
+	}
+
+	err = counter.Clear()
+	if err != nil {
+		t.Fatalf("Failed to clear counter %v", err)
+	}
+
+	value, err = counter.Get()
+	if err != nil {
+		t.Fatalf("Failed to get counter %v", err)
+	}
+
+	if value != 0 {
+		t.Fatalf("Expected cleared counter to be 0 but was %d", value)
+	}
+}
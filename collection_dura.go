@@ -7,18 +7,24 @@ import (
 )
 
 func (c *Collection) observeOnceSeqNo(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	docID string,
 	mt gocbcore.MutationToken,
 	replicaIdx int,
+	deadline time.Time,
 	cancelCh chan struct{},
 ) (didReplicate, didPersist bool, errOut error) {
 	opm := c.newKvOpManager("observeOnceSeqNo", tracectx)
 	defer opm.Finish()
 
 	opm.SetDocumentID(docID)
+	opm.SetDeadline(deadline)
 	opm.SetCancelCh(cancelCh)
 
+	if err := opm.CheckReadyForOp(); err != nil {
+		return false, false, err
+	}
+
 	agent, err := c.getKvProvider()
 	if err != nil {
 		return false, false, err
@@ -27,7 +33,7 @@ func (c *Collection) observeOnceSeqNo(
 		VbID:         mt.VbID,
 		VbUUID:       mt.VbUUID,
 		ReplicaIdx:   replicaIdx,
-		TraceContext: opm.TraceSpan(),
+		TraceContext: opm.TraceContext(),
 	}, func(res *gocbcore.ObserveVbResult, err error) {
 		if err != nil || res == nil {
 			errOut = opm.EnhanceErr(err)
@@ -46,11 +52,12 @@ func (c *Collection) observeOnceSeqNo(
 }
 
 func (c *Collection) observeOne(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	docID string,
 	mt gocbcore.MutationToken,
 	replicaIdx int,
 	replicaCh, persistCh chan struct{},
+	deadline time.Time,
 	cancelCh chan struct{},
 ) {
 	sentReplicated := false
@@ -65,7 +72,7 @@ ObserveLoop:
 			// not cancelled yet
 		}
 
-		didReplicate, didPersist, err := c.observeOnceSeqNo(tracectx, docID, mt, replicaIdx, cancelCh)
+		didReplicate, didPersist, err := c.observeOnceSeqNo(tracectx, docID, mt, replicaIdx, deadline, cancelCh)
 		if err != nil {
 			logDebugf("ObserveOnce failed unexpected: %s", err)
 			return
@@ -86,18 +93,24 @@ ObserveLoop:
 			break ObserveLoop
 		}
 
-		waitTmr := gocbcore.AcquireTimer(c.sb.DuraPollTimeout)
+		// Never poll beyond the deadline of the durability wait as a whole.
+		pollInterval := c.sb.DuraPollTimeout
+		if remaining := deadline.Sub(clockOrSystem(c.sb.Clock).Now()); remaining < pollInterval {
+			if remaining <= 0 {
+				break ObserveLoop
+			}
+			pollInterval = remaining
+		}
+
 		select {
-		case <-waitTmr.C:
-			gocbcore.ReleaseTimer(waitTmr, true)
+		case <-clockOrSystem(c.sb.Clock).After(pollInterval):
 		case <-cancelCh:
-			gocbcore.ReleaseTimer(waitTmr, false)
 		}
 	}
 }
 
 func (c *Collection) waitForDurability(
-	tracectx requestSpanContext,
+	tracectx RequestSpanContext,
 	docID string,
 	mt gocbcore.MutationToken,
 	replicateTo uint,
@@ -125,11 +138,12 @@ func (c *Collection) waitForDurability(
 	persistCh := make(chan struct{}, numServers)
 
 	for replicaIdx := 0; replicaIdx < numServers; replicaIdx++ {
-		go c.observeOne(opm.TraceSpan(), docID, mt, replicaIdx, replicaCh, persistCh, subOpCancelCh)
+		go c.observeOne(opm.TraceSpan().Context(), docID, mt, replicaIdx, replicaCh, persistCh, deadline, subOpCancelCh)
 	}
 
 	numReplicated := uint(0)
 	numPersisted := uint(0)
+	clk := clockOrSystem(c.sb.Clock)
 
 	for {
 		select {
@@ -137,7 +151,7 @@ func (c *Collection) waitForDurability(
 			numReplicated++
 		case <-persistCh:
 			numPersisted++
-		case <-time.After(deadline.Sub(time.Now())):
+		case <-clk.After(deadline.Sub(clk.Now())):
 			// deadline exceeded
 			close(subOpCancelCh)
 			return opm.EnhanceErr(ErrAmbiguousTimeout)
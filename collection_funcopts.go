@@ -0,0 +1,236 @@
+package gocb
+
+import (
+	"time"
+)
+
+type operationOptionField uint32
+
+const (
+	operationOptionExpiry operationOptionField = 1 << iota
+	operationOptionCas
+	operationOptionDurability
+	operationOptionTranscoder
+	operationOptionFetchExpiry
+	operationOptionProject
+)
+
+// OperationOptions holds the values set by OperationOption functions.
+type OperationOptions struct {
+	set operationOptionField
+
+	expiry          time.Duration
+	cas             Cas
+	persistTo       uint
+	replicateTo     uint
+	durabilityLevel DurabilityLevel
+	transcoder      Transcoder
+	project         []string
+	timeout         time.Duration
+	retryStrategy   RetryStrategy
+	tags            map[string]string
+}
+
+// OperationOption sets an option of an operation, as an alternative to passing an options struct.
+// Options compose more easily than structs for libraries which wrap the SDK, for instance:
+//
+//	res, err := collection.UpsertWith("id", doc, gocb.WithExpiry(time.Hour), gocb.WithDurability(gocb.DurabilityLevelMajority))
+//
+// UNCOMMITTED: This API may change in the future.
+type OperationOption func(opts *OperationOptions)
+
+// WithExpiry sets the expiry of the document written by a mutation.
+func WithExpiry(expiry time.Duration) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.set |= operationOptionExpiry
+		opts.expiry = expiry
+	}
+}
+
+// WithCas sets the CAS which the document must have for a Replace or Remove to succeed.
+func WithCas(cas Cas) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.set |= operationOptionCas
+		opts.cas = cas
+	}
+}
+
+// WithDurability sets the durability level of a mutation.
+func WithDurability(level DurabilityLevel) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.set |= operationOptionDurability
+		opts.durabilityLevel = level
+	}
+}
+
+// WithObserveDurability sets the number of nodes that a mutation must be persisted to and
+// replicated to before it is considered successful.
+func WithObserveDurability(persistTo, replicateTo uint) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.set |= operationOptionDurability
+		opts.persistTo = persistTo
+		opts.replicateTo = replicateTo
+	}
+}
+
+// WithTranscoder sets the transcoder used to encode or decode the document.
+func WithTranscoder(transcoder Transcoder) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.set |= operationOptionTranscoder
+		opts.transcoder = transcoder
+	}
+}
+
+// WithFetchExpiry causes a Get to also fetch the expiry of the document.
+func WithFetchExpiry() OperationOption {
+	return func(opts *OperationOptions) {
+		opts.set |= operationOptionFetchExpiry
+	}
+}
+
+// WithProject causes a Get to only fetch the fields indicated by the paths.
+func WithProject(paths ...string) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.set |= operationOptionProject
+		opts.project = paths
+	}
+}
+
+// WithTimeout sets the timeout of an operation.
+func WithTimeout(timeout time.Duration) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.timeout = timeout
+	}
+}
+
+// WithRetryStrategy sets the retry strategy of an operation.
+func WithRetryStrategy(strategy RetryStrategy) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.retryStrategy = strategy
+	}
+}
+
+// WithTags sets tags which are attached to the tracing span of an operation.
+func WithTags(tags map[string]string) OperationOption {
+	return func(opts *OperationOptions) {
+		opts.tags = tags
+	}
+}
+
+func applyOperationOptions(op string, allowed operationOptionField, options []OperationOption) (*OperationOptions, error) {
+	opts := &OperationOptions{}
+	for _, option := range options {
+		if option != nil {
+			option(opts)
+		}
+	}
+
+	// Timeout, retry strategy and tags apply to every operation so are not tracked.
+	if opts.set&^allowed != 0 {
+		return nil, makeInvalidArgumentsError("an option was passed which does not apply to " + op)
+	}
+
+	return opts, nil
+}
+
+// InsertWith is the same as Insert, taking OperationOption values instead of InsertOptions.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) InsertWith(id string, val interface{}, options ...OperationOption) (*MutationResult, error) {
+	opts, err := applyOperationOptions("Insert",
+		operationOptionExpiry|operationOptionDurability|operationOptionTranscoder, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Insert(id, val, &InsertOptions{
+		Expiry:          opts.expiry,
+		PersistTo:       opts.persistTo,
+		ReplicateTo:     opts.replicateTo,
+		DurabilityLevel: opts.durabilityLevel,
+		Transcoder:      opts.transcoder,
+		Timeout:         opts.timeout,
+		RetryStrategy:   opts.retryStrategy,
+		Tags:            opts.tags,
+	})
+}
+
+// UpsertWith is the same as Upsert, taking OperationOption values instead of UpsertOptions.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) UpsertWith(id string, val interface{}, options ...OperationOption) (*MutationResult, error) {
+	opts, err := applyOperationOptions("Upsert",
+		operationOptionExpiry|operationOptionDurability|operationOptionTranscoder, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Upsert(id, val, &UpsertOptions{
+		Expiry:          opts.expiry,
+		PersistTo:       opts.persistTo,
+		ReplicateTo:     opts.replicateTo,
+		DurabilityLevel: opts.durabilityLevel,
+		Transcoder:      opts.transcoder,
+		Timeout:         opts.timeout,
+		RetryStrategy:   opts.retryStrategy,
+		Tags:            opts.tags,
+	})
+}
+
+// ReplaceWith is the same as Replace, taking OperationOption values instead of ReplaceOptions.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) ReplaceWith(id string, val interface{}, options ...OperationOption) (*MutationResult, error) {
+	opts, err := applyOperationOptions("Replace",
+		operationOptionExpiry|operationOptionCas|operationOptionDurability|operationOptionTranscoder, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Replace(id, val, &ReplaceOptions{
+		Expiry:          opts.expiry,
+		Cas:             opts.cas,
+		PersistTo:       opts.persistTo,
+		ReplicateTo:     opts.replicateTo,
+		DurabilityLevel: opts.durabilityLevel,
+		Transcoder:      opts.transcoder,
+		Timeout:         opts.timeout,
+		RetryStrategy:   opts.retryStrategy,
+		Tags:            opts.tags,
+	})
+}
+
+// GetWith is the same as Get, taking OperationOption values instead of GetOptions.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) GetWith(id string, options ...OperationOption) (*GetResult, error) {
+	opts, err := applyOperationOptions("Get",
+		operationOptionTranscoder|operationOptionFetchExpiry|operationOptionProject, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Get(id, &GetOptions{
+		WithExpiry:    opts.set&operationOptionFetchExpiry != 0,
+		Project:       opts.project,
+		Transcoder:    opts.transcoder,
+		Timeout:       opts.timeout,
+		RetryStrategy: opts.retryStrategy,
+		Tags:          opts.tags,
+	})
+}
+
+// RemoveWith is the same as Remove, taking OperationOption values instead of RemoveOptions.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) RemoveWith(id string, options ...OperationOption) (*MutationResult, error) {
+	opts, err := applyOperationOptions("Remove", operationOptionCas|operationOptionDurability, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Remove(id, &RemoveOptions{
+		Cas:             opts.cas,
+		PersistTo:       opts.persistTo,
+		ReplicateTo:     opts.replicateTo,
+		DurabilityLevel: opts.durabilityLevel,
+		Timeout:         opts.timeout,
+		RetryStrategy:   opts.retryStrategy,
+		Tags:            opts.tags,
+	})
+}
@@ -0,0 +1,57 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestApplyOperationOptions(t *testing.T) {
+	tags := map[string]string{"a": "b"}
+	opts, err := applyOperationOptions("Replace",
+		operationOptionExpiry|operationOptionCas|operationOptionDurability, []OperationOption{
+			WithExpiry(time.Minute),
+			WithCas(5),
+			WithDurability(DurabilityLevelMajority),
+			WithTimeout(time.Second),
+			WithTags(tags),
+			nil,
+		})
+	if err != nil {
+		t.Fatalf("Failed to apply options: %v", err)
+	}
+
+	if opts.expiry != time.Minute || opts.cas != 5 || opts.durabilityLevel != DurabilityLevelMajority ||
+		opts.timeout != time.Second || opts.tags["a"] != "b" {
+		t.Fatalf("Unexpected options %+v", opts)
+	}
+}
+
+func TestOperationOptionsNotApplicable(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	_, err := col.RemoveWith("id", WithExpiry(time.Minute))
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+
+	_, err = col.UpsertWith("id", "value", WithCas(1))
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+}
+
+func TestUpsertWith(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{cas: gocbcore.Cas(7)})
+
+	res, err := col.UpsertWith("id", "value", WithExpiry(time.Minute), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("UpsertWith failed: %v", err)
+	}
+
+	if res.Cas() != 7 {
+		t.Fatalf("Unexpected cas %d", res.Cas())
+	}
+}
@@ -0,0 +1,195 @@
+package gocb
+
+import (
+	"encoding/base64"
+	"time"
+)
+
+// ScanKeysOptions are the options available to the ScanKeys operation.
+type ScanKeysOptions struct {
+	// PageSize is the number of keys fetched from the server in each request.  The default is 1000.
+	PageSize uint32
+
+	// ResumeToken, if set, resumes a scan from the point at which a previous scan returned the token.
+	ResumeToken string
+
+	ScanConsistency QueryScanConsistency
+
+	// Timeout is the length of time that each page may take to be fetched.
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// KeyScanResult iterates over the document IDs in a collection, in ascending order.
+// UNCOMMITTED: This API may change in the future.
+type KeyScanResult struct {
+	fetchPage func(after string, limit uint32) ([]string, error)
+	pageSize  uint32
+
+	after   string
+	page    []string
+	current string
+	done    bool
+	err     error
+}
+
+// ScanKeys iterates over the IDs of every document in the collection, fetching them from the
+// server a page at a time.  The scan uses a N1QL query covered by the primary index of the
+// collection, so the primary index must exist.  Documents which are created or removed whilst
+// the scan is running may or may not be returned.  Servers which support range scans can return
+// the keys directly from the data service, without an index, but gocbcore does not yet support
+// range scans, so the query service is always used.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) ScanKeys(opts *ScanKeysOptions) (*KeyScanResult, error) {
+	if opts == nil {
+		opts = &ScanKeysOptions{}
+	}
+
+	after, err := decodeKeyScanToken(opts.ResumeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	scanOpts := *opts
+	return newKeyScanResult(func(after string, limit uint32) ([]string, error) {
+		return c.scanKeysPage(after, limit, &scanOpts)
+	}, after, opts.PageSize), nil
+}
+
+func newKeyScanResult(fetchPage func(after string, limit uint32) ([]string, error), after string,
+	pageSize uint32) *KeyScanResult {
+	if pageSize == 0 {
+		pageSize = 1000
+	}
+
+	return &KeyScanResult{
+		fetchPage: fetchPage,
+		pageSize:  pageSize,
+		after:     after,
+	}
+}
+
+// Next moves to the next document ID, fetching the next page from the server if required,
+// returning false once every ID has been returned or an error occurs.
+func (r *KeyScanResult) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	if len(r.page) == 0 {
+		if r.done {
+			return false
+		}
+
+		page, err := r.fetchPage(r.after, r.pageSize)
+		if err != nil {
+			r.err = err
+			return false
+		}
+
+		// A short page means that we have reached the end of the collection.
+		r.done = uint32(len(page)) < r.pageSize
+		r.page = page
+		if len(page) == 0 {
+			return false
+		}
+	}
+
+	r.current = r.page[0]
+	r.page = r.page[1:]
+	r.after = r.current
+	return true
+}
+
+// Key returns the document ID returned by the last call to Next.
+func (r *KeyScanResult) Key() string {
+	return r.current
+}
+
+// Err returns any error which occurred during the scan.
+func (r *KeyScanResult) Err() error {
+	return r.err
+}
+
+// ResumeToken returns a token which can be passed as ScanKeysOptions.ResumeToken to resume the
+// scan immediately after the ID returned by the last call to Next.
+func (r *KeyScanResult) ResumeToken() string {
+	return encodeKeyScanToken(r.after)
+}
+
+// encodeKeyScanToken returns the resume token for a scan which continues after the ID given.
+func encodeKeyScanToken(after string) string {
+	if after == "" {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(after))
+}
+
+func decodeKeyScanToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", makeInvalidArgumentsError("resume token is invalid")
+	}
+
+	return string(after), nil
+}
+
+func (c *Collection) keyspace() string {
+	// The default collection can be addressed by the bucket name on servers with and without
+	// collections support.
+	if (c.scopeName() == "" || c.scopeName() == "_default") &&
+		(c.name() == "" || c.name() == "_default") {
+		return QueryIdentifier(c.sb.BucketName)
+	}
+
+	return QueryIdentifier(c.sb.BucketName, c.scopeName(), c.name())
+}
+
+func (c *Collection) scanKeysPage(after string, limit uint32, opts *ScanKeysOptions) ([]string, error) {
+	statement := "SELECT RAW META().id FROM " + c.keyspace() + " WHERE META().id > $1 ORDER BY META().id LIMIT $2"
+
+	span := applyOperationTags(c.sb.Tracer.StartSpan("ScanKeys", nil).
+		SetTag("couchbase.service", "query"), opts.Tags)
+	defer span.Finish()
+
+	// The keyspace is fully qualified, so the statement is run without a query context.
+	result, err := c.cluster.query(statement, "", &QueryOptions{
+		ScanConsistency:      opts.ScanConsistency,
+		PositionalParameters: []interface{}{after, limit},
+		Adhoc:                true,
+		Timeout:              opts.Timeout,
+		RetryStrategy:        opts.RetryStrategy,
+		ParentSpan:           span.Context(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for result.Next() {
+		var key string
+		err := result.Row(&key)
+		if err != nil {
+			closeErr := result.Close()
+			if closeErr != nil {
+				logDebugf("Failed to close key scan results (%s)", closeErr)
+			}
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	err = result.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,133 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKeyScanResultPages(t *testing.T) {
+	var keys []string
+	for i := 0; i < 5; i++ {
+		keys = append(keys, fmt.Sprintf("key-%d", i))
+	}
+
+	var requests []string
+	fetchPage := func(after string, limit uint32) ([]string, error) {
+		requests = append(requests, after)
+
+		var page []string
+		for _, key := range keys {
+			if key > after && uint32(len(page)) < limit {
+				page = append(page, key)
+			}
+		}
+		return page, nil
+	}
+
+	res := newKeyScanResult(fetchPage, "", 2)
+	var scanned []string
+	var token string
+	for res.Next() {
+		scanned = append(scanned, res.Key())
+		if res.Key() == "key-2" {
+			token = res.ResumeToken()
+		}
+	}
+	if res.Err() != nil {
+		t.Fatalf("Scan failed: %v", res.Err())
+	}
+
+	if len(scanned) != 5 {
+		t.Fatalf("Expected 5 keys but was %v", scanned)
+	}
+
+	// The final page is short, so no further request is required.
+	if len(requests) != 3 || requests[1] != "key-1" || requests[2] != "key-3" {
+		t.Fatalf("Unexpected page requests %v", requests)
+	}
+
+	after, err := decodeKeyScanToken(token)
+	if err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+
+	res = newKeyScanResult(fetchPage, after, 2)
+	scanned = nil
+	for res.Next() {
+		scanned = append(scanned, res.Key())
+	}
+	if len(scanned) != 2 || scanned[0] != "key-3" {
+		t.Fatalf("Expected scan to resume after key-2 but was %v", scanned)
+	}
+}
+
+func TestKeyScanResultError(t *testing.T) {
+	res := newKeyScanResult(func(after string, limit uint32) ([]string, error) {
+		return nil, ErrIndexNotFound
+	}, "", 0)
+
+	if res.Next() {
+		t.Fatalf("Expected scan to fail")
+	}
+	if !errors.Is(res.Err(), ErrIndexNotFound) {
+		t.Fatalf("Expected index not found error but was %v", res.Err())
+	}
+}
+
+func TestScanKeysInvalidToken(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	_, err := col.ScanKeys(&ScanKeysOptions{ResumeToken: "!!"})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestScanKeysStatement(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	col := testGetCollection(t, &mockKvProvider{})
+	col.sb.getCachedClient().(*mockClient).mockQueryProvider = provider
+
+	res, err := col.ScanKeys(&ScanKeysOptions{PageSize: 10})
+	if err != nil {
+		t.Fatalf("ScanKeys failed: %v", err)
+	}
+	if res.Next() {
+		t.Fatalf("Expected scan to fail")
+	}
+
+	if len(provider.payloads) != 1 {
+		t.Fatalf("Expected 1 request but was %d", len(provider.payloads))
+	}
+
+	var payload map[string]interface{}
+	err = json.Unmarshal(provider.payloads[0], &payload)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	expected := "SELECT RAW META().id FROM `mock` WHERE META().id > $1 ORDER BY META().id LIMIT $2"
+	if payload["statement"] != expected {
+		t.Fatalf("Unexpected statement %v", payload["statement"])
+	}
+
+	args, ok := payload["args"].([]interface{})
+	if !ok || len(args) != 2 || args[0] != "" || args[1] != float64(10) {
+		t.Fatalf("Unexpected args %v", payload["args"])
+	}
+	if _, ok := payload["query_context"]; ok {
+		t.Fatalf("Expected no query context for a fully qualified keyspace")
+	}
+}
+
+func TestCollectionKeyspace(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+	col.sb.ScopeName = "inventory"
+	col.sb.CollectionName = "air`line"
+
+	if col.keyspace() != "`mock`.`inventory`.`air``line`" {
+		t.Fatalf("Unexpected keyspace %s", col.keyspace())
+	}
+}
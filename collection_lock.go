@@ -0,0 +1,88 @@
+package gocb
+
+import (
+	"time"
+)
+
+// WithLockOptions are the options available to the WithLock operation.
+type WithLockOptions struct {
+	// Expiry is the expiry to set on the document when it is replaced.  A value of 0 will set the
+	// document to never expire.
+	Expiry          time.Duration
+	PersistTo       uint
+	ReplicateTo     uint
+	DurabilityLevel DurabilityLevel
+	Transcoder      Transcoder
+	Timeout         time.Duration
+	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+}
+
+// WithLock locks a document for lockTime, passes it to fn and replaces the document with the value
+// which fn returns, releasing the lock.  If fn returns an error, or panics, or the replace fails
+// then the document is unlocked and the document is left unchanged.
+//
+// As with GetAndLock, a lockTime value of over 30 seconds will be treated as 30 seconds.  If fn
+// takes longer than lockTime to complete then the lock will have expired and the replace will fail
+// with ErrCasMismatch if the document has been modified in the meantime.
+func (c *Collection) WithLock(id string, lockTime time.Duration, fn func(doc *GetResult) (interface{}, error),
+	opts *WithLockOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &WithLockOptions{}
+	}
+
+	if fn == nil {
+		return nil, makeInvalidArgumentsError("fn cannot be nil")
+	}
+
+	doc, err := c.GetAndLock(id, lockTime, &GetAndLockOptions{
+		Transcoder:    opts.Transcoder,
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock is released by a successful replace, in every other case including fn panicking
+	// we must release it ourselves.
+	released := false
+	defer func() {
+		if released {
+			return
+		}
+
+		err := c.Unlock(id, doc.Cas(), &UnlockOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			Tags:          opts.Tags,
+		})
+		if err != nil {
+			logDebugf("Failed to unlock document (%s)", err)
+		}
+	}()
+
+	value, err := fn(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.Replace(id, value, &ReplaceOptions{
+		Expiry:          opts.Expiry,
+		Cas:             doc.Cas(),
+		PersistTo:       opts.PersistTo,
+		ReplicateTo:     opts.ReplicateTo,
+		DurabilityLevel: opts.DurabilityLevel,
+		Transcoder:      opts.Transcoder,
+		Timeout:         opts.Timeout,
+		RetryStrategy:   opts.RetryStrategy,
+		Tags:            opts.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	released = true
+	return res, nil
+}
@@ -0,0 +1,109 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithLock(t *testing.T) {
+	mutRes, err := globalCollection.Upsert("withLock", map[string]interface{}{"count": 1}, nil)
+	if err != nil {
+		t.Fatalf("Upsert failed, error was %v", err)
+	}
+
+	res, err := globalCollection.WithLock("withLock", 10*time.Second, func(doc *GetResult) (interface{}, error) {
+		var content map[string]int
+		err := doc.Content(&content)
+		if err != nil {
+			return nil, err
+		}
+
+		content["count"]++
+		return content, nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("WithLock failed, error was %v", err)
+	}
+
+	if res.Cas() == 0 || res.Cas() == mutRes.Cas() {
+		t.Fatalf("Expected WithLock to return a new CAS but was %d", res.Cas())
+	}
+
+	getRes, err := globalCollection.Get("withLock", nil)
+	if err != nil {
+		t.Fatalf("Get failed, error was %v", err)
+	}
+
+	var content map[string]int
+	err = getRes.Content(&content)
+	if err != nil {
+		t.Fatalf("Content failed, error was %v", err)
+	}
+
+	if content["count"] != 2 {
+		t.Fatalf("Expected count to be 2 but was %d", content["count"])
+	}
+
+	// The document should have been unlocked, so we can write to it straight away.
+	_, err = globalCollection.Upsert("withLock", content, &UpsertOptions{
+		RetryStrategy: newFailFastRetryStrategy(),
+	})
+	if err != nil {
+		t.Fatalf("Upsert failed, error was %v", err)
+	}
+}
+
+func TestWithLockUnlocksOnError(t *testing.T) {
+	_, err := globalCollection.Upsert("withLockError", map[string]interface{}{"count": 1}, nil)
+	if err != nil {
+		t.Fatalf("Upsert failed, error was %v", err)
+	}
+
+	fnErr := errors.New("callback failed")
+	_, err = globalCollection.WithLock("withLockError", 10*time.Second, func(doc *GetResult) (interface{}, error) {
+		return nil, fnErr
+	}, nil)
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("Expected WithLock to return callback error but was %v", err)
+	}
+
+	_, err = globalCollection.Upsert("withLockError", map[string]interface{}{"count": 2}, &UpsertOptions{
+		RetryStrategy: newFailFastRetryStrategy(),
+	})
+	if err != nil {
+		t.Fatalf("Expected document to be unlocked but Upsert failed, error was %v", err)
+	}
+}
+
+func TestWithLockCallbackError(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"count":1}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	var lockedCas Cas
+	fnErr := errors.New("callback failed")
+	_, err := col.WithLock("withLock", 10*time.Second, func(doc *GetResult) (interface{}, error) {
+		lockedCas = doc.Cas()
+		return nil, fnErr
+	}, nil)
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("Expected WithLock to return callback error but was %v", err)
+	}
+
+	if lockedCas != 10 {
+		t.Fatalf("Expected callback to receive locked document with cas 10 but was %d", lockedCas)
+	}
+}
+
+func TestWithLockNilCallback(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	_, err := col.WithLock("withLock", 10*time.Second, nil, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
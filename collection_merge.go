@@ -0,0 +1,178 @@
+package gocb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeOptions are the set of options available to Merge.
+type MergeOptions struct {
+	Expiry          time.Duration
+	PersistTo       uint
+	ReplicateTo     uint
+	DurabilityLevel DurabilityLevel
+	Timeout         time.Duration
+	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+}
+
+type mergePatchUpsert struct {
+	path  string
+	value interface{}
+}
+
+// Merge applies patch to the document specified by id following the semantics of a JSON merge
+// patch (RFC 7386), without needing to read and replace the whole document.  Members of the patch
+// are set on the document, members which are null are removed from it, and nested objects are
+// merged recursively.  The document is created if it does not exist.
+//
+// Unlike RFC 7386, merging a nested object into a member which exists but is not itself an object
+// fails with ErrPathMismatch rather than replacing the member, and empty nested objects are ignored.
+// As the patch is applied using a single sub-document operation it may contain at most 16 changes.
+func (c *Collection) Merge(id string, patch interface{}, opts *MergeOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	var patchDoc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(patchBytes))
+	dec.UseNumber()
+	err = dec.Decode(&patchDoc)
+	if err != nil || patchDoc == nil {
+		return nil, makeInvalidArgumentsError("patch must be a JSON object")
+	}
+
+	var upserts []mergePatchUpsert
+	var removals []string
+	flattenMergePatch("", patchDoc, &upserts, &removals)
+
+	if len(upserts) == 0 && len(removals) == 0 {
+		return nil, makeInvalidArgumentsError("patch must contain at least one change")
+	}
+	if len(upserts)+len(removals) > 16 {
+		return nil, makeInvalidArgumentsError("patch cannot contain more than 16 changes")
+	}
+
+	for i := 0; i < 16; i++ {
+		var cas Cas
+		storeSemantic := StoreSemanticsUpsert
+		existingRemovals := removals
+
+		// Removing a path which does not exist would fail the whole operation, so we only remove
+		// the paths which exist, using the CAS to ensure that they still do.
+		if len(removals) > 0 {
+			existingRemovals = nil
+
+			lookupOps := make([]LookupInSpec, len(removals))
+			for j, path := range removals {
+				lookupOps[j] = ExistsSpec(path, nil)
+			}
+
+			lookupRes, err := c.LookupIn(id, lookupOps, &LookupInOptions{
+				Timeout:       opts.Timeout,
+				RetryStrategy: opts.RetryStrategy,
+				Tags:          opts.Tags,
+			})
+			if errors.Is(err, ErrDocumentNotFound) {
+				storeSemantic = StoreSemanticsInsert
+			} else if err != nil {
+				return nil, err
+			} else {
+				cas = lookupRes.Cas()
+				storeSemantic = StoreSemanticsReplace
+				for j, path := range removals {
+					if lookupRes.Exists(uint(j)) {
+						existingRemovals = append(existingRemovals, path)
+					}
+				}
+			}
+		}
+
+		ops := make([]MutateInSpec, 0, len(upserts)+len(existingRemovals))
+		for _, upsert := range upserts {
+			ops = append(ops, UpsertSpec(upsert.path, upsert.value, &UpsertSpecOptions{CreatePath: true}))
+		}
+		for _, path := range existingRemovals {
+			ops = append(ops, RemoveSpec(path, nil))
+		}
+
+		// The patch only removes paths which do not exist, so there is nothing to change.
+		if len(ops) == 0 {
+			return &MutationResult{
+				Result: Result{
+					cas: cas,
+				},
+			}, nil
+		}
+
+		res, err := c.MutateIn(id, ops, &MutateInOptions{
+			Expiry:          opts.Expiry,
+			Cas:             cas,
+			PersistTo:       opts.PersistTo,
+			ReplicateTo:     opts.ReplicateTo,
+			DurabilityLevel: opts.DurabilityLevel,
+			StoreSemantic:   storeSemantic,
+			Timeout:         opts.Timeout,
+			RetryStrategy:   opts.RetryStrategy,
+			Tags:            opts.Tags,
+		})
+		if errors.Is(err, ErrCasMismatch) || errors.Is(err, ErrDocumentExists) || errors.Is(err, ErrDocumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &res.MutationResult, nil
+	}
+
+	return nil, errors.New("failed to perform operation after 16 retries")
+}
+
+// flattenMergePatch converts a merge patch into the sub-document paths which must be upserted or
+// removed to apply it.
+func flattenMergePatch(prefix string, patch map[string]interface{}, upserts *[]mergePatchUpsert, removals *[]string) {
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := escapeSubdocPathKey(key)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		switch value := patch[key].(type) {
+		case nil:
+			*removals = append(*removals, path)
+		case map[string]interface{}:
+			flattenMergePatch(path, value, upserts, removals)
+		default:
+			*upserts = append(*upserts, mergePatchUpsert{
+				path:  path,
+				value: value,
+			})
+		}
+	}
+}
+
+// escapeSubdocPathKey quotes a key for use within a sub-document path if it contains characters
+// which would otherwise be interpreted as part of the path syntax.
+func escapeSubdocPathKey(key string) string {
+	if !strings.ContainsAny(key, ".[]`") {
+		return key
+	}
+
+	return "`" + strings.Replace(key, "`", "``", -1) + "`"
+}
@@ -0,0 +1,107 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFlattenMergePatch(t *testing.T) {
+	var patch map[string]interface{}
+	err := json.Unmarshal([]byte(`{"name":"mike","address":{"city":"London","zip":null},"a.b":1,"tags":["x"],"age":null}`), &patch)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal patch: %v", err)
+	}
+
+	var upserts []mergePatchUpsert
+	var removals []string
+	flattenMergePatch("", patch, &upserts, &removals)
+
+	expectedUpserts := []string{"`a.b`", "address.city", "name", "tags"}
+	if len(upserts) != len(expectedUpserts) {
+		t.Fatalf("Expected %d upserts but had %d", len(expectedUpserts), len(upserts))
+	}
+	for i, path := range expectedUpserts {
+		if upserts[i].path != path {
+			t.Fatalf("Expected upsert %d to be %s but was %s", i, path, upserts[i].path)
+		}
+	}
+
+	expectedRemovals := []string{"address.zip", "age"}
+	if len(removals) != len(expectedRemovals) {
+		t.Fatalf("Expected %d removals but had %d", len(expectedRemovals), len(removals))
+	}
+	for i, path := range expectedRemovals {
+		if removals[i] != path {
+			t.Fatalf("Expected removal %d to be %s but was %s", i, path, removals[i])
+		}
+	}
+}
+
+func TestMergeInvalidPatch(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	_, err := col.Merge("merge", []string{"not", "an", "object"}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	_, err = col.Merge("merge", map[string]interface{}{}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	_, err := globalCollection.Upsert("merge", map[string]interface{}{
+		"name": "mike",
+		"age":  32,
+		"address": map[string]interface{}{
+			"city": "Manchester",
+			"zip":  "M1",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to Upsert, err: %v", err)
+	}
+
+	res, err := globalCollection.Merge("merge", map[string]interface{}{
+		"age":      nil,
+		"missing":  nil,
+		"nickname": "mikey",
+		"address": map[string]interface{}{
+			"city": "London",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to Merge, err: %v", err)
+	}
+
+	if res.Cas() == 0 {
+		t.Fatalf("Expected Cas to be non-zero")
+	}
+
+	doc, err := globalCollection.Get("merge", nil)
+	if err != nil {
+		t.Fatalf("Get failed, error was %v", err)
+	}
+
+	var content map[string]interface{}
+	err = doc.Content(&content)
+	if err != nil {
+		t.Fatalf("Content failed, error was %v", err)
+	}
+
+	if _, ok := content["age"]; ok {
+		t.Fatalf("Expected age to have been removed")
+	}
+
+	if content["name"] != "mike" || content["nickname"] != "mikey" {
+		t.Fatalf("Expected name and nickname to be set but was %v", content)
+	}
+
+	address, ok := content["address"].(map[string]interface{})
+	if !ok || address["city"] != "London" || address["zip"] != "M1" {
+		t.Fatalf("Expected address to have been merged but was %v", content["address"])
+	}
+}
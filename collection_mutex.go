@@ -0,0 +1,209 @@
+package gocb
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CouchbaseMutex represents a named mutex which can be used to provide mutual exclusion between
+// processes across the cluster.  A holder of the mutex is granted a lease which expires unless it
+// is renewed, so that the mutex is released if the holder fails.
+//
+// As a lease may expire whilst its holder still believes that it holds the mutex, each lease is
+// issued with a fencing token which is greater than that of every lease issued before it.
+// Resources protected by the mutex should reject requests carrying a token lower than the highest
+// which they have already seen.
+type CouchbaseMutex struct {
+	collection *Collection
+	id         string
+}
+
+// Mutex returns a new CouchbaseMutex for the document specified by id.  The fencing tokens for the
+// mutex are stored in a separate document, named by suffixing id with "::fence", which must not be
+// removed whilst the mutex is in use.
+func (c *Collection) Mutex(id string) *CouchbaseMutex {
+	return &CouchbaseMutex{
+		collection: c,
+		id:         id,
+	}
+}
+
+type mutexDocument struct {
+	Owner string `json:"owner"`
+	Token uint64 `json:"token"`
+}
+
+// MutexLockOptions are the options available to the Lock operation.
+type MutexLockOptions struct {
+	// WaitTimeout is the length of time to wait for the mutex to be released if it is held by
+	// another owner.  A value of 0 will fail immediately with ErrMutexLocked.
+	WaitTimeout time.Duration
+
+	// Timeout and RetryStrategy apply to each of the operations performed by the mutex, including
+	// those performed by the returned lease.
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+}
+
+// Lock acquires the mutex, returning a lease which is held for ttl unless renewed.  The resolution
+// of ttl is seconds, and it must be at least one second.
+func (cm *CouchbaseMutex) Lock(ttl time.Duration, opts *MutexLockOptions) (*MutexLease, error) {
+	if opts == nil {
+		opts = &MutexLockOptions{}
+	}
+
+	if ttl < time.Second {
+		return nil, makeInvalidArgumentsError("ttl must be at least one second")
+	}
+
+	clk := clockOrSystem(cm.collection.sb.Clock)
+	deadline := clk.Now().Add(opts.WaitTimeout)
+
+	curInterval := 10 * time.Millisecond
+	for {
+		lease, err := cm.tryLock(ttl, opts)
+		if !errors.Is(err, ErrMutexLocked) {
+			return lease, err
+		}
+
+		if !clk.Now().Before(deadline) {
+			return nil, err
+		}
+
+		curInterval += 50 * time.Millisecond
+		if curInterval > 1000*time.Millisecond {
+			curInterval = 1000 * time.Millisecond
+		}
+
+		// Make sure we don't sleep past our overall deadline, if we adjust the
+		// deadline then it will be caught at the top of this loop as a timeout.
+		now := clk.Now()
+		sleepDeadline := now.Add(curInterval)
+		if sleepDeadline.After(deadline) {
+			sleepDeadline = deadline
+		}
+
+		clk.Sleep(sleepDeadline.Sub(now))
+	}
+}
+
+func (cm *CouchbaseMutex) tryLock(ttl time.Duration, opts *MutexLockOptions) (*MutexLease, error) {
+	lease := &MutexLease{
+		mutex:         cm,
+		owner:         uuid.New().String(),
+		timeout:       opts.Timeout,
+		retryStrategy: opts.RetryStrategy,
+	}
+
+	res, err := cm.collection.Insert(cm.id, mutexDocument{Owner: lease.owner}, &InsertOptions{
+		Expiry:        ttl,
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+	})
+	if errors.Is(err, ErrDocumentExists) {
+		return nil, ErrMutexLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	lease.cas = res.Cas()
+
+	// The token is only issued once we hold the mutex, and only becomes valid once it has been
+	// stored against our lease.  This guarantees that every valid token is greater than those of
+	// the leases which were held before it.
+	counterRes, err := cm.collection.Binary().Increment(cm.id+"::fence", &IncrementOptions{
+		Initial:       1,
+		Delta:         1,
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+	})
+	if err != nil {
+		lease.release()
+		return nil, err
+	}
+	lease.token = counterRes.Content()
+
+	err = lease.replace(ttl)
+	if err != nil {
+		lease.release()
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// MutexLease represents a period during which a CouchbaseMutex is held.
+type MutexLease struct {
+	mutex         *CouchbaseMutex
+	owner         string
+	token         uint64
+	timeout       time.Duration
+	retryStrategy RetryStrategy
+
+	lock sync.Mutex
+	cas  Cas
+}
+
+// Token returns the fencing token of the lease.
+func (ml *MutexLease) Token() uint64 {
+	return ml.token
+}
+
+// Renew extends the lease so that it expires after ttl, failing with ErrMutexLeaseLost if the
+// lease has already expired.
+func (ml *MutexLease) Renew(ttl time.Duration) error {
+	if ttl < time.Second {
+		return makeInvalidArgumentsError("ttl must be at least one second")
+	}
+
+	return ml.replace(ttl)
+}
+
+// Unlock releases the mutex, failing with ErrMutexLeaseLost if the lease has already expired.
+func (ml *MutexLease) Unlock() error {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
+	_, err := ml.mutex.collection.Remove(ml.mutex.id, &RemoveOptions{
+		Cas:           ml.cas,
+		Timeout:       ml.timeout,
+		RetryStrategy: ml.retryStrategy,
+	})
+	if errors.Is(err, ErrCasMismatch) || errors.Is(err, ErrDocumentNotFound) {
+		return ErrMutexLeaseLost
+	}
+
+	return err
+}
+
+func (ml *MutexLease) replace(ttl time.Duration) error {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
+	res, err := ml.mutex.collection.Replace(ml.mutex.id, mutexDocument{Owner: ml.owner, Token: ml.token}, &ReplaceOptions{
+		Expiry:        ttl,
+		Cas:           ml.cas,
+		Timeout:       ml.timeout,
+		RetryStrategy: ml.retryStrategy,
+	})
+	if errors.Is(err, ErrCasMismatch) || errors.Is(err, ErrDocumentNotFound) {
+		return ErrMutexLeaseLost
+	}
+	if err != nil {
+		return err
+	}
+
+	ml.cas = res.Cas()
+	return nil
+}
+
+// release removes the lease document, ignoring any failure as the lease will expire regardless.
+func (ml *MutexLease) release() {
+	err := ml.Unlock()
+	if err != nil {
+		logDebugf("Failed to release mutex lease (%s)", err)
+	}
+}
@@ -0,0 +1,123 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMutex(t *testing.T) {
+	mutex := globalCollection.Mutex("mutex")
+
+	lease, err := mutex.Lock(10*time.Second, nil)
+	if err != nil {
+		t.Fatalf("Lock failed, error was %v", err)
+	}
+
+	_, err = mutex.Lock(10*time.Second, nil)
+	if !errors.Is(err, ErrMutexLocked) {
+		t.Fatalf("Expected mutex to be locked but error was %v", err)
+	}
+
+	err = lease.Renew(10 * time.Second)
+	if err != nil {
+		t.Fatalf("Renew failed, error was %v", err)
+	}
+
+	err = lease.Unlock()
+	if err != nil {
+		t.Fatalf("Unlock failed, error was %v", err)
+	}
+
+	err = lease.Unlock()
+	if !errors.Is(err, ErrMutexLeaseLost) {
+		t.Fatalf("Expected lease to be lost but error was %v", err)
+	}
+
+	nextLease, err := mutex.Lock(10*time.Second, nil)
+	if err != nil {
+		t.Fatalf("Lock failed, error was %v", err)
+	}
+
+	if nextLease.Token() <= lease.Token() {
+		t.Fatalf("Expected token to be greater than %d but was %d", lease.Token(), nextLease.Token())
+	}
+
+	err = nextLease.Unlock()
+	if err != nil {
+		t.Fatalf("Unlock failed, error was %v", err)
+	}
+}
+
+func TestMutexLeaseExpiry(t *testing.T) {
+	mutex := globalCollection.Mutex("mutexExpiry")
+
+	lease, err := mutex.Lock(1*time.Second, nil)
+	if err != nil {
+		t.Fatalf("Lock failed, error was %v", err)
+	}
+
+	globalCluster.TimeTravel(2000 * time.Millisecond)
+
+	nextLease, err := mutex.Lock(10*time.Second, &MutexLockOptions{
+		WaitTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Lock failed, error was %v", err)
+	}
+
+	err = lease.Renew(10 * time.Second)
+	if !errors.Is(err, ErrMutexLeaseLost) {
+		t.Fatalf("Expected lease to be lost but error was %v", err)
+	}
+
+	err = nextLease.Unlock()
+	if err != nil {
+		t.Fatalf("Unlock failed, error was %v", err)
+	}
+}
+
+func TestMutexLock(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: uint64(7),
+	}
+	col := testGetCollection(t, provider)
+
+	lease, err := col.Mutex("mutex").Lock(10*time.Second, nil)
+	if err != nil {
+		t.Fatalf("Lock failed, error was %v", err)
+	}
+
+	if lease.Token() != 7 {
+		t.Fatalf("Expected token to be 7 but was %d", lease.Token())
+	}
+}
+
+func TestMutexLockHeld(t *testing.T) {
+	provider := &mockKvProvider{
+		err: ErrDocumentExists,
+	}
+	col := testGetCollection(t, provider)
+
+	start := time.Now()
+	_, err := col.Mutex("mutex").Lock(10*time.Second, &MutexLockOptions{
+		WaitTimeout: 200 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrMutexLocked) {
+		t.Fatalf("Expected mutex to be locked but error was %v", err)
+	}
+
+	if time.Since(start) < 200*time.Millisecond {
+		t.Fatalf("Expected Lock to wait for the mutex to be released")
+	}
+}
+
+func TestMutexLockInvalidTTL(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{})
+
+	_, err := col.Mutex("mutex").Lock(500*time.Millisecond, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
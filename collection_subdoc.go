@@ -12,19 +12,25 @@ import (
 type LookupInOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
+	Tags          map[string]string
+	ParentSpan    RequestSpanContext
 }
 
 // LookupIn performs a set of subdocument lookup operations on the document identified by id.
+// The lookup succeeds as long as the document exists, even if some of the specs fail, such as
+// when an optional path is missing.  The error of each spec, a SubDocumentError, is returned by
+// ContentAt on the result, and Exists reports whether the spec succeeded.
 func (c *Collection) LookupIn(id string, ops []LookupInSpec, opts *LookupInOptions) (docOut *LookupInResult, errOut error) {
 	if opts == nil {
 		opts = &LookupInOptions{}
 	}
 
-	opm := c.newKvOpManager("LookupIn", nil)
+	opm := c.newKvOpManager("LookupIn", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -85,7 +91,7 @@ func (c *Collection) internalLookupIn(
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
 		RetryStrategy:  opm.RetryStrategy(),
-		TraceContext:   opm.TraceSpan(),
+		TraceContext:   opm.TraceContext(),
 	}, func(res *gocbcore.LookupInResult, err error) {
 		if err != nil && res == nil {
 			errOut = opm.EnhanceErr(err)
@@ -94,11 +100,21 @@ func (c *Collection) internalLookupIn(
 		if res != nil {
 			docOut = &LookupInResult{}
 			docOut.cas = Cas(res.Cas)
+			docOut.serverDuration = opm.ServerDuration()
 			docOut.contents = make([]lookupInPartial, len(subdocs))
+			var specErr error
 			for i, opRes := range res.Ops {
-				docOut.contents[i].err = opm.EnhanceErr(opRes.Err)
+				// The failure of a spec is not the outcome of the lookup, so it is enhanced directly.
+				docOut.contents[i].err = withSubDocPath(maybeEnhanceKVErr(opRes.Err, c.sb.BucketName, c.Name(),
+					c.scopeName(), opm.documentID), subdocs)
 				docOut.contents[i].data = json.RawMessage(opRes.Value)
+				if specErr == nil {
+					specErr = docOut.contents[i].err
+				}
 			}
+
+			// However many of the specs failed, the lookup only counts as a single error.
+			c.sb.Telemetry.recordError("kv", specErr)
 		}
 
 		if err == nil {
@@ -138,6 +154,8 @@ type MutateInOptions struct {
 	StoreSemantic   StoreSemantics
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
+	Tags            map[string]string
+	ParentSpan      RequestSpanContext
 }
 
 // MutateIn performs a set of subdocument mutations on the document specified by id.
@@ -146,11 +164,12 @@ func (c *Collection) MutateIn(id string, ops []MutateInSpec, opts *MutateInOptio
 		opts = &MutateInOptions{}
 	}
 
-	opm := c.newKvOpManager("MutateIn", nil)
+	opm := c.newKvOpManager("MutateIn", opts.ParentSpan)
 	defer opm.Finish()
 
 	opm.SetDocumentID(id)
 	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTags(opts.Tags)
 	opm.SetTimeout(opts.Timeout)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -212,6 +231,7 @@ func (c *Collection) internalMutateIn(
 	}
 
 	var subdocs []gocbcore.SubDocOp
+	valueSize := 0
 	for _, op := range ops {
 		if op.path == "" {
 			switch op.op {
@@ -225,7 +245,7 @@ func (c *Collection) internalMutateIn(
 			}
 		}
 
-		etrace := c.startKvOpTrace("encode", opm.TraceSpan())
+		etrace := c.startKvOpTrace("encode", opm.TraceSpan().Context())
 		bytes, flags, err := jsonMarshalMutateSpec(op)
 		etrace.Finish()
 		if err != nil {
@@ -246,6 +266,11 @@ func (c *Collection) internalMutateIn(
 			Path:  op.path,
 			Value: bytes,
 		})
+		valueSize += len(bytes)
+	}
+
+	if err := c.checkValueSize(opm.documentID, valueSize); err != nil {
+		return nil, err
 	}
 
 	agent, err := c.getKvProvider()
@@ -263,16 +288,17 @@ func (c *Collection) internalMutateIn(
 		DurabilityLevel:        opm.DurabilityLevel(),
 		DurabilityLevelTimeout: opm.DurabilityTimeout(),
 		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpan(),
+		TraceContext:           opm.TraceContext(),
 	}, func(res *gocbcore.MutateInResult, err error) {
 		if err != nil {
-			errOut = opm.EnhanceErr(err)
+			errOut = withSubDocPath(opm.EnhanceErr(err), subdocs)
 			opm.Reject()
 			return
 		}
 
 		mutOut = &MutateInResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 		mutOut.contents = make([]mutateInPartial, len(res.Ops))
 		for i, op := range res.Ops {
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"strings"
 	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
 )
 
 func TestInsertLookupIn(t *testing.T) {
@@ -422,3 +424,104 @@ func TestMutateInLookupInMacro(t *testing.T) {
 		t.Fatalf("Expected caspath to start with 0x but was %s", caspath)
 	}
 }
+
+func TestLookupInTypedPathErrors(t *testing.T) {
+	provider := &mockKvProvider{
+		cas: 5,
+		value: []gocbcore.SubDocResult{
+			{Value: []byte(`"value"`)},
+			{Err: gocbcore.SubDocumentError{
+				Index:      1,
+				InnerError: &gocbcore.KeyValueError{InnerError: gocbcore.ErrPathNotFound},
+			}},
+			{Err: gocbcore.SubDocumentError{
+				Index:      2,
+				InnerError: &gocbcore.KeyValueError{InnerError: gocbcore.ErrMemdSubDocPathMismatch},
+			}},
+		},
+	}
+	col := testGetCollection(t, provider)
+
+	res, err := col.LookupIn("lookupDoc", []LookupInSpec{
+		GetSpec("exists", nil),
+		GetSpec("missing", nil),
+		GetSpec("exists.child", nil),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected LookupIn to succeed but was %v", err)
+	}
+
+	var value string
+	err = res.ContentAt(0, &value)
+	if err != nil || value != "value" {
+		t.Fatalf("Expected first spec to succeed but was %v", err)
+	}
+
+	expected := []struct {
+		err  error
+		path string
+	}{
+		{ErrPathNotFound, "missing"},
+		{ErrPathMismatch, "exists.child"},
+	}
+	for i, test := range expected {
+		idx := uint(i + 1)
+		err = res.ContentAt(idx, &value)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Expected spec %d to fail with %v but was %v", idx, test.err, err)
+		}
+
+		var subdocErr SubDocumentError
+		if !errors.As(err, &subdocErr) {
+			t.Fatalf("Expected spec %d error to be a SubDocumentError but was %T", idx, err)
+		}
+		if subdocErr.Index != int(idx) || subdocErr.Path != test.path {
+			t.Fatalf("Expected spec %d error to be for %s but was %d %s", idx, test.path, subdocErr.Index, subdocErr.Path)
+		}
+
+		if res.Exists(idx) {
+			t.Fatalf("Expected spec %d not to exist", idx)
+		}
+	}
+}
+
+func TestLookupInPartialResults(t *testing.T) {
+	provider := &mockKvProvider{
+		cas: 5,
+		value: []gocbcore.SubDocResult{
+			{Err: gocbcore.SubDocumentError{
+				Index:      0,
+				InnerError: &gocbcore.KeyValueError{InnerError: gocbcore.ErrPathNotFound},
+			}},
+			{Value: []byte(`"present"`)},
+		},
+		// The server reports that some of the specs failed, alongside their results.
+		resultErr: &gocbcore.KeyValueError{InnerError: gocbcore.ErrMemdSubDocBadMulti},
+	}
+	col := testGetCollection(t, provider)
+
+	res, err := col.LookupIn("lookupDoc", []LookupInSpec{
+		GetSpec("optional", nil),
+		GetSpec("required", nil),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected LookupIn with a missing path to succeed but was %v", err)
+	}
+	if res.Cas() != 5 {
+		t.Fatalf("Expected cas to be 5 but was %d", res.Cas())
+	}
+
+	if res.Exists(0) {
+		t.Fatalf("Expected optional path not to exist")
+	}
+	err = res.ContentAt(0, nil)
+	if !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("Expected optional path to fail with path not found but was %v", err)
+	}
+
+	var value string
+	err = res.ContentAt(1, &value)
+	if err != nil || value != "present" {
+		t.Fatalf("Expected required path to be present but was %s, %v", value, err)
+	}
+}
@@ -0,0 +1,492 @@
+package gocb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportFormat specifies the format of the data read by Import.
+type ImportFormat uint
+
+const (
+	// ImportFormatNDJSON indicates that each line of the input is a JSON document.
+	ImportFormatNDJSON = ImportFormat(1)
+
+	// ImportFormatCSV indicates that the input is CSV with a header row, each subsequent row
+	// being converted to a JSON document with a field per column.
+	ImportFormatCSV = ImportFormat(2)
+)
+
+// ImportOptions are the options available to the Import operation.
+type ImportOptions struct {
+	// Format is the format of the input.  The default is ImportFormatNDJSON.
+	Format ImportFormat
+
+	// KeyTemplate generates the ID of each document.  Within the template, %field% is replaced
+	// by the value of the top level field of the document, #UUID# by a random UUID and
+	// #MONO_INCR# by the number of the record within the input, starting at 1.
+	KeyTemplate string
+
+	// InferTypes converts CSV values which are numbers or booleans to those types, rather than
+	// importing every value as a string.
+	InferTypes bool
+
+	// Skip is the number of records at the start of the input to skip, allowing an import to be
+	// resumed from ImportResult.Records.
+	Skip uint64
+
+	// OpsPerSecond, if set, limits the rate at which documents are written.
+	OpsPerSecond uint32
+
+	// MaxInFlight is the maximum number of documents being written at once.  The default is 128.
+	MaxInFlight int
+
+	// OnProgress, if set, is called each time that a document has been written.
+	OnProgress func(progress BulkProgress)
+
+	Expiry        time.Duration
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// ImportResult is the outcome of an Import operation.
+type ImportResult struct {
+	// Records is the number of records at the start of the input which were either skipped or
+	// written successfully.  Passing it as ImportOptions.Skip resumes the import from the first
+	// record which was not written, writing again any later records which were.
+	Records uint64
+
+	Succeeded int
+	Failures  []BulkFailure
+}
+
+// Import reads documents from r and upserts them into the collection, keeping a bounded number of
+// writes in flight.  An error in the input stops the import, whereas failing to write a document
+// does not and is instead reported in the result.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) Import(r io.Reader, opts *ImportOptions) (*ImportResult, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+
+	if opts.KeyTemplate == "" {
+		return nil, makeInvalidArgumentsError("key template cannot be empty")
+	}
+
+	var next func() (map[string]interface{}, json.RawMessage, error)
+	switch opts.Format {
+	case 0, ImportFormatNDJSON:
+		next = newNDJSONRecordReader(r)
+	case ImportFormatCSV:
+		next = newCSVRecordReader(r, opts.InferTypes)
+	default:
+		return nil, makeInvalidArgumentsError("unexpected import format")
+	}
+
+	throttle := newTransferThrottle(clockOrSystem(c.sb.Clock), opts.OpsPerSecond)
+	records := uint64(0)
+	var readErr error
+
+	// The record number of each write is tracked until it completes, so that Records only
+	// counts the records up to the first which failed to be written.
+	var recordsLock sync.Mutex
+	inFlight := make(map[BulkOp]uint64)
+	firstFailed := uint64(0)
+
+	opsCh := make(chan BulkOp)
+	go func() {
+		defer close(opsCh)
+
+		for {
+			fields, value, err := next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr = wrapError(err, fmt.Sprintf("failed to read record %d", records+1))
+				return
+			}
+
+			record := records + 1
+			if record <= opts.Skip {
+				records = record
+				continue
+			}
+
+			key, err := expandKeyTemplate(opts.KeyTemplate, fields, record)
+			if err != nil {
+				readErr = wrapError(err, fmt.Sprintf("failed to generate key for record %d", record))
+				return
+			}
+			records = record
+
+			throttle()
+			op := &UpsertOp{
+				ID:     key,
+				Value:  value,
+				Expiry: opts.Expiry,
+			}
+			recordsLock.Lock()
+			inFlight[op] = record
+			recordsLock.Unlock()
+			opsCh <- op
+		}
+	}()
+
+	executor := c.BulkExecutor(&BulkExecutorOptions{
+		MaxInFlight:   opts.MaxInFlight,
+		Timeout:       opts.Timeout,
+		OnProgress:    opts.OnProgress,
+		Transcoder:    NewJSONTranscoder(),
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	})
+	executor.onComplete = func(op BulkOp) {
+		recordsLock.Lock()
+		record := inFlight[op]
+		delete(inFlight, op)
+		if op.err() != nil && (firstFailed == 0 || record < firstFailed) {
+			firstFailed = record
+		}
+		recordsLock.Unlock()
+	}
+
+	res, err := executor.ExecuteStream(opsCh)
+	if err != nil {
+		// Drain the remaining operations so that the reader can exit.
+		for range opsCh {
+		}
+		return nil, err
+	}
+
+	if firstFailed > 0 {
+		records = firstFailed - 1
+	}
+
+	result := &ImportResult{
+		Records:   records,
+		Succeeded: res.Succeeded,
+		Failures:  res.Failures,
+	}
+	if readErr != nil {
+		return result, readErr
+	}
+
+	return result, nil
+}
+
+func newNDJSONRecordReader(r io.Reader) func() (map[string]interface{}, json.RawMessage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 20*1024*1024)
+
+	return func() (map[string]interface{}, json.RawMessage, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			var fields map[string]interface{}
+			err := json.Unmarshal(line, &fields)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			value := make(json.RawMessage, len(line))
+			copy(value, line)
+			return fields, value, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, io.EOF
+	}
+}
+
+func newCSVRecordReader(r io.Reader, inferTypes bool) func() (map[string]interface{}, json.RawMessage, error) {
+	reader := csv.NewReader(r)
+	var header []string
+
+	return func() (map[string]interface{}, json.RawMessage, error) {
+		if header == nil {
+			row, err := reader.Read()
+			if err != nil {
+				return nil, nil, err
+			}
+			header = row
+		}
+
+		row, err := reader.Read()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fields := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			if inferTypes {
+				fields[name] = inferCSVValue(row[i])
+			} else {
+				fields[name] = row[i]
+			}
+		}
+
+		value, err := json.Marshal(fields)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return fields, value, nil
+	}
+}
+
+func inferCSVValue(value string) interface{} {
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+
+	return value
+}
+
+func expandKeyTemplate(template string, fields map[string]interface{}, record uint64) (string, error) {
+	key := strings.Replace(template, "#MONO_INCR#", strconv.FormatUint(record, 10), -1)
+	for strings.Contains(key, "#UUID#") {
+		key = strings.Replace(key, "#UUID#", uuid.New().String(), 1)
+	}
+
+	var out strings.Builder
+	for {
+		start := strings.IndexByte(key, '%')
+		if start < 0 {
+			out.WriteString(key)
+			break
+		}
+
+		end := strings.IndexByte(key[start+1:], '%')
+		if end < 0 {
+			return "", makeInvalidArgumentsError("key template contains an unterminated field")
+		}
+		end += start + 1
+
+		name := key[start+1 : end]
+		value, ok := fields[name]
+		if !ok || value == nil {
+			return "", fmt.Errorf("field %s is missing", name)
+		}
+
+		out.WriteString(key[:start])
+		switch typedValue := value.(type) {
+		case string:
+			out.WriteString(typedValue)
+		case float64:
+			out.WriteString(strconv.FormatFloat(typedValue, 'f', -1, 64))
+		default:
+			out.WriteString(fmt.Sprintf("%v", typedValue))
+		}
+		key = key[end+1:]
+	}
+
+	if out.Len() == 0 {
+		return "", errors.New("key is empty")
+	}
+
+	return out.String(), nil
+}
+
+// newTransferThrottle returns a function which blocks as required to keep calls to it within
+// opsPerSecond.  A limit of 0 does not throttle at all.
+func newTransferThrottle(clk clock, opsPerSecond uint32) func() {
+	if opsPerSecond == 0 {
+		return func() {}
+	}
+
+	interval := time.Second / time.Duration(opsPerSecond)
+	next := clk.Now()
+	return func() {
+		if wait := next.Sub(clk.Now()); wait > 0 {
+			clk.Sleep(wait)
+		}
+
+		// If we have fallen behind we do not let the rate burst in order to catch up.
+		now := clk.Now()
+		if next.Before(now) {
+			next = now
+		}
+		next = next.Add(interval)
+	}
+}
+
+// ExportOptions are the options available to the Export operation.
+type ExportOptions struct {
+	// KeyField, if set, adds a field with this name holding the ID of each document to the
+	// exported documents.
+	KeyField string
+
+	// ResumeToken, if set, resumes an export from ExportResult.ResumeToken.
+	ResumeToken string
+
+	// PageSize is the number of documents fetched from the collection at a time.  The default is 1000.
+	PageSize uint32
+
+	// OpsPerSecond, if set, limits the rate at which documents are fetched.
+	OpsPerSecond uint32
+
+	// MaxInFlight is the maximum number of documents being fetched at once.  The default is 128.
+	MaxInFlight int
+
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// ExportResult is the outcome of an Export operation.
+type ExportResult struct {
+	Exported int
+
+	// ResumeToken can be passed as ExportOptions.ResumeToken to resume the export after the last
+	// document which was written.
+	ResumeToken string
+}
+
+// Export writes every document in the collection to w as NDJSON, in order of document ID.  The
+// document IDs are listed using ScanKeys, and so the collection must have a primary index.
+// Documents which are removed whilst the export is running are skipped.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) Export(w io.Writer, opts *ExportOptions) (*ExportResult, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	keys, err := c.ScanKeys(&ScanKeysOptions{
+		PageSize:      opts.PageSize,
+		ResumeToken:   opts.ResumeToken,
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.exportKeys(keys, w, opts)
+}
+
+func (c *Collection) exportKeys(keys *KeyScanResult, w io.Writer, opts *ExportOptions) (*ExportResult, error) {
+	executor := c.BulkExecutor(&BulkExecutorOptions{
+		MaxInFlight:   opts.MaxInFlight,
+		Timeout:       opts.Timeout,
+		Transcoder:    NewJSONTranscoder(),
+		RetryStrategy: opts.RetryStrategy,
+		Tags:          opts.Tags,
+	})
+	throttle := newTransferThrottle(clockOrSystem(c.sb.Clock), opts.OpsPerSecond)
+	result := &ExportResult{
+		ResumeToken: opts.ResumeToken,
+	}
+
+	// We fetch the documents a page of keys at a time, so that we can write them out in order and
+	// the resume token always reflects every document which has been written.
+	for {
+		var ops []*GetOp
+		for uint32(len(ops)) < keys.pageSize && keys.Next() {
+			ops = append(ops, &GetOp{ID: keys.Key()})
+		}
+		if keys.Err() != nil {
+			return result, keys.Err()
+		}
+		if len(ops) == 0 {
+			return result, nil
+		}
+
+		opsCh := make(chan BulkOp)
+		go func() {
+			for _, op := range ops {
+				throttle()
+				opsCh <- op
+			}
+			close(opsCh)
+		}()
+
+		_, err := executor.ExecuteStream(opsCh)
+		if err != nil {
+			for range opsCh {
+			}
+			return result, err
+		}
+
+		// The resume token is advanced as each document is written, so that an export which
+		// fails part way through a page resumes from the first document which was not written.
+		for _, op := range ops {
+			if errors.Is(op.Err, ErrDocumentNotFound) {
+				result.ResumeToken = encodeKeyScanToken(op.ID)
+				continue
+			}
+			if op.Err != nil {
+				return result, op.Err
+			}
+
+			line, err := exportDocument(op, opts.KeyField)
+			if err != nil {
+				return result, err
+			}
+
+			_, err = w.Write(line)
+			if err != nil {
+				return result, err
+			}
+
+			result.Exported++
+			result.ResumeToken = encodeKeyScanToken(op.ID)
+		}
+	}
+}
+
+func exportDocument(op *GetOp, keyField string) ([]byte, error) {
+	var raw json.RawMessage
+	err := op.Result.Content(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyField != "" {
+		var fields map[string]interface{}
+		err := json.Unmarshal(raw, &fields)
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("document %s is not a JSON object", op.ID))
+		}
+
+		fields[keyField] = op.ID
+		raw, err = json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var line bytes.Buffer
+	err = json.Compact(&line, raw)
+	if err != nil {
+		return nil, err
+	}
+	line.WriteByte('\n')
+
+	return line.Bytes(), nil
+}
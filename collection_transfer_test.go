@@ -0,0 +1,216 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandKeyTemplate(t *testing.T) {
+	fields := map[string]interface{}{
+		"name": "mike",
+		"age":  float64(32),
+	}
+
+	key, err := expandKeyTemplate("user::%name%::%age%::#MONO_INCR#", fields, 7)
+	if err != nil {
+		t.Fatalf("Failed to expand template: %v", err)
+	}
+	if key != "user::mike::32::7" {
+		t.Fatalf("Unexpected key %s", key)
+	}
+
+	key, err = expandKeyTemplate("#UUID#", fields, 1)
+	if err != nil {
+		t.Fatalf("Failed to expand template: %v", err)
+	}
+	if len(key) != 36 {
+		t.Fatalf("Expected key to be a UUID but was %s", key)
+	}
+
+	_, err = expandKeyTemplate("user::%email%", fields, 1)
+	if err == nil {
+		t.Fatalf("Expected missing field to fail")
+	}
+
+	_, err = expandKeyTemplate("user::%name", fields, 1)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestCSVRecordReader(t *testing.T) {
+	next := newCSVRecordReader(strings.NewReader("name,age,admin\nmike,32,true\n"), true)
+
+	fields, value, err := next()
+	if err != nil {
+		t.Fatalf("Failed to read record: %v", err)
+	}
+
+	if fields["name"] != "mike" || fields["age"] != int64(32) || fields["admin"] != true {
+		t.Fatalf("Unexpected fields %v", fields)
+	}
+
+	if string(value) != `{"admin":true,"age":32,"name":"mike"}` {
+		t.Fatalf("Unexpected value %s", value)
+	}
+}
+
+func TestImportNDJSON(t *testing.T) {
+	provider := &mockKvProvider{
+		cas: 10,
+	}
+	col := testGetCollection(t, provider)
+
+	input := "{\"name\":\"mike\"}\n\n{\"name\":\"bob\"}\n{\"name\":\"sue\"}\n"
+	res, err := col.Import(strings.NewReader(input), &ImportOptions{
+		KeyTemplate: "user::%name%",
+		Skip:        1,
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if res.Records != 3 || res.Succeeded != 2 || len(res.Failures) != 0 {
+		t.Fatalf("Unexpected result %v", res)
+	}
+}
+
+func TestImportInvalidRecord(t *testing.T) {
+	provider := &mockKvProvider{
+		cas: 10,
+	}
+	col := testGetCollection(t, provider)
+
+	input := "{\"name\":\"mike\"}\nnot json\n"
+	res, err := col.Import(strings.NewReader(input), &ImportOptions{
+		KeyTemplate: "user::%name%",
+	})
+	if err == nil {
+		t.Fatalf("Expected import to fail")
+	}
+
+	if res.Records != 1 || res.Succeeded != 1 {
+		t.Fatalf("Expected first record to be imported but was %v", res)
+	}
+}
+
+func TestImportFailedWrites(t *testing.T) {
+	provider := &mockKvProvider{
+		err: ErrTemporaryFailure,
+	}
+	col := testGetCollection(t, provider)
+
+	input := "{\"name\":\"mike\"}\n{\"name\":\"bob\"}\n{\"name\":\"sue\"}\n"
+	res, err := col.Import(strings.NewReader(input), &ImportOptions{
+		KeyTemplate: "user::%name%",
+		Skip:        1,
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	// Only the skipped record is counted, so that resuming writes the failed records again.
+	if res.Records != 1 || res.Succeeded != 0 || len(res.Failures) != 2 {
+		t.Fatalf("Unexpected result %v", res)
+	}
+}
+
+func TestImportRateLimited(t *testing.T) {
+	provider := &mockKvProvider{
+		cas: 10,
+	}
+	col := testGetCollection(t, provider)
+
+	input := strings.Repeat("{\"name\":\"mike\"}\n", 5)
+	start := time.Now()
+	_, err := col.Import(strings.NewReader(input), &ImportOptions{
+		KeyTemplate:  "user::#MONO_INCR#",
+		OpsPerSecond: 50,
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if time.Since(start) < 80*time.Millisecond {
+		t.Fatalf("Expected import to be rate limited")
+	}
+}
+
+func TestExportKeys(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte("{\n  \"name\": \"mike\"\n}"),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	keys := newKeyScanResult(func(after string, limit uint32) ([]string, error) {
+		if after == "" {
+			return []string{"a", "b"}, nil
+		}
+		return nil, nil
+	}, "", 2)
+
+	var out bytes.Buffer
+	res, err := col.exportKeys(keys, &out, &ExportOptions{KeyField: "id"})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	expected := "{\"id\":\"a\",\"name\":\"mike\"}\n{\"id\":\"b\",\"name\":\"mike\"}\n"
+	if out.String() != expected {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+
+	after, err := decodeKeyScanToken(res.ResumeToken)
+	if err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+	if res.Exported != 2 || after != "b" {
+		t.Fatalf("Unexpected result %v", res)
+	}
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.writes--
+	return len(p), nil
+}
+
+func TestExportKeysResumeTokenPerDocument(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"name":"mike"}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+
+	keys := newKeyScanResult(func(after string, limit uint32) ([]string, error) {
+		if after == "" {
+			return []string{"a", "b", "c"}, nil
+		}
+		return nil, nil
+	}, "", 3)
+
+	res, err := col.exportKeys(keys, &failingWriter{writes: 1}, &ExportOptions{})
+	if err == nil {
+		t.Fatalf("Expected export to fail")
+	}
+
+	after, err := decodeKeyScanToken(res.ResumeToken)
+	if err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+	if res.Exported != 1 || after != "a" {
+		t.Fatalf("Expected export to resume after the written document but was %v, %s", res, after)
+	}
+}
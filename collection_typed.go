@@ -0,0 +1,34 @@
+//go:build go1.21
+// +build go1.21
+
+package gocb
+
+// GetAs fetches a document from the collection and decodes its content as a T.
+// UNCOMMITTED: This API may change in the future.
+func GetAs[T any](c *Collection, id string, opts *GetOptions) (T, error) {
+	var value T
+
+	result, err := c.Get(id, opts)
+	if err != nil {
+		return value, err
+	}
+
+	return ContentAs[T](result)
+}
+
+// ContentAs decodes the content of a GetResult as a T.  Go does not allow methods to have type
+// parameters, so this is a function rather than a method of GetResult.
+// UNCOMMITTED: This API may change in the future.
+func ContentAs[T any](result *GetResult) (T, error) {
+	var value T
+	err := result.Content(&value)
+	return value, err
+}
+
+// ContentAtAs decodes the value of the LookupIn operation at idx as a T.
+// UNCOMMITTED: This API may change in the future.
+func ContentAtAs[T any](result *LookupInResult, idx uint) (T, error) {
+	var value T
+	err := result.ContentAt(idx, &value)
+	return value, err
+}
@@ -0,0 +1,81 @@
+//go:build go1.21
+// +build go1.21
+
+package gocb
+
+import (
+	"errors"
+	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestGetAs(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{
+		cas:   gocbcore.Cas(1),
+		value: []byte(`{"name":"mock"}`),
+		flags: 33554432,
+	})
+
+	type doc struct {
+		Name string `json:"name"`
+	}
+
+	value, err := GetAs[doc](col, "typed", nil)
+	if err != nil {
+		t.Fatalf("GetAs failed: %v", err)
+	}
+
+	if value.Name != "mock" {
+		t.Fatalf("Unexpected value %v", value)
+	}
+}
+
+func TestGetAsError(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{
+		err: gocbcore.ErrDocumentNotFound,
+	})
+
+	_, err := GetAs[map[string]interface{}](col, "typed", nil)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("Expected document not found but was %v", err)
+	}
+}
+
+func TestContentAs(t *testing.T) {
+	result := &GetResult{
+		transcoder: NewJSONTranscoder(),
+		flags:      33554432,
+		contents:   []byte(`[1,2,3]`),
+	}
+
+	value, err := ContentAs[[]int](result)
+	if err != nil {
+		t.Fatalf("ContentAs failed: %v", err)
+	}
+
+	if len(value) != 3 || value[2] != 3 {
+		t.Fatalf("Unexpected value %v", value)
+	}
+
+	_, err = ContentAs[string](result)
+	if err == nil {
+		t.Fatalf("Expected decode error")
+	}
+}
+
+func TestContentAtAs(t *testing.T) {
+	result := &LookupInResult{
+		contents: []lookupInPartial{{data: []byte(`42`)}},
+	}
+
+	value, err := ContentAtAs[int](result, 0)
+	if err != nil || value != 42 {
+		t.Fatalf("Unexpected value %d, error %v", value, err)
+	}
+
+	_, err = ContentAtAs[int](result, 1)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package gocb
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DocumentChangeType specifies the type of a DocumentChange.
+type DocumentChangeType uint
+
+const (
+	// DocumentChangeMutation indicates that the document was created or modified.
+	DocumentChangeMutation = DocumentChangeType(1)
+
+	// DocumentChangeDeletion indicates that the document was removed, or has expired.
+	DocumentChangeDeletion = DocumentChangeType(2)
+)
+
+// DocumentChange represents a change to a document being watched.
+type DocumentChange struct {
+	Type DocumentChangeType
+
+	// Result is the new state of the document, it is nil for deletions.
+	Result *GetResult
+}
+
+// WatchOptions are the options available to the Watch operation.
+type WatchOptions struct {
+	// PollInterval is how often the document is checked for changes.  The default is 1 second.
+	PollInterval time.Duration
+
+	Transcoder    Transcoder
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	Tags          map[string]string
+}
+
+// DocumentWatcher reports the changes made to a single document.
+// UNCOMMITTED: This API may change in the future.
+type DocumentWatcher struct {
+	collection *Collection
+	id         string
+	opts       WatchOptions
+
+	changes   chan DocumentChange
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// Watch watches a document for changes, which is useful for documents such as configuration which
+// are read often but rarely change.  The document is polled and a change is reported each time
+// that its CAS differs from the last poll, so a change made and then reverted between two polls
+// may not be seen and several changes made between two polls are reported as one.
+// The current state of the document, if it exists, is reported as the first change.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) Watch(id string, opts *WatchOptions) (*DocumentWatcher, error) {
+	if opts == nil {
+		opts = &WatchOptions{}
+	}
+
+	w := &DocumentWatcher{
+		collection: c,
+		id:         id,
+		opts:       *opts,
+		changes:    make(chan DocumentChange, 1),
+		closeCh:    make(chan struct{}),
+	}
+	if w.opts.PollInterval <= 0 {
+		w.opts.PollInterval = 1 * time.Second
+	}
+
+	// We fetch the document once up front so that errors such as the collection not existing
+	// are returned to the caller, rather than ending the watch straight away.
+	doc, err := w.get()
+	if err != nil && !errors.Is(err, ErrDocumentNotFound) {
+		return nil, err
+	}
+
+	c.sb.Workers.start(func() {
+		w.loop(doc)
+	})
+
+	return w, nil
+}
+
+// Changes returns the channel on which changes to the document are delivered.  The channel is
+// closed once the watcher or the Cluster is closed, or a fatal error occurs, which is then
+// available from Err.
+func (w *DocumentWatcher) Changes() <-chan DocumentChange {
+	return w.changes
+}
+
+// Err returns the error which caused the watcher to stop, if any.  It must only be called once
+// the changes channel has been closed.
+func (w *DocumentWatcher) Err() error {
+	return w.err
+}
+
+// Close stops watching the document.
+func (w *DocumentWatcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.closeCh)
+	})
+}
+
+func (w *DocumentWatcher) get() (*GetResult, error) {
+	return w.collection.Get(w.id, &GetOptions{
+		Transcoder:    w.opts.Transcoder,
+		Timeout:       w.opts.Timeout,
+		RetryStrategy: w.opts.RetryStrategy,
+		Tags:          w.opts.Tags,
+	})
+}
+
+func (w *DocumentWatcher) send(change DocumentChange) bool {
+	select {
+	case w.changes <- change:
+		return true
+	case <-w.closeCh:
+		return false
+	case <-w.collection.sb.Workers.closed():
+		return false
+	}
+}
+
+func (w *DocumentWatcher) isClosed() bool {
+	select {
+	case <-w.closeCh:
+		return true
+	case <-w.collection.sb.Workers.closed():
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *DocumentWatcher) loop(doc *GetResult) {
+	defer close(w.changes)
+
+	var lastCas Cas
+	if doc != nil {
+		lastCas = doc.Cas()
+		if !w.send(DocumentChange{Type: DocumentChangeMutation, Result: doc}) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-clockOrSystem(w.collection.sb.Clock).After(w.opts.PollInterval):
+		case <-w.closeCh:
+			return
+		case <-w.collection.sb.Workers.closed():
+			return
+		}
+
+		doc, err := w.get()
+		if w.isClosed() {
+			return
+		}
+		if errors.Is(err, ErrDocumentNotFound) {
+			if lastCas == 0 {
+				continue
+			}
+
+			lastCas = 0
+			if !w.send(DocumentChange{Type: DocumentChangeDeletion}) {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			if IsTimeout(err) || IsRetryableError(err) {
+				logDebugf("Failed to poll watched document, will retry (%s)", err)
+				continue
+			}
+
+			w.err = err
+			return
+		}
+
+		if doc.Cas() == lastCas {
+			continue
+		}
+
+		lastCas = doc.Cas()
+		if !w.send(DocumentChange{Type: DocumentChangeMutation, Result: doc}) {
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package gocb
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// mockWatchKvProvider allows the state of the document to be changed whilst it is being watched.
+type mockWatchKvProvider struct {
+	*mockKvProvider
+
+	lock sync.Mutex
+	cas  gocbcore.Cas
+	err  error
+}
+
+func (mwp *mockWatchKvProvider) setState(cas gocbcore.Cas, err error) {
+	mwp.lock.Lock()
+	mwp.cas = cas
+	mwp.err = err
+	mwp.lock.Unlock()
+}
+
+func (mwp *mockWatchKvProvider) GetEx(opts gocbcore.GetOptions, cb gocbcore.GetExCallback) (gocbcore.PendingOp, error) {
+	mwp.lock.Lock()
+	cas, err := mwp.cas, mwp.err
+	mwp.lock.Unlock()
+
+	if err != nil {
+		cb(nil, err)
+	} else {
+		cb(&gocbcore.GetResult{
+			Cas:   cas,
+			Flags: 2 << 24,
+			Value: []byte(`{"name":"mike"}`),
+		}, nil)
+	}
+
+	return &mockPendingOp{}, nil
+}
+
+func testWatchCollection(t *testing.T, cas gocbcore.Cas, err error) (*Collection, *mockWatchKvProvider) {
+	provider := &mockWatchKvProvider{
+		mockKvProvider: &mockKvProvider{},
+		cas:            cas,
+		err:            err,
+	}
+	col := testGetCollection(t, provider.mockKvProvider)
+	col.sb.getCachedClient().(*mockClient).mockKvProvider = provider
+
+	return col, provider
+}
+
+func testNextDocumentChange(t *testing.T, w *DocumentWatcher) DocumentChange {
+	select {
+	case change, ok := <-w.Changes():
+		if !ok {
+			t.Fatalf("Expected a change but watcher closed with %v", w.Err())
+		}
+		return change
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for a change")
+	}
+
+	return DocumentChange{}
+}
+
+func TestWatchReportsChanges(t *testing.T) {
+	col, provider := testWatchCollection(t, 10, nil)
+
+	w, err := col.Watch("config", &WatchOptions{
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Close()
+
+	change := testNextDocumentChange(t, w)
+	if change.Type != DocumentChangeMutation || change.Result.Cas() != 10 {
+		t.Fatalf("Expected initial state of document but was %v", change)
+	}
+
+	var content map[string]string
+	err = change.Result.Content(&content)
+	if err != nil {
+		t.Fatalf("Content failed: %v", err)
+	}
+	if content["name"] != "mike" {
+		t.Fatalf("Expected content to be decoded but was %v", content)
+	}
+
+	provider.setState(11, nil)
+	change = testNextDocumentChange(t, w)
+	if change.Type != DocumentChangeMutation || change.Result.Cas() != 11 {
+		t.Fatalf("Expected mutation but was %v", change)
+	}
+
+	provider.setState(0, ErrDocumentNotFound)
+	change = testNextDocumentChange(t, w)
+	if change.Type != DocumentChangeDeletion || change.Result != nil {
+		t.Fatalf("Expected deletion but was %v", change)
+	}
+
+	provider.setState(12, nil)
+	change = testNextDocumentChange(t, w)
+	if change.Type != DocumentChangeMutation || change.Result.Cas() != 12 {
+		t.Fatalf("Expected mutation but was %v", change)
+	}
+}
+
+func TestWatchMissingDocument(t *testing.T) {
+	col, provider := testWatchCollection(t, 0, ErrDocumentNotFound)
+
+	w, err := col.Watch("config", &WatchOptions{
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Close()
+
+	provider.setState(10, nil)
+	change := testNextDocumentChange(t, w)
+	if change.Type != DocumentChangeMutation || change.Result.Cas() != 10 {
+		t.Fatalf("Expected document creation but was %v", change)
+	}
+}
+
+func TestWatchStopsOnFatalError(t *testing.T) {
+	col, provider := testWatchCollection(t, 10, nil)
+
+	w, err := col.Watch("config", &WatchOptions{
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Close()
+
+	testNextDocumentChange(t, w)
+
+	provider.setState(0, ErrCollectionNotFound)
+	select {
+	case _, ok := <-w.Changes():
+		if ok {
+			t.Fatalf("Expected watcher to close")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for watcher to close")
+	}
+
+	if !errors.Is(w.Err(), ErrCollectionNotFound) {
+		t.Fatalf("Expected collection not found error but was %v", w.Err())
+	}
+}
+
+func TestWatchClose(t *testing.T) {
+	col, _ := testWatchCollection(t, 10, nil)
+
+	w, err := col.Watch("config", nil)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	w.Close()
+	w.Close()
+
+	select {
+	case <-w.Changes():
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for watcher to close")
+	}
+}
@@ -0,0 +1,186 @@
+package gocb
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/couchbaselabs/gocbconnstr"
+)
+
+type connStrOptionKind int
+
+const (
+	connStrOptionString connStrOptionKind = iota
+	connStrOptionBool
+	connStrOptionInt
+	connStrOptionFloat
+	connStrOptionDuration
+)
+
+// connStrOptionValues lists the permitted values of options which only accept a fixed set.
+var connStrOptionValues = map[string][]string{
+	"bootstrap_on": {"http", "cccp", "both"},
+	"dcp_priority": {"low", "medium", "high"},
+}
+
+// ConnectionStringOption is an option in a connection string which is recognised by the SDK.
+type ConnectionStringOption struct {
+	Name string
+	// Value is the value of the option as it will be used by the SDK.  It is a time.Duration,
+	// bool, int64, float64 or string depending on the option.  It is nil if the value is not
+	// valid for the option.
+	Value interface{}
+}
+
+// ConnectionStringReport describes a connection string as it would be understood by Connect.
+// UNCOMMITTED: This API may change in the future.
+type ConnectionStringReport struct {
+	Scheme string
+	UseTLS bool
+	// Hosts lists each host in the connection string, with its port if one was given.
+	Hosts []string
+	// Options lists the recognised options in the connection string, ordered by name.  If an
+	// option is given more than once then the last value is used.
+	Options []ConnectionStringOption
+	// UnknownOptions lists the names of options in the connection string which are not
+	// recognised, ordered by name.  They are ignored by Connect unless
+	// ClusterOptions.StrictConnectionString is set.
+	UnknownOptions []string
+	// Errors lists the problems which would cause Connect, or the connections which it makes,
+	// to fail.
+	Errors []string
+	// Warnings lists the problems which would not cause Connect to fail, but are likely to be
+	// mistakes.
+	Warnings []string
+}
+
+// Valid returns whether the connection string has no errors.
+func (r *ConnectionStringReport) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// ValidateConnectionString checks a connection string without connecting to the cluster.  An
+// error is only returned if the connection string cannot be parsed at all, any other problems
+// are described by the returned report.  No DNS lookups are performed, and files referred to
+// by the connection string are not read.
+// UNCOMMITTED: This API may change in the future.
+func ValidateConnectionString(connStr string) (*ConnectionStringReport, error) {
+	spec, err := gocbconnstr.Parse(connStr)
+	if err != nil {
+		return nil, makeInvalidArgumentsError(err.Error())
+	}
+
+	report := &ConnectionStringReport{
+		Scheme: spec.Scheme,
+		UseTLS: spec.Scheme == "couchbases",
+	}
+
+	switch spec.Scheme {
+	case "couchbase", "couchbases", "":
+	case "http":
+		report.Errors = append(report.Errors, "http scheme is not supported, use couchbase or couchbases instead")
+	default:
+		report.Errors = append(report.Errors, fmt.Sprintf("unknown scheme %s", spec.Scheme))
+	}
+
+	for _, address := range spec.Addresses {
+		if address.Port <= 0 {
+			report.Hosts = append(report.Hosts, address.Host)
+			continue
+		}
+		report.Hosts = append(report.Hosts, fmt.Sprintf("%s:%d", address.Host, address.Port))
+
+		if report.UseTLS && (address.Port == gocbconnstr.DefaultMemdPort || address.Port == gocbconnstr.DefaultHttpPort) {
+			report.Errors = append(report.Errors,
+				fmt.Sprintf("host %s uses a non-TLS port with the couchbases scheme", address.Host))
+		} else if !report.UseTLS &&
+			(address.Port == gocbconnstr.DefaultSslMemdPort || address.Port == gocbconnstr.DefaultSslHttpPort) {
+			report.Errors = append(report.Errors,
+				fmt.Sprintf("host %s uses a TLS port without the couchbases scheme", address.Host))
+		}
+	}
+
+	names := make([]string, 0, len(spec.Options))
+	for name := range spec.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		values := spec.Options[name]
+		kind, ok := knownConnStrOptions[name]
+		if !ok {
+			report.UnknownOptions = append(report.UnknownOptions, name)
+			if suggestion := closestConnStrOption(name); suggestion != "" {
+				report.Warnings = append(report.Warnings,
+					fmt.Sprintf("unrecognised option %s, did you mean %s?", name, suggestion))
+			} else {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("unrecognised option %s", name))
+			}
+			continue
+		}
+
+		if len(values) > 1 && name != "ca_cert_path" {
+			report.Warnings = append(report.Warnings,
+				fmt.Sprintf("option %s is specified %d times, only the last value is used", name, len(values)))
+		}
+
+		value, err := coerceConnStrOption(name, kind, values[len(values)-1])
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		report.Options = append(report.Options, ConnectionStringOption{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	if _, ok := spec.Options["ca_cert_path"]; ok && !report.UseTLS {
+		report.Warnings = append(report.Warnings, "ca_cert_path is ignored without the couchbases scheme")
+	}
+
+	return report, nil
+}
+
+func coerceConnStrOption(name string, kind connStrOptionKind, valStr string) (interface{}, error) {
+	switch kind {
+	case connStrOptionBool:
+		val, err := strconv.ParseBool(valStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s option must be a boolean", name)
+		}
+		return val, nil
+	case connStrOptionInt:
+		val, err := strconv.ParseInt(valStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s option must be a number", name)
+		}
+		return val, nil
+	case connStrOptionFloat:
+		val, err := strconv.ParseFloat(valStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s option must be a number", name)
+		}
+		return val, nil
+	case connStrOptionDuration:
+		val, err := strconv.ParseInt(valStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s option must be a duration in milliseconds", name)
+		}
+		return time.Duration(val) * time.Millisecond, nil
+	}
+
+	if allowed, ok := connStrOptionValues[name]; ok {
+		for _, value := range allowed {
+			if valStr == value {
+				return valStr, nil
+			}
+		}
+		return nil, fmt.Errorf("%s option must be one of %s", name, strings.Join(allowed, ", "))
+	}
+
+	return valStr, nil
+}
@@ -0,0 +1,89 @@
+package gocb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateConnectionString(t *testing.T) {
+	report, err := ValidateConnectionString(
+		"couchbases://10.0.0.1,10.0.0.2:11207?kv_pool_size=2&query_timeout=500&compression=false&bootstrap_on=cccp")
+	if err != nil {
+		t.Fatalf("ValidateConnectionString failed: %v", err)
+	}
+
+	if !report.Valid() || len(report.Warnings) != 0 || len(report.UnknownOptions) != 0 {
+		t.Fatalf("Expected connection string to be valid but was %+v", report)
+	}
+
+	if !report.UseTLS || len(report.Hosts) != 2 || report.Hosts[0] != "10.0.0.1" || report.Hosts[1] != "10.0.0.2:11207" {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+
+	expected := []ConnectionStringOption{
+		{Name: "bootstrap_on", Value: "cccp"},
+		{Name: "compression", Value: false},
+		{Name: "kv_pool_size", Value: int64(2)},
+		{Name: "query_timeout", Value: 500 * time.Millisecond},
+	}
+	if len(report.Options) != len(expected) {
+		t.Fatalf("Unexpected options %+v", report.Options)
+	}
+	for i, option := range expected {
+		if report.Options[i] != option {
+			t.Fatalf("Expected option %+v but was %+v", option, report.Options[i])
+		}
+	}
+}
+
+func TestValidateConnectionStringProblems(t *testing.T) {
+	report, err := ValidateConnectionString(
+		"couchbase://10.0.0.1:11207?kv_pool_sise=2&query_timeout=fast&bootstrap_on=sometimes&ca_cert_path=/ca.pem")
+	if err != nil {
+		t.Fatalf("ValidateConnectionString failed: %v", err)
+	}
+
+	if report.Valid() {
+		t.Fatalf("Expected connection string to be invalid")
+	}
+
+	expectedErrors := []string{
+		"host 10.0.0.1 uses a TLS port without the couchbases scheme",
+		"bootstrap_on option must be one of http, cccp, both",
+		"query_timeout option must be a duration in milliseconds",
+	}
+	if len(report.Errors) != len(expectedErrors) {
+		t.Fatalf("Unexpected errors %v", report.Errors)
+	}
+	for i, msg := range expectedErrors {
+		if report.Errors[i] != msg {
+			t.Fatalf("Expected error %q but was %q", msg, report.Errors[i])
+		}
+	}
+
+	expectedWarnings := []string{
+		"unrecognised option kv_pool_sise, did you mean kv_pool_size?",
+		"ca_cert_path is ignored without the couchbases scheme",
+	}
+	if len(report.Warnings) != len(expectedWarnings) {
+		t.Fatalf("Unexpected warnings %v", report.Warnings)
+	}
+	for i, msg := range expectedWarnings {
+		if report.Warnings[i] != msg {
+			t.Fatalf("Expected warning %q but was %q", msg, report.Warnings[i])
+		}
+	}
+
+	if len(report.UnknownOptions) != 1 || report.UnknownOptions[0] != "kv_pool_sise" {
+		t.Fatalf("Unexpected unknown options %v", report.UnknownOptions)
+	}
+
+	report, err = ValidateConnectionString("http://10.0.0.1")
+	if err != nil {
+		t.Fatalf("ValidateConnectionString failed: %v", err)
+	}
+
+	if report.Valid() {
+		t.Fatalf("Expected http scheme to be invalid")
+	}
+}
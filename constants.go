@@ -126,6 +126,21 @@ const (
 	SubdocDocFlagAccessDeleted = SubdocDocFlag(gocbcore.SubdocDocFlagAccessDeleted)
 )
 
+// DocumentDatatype specifies the datatype bits which the server stores alongside the value of a document.
+type DocumentDatatype uint8
+
+const (
+	// DocumentDatatypeJSON indicates the server believes the value to be JSON.
+	DocumentDatatypeJSON = DocumentDatatype(gocbcore.DatatypeFlagJSON)
+
+	// DocumentDatatypeCompressed indicates the value was snappy compressed.  Values are always
+	// decompressed before being returned, so this is never set on a result.
+	DocumentDatatypeCompressed = DocumentDatatype(gocbcore.DatatypeFlagCompressed)
+
+	// DocumentDatatypeXattrs indicates the document has extended attributes.
+	DocumentDatatypeXattrs = DocumentDatatype(gocbcore.DatatypeFlagXattrs)
+)
+
 // DurabilityLevel specifies the level of synchronous replication to use.
 type DurabilityLevel uint8
 
@@ -3,6 +3,8 @@ package gocb
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	gocbcore "github.com/couchbase/gocbcore/v8"
 )
@@ -49,6 +51,59 @@ func makeInvalidArgumentsError(message string) error {
 	}
 }
 
+// ValueTooLargeError is returned when an encoded document value is larger than
+// OperationLimitsConfig.MaxValueSize, before any request is sent to the server.
+type ValueTooLargeError struct {
+	DocumentID string
+	Size       int
+	MaxSize    uint32
+}
+
+func (e ValueTooLargeError) Error() string {
+	return fmt.Sprintf("value of document %s is %d bytes which is larger than the maximum of %d bytes",
+		e.DocumentID, e.Size, e.MaxSize)
+}
+
+// Unwrap returns ErrValueTooLarge.
+func (e ValueTooLargeError) Unwrap() error {
+	return ErrValueTooLarge
+}
+
+// ClusterCloseError is returned from Close when one or more of the clients used by a
+// Cluster failed to shut down.
+type ClusterCloseError struct {
+	// ClientErrors maps the name of each client which failed to close to the reason why.  Bucket
+	// clients are named after their bucket, and the client used for cluster level operations is
+	// named <cluster>.
+	ClientErrors map[string]error
+}
+
+func (e ClusterCloseError) Error() string {
+	names := make([]string, 0, len(e.ClientErrors))
+	for name := range e.ClientErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failures := make([]string, len(names))
+	for i, name := range names {
+		failures[i] = fmt.Sprintf("%s (%s)", name, e.ClientErrors[name])
+	}
+
+	return fmt.Sprintf("failed to close clients: %s", strings.Join(failures, ", "))
+}
+
+// Is returns whether any of the client errors matches target.
+func (e ClusterCloseError) Is(target error) bool {
+	for _, err := range e.ClientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Shared Error Definitions RFC#58@15
 var (
 	// ErrTimeout occurs when an operation does not receive a response in a timely manner.
@@ -137,6 +192,10 @@ var (
 	ErrDataverseExists = gocbcore.ErrDataverseExists
 
 	ErrLinkNotFound = gocbcore.ErrLinkNotFound
+
+	// ErrAnalyticsLinkExists occurs when creating an analytics link with the name of a link which
+	// already exists in the dataverse.
+	ErrAnalyticsLinkExists = errors.New("analytics link already exists")
 )
 
 // Search Error Definitions RFC#58@15
@@ -165,4 +224,39 @@ var (
 	ErrOverload = gocbcore.ErrOverload
 
 	ErrNoResult = errors.New("no result was available")
+
+	// ErrBucketSettingImmutable occurs when attempting to change a bucket setting which can only
+	// be specified when the bucket is created.
+	ErrBucketSettingImmutable = errors.New("bucket setting cannot be changed after creation")
+
+	// ErrMutexLocked occurs when attempting to lock a mutex which is held by another owner.
+	ErrMutexLocked = errors.New("mutex is held by another owner")
+
+	// ErrMutexLeaseLost occurs when using a mutex lease which has expired, and so may have been
+	// acquired by another owner.
+	ErrMutexLeaseLost = errors.New("mutex lease has expired")
+
+	// ErrRateLimited occurs when the server rejects an operation because a rate limit, such as the
+	// number of requests or amount of data per minute, has been exceeded.  It is not retried by the
+	// RetryStrategy, so it is returned as soon as the server responds, and the application can retry
+	// the operation once the limit has recovered.  Search requests rejected with HTTP 429 are the
+	// exception, being retried with SearchTooManyRequestsRetryReason.
+	ErrRateLimited = errors.New("operation was rate limited")
+
+	// ErrQuotaLimited occurs when the server rejects an operation because a quota, such as the size
+	// of a scope or the number of indexes, has been reached.  It is never retried, as retrying the
+	// operation will not succeed until the usage has been reduced or the quota raised.
+	ErrQuotaLimited = errors.New("operation was quota limited")
+
+	// ErrOperationQueueFull occurs when an operation cannot be queued as too many operations
+	// are already waiting for the limits set by OperationLimitsConfig.
+	ErrOperationQueueFull = errors.New("operation queue is full")
+
+	// ErrReadOnly occurs when an operation which would modify data is attempted by a Cluster
+	// connected with ClusterOptions.ReadOnly set.
+	ErrReadOnly = errors.New("operation not permitted in read-only mode")
+
+	// ErrDeveloperPreviewNotEnabled occurs when an API which depends on developer preview server
+	// features is used by a Cluster connected without ClusterOptions.EnableDeveloperPreview set.
+	ErrDeveloperPreviewNotEnabled = errors.New("developer preview features are not enabled")
 )
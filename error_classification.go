@@ -0,0 +1,93 @@
+package gocb
+
+import (
+	"errors"
+)
+
+// IsTimeout returns whether err was caused by an operation timing out, regardless
+// of whether the timeout was ambiguous.
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout)
+}
+
+// IsAmbiguousTimeout returns whether err was caused by an operation timing out in a
+// way that means it may, or may not, have been applied on the server.  Retrying a
+// non-idempotent operation after an ambiguous timeout may apply it twice.
+func IsAmbiguousTimeout(err error) bool {
+	return errors.Is(err, ErrAmbiguousTimeout)
+}
+
+// IsUnambiguousTimeout returns whether err was caused by an operation timing out
+// before it could have been applied on the server.
+func IsUnambiguousTimeout(err error) bool {
+	return errors.Is(err, ErrUnambiguousTimeout)
+}
+
+// IsTemporaryFailure returns whether err was caused by a temporary condition on
+// the server, such as it being out of memory or warming up.
+func IsTemporaryFailure(err error) bool {
+	return errors.Is(err, ErrTemporaryFailure) || errors.Is(err, ErrOverload)
+}
+
+// ErrorRetryReasons returns the reasons that the operation which produced err was
+// retried, if any are available.
+func ErrorRetryReasons(err error) []RetryReason {
+	var kvErr KeyValueError
+	if errors.As(err, &kvErr) {
+		return kvErr.RetryReasons
+	}
+
+	var queryErr QueryError
+	if errors.As(err, &queryErr) {
+		return queryErr.RetryReasons
+	}
+
+	var analyticsErr AnalyticsError
+	if errors.As(err, &analyticsErr) {
+		return analyticsErr.RetryReasons
+	}
+
+	var searchErr SearchError
+	if errors.As(err, &searchErr) {
+		return searchErr.RetryReasons
+	}
+
+	var viewErr ViewError
+	if errors.As(err, &viewErr) {
+		return viewErr.RetryReasons
+	}
+
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.RetryReasons
+	}
+
+	return nil
+}
+
+// IsRetryableError returns whether the operation which produced err can safely be
+// retried by the application, regardless of whether the operation is idempotent.
+// Ambiguous timeouts are never considered retryable as the operation may already
+// have been applied; use IsAmbiguousTimeout to handle those explicitly.
+func IsRetryableError(err error) bool {
+	if err == nil || IsAmbiguousTimeout(err) {
+		return false
+	}
+
+	if IsUnambiguousTimeout(err) ||
+		IsTemporaryFailure(err) ||
+		errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrDocumentLocked) ||
+		errors.Is(err, ErrDurableWriteInProgress) ||
+		errors.Is(err, ErrDurableWriteReCommitInProgress) {
+		return true
+	}
+
+	for _, reason := range ErrorRetryReasons(err) {
+		if reason.AlwaysRetry() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package gocb
+
+import (
+	"testing"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"nil", nil, false},
+		{"temporary failure", KeyValueError{InnerError: ErrTemporaryFailure}, true},
+		{"unambiguous timeout", wrapError(ErrUnambiguousTimeout, "timed out"), true},
+		{"ambiguous timeout", KeyValueError{InnerError: ErrAmbiguousTimeout}, false},
+		{"document not found", KeyValueError{InnerError: ErrDocumentNotFound}, false},
+		{"rate limited", KeyValueError{InnerError: ErrRateLimited}, true},
+		{"quota limited", KeyValueError{InnerError: ErrQuotaLimited}, false},
+		{"always retry reason", QueryError{
+			InnerError:   ErrInternalServerFailure,
+			RetryReasons: []RetryReason{KVNotMyVBucketRetryReason},
+		}, true},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			if IsRetryableError(tCase.err) != tCase.retryable {
+				t.Fatalf("Expected IsRetryableError to be %t for %v", tCase.retryable, tCase.err)
+			}
+		})
+	}
+}
+
+func TestIsTimeoutPredicates(t *testing.T) {
+	ambiguous := KeyValueError{InnerError: ErrAmbiguousTimeout}
+	if !IsTimeout(ambiguous) || !IsAmbiguousTimeout(ambiguous) || IsUnambiguousTimeout(ambiguous) {
+		t.Fatalf("Ambiguous timeout was not classified correctly")
+	}
+
+	unambiguous := KeyValueError{InnerError: ErrUnambiguousTimeout}
+	if !IsTimeout(unambiguous) || IsAmbiguousTimeout(unambiguous) || !IsUnambiguousTimeout(unambiguous) {
+		t.Fatalf("Unambiguous timeout was not classified correctly")
+	}
+}
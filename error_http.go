@@ -47,6 +47,10 @@ func makeGenericHTTPError(baseErr error, req *gocbcore.HTTPRequest, resp *gocbco
 }
 
 func makeHTTPBadStatusError(message string, req *gocbcore.HTTPRequest, resp *gocbcore.HTTPResponse) error {
+	if resp != nil && resp.StatusCode == 429 {
+		return makeGenericHTTPError(wrapError(ErrRateLimited, message), req, resp)
+	}
+
 	return makeGenericHTTPError(errors.New(message), req, resp)
 }
 
@@ -72,5 +76,9 @@ func makeGenericMgmtError(baseErr error, req *mgmtRequest, resp *mgmtResponse) e
 }
 
 func makeMgmtBadStatusError(message string, req *mgmtRequest, resp *mgmtResponse) error {
+	if resp != nil && resp.StatusCode == 429 {
+		return makeGenericMgmtError(wrapError(ErrRateLimited, message), req, resp)
+	}
+
 	return makeGenericMgmtError(errors.New(message), req, resp)
 }
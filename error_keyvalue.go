@@ -29,3 +29,23 @@ func (e KeyValueError) Error() string {
 func (e KeyValueError) Unwrap() error {
 	return e.InnerError
 }
+
+// SubDocumentError is the error type of a single sub-document spec which failed, such as
+// ErrPathNotFound or ErrPathExists.  For LookupIn it is returned by LookupInResult.ContentAt for
+// the spec, and for MutateIn it is returned by the operation for the first spec which failed.
+// UNCOMMITTED: This API may change in the future.
+type SubDocumentError struct {
+	InnerError error  `json:"-"`
+	Index      int    `json:"index"`
+	Path       string `json:"path,omitempty"`
+}
+
+// Error returns the string representation of a sub-document error.
+func (e SubDocumentError) Error() string {
+	return e.InnerError.Error() + " | " + serializeWrappedError(e)
+}
+
+// Unwrap returns the underlying reason for the error
+func (e SubDocumentError) Unwrap() error {
+	return e.InnerError
+}
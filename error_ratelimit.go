@@ -0,0 +1,69 @@
+package gocb
+
+import (
+	"strings"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// The memcached status codes used by Couchbase Server 7.0 and above to indicate that a limit was
+// exceeded, these are not yet known by gocbcore.
+const (
+	memdStatusRateLimitedNetworkIngress = gocbcore.StatusCode(0x30)
+	memdStatusRateLimitedNetworkEgress  = gocbcore.StatusCode(0x31)
+	memdStatusRateLimitedMaxConnections = gocbcore.StatusCode(0x32)
+	memdStatusRateLimitedMaxCommands    = gocbcore.StatusCode(0x33)
+	memdStatusScopeSizeLimitExceeded    = gocbcore.StatusCode(0x34)
+)
+
+// The query error codes used to indicate that a user limit was exceeded.
+const (
+	queryErrCodeUserRequestExceeded     = 1191
+	queryErrCodeUserRequestRateExceeded = 1192
+	queryErrCodeUserRequestSizeExceeded = 1193
+	queryErrCodeUserResultSizeExceeded  = 1194
+)
+
+func translateKVLimitErr(code gocbcore.StatusCode, err error) error {
+	switch code {
+	case memdStatusRateLimitedNetworkIngress, memdStatusRateLimitedNetworkEgress,
+		memdStatusRateLimitedMaxConnections, memdStatusRateLimitedMaxCommands:
+		return ErrRateLimited
+	case memdStatusScopeSizeLimitExceeded:
+		return ErrQuotaLimited
+	}
+
+	return err
+}
+
+func translateQueryLimitErr(descs []gocbcore.N1QLErrorDesc, err error) error {
+	for _, desc := range descs {
+		switch desc.Code {
+		case queryErrCodeUserRequestExceeded, queryErrCodeUserRequestRateExceeded,
+			queryErrCodeUserRequestSizeExceeded, queryErrCodeUserResultSizeExceeded:
+			return ErrRateLimited
+		}
+
+		if desc.Code == 5000 && strings.Contains(desc.Message, "Limit for number of indexes that can be created per scope has been reached") {
+			return ErrQuotaLimited
+		}
+	}
+
+	return err
+}
+
+func translateSearchLimitErr(statusCode int, errText string, err error) error {
+	if statusCode == 429 {
+		if strings.Contains(errText, "num_concurrent_requests") ||
+			strings.Contains(errText, "num_queries_per_min") ||
+			strings.Contains(errText, "ingress_mib_per_min") ||
+			strings.Contains(errText, "egress_mib_per_min") {
+			return ErrRateLimited
+		}
+	}
+	if statusCode == 400 && strings.Contains(errText, "num_fts_indexes") {
+		return ErrQuotaLimited
+	}
+
+	return err
+}
@@ -0,0 +1,105 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestEnhanceKVLimitErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		code     gocbcore.StatusCode
+		expected error
+	}{
+		{"network ingress", memdStatusRateLimitedNetworkIngress, ErrRateLimited},
+		{"network egress", memdStatusRateLimitedNetworkEgress, ErrRateLimited},
+		{"max connections", memdStatusRateLimitedMaxConnections, ErrRateLimited},
+		{"max commands", memdStatusRateLimitedMaxCommands, ErrRateLimited},
+		{"scope size", memdStatusScopeSizeLimitExceeded, ErrQuotaLimited},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			err := maybeEnhanceCoreErr(gocbcore.KeyValueError{
+				InnerError: errors.New("unknown kv status code"),
+				StatusCode: tCase.code,
+			})
+			if !errors.Is(err, tCase.expected) {
+				t.Fatalf("Expected error to be %v but was %v", tCase.expected, err)
+			}
+
+			var kvErr KeyValueError
+			if !errors.As(err, &kvErr) || kvErr.StatusCode != tCase.code {
+				t.Fatalf("Expected KeyValueError with status code %d but was %v", tCase.code, err)
+			}
+		})
+	}
+
+	err := maybeEnhanceCoreErr(gocbcore.KeyValueError{
+		InnerError: gocbcore.ErrDocumentNotFound,
+		StatusCode: gocbcore.StatusKeyNotFound,
+	})
+	if !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("Expected error to be document not found but was %v", err)
+	}
+}
+
+func TestEnhanceQueryLimitErrors(t *testing.T) {
+	err := maybeEnhanceCoreErr(gocbcore.N1QLError{
+		InnerError: errors.New("query error"),
+		Errors: []gocbcore.N1QLErrorDesc{
+			{Code: 1192, Message: "User has more requests per minute than allowed"},
+		},
+	})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected error to be rate limited but was %v", err)
+	}
+
+	err = maybeEnhanceCoreErr(gocbcore.N1QLError{
+		InnerError: errors.New("query error"),
+		Errors: []gocbcore.N1QLErrorDesc{
+			{Code: 5000, Message: "Limit for number of indexes that can be created per scope has been reached. Limit : 1"},
+		},
+	})
+	if !errors.Is(err, ErrQuotaLimited) {
+		t.Fatalf("Expected error to be quota limited but was %v", err)
+	}
+}
+
+func TestEnhanceSearchLimitErrors(t *testing.T) {
+	err := maybeEnhanceCoreErr(&gocbcore.SearchError{
+		InnerError:       errors.New("search error"),
+		ErrorText:        "rest_auth: preparePerms, err: num_queries_per_min limit exceeded",
+		HTTPResponseCode: 429,
+	})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected error to be rate limited but was %v", err)
+	}
+
+	var searchErr SearchError
+	if !errors.As(err, &searchErr) {
+		t.Fatalf("Expected error to be a SearchError but was %v", err)
+	}
+
+	err = maybeEnhanceCoreErr(&gocbcore.SearchError{
+		InnerError:       errors.New("search error"),
+		HTTPResponseCode: 429,
+	})
+	if errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected error to not be rate limited")
+	}
+}
+
+func TestMgmtRateLimitedError(t *testing.T) {
+	err := makeMgmtBadStatusError("failed to get all indexes", &mgmtRequest{}, &mgmtResponse{StatusCode: 429})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected error to be rate limited but was %v", err)
+	}
+
+	err = makeMgmtBadStatusError("failed to get all indexes", &mgmtRequest{}, &mgmtResponse{StatusCode: 500})
+	if errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected error to not be rate limited")
+	}
+}
@@ -17,7 +17,7 @@ func serializeWrappedError(err error) string {
 func maybeEnhanceCoreErr(err error) error {
 	if kvErr, ok := err.(gocbcore.KeyValueError); ok {
 		return KeyValueError{
-			InnerError:       kvErr.InnerError,
+			InnerError:       translateSubDocErr(translateKVLimitErr(kvErr.StatusCode, kvErr.InnerError)),
 			StatusCode:       kvErr.StatusCode,
 			BucketName:       kvErr.BucketName,
 			ScopeName:        kvErr.ScopeName,
@@ -32,6 +32,16 @@ func maybeEnhanceCoreErr(err error) error {
 			RetryAttempts:    kvErr.RetryAttempts,
 		}
 	}
+	if subdocErr, ok := err.(gocbcore.SubDocumentError); ok {
+		innerErr := subdocErr.InnerError
+		if kvErr, ok := innerErr.(*gocbcore.KeyValueError); ok {
+			innerErr = *kvErr
+		}
+		return SubDocumentError{
+			InnerError: translateSubDocErr(maybeEnhanceCoreErr(innerErr)),
+			Index:      subdocErr.Index,
+		}
+	}
 	if viewErr, ok := err.(gocbcore.ViewError); ok {
 		return ViewError{
 			InnerError:         viewErr.InnerError,
@@ -45,7 +55,7 @@ func maybeEnhanceCoreErr(err error) error {
 	}
 	if queryErr, ok := err.(gocbcore.N1QLError); ok {
 		return QueryError{
-			InnerError:      queryErr.InnerError,
+			InnerError:      translateQueryLimitErr(queryErr.Errors, queryErr.InnerError),
 			Statement:       queryErr.Statement,
 			ClientContextID: queryErr.ClientContextID,
 			Errors:          translateCoreQueryErrorDesc(queryErr.Errors),
@@ -65,6 +75,15 @@ func maybeEnhanceCoreErr(err error) error {
 			RetryAttempts:   analyticsErr.RetryAttempts,
 		}
 	}
+	if searchErr, ok := err.(*gocbcore.SearchError); ok {
+		return SearchError{
+			InnerError:    translateSearchLimitErr(searchErr.HTTPResponseCode, searchErr.ErrorText, searchErr.InnerError),
+			Query:         searchErr.Query,
+			Endpoint:      searchErr.Endpoint,
+			RetryReasons:  translateCoreRetryReasons(searchErr.RetryReasons),
+			RetryAttempts: searchErr.RetryAttempts,
+		}
+	}
 	if httpErr, ok := err.(gocbcore.HTTPError); ok {
 		return HTTPError{
 			InnerError:    httpErr.InnerError,
@@ -77,11 +96,29 @@ func maybeEnhanceCoreErr(err error) error {
 	return err
 }
 
+// translateSubDocErr translates the sub-document statuses which gocbcore does not map to an error.
+func translateSubDocErr(err error) error {
+	if err == gocbcore.ErrMemdSubDocPathMismatch {
+		return ErrPathMismatch
+	}
+	return err
+}
+
+// withSubDocPath sets the path of err if it is a SubDocumentError.
+func withSubDocPath(err error, ops []gocbcore.SubDocOp) error {
+	if subdocErr, ok := err.(SubDocumentError); ok && subdocErr.Index >= 0 && subdocErr.Index < len(ops) {
+		subdocErr.Path = ops[subdocErr.Index].Path
+		return subdocErr
+	}
+	return err
+}
+
 func maybeEnhanceKVErr(err error, bucketName, scopeName, collName, docKey string) error {
 	return maybeEnhanceCoreErr(err)
 }
 
 func maybeEnhanceCollKVErr(err error, bucket kvProvider, coll *Collection, docKey string) error {
+	coll.sb.Telemetry.recordError("kv", err)
 	return maybeEnhanceKVErr(err, coll.sb.BucketName, coll.Name(), coll.scopeName(), docKey)
 }
 
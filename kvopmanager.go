@@ -1,7 +1,9 @@
 package gocb
 
 import (
+	"fmt"
 	"time"
+	"unicode"
 
 	gocbcore "github.com/couchbase/gocbcore/v8"
 	"github.com/pkg/errors"
@@ -12,10 +14,11 @@ type kvOpManager struct {
 	signal chan struct{}
 
 	err           error
+	opErr         error
 	wasResolved   bool
 	mutationToken *MutationToken
 
-	span            requestSpan
+	span            RequestSpan
 	documentID      string
 	transcoder      Transcoder
 	deadline        time.Time
@@ -26,21 +29,60 @@ type kvOpManager struct {
 	durabilityLevel DurabilityLevel
 	retryStrategy   *retryStrategyWrapper
 	cancelCh        chan struct{}
+	releaseLimit    func()
+	serverDuration  serverDurationRecorder
 }
 
 func (m *kvOpManager) SetDocumentID(id string) {
+	if err := validateDocumentID(id); err != nil && m.err == nil {
+		m.err = err
+	}
 	m.documentID = id
 }
 
+// maxDocumentIDLength is the longest document ID, in bytes, which the server accepts.
+const maxDocumentIDLength = 250
+
+// validateDocumentID checks that a document ID would be accepted by the server, so that we can
+// return an error naming the ID rather than sending a request which is bound to fail.
+func validateDocumentID(id string) error {
+	if id == "" {
+		return makeInvalidArgumentsError("document ID must not be empty")
+	}
+
+	if len(id) > maxDocumentIDLength {
+		return makeInvalidArgumentsError(fmt.Sprintf("document ID %q is %d bytes, longer than the maximum of %d bytes",
+			id, len(id), maxDocumentIDLength))
+	}
+
+	for _, r := range id {
+		if unicode.IsControl(r) {
+			return makeInvalidArgumentsError(fmt.Sprintf("document ID %q contains the control character %U", id, r))
+		}
+	}
+
+	return nil
+}
+
 func (m *kvOpManager) SetCancelCh(cancelCh chan struct{}) {
 	m.cancelCh = cancelCh
 }
 
 func (m *kvOpManager) SetTimeout(timeout time.Duration) {
-	if timeout == 0 || timeout > m.parent.sb.KvTimeout {
-		timeout = m.parent.sb.KvTimeout
+	if timeout == 0 || timeout > m.parent.sb.kvTimeout() {
+		timeout = m.parent.sb.kvTimeout()
 	}
-	m.deadline = time.Now().Add(timeout)
+	m.deadline = clockOrSystem(m.parent.sb.Clock).Now().Add(timeout)
+}
+
+// SetDeadline bounds the operation by a deadline derived from a parent operation, so that
+// each step of a composite operation shares the budget of the operation as a whole.
+func (m *kvOpManager) SetDeadline(deadline time.Time) {
+	m.deadline = deadline
+}
+
+func (m *kvOpManager) SetTags(tags map[string]string) {
+	m.span = applyOperationTags(m.span, tags)
 }
 
 func (m *kvOpManager) SetTranscoder(transcoder Transcoder) {
@@ -59,7 +101,7 @@ func (m *kvOpManager) SetValue(val interface{}) {
 		return
 	}
 
-	espan := m.parent.startKvOpTrace("encode", m.span)
+	espan := m.parent.startKvOpTrace("encode", m.span.Context())
 	defer espan.Finish()
 
 	bytes, flags, err := m.transcoder.Encode(val)
@@ -68,6 +110,12 @@ func (m *kvOpManager) SetValue(val interface{}) {
 		return
 	}
 
+	err = m.parent.checkValueSize(m.documentID, len(bytes))
+	if err != nil {
+		m.err = err
+		return
+	}
+
 	m.bytes = bytes
 	m.flags = flags
 }
@@ -91,21 +139,40 @@ func (m *kvOpManager) SetDuraOptions(persistTo, replicateTo uint, level Durabili
 }
 
 func (m *kvOpManager) SetRetryStrategy(retryStrategy RetryStrategy) {
-	wrapper := m.parent.sb.RetryStrategyWrapper
-	if retryStrategy != nil {
-		wrapper = newRetryStrategyWrapper(retryStrategy)
-	}
+	wrapper := m.parent.sb.RetryStrategyWrapper.withStrategy(retryStrategy)
 	m.retryStrategy = wrapper
 }
 
 func (m *kvOpManager) Finish() {
+	if m.releaseLimit != nil {
+		m.releaseLimit()
+	}
+
+	if m.err != nil {
+		setSpanOutcome(m.span, m.err)
+	} else {
+		setSpanOutcome(m.span, m.opErr)
+	}
 	m.span.Finish()
 }
 
-func (m *kvOpManager) TraceSpan() requestSpan {
+func (m *kvOpManager) TraceSpan() RequestSpan {
 	return m.span
 }
 
+// TraceContext returns the context to pass to gocbcore as the parent of its spans, which records
+// the server duration of the operation.
+func (m *kvOpManager) TraceContext() RequestSpanContext {
+	return &serverDurationContext{
+		parent:   m.span,
+		recorder: &m.serverDuration,
+	}
+}
+
+func (m *kvOpManager) ServerDuration() time.Duration {
+	return m.serverDuration.Duration()
+}
+
 func (m *kvOpManager) DocumentID() []byte {
 	return []byte(m.documentID)
 }
@@ -139,7 +206,7 @@ func (m *kvOpManager) DurabilityLevel() gocbcore.DurabilityLevel {
 }
 
 func (m *kvOpManager) DurabilityTimeout() uint16 {
-	duraTimeout := m.deadline.Sub(time.Now()) * 10 / 9
+	duraTimeout := m.deadline.Sub(clockOrSystem(m.parent.sb.Clock).Now()) * 10 / 9
 	return uint16(duraTimeout / time.Millisecond)
 }
 
@@ -156,6 +223,13 @@ func (m *kvOpManager) CheckReadyForOp() error {
 		return errors.New("op manager had no deadline specified")
 	}
 
+	releaseLimit, err := m.parent.sb.KvLimiter.Acquire(m.deadline)
+	if err != nil {
+		m.opErr = err
+		return err
+	}
+	m.releaseLimit = releaseLimit
+
 	return nil
 }
 
@@ -163,8 +237,13 @@ func (m *kvOpManager) NeedsObserve() bool {
 	return m.persistTo > 0 || m.replicateTo > 0
 }
 
+// EnhanceErr enhances the error of the operation, which is also used as its outcome.
 func (m *kvOpManager) EnhanceErr(err error) error {
-	return maybeEnhanceCollKVErr(err, nil, m.parent, m.documentID)
+	err = maybeEnhanceCollKVErr(err, nil, m.parent, m.documentID)
+	if err != nil {
+		m.opErr = err
+	}
+	return err
 }
 
 func (m *kvOpManager) EnhanceMt(token gocbcore.MutationToken) *MutationToken {
@@ -188,16 +267,31 @@ func (m *kvOpManager) Resolve(token *MutationToken) {
 	m.signal <- struct{}{}
 }
 
+// Wait waits for the operation to complete, and for any durability requirements to be met.  Any
+// error returned is also used as the outcome of the operation.
 func (m *kvOpManager) Wait(op gocbcore.PendingOp, err error) error {
+	err = m.wait(op, err)
+	if err != nil {
+		m.opErr = err
+	}
+	return err
+}
+
+func (m *kvOpManager) wait(op gocbcore.PendingOp, err error) error {
+	if err != nil {
+		return err
+	}
 	if m.err != nil {
 		op.Cancel(errors.New("performed operation with invalid data"))
 	}
 
+	clk := clockOrSystem(m.parent.sb.Clock)
+
 	// We do this to allow operations with no deadline to still proceed
 	// without immediately timing out due to bad math on the sub time below.
 	waitDeadline := m.deadline
 	if waitDeadline.IsZero() {
-		waitDeadline = time.Now().Add(24 * time.Hour)
+		waitDeadline = clk.Now().Add(24 * time.Hour)
 	}
 
 	select {
@@ -206,7 +300,7 @@ func (m *kvOpManager) Wait(op gocbcore.PendingOp, err error) error {
 	case <-m.cancelCh:
 		op.Cancel(ErrRequestCanceled)
 		<-m.signal
-	case <-time.After(waitDeadline.Sub(time.Now())):
+	case <-clk.After(waitDeadline.Sub(clk.Now())):
 		// Ran out of time...
 		op.Cancel(ErrAmbiguousTimeout)
 		<-m.signal
@@ -217,8 +311,15 @@ func (m *kvOpManager) Wait(op gocbcore.PendingOp, err error) error {
 			return errors.New("expected a mutation token")
 		}
 
+		// The mutation has completed, so its slot is given up to the observes which follow, each
+		// of which is limited on its own.
+		if m.releaseLimit != nil {
+			m.releaseLimit()
+			m.releaseLimit = nil
+		}
+
 		return m.parent.waitForDurability(
-			m.span,
+			m.span.Context(),
 			m.documentID,
 			m.mutationToken.token,
 			m.replicateTo,
@@ -231,7 +332,7 @@ func (m *kvOpManager) Wait(op gocbcore.PendingOp, err error) error {
 	return nil
 }
 
-func (c *Collection) newKvOpManager(opName string, tracectx requestSpanContext) *kvOpManager {
+func (c *Collection) newKvOpManager(opName string, tracectx RequestSpanContext) *kvOpManager {
 	span := c.startKvOpTrace(opName, tracectx)
 
 	return &kvOpManager{
@@ -0,0 +1,51 @@
+package gocb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateDocumentID(t *testing.T) {
+	valid := []string{"a", "user::1234", "ключ", strings.Repeat("a", 250)}
+	for _, id := range valid {
+		if err := validateDocumentID(id); err != nil {
+			t.Fatalf("Expected %q to be valid but was %v", id, err)
+		}
+	}
+
+	invalid := []string{"", strings.Repeat("a", 251), "line\nbreak", "nul\x00", "del\x7f"}
+	for _, id := range invalid {
+		if err := validateDocumentID(id); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("Expected %q to be invalid but was %v", id, err)
+		}
+	}
+}
+
+func TestInvalidDocumentID(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{cas: 10, value: []byte(`{}`), flags: 2 << 24})
+
+	_, err := col.Get("bad\tid", nil)
+	if !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), `"bad\tid"`) {
+		t.Fatalf("Expected invalid argument naming the ID but was %v", err)
+	}
+
+	_, err = col.Upsert(strings.Repeat("a", 251), "value", nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", err)
+	}
+
+	ops := []BulkOp{&GetOp{ID: "bad\tid"}, &GetOp{ID: "good"}}
+	err = col.Do(ops, nil)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	if !errors.Is(ops[0].(*GetOp).Err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument but was %v", ops[0].(*GetOp).Err)
+	}
+
+	if ops[1].(*GetOp).Err != nil {
+		t.Fatalf("Expected valid ID to succeed but was %v", ops[1].(*GetOp).Err)
+	}
+}
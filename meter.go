@@ -0,0 +1,241 @@
+package gocb
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// meterNameOperations records the duration, in microseconds, of each operation.
+	meterNameOperations = "db.couchbase.operations"
+	// meterNameRequests counts the operations performed.
+	meterNameRequests = "db.couchbase.requests"
+	// meterNameRetries counts the retries of requests which the retry strategy allowed.
+	meterNameRetries = "db.couchbase.retries"
+	// meterNameQueryCache counts the queries which used, or had to populate, the prepared
+	// statement cache.
+	meterNameQueryCache = "db.couchbase.query.prepared_cache"
+	// meterNameQueryPrepares records the duration, in microseconds, of each PREPARE request.
+	meterNameQueryPrepares = "db.couchbase.query.prepares"
+
+	meterTagService     = "db.couchbase.service"
+	meterTagOperation   = "db.operation"
+	meterTagOutcome     = "outcome"
+	meterTagRetryReason = "db.couchbase.retry_reason"
+	meterTagCacheResult = "db.couchbase.cache_result"
+
+	// spanTagOutcome is set on the span of an operation when it completes, and is used as the
+	// outcome tag of its metrics.
+	spanTagOutcome = "couchbase.outcome"
+)
+
+const (
+	cacheResultHit  = "hit"
+	cacheResultMiss = "miss"
+)
+
+const (
+	outcomeSuccess = "Success"
+	outcomeTimeout = "Timeout"
+	outcomeError   = "Error"
+)
+
+// setSpanOutcome tags the span of an operation with whether it succeeded, timed out or failed.
+func setSpanOutcome(span RequestSpan, err error) {
+	outcome := outcomeSuccess
+	if errors.Is(err, ErrTimeout) {
+		outcome = outcomeTimeout
+	} else if err != nil {
+		outcome = outcomeError
+	}
+
+	span.SetTag(spanTagOutcome, outcome)
+}
+
+// meterIncrement increments the named counter of meter by one, if a meter is set.
+func meterIncrement(meter Meter, name string, tags map[string]string) {
+	if meter == nil {
+		return
+	}
+
+	counter, err := meter.Counter(name, tags)
+	if err != nil {
+		logDebugf("Failed to create counter: %s", err)
+		return
+	}
+	counter.IncrementBy(1)
+}
+
+// meterRecordDuration records d, in microseconds, to the named value recorder of meter, if a
+// meter is set.
+func meterRecordDuration(meter Meter, name string, tags map[string]string, d time.Duration) {
+	if meter == nil {
+		return
+	}
+
+	recorder, err := meter.ValueRecorder(name, tags)
+	if err != nil {
+		logDebugf("Failed to create value recorder: %s", err)
+		return
+	}
+	recorder.RecordValue(uint64(d / time.Microsecond))
+}
+
+// Meter creates the instruments used to record metrics about the operations performed by the
+// SDK, and is set using ClusterOptions.Meter.  The duration of each operation, in microseconds,
+// is recorded to the db.couchbase.operations value recorder and each operation is counted by
+// the db.couchbase.requests counter.  The instruments are tagged with the service
+// (db.couchbase.service), the operation (db.operation) and, for operations which report it, the
+// outcome (Success, Timeout or Error), as well as any Tags given in the options of the operation.
+// Each retry allowed by the RetryStrategy is counted by the db.couchbase.retries counter, tagged
+// with the reason for the retry (db.couchbase.retry_reason).  Queries using the prepared
+// statement cache are counted by the db.couchbase.query.prepared_cache counter, tagged with
+// whether the cache was hit or missed (db.couchbase.cache_result), and the duration of each
+// PREPARE request, in microseconds, is recorded to the db.couchbase.query.prepares value recorder.
+// UNCOMMITTED: This API may change in the future.
+type Meter interface {
+	Counter(name string, tags map[string]string) (Counter, error)
+	ValueRecorder(name string, tags map[string]string) (ValueRecorder, error)
+}
+
+// Counter is a metric instrument which counts events.
+// UNCOMMITTED: This API may change in the future.
+type Counter interface {
+	IncrementBy(num uint64)
+}
+
+// ValueRecorder is a metric instrument which records a distribution of values.
+// UNCOMMITTED: This API may change in the future.
+type ValueRecorder interface {
+	RecordValue(val uint64)
+}
+
+func meterAddRef(meter Meter) {
+	if refMeter, ok := meter.(interface {
+		AddRef() int32
+	}); ok {
+		refMeter.AddRef()
+	}
+}
+
+func meterDecRef(meter Meter) {
+	if refMeter, ok := meter.(interface {
+		DecRef() int32
+	}); ok {
+		refMeter.DecRef()
+	}
+}
+
+// meteringTracer wraps the tracer in use to record the duration of each operation to a Meter.
+// Operations are identified by their spans, which are the spans started without a parent
+// span from this tracer and tagged with the service which they use.
+type meteringTracer struct {
+	tracer RequestTracer
+	meter  Meter
+	clock  clock
+}
+
+func newMeteringTracer(tracer RequestTracer, meter Meter) *meteringTracer {
+	return &meteringTracer{
+		tracer: tracer,
+		meter:  meter,
+		clock:  systemClock{},
+	}
+}
+
+func (t *meteringTracer) AddRef() int32 {
+	tracerAddRef(t.tracer)
+	meterAddRef(t.meter)
+	return 0
+}
+
+func (t *meteringTracer) DecRef() int32 {
+	tracerDecRef(t.tracer)
+	meterDecRef(t.meter)
+	return 0
+}
+
+func (t *meteringTracer) StartSpan(operationName string, parentContext RequestSpanContext) RequestSpan {
+	isOperation := true
+	switch parent := parentContext.(type) {
+	case *meteringSpanContext:
+		isOperation = false
+		parentContext = parent.context
+	}
+
+	return &meteringSpan{
+		tracer:      t,
+		span:        t.tracer.StartSpan(operationName, parentContext),
+		opName:      operationName,
+		isOperation: isOperation,
+		startTime:   t.clock.Now(),
+	}
+}
+
+type meteringSpan struct {
+	tracer      *meteringTracer
+	span        RequestSpan
+	opName      string
+	isOperation bool
+	startTime   time.Time
+	service     string
+	outcome     string
+	tags        map[string]string
+	finished    uint32
+}
+
+type meteringSpanContext struct {
+	context RequestSpanContext
+}
+
+func (s *meteringSpan) Context() RequestSpanContext {
+	return &meteringSpanContext{
+		context: s.span.Context(),
+	}
+}
+
+func (s *meteringSpan) SetTag(key string, value interface{}) RequestSpan {
+	if s.isOperation {
+		if key == "couchbase.service" {
+			s.service, _ = value.(string)
+		} else if key == spanTagOutcome {
+			s.outcome, _ = value.(string)
+		} else if strings.HasPrefix(key, operationTagPrefix) {
+			if valueStr, ok := value.(string); ok {
+				if s.tags == nil {
+					s.tags = make(map[string]string)
+				}
+				s.tags[strings.TrimPrefix(key, operationTagPrefix)] = valueStr
+			}
+		}
+	}
+
+	s.span = s.span.SetTag(key, value)
+	return s
+}
+
+func (s *meteringSpan) Finish() {
+	s.span.Finish()
+
+	if !s.isOperation || s.service == "" || !atomic.CompareAndSwapUint32(&s.finished, 0, 1) {
+		return
+	}
+
+	// Operation tags are applied first so that they cannot replace the tags set by the SDK.
+	tags := make(map[string]string, len(s.tags)+3)
+	for key, value := range s.tags {
+		tags[key] = value
+	}
+	tags[meterTagService] = s.service
+	tags[meterTagOperation] = s.opName
+	if s.outcome != "" {
+		tags[meterTagOutcome] = s.outcome
+	}
+
+	duration := s.tracer.clock.Now().Sub(s.startTime)
+
+	meterRecordDuration(s.tracer.meter, meterNameOperations, tags, duration)
+	meterIncrement(s.tracer.meter, meterNameRequests, tags)
+}
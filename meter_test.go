@@ -0,0 +1,220 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestMeteringTracerRecordsOperations(t *testing.T) {
+	clk := newFakeClock()
+	meter := NewAggregatingMeter(nil)
+	tracer := newMeteringTracer(&noopTracer{}, meter)
+	tracer.clock = clk
+
+	col := testGetCollection(t, &mockKvProvider{})
+	col.sb.Tracer = tracer
+
+	_, err := col.Upsert("key", "value", &UpsertOptions{
+		Tags: map[string]string{"tenant": "acme"},
+	})
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	span := tracer.StartSpan("Query", nil).SetTag("couchbase.service", "query")
+	clk.Advance(250 * time.Millisecond)
+	span.Finish()
+
+	summary := meter.summary()
+
+	// The encode span started by Upsert must not be counted as an operation.
+	if len(summary.Counters) != 2 {
+		t.Fatalf("Expected 2 counters but was %+v", summary.Counters)
+	}
+
+	kvCounter := summary.Counters[0]
+	if kvCounter.Name != meterNameRequests || kvCounter.Value != 1 {
+		t.Fatalf("Expected kv request count of 1 but was %+v", kvCounter)
+	}
+	expectedTags := map[string]string{
+		meterTagService:   "kv",
+		meterTagOperation: "Upsert",
+		meterTagOutcome:   outcomeSuccess,
+		"tenant":          "acme",
+	}
+	if len(kvCounter.Tags) != len(expectedTags) {
+		t.Fatalf("Expected tags %v but was %v", expectedTags, kvCounter.Tags)
+	}
+	for key, value := range expectedTags {
+		if kvCounter.Tags[key] != value {
+			t.Fatalf("Expected tags %v but was %v", expectedTags, kvCounter.Tags)
+		}
+	}
+
+	var queryRecorder *aggregatingValueRecorderJSON
+	for i, recorder := range summary.ValueRecorders {
+		if recorder.Tags[meterTagService] == "query" {
+			queryRecorder = &summary.ValueRecorders[i]
+		}
+	}
+	if queryRecorder == nil {
+		t.Fatalf("Expected query operations to be recorded but was %+v", summary.ValueRecorders)
+	}
+	if queryRecorder.Count != 1 || queryRecorder.Max != 250000 {
+		t.Fatalf("Expected one query of 250000us but was %+v", queryRecorder)
+	}
+}
+
+func TestMeteringTracerRecordsTimeouts(t *testing.T) {
+	meter := NewAggregatingMeter(nil)
+	col := testGetCollection(t, &mockKvProvider{err: ErrAmbiguousTimeout})
+	col.sb.Tracer = newMeteringTracer(&noopTracer{}, meter)
+
+	_, err := col.Get("key", nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected Get to time out but was %v", err)
+	}
+
+	_, err = col.Get("", nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected Get to fail but was %v", err)
+	}
+
+	summary := meter.summary()
+	outcomes := make(map[string]uint64)
+	for _, counter := range summary.Counters {
+		outcomes[counter.Tags[meterTagOutcome]] += counter.Value
+	}
+	if outcomes[outcomeTimeout] != 1 || outcomes[outcomeError] != 1 {
+		t.Fatalf("Expected one timeout and one error but was %v", outcomes)
+	}
+}
+
+func TestRetryStrategyWrapperCountsRetries(t *testing.T) {
+	meter := NewAggregatingMeter(nil)
+	wrapper := newRetryStrategyWrapper(NewBestEffortRetryStrategy(nil))
+	wrapper.meter = meter
+
+	// Strategies given in the options of an operation report to the same meter.
+	override := wrapper.withStrategy(newFailFastRetryStrategy())
+	if override.meter != meter {
+		t.Fatalf("Expected the override to report to the meter")
+	}
+
+	wrapper.RetryAfter(&mockGocbcoreRequest{idempotent: true}, gocbcore.KVLockedRetryReason)
+	wrapper.RetryAfter(&mockGocbcoreRequest{idempotent: true}, gocbcore.KVLockedRetryReason)
+	override.RetryAfter(&mockGocbcoreRequest{idempotent: true}, gocbcore.KVLockedRetryReason)
+
+	summary := meter.summary()
+	if len(summary.Counters) != 1 {
+		t.Fatalf("Expected 1 counter but was %+v", summary.Counters)
+	}
+	counter := summary.Counters[0]
+	if counter.Name != meterNameRetries || counter.Value != 2 ||
+		counter.Tags[meterTagRetryReason] != gocbcore.KVLockedRetryReason.Description() {
+		t.Fatalf("Expected 2 retries for the locked reason but was %+v", counter)
+	}
+}
+
+func TestAggregatingMeterSummary(t *testing.T) {
+	var summaries [][]byte
+	meter := NewAggregatingMeter(&AggregatingMeterOptions{
+		Emit: func(summary []byte) {
+			summaries = append(summaries, summary)
+		},
+	})
+	meter.AddRef()
+
+	tags := map[string]string{meterTagService: "kv"}
+	recorder, err := meter.ValueRecorder(meterNameOperations, tags)
+	if err != nil {
+		t.Fatalf("Failed to get value recorder: %v", err)
+	}
+	for i := uint64(1); i <= 100; i++ {
+		recorder.RecordValue(i)
+	}
+
+	// Instruments with the same name and tags are shared.
+	sameRecorder, err := meter.ValueRecorder(meterNameOperations, map[string]string{meterTagService: "kv"})
+	if err != nil {
+		t.Fatalf("Failed to get value recorder: %v", err)
+	}
+	if sameRecorder != recorder {
+		t.Fatalf("Expected the same value recorder to be returned")
+	}
+
+	meter.DecRef()
+
+	if len(summaries) != 1 {
+		t.Fatalf("Expected a final summary to be emitted but was %d summaries", len(summaries))
+	}
+
+	var summary aggregatingMeterSummary
+	err = json.Unmarshal(summaries[0], &summary)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal summary: %v", err)
+	}
+
+	if len(summary.ValueRecorders) != 1 {
+		t.Fatalf("Expected 1 value recorder but was %+v", summary.ValueRecorders)
+	}
+	recorded := summary.ValueRecorders[0]
+	if recorded.Count != 100 || recorded.Min != 1 || recorded.Max != 100 {
+		t.Fatalf("Expected 100 values from 1 to 100 but was %+v", recorded)
+	}
+	if recorded.Percentiles["50.0"] != 50 || recorded.Percentiles["99.0"] != 99 {
+		t.Fatalf("Unexpected percentiles %v", recorded.Percentiles)
+	}
+
+	if next := meter.summary(); len(next.ValueRecorders) != 0 {
+		t.Fatalf("Expected values to be reset after a summary but was %+v", next.ValueRecorders)
+	}
+}
+
+// dispatchErrKvProvider fails to dispatch every get, as gocbcore does when a request cannot
+// be queued.
+type dispatchErrKvProvider struct {
+	*mockKvProvider
+}
+
+func (p *dispatchErrKvProvider) GetEx(opts gocbcore.GetOptions, cb gocbcore.GetExCallback) (gocbcore.PendingOp, error) {
+	return nil, gocbcore.ErrOverload
+}
+
+func TestMeteringTracerRecordsUnsentOperations(t *testing.T) {
+	meter := NewAggregatingMeter(nil)
+	col := testGetCollection(t, &mockKvProvider{value: []byte(`{}`)})
+	col.sb.Tracer = newMeteringTracer(&noopTracer{}, meter)
+	col.sb.KvLimiter = newOpLimiter(nil, 1, 0)
+
+	release, err := col.sb.KvLimiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	_, err = col.Get("key", &GetOptions{Timeout: 20 * time.Millisecond})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected Get to time out waiting for the limiter but was %v", err)
+	}
+	release()
+
+	provider := &dispatchErrKvProvider{mockKvProvider: &mockKvProvider{}}
+	col.sb.getCachedClient().(*mockClient).mockKvProvider = provider
+	_, err = col.Get("key", nil)
+	if err == nil {
+		t.Fatalf("Expected Get to fail to dispatch")
+	}
+
+	summary := meter.summary()
+	outcomes := make(map[string]uint64)
+	for _, counter := range summary.Counters {
+		outcomes[counter.Tags[meterTagOutcome]] += counter.Value
+	}
+	if outcomes[outcomeTimeout] != 1 || outcomes[outcomeError] != 1 || outcomes[outcomeSuccess] != 0 {
+		t.Fatalf("Expected one timeout and one error but was %v", outcomes)
+	}
+}
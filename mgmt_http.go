@@ -20,7 +20,15 @@ type mgmtRequest struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
-	parentSpan requestSpanContext
+	parentSpan RequestSpanContext
+
+	// internal marks requests which the SDK makes on its own behalf, such as cancelling a
+	// request, which are permitted even when the cluster is read-only.
+	internal bool
+
+	// readOnly marks requests which do not modify anything despite their method, such as
+	// analyzing a document, which are permitted even when the cluster is read-only.
+	readOnly bool
 }
 
 type mgmtResponse struct {
@@ -30,20 +38,23 @@ type mgmtResponse struct {
 }
 
 func (c *Cluster) executeMgmtRequest(req mgmtRequest) (*mgmtResponse, error) {
+	if c.sb.ReadOnly {
+		if err := checkReadOnlyMgmtRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
 	provider, err := c.getHTTPProvider()
 	if err != nil {
 		return nil, err
 	}
 
-	timeout := c.sb.ManagementTimeout
+	timeout := c.sb.managementTimeout()
 	if req.Timeout > 0 && req.Timeout < timeout {
 		timeout = req.Timeout
 	}
 
-	retryStrategy := c.sb.RetryStrategyWrapper
-	if req.RetryStrategy != nil {
-		retryStrategy = newRetryStrategyWrapper(req.RetryStrategy)
-	}
+	retryStrategy := c.sb.RetryStrategyWrapper.withStrategy(req.RetryStrategy)
 
 	corereq := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(req.Service),
@@ -72,20 +83,23 @@ func (c *Cluster) executeMgmtRequest(req mgmtRequest) (*mgmtResponse, error) {
 }
 
 func (b *Bucket) executeMgmtRequest(req mgmtRequest) (*mgmtResponse, error) {
+	if b.sb.ReadOnly {
+		if err := checkReadOnlyMgmtRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
 	provider, err := b.sb.getCachedClient().getHTTPProvider()
 	if err != nil {
 		return nil, err
 	}
 
-	timeout := b.sb.ManagementTimeout
+	timeout := b.sb.managementTimeout()
 	if req.Timeout > 0 && req.Timeout < timeout {
 		timeout = req.Timeout
 	}
 
-	retryStrategy := b.sb.RetryStrategyWrapper
-	if req.RetryStrategy != nil {
-		retryStrategy = newRetryStrategyWrapper(req.RetryStrategy)
-	}
+	retryStrategy := b.sb.RetryStrategyWrapper.withStrategy(req.RetryStrategy)
 
 	corereq := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(req.Service),
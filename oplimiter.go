@@ -0,0 +1,370 @@
+package gocb
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// OperationLimitsConfig specifies limits on the number of operations which can be
+// outstanding at once, protecting the cluster from bursts of requests from the client.
+// Operations over a limit are queued until an outstanding operation completes.
+type OperationLimitsConfig struct {
+	// MaxInFlightKV is the maximum number of key-value operations which can be outstanding
+	// at once against each bucket.  Each replica read, durability observe and operation within
+	// a Collection.Do or BulkExecutor batch counts as an operation of its own.  A value of 0
+	// means no limit.
+	MaxInFlightKV uint32
+
+	// MaxInFlightHTTP is the maximum number of query, analytics, search and view requests
+	// which can be outstanding at once across the cluster.  A request is outstanding until
+	// all of its results have been read or it is closed.  A value of 0 means no limit.
+	MaxInFlightHTTP uint32
+
+	// MaxQueuedOperations is the maximum number of operations which can be waiting for each
+	// limit at once, beyond this operations fail with ErrOperationQueueFull.  A value of 0
+	// means that operations are queued until they time out.
+	MaxQueuedOperations uint32
+
+	// MaxValueSize is the largest encoded document value, in bytes, which will be sent to the
+	// server.  The values of the specs of a MutateIn are counted together, as are the bytes
+	// given to Append and Prepend.  Larger values fail immediately with a ValueTooLargeError
+	// rather than being rejected by the server.  A value of 0 means the server default of 20MiB.
+	MaxValueSize uint32
+}
+
+const defaultMaxValueSize = 20 * 1024 * 1024
+
+// opLimiter limits the number of operations which can be in flight at once.  A nil
+// opLimiter imposes no limit.
+type opLimiter struct {
+	slots     chan struct{}
+	queued    int32
+	maxQueued int32
+	clock     clock
+}
+
+func newOpLimiter(clk clock, maxInFlight, maxQueued uint32) *opLimiter {
+	if maxInFlight == 0 {
+		return nil
+	}
+
+	limiter := &opLimiter{
+		slots:     make(chan struct{}, maxInFlight),
+		maxQueued: int32(maxQueued),
+		clock:     clockOrSystem(clk),
+	}
+	if maxQueued == 0 {
+		limiter.maxQueued = -1
+	}
+
+	return limiter
+}
+
+// Acquire waits until an operation can be dispatched, returning a function which must be
+// called once the operation is complete.
+func (l *opLimiter) Acquire(deadline time.Time) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaseFunc(), nil
+	default:
+	}
+
+	queued := atomic.AddInt32(&l.queued, 1)
+	defer atomic.AddInt32(&l.queued, -1)
+	if l.maxQueued >= 0 && queued > l.maxQueued {
+		return nil, ErrOperationQueueFull
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaseFunc(), nil
+	case <-l.clock.After(deadline.Sub(l.clock.Now())):
+		return nil, ErrUnambiguousTimeout
+	}
+}
+
+func (l *opLimiter) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-l.slots
+		})
+	}
+}
+
+// limitedKvProvider acquires a slot from a limiter for each of the operations used by bulk
+// operations, which do not go through a kvOpManager, releasing it once the operation completes.
+type limitedKvProvider struct {
+	kvProvider
+	limiter  *opLimiter
+	deadline func() time.Time
+}
+
+// newLimitedKvProvider wraps provider so that its bulk operations are limited by limiter, each
+// waiting for a slot until the time returned by deadline.
+func newLimitedKvProvider(provider kvProvider, limiter *opLimiter, deadline func() time.Time) kvProvider {
+	if limiter == nil {
+		return provider
+	}
+
+	return limitedKvProvider{
+		kvProvider: provider,
+		limiter:    limiter,
+		deadline:   deadline,
+	}
+}
+
+func (p limitedKvProvider) GetEx(opts gocbcore.GetOptions, cb gocbcore.GetExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.GetEx(opts, func(res *gocbcore.GetResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) GetAndTouchEx(opts gocbcore.GetAndTouchOptions, cb gocbcore.GetAndTouchExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.GetAndTouchEx(opts, func(res *gocbcore.GetAndTouchResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) TouchEx(opts gocbcore.TouchOptions, cb gocbcore.TouchExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.TouchEx(opts, func(res *gocbcore.TouchResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) GetMetaEx(opts gocbcore.GetMetaOptions, cb gocbcore.GetMetaExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.GetMetaEx(opts, func(res *gocbcore.GetMetaResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) AddEx(opts gocbcore.AddOptions, cb gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.AddEx(opts, func(res *gocbcore.StoreResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) SetEx(opts gocbcore.SetOptions, cb gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.SetEx(opts, func(res *gocbcore.StoreResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) ReplaceEx(opts gocbcore.ReplaceOptions, cb gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.ReplaceEx(opts, func(res *gocbcore.StoreResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) DeleteEx(opts gocbcore.DeleteOptions, cb gocbcore.DeleteExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.DeleteEx(opts, func(res *gocbcore.DeleteResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) AppendEx(opts gocbcore.AdjoinOptions, cb gocbcore.AdjoinExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.AppendEx(opts, func(res *gocbcore.AdjoinResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) PrependEx(opts gocbcore.AdjoinOptions, cb gocbcore.AdjoinExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.PrependEx(opts, func(res *gocbcore.AdjoinResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) IncrementEx(opts gocbcore.CounterOptions, cb gocbcore.CounterExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.IncrementEx(opts, func(res *gocbcore.CounterResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+func (p limitedKvProvider) DecrementEx(opts gocbcore.CounterOptions, cb gocbcore.CounterExCallback) (gocbcore.PendingOp, error) {
+	release, err := p.limiter.Acquire(p.deadline())
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := p.kvProvider.DecrementEx(opts, func(res *gocbcore.CounterResult, err error) {
+		release()
+		cb(res, err)
+	})
+	if err != nil {
+		release()
+	}
+	return op, err
+}
+
+// limitedRowReader wraps a rowReader so that the slot held by the request is released
+// once the stream has been fully read or is closed.
+type limitedRowReader struct {
+	reader  rowReader
+	release func()
+}
+
+func newLimitedRowReader(reader rowReader, release func()) rowReader {
+	return &limitedRowReader{
+		reader:  reader,
+		release: release,
+	}
+}
+
+func (r *limitedRowReader) NextRow() []byte {
+	rowBytes := r.reader.NextRow()
+	if rowBytes == nil {
+		r.release()
+	}
+
+	return rowBytes
+}
+
+func (r *limitedRowReader) Err() error {
+	return r.reader.Err()
+}
+
+func (r *limitedRowReader) MetaData() ([]byte, error) {
+	return r.reader.MetaData()
+}
+
+func (r *limitedRowReader) Close() error {
+	defer r.release()
+
+	return r.reader.Close()
+}
+
+// checkValueSize returns a ValueTooLargeError if size, the number of bytes of value sent for a
+// document, is larger than the configured maximum value size.
+func (c *Collection) checkValueSize(id string, size int) error {
+	maxSize := c.sb.OperationLimitsConfig.MaxValueSize
+	if maxSize == 0 {
+		maxSize = defaultMaxValueSize
+	}
+
+	if uint64(size) > uint64(maxSize) {
+		return ValueTooLargeError{
+			DocumentID: id,
+			Size:       size,
+			MaxSize:    maxSize,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package gocb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestOpLimiterUnlimited(t *testing.T) {
+	limiter := newOpLimiter(nil, 0, 0)
+	if limiter != nil {
+		t.Fatalf("Expected limiter with no limit to be nil")
+	}
+
+	release, err := limiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	release()
+}
+
+func TestOpLimiterWaitsForRelease(t *testing.T) {
+	limiter := newOpLimiter(nil, 1, 0)
+
+	release, err := limiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	time.AfterFunc(50*time.Millisecond, release)
+
+	start := time.Now()
+	nextRelease, err := limiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer nextRelease()
+
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("Expected Acquire to wait for the outstanding operation to be released")
+	}
+
+	// Releasing more than once must not free up additional slots.
+	release()
+	_, err = limiter.Acquire(time.Now().Add(50 * time.Millisecond))
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout error but was %v", err)
+	}
+}
+
+func TestOpLimiterQueueFull(t *testing.T) {
+	limiter := newOpLimiter(nil, 1, 1)
+
+	release, err := limiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer release()
+
+	queuedCh := make(chan error, 1)
+	go func() {
+		_, err := limiter.Acquire(time.Now().Add(200 * time.Millisecond))
+		queuedCh <- err
+	}()
+
+	// Give the first waiter time to be queued.
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = limiter.Acquire(time.Now().Add(time.Second))
+	if !errors.Is(err, ErrOperationQueueFull) {
+		t.Fatalf("Expected queue full error but was %v", err)
+	}
+
+	err = <-queuedCh
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout error but was %v", err)
+	}
+}
+
+func TestOpLimiterKvOperations(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"name":"mike"}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+	col.sb.KvLimiter = newOpLimiter(nil, 1, 0)
+
+	release, err := col.sb.KvLimiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	_, err = col.Get("limited", &GetOptions{
+		Timeout: 50 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout error but was %v", err)
+	}
+
+	release()
+
+	_, err = col.Get("limited", nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	// The slot used by the previous operation must have been released.
+	_, err = col.Get("limited", nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+}
+
+func TestOpLimiterKvReplicaAndObserve(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"name":"mike"}`),
+		flags: 2 << 24,
+		mt:    gocbcore.MutationToken{VbUUID: 1},
+	}
+	col := testGetCollection(t, provider)
+	col.sb.UseMutationTokens = true
+	col.sb.DuraPollTimeout = time.Millisecond
+	col.sb.KvLimiter = newOpLimiter(nil, 1, 0)
+
+	release, err := col.sb.KvLimiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	_, err = col.GetAnyReplica("limited", &GetAnyReplicaOptions{
+		Timeout: 50 * time.Millisecond,
+	})
+	if err == nil {
+		t.Fatalf("Expected replica read to wait for the limiter")
+	}
+
+	release()
+
+	_, err = col.GetAnyReplica("limited", nil)
+	if err != nil {
+		t.Fatalf("GetAnyReplica failed: %v", err)
+	}
+
+	// The observes of a mutation must not wait for the slot held by the mutation itself.
+	_, err = col.Upsert("limited", "value", &UpsertOptions{PersistTo: 1, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+}
+
+func TestOpLimiterBulkOperations(t *testing.T) {
+	provider := &mockKvProvider{
+		cas:   10,
+		value: []byte(`{"name":"mike"}`),
+		flags: 2 << 24,
+	}
+	col := testGetCollection(t, provider)
+	col.sb.KvLimiter = newOpLimiter(nil, 1, 0)
+
+	release, err := col.sb.KvLimiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	ops := []BulkOp{&GetOp{ID: "limited"}}
+	err = col.Do(ops, &BulkOpOptions{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if !errors.Is(ops[0].err(), ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout error but was %v", ops[0].err())
+	}
+
+	res, err := col.BulkExecutor(&BulkExecutorOptions{Timeout: 50 * time.Millisecond}).
+		Execute([]BulkOp{&GetOp{ID: "limited"}})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(res.Failures) != 1 || !errors.Is(res.Failures[0].Err, ErrUnambiguousTimeout) {
+		t.Fatalf("Expected unambiguous timeout failure but was %v", res.Failures)
+	}
+
+	release()
+
+	// Each op must release its slot once complete, so that the next can be dispatched.
+	ops = []BulkOp{&GetOp{ID: "a"}, &UpsertOp{ID: "b", Value: "value"}, &GetOp{ID: "c"}}
+	err = col.Do(ops, nil)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	for _, op := range ops {
+		if op.err() != nil {
+			t.Fatalf("Expected op to succeed but was %v", op.err())
+		}
+	}
+
+	res, err = col.BulkExecutor(nil).Execute([]BulkOp{&GetOp{ID: "a"}, &GetOp{ID: "b"}, &GetOp{ID: "c"}})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if res.Succeeded != 3 {
+		t.Fatalf("Expected 3 ops to succeed but was %d", res.Succeeded)
+	}
+}
+
+func TestLimitedRowReaderReleasesOnClose(t *testing.T) {
+	limiter := newOpLimiter(nil, 1, 0)
+
+	release, err := limiter.Acquire(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	reader := newLimitedRowReader(&mockRowReader{rows: [][]byte{[]byte("1"), []byte("2")}}, release)
+	if reader.NextRow() == nil {
+		t.Fatalf("Expected a row to be returned")
+	}
+
+	err = reader.Close()
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	release, err = limiter.Acquire(time.Now().Add(50 * time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected slot to be released on close but Acquire failed: %v", err)
+	}
+	release()
+}
+
+func TestMaxValueSize(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{cas: 10})
+	col.sb.OperationLimitsConfig.MaxValueSize = 10
+
+	_, err := col.Upsert("large", "a value which is too large", nil)
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("Expected value too large error but was %v", err)
+	}
+
+	var sizeErr ValueTooLargeError
+	if !errors.As(err, &sizeErr) || sizeErr.DocumentID != "large" || sizeErr.Size != 28 || sizeErr.MaxSize != 10 {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	_, err = col.Upsert("small", "small", nil)
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	ops := []BulkOp{&UpsertOp{ID: "large", Value: "a value which is too large"}}
+	err = col.Do(ops, nil)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	if !errors.Is(ops[0].(*UpsertOp).Err, ErrValueTooLarge) {
+		t.Fatalf("Expected value too large error but was %v", ops[0].(*UpsertOp).Err)
+	}
+}
+
+func TestMaxValueSizeSubDocAndBinary(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{cas: 10, value: []gocbcore.SubDocResult{{}, {}}})
+	col.sb.OperationLimitsConfig.MaxValueSize = 10
+
+	// Each spec is small, but together they are too large.
+	_, err := col.MutateIn("large", []MutateInSpec{
+		UpsertSpec("a", "value", nil),
+		UpsertSpec("b", "value", nil),
+	}, nil)
+	var sizeErr ValueTooLargeError
+	if !errors.As(err, &sizeErr) || sizeErr.Size != 14 {
+		t.Fatalf("Expected value too large error for MutateIn but was %v", err)
+	}
+
+	_, err = col.MutateIn("small", []MutateInSpec{UpsertSpec("a", "value", nil)}, nil)
+	if err != nil {
+		t.Fatalf("MutateIn failed: %v", err)
+	}
+
+	_, err = col.Binary().Append("large", []byte("a value which is too large"), nil)
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("Expected value too large error for Append but was %v", err)
+	}
+
+	_, err = col.Binary().Prepend("large", []byte("a value which is too large"), nil)
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("Expected value too large error for Prepend but was %v", err)
+	}
+
+	ops := []BulkOp{&AppendOp{ID: "large", Value: "a value which is too large"}}
+	err = col.Do(ops, nil)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if !errors.Is(ops[0].(*AppendOp).Err, ErrValueTooLarge) {
+		t.Fatalf("Expected value too large error for AppendOp but was %v", ops[0].(*AppendOp).Err)
+	}
+}
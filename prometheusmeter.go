@@ -0,0 +1,248 @@
+package gocb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PrometheusMeterOptions specifies options for a PrometheusMeter.
+// UNCOMMITTED: This API may change in the future.
+type PrometheusMeterOptions struct {
+	// Buckets are the upper bounds of the histogram buckets which value recorders are exported
+	// as, in ascending order.  Operation durations are recorded in microseconds, so the default
+	// buckets range from 100 microseconds to 10 seconds.
+	Buckets []uint64
+}
+
+// PrometheusMeter is a Meter which exports the values recorded to it in the Prometheus text
+// exposition format, so that it can be scraped by Prometheus.  Counters are exported as
+// Prometheus counters and value recorders as histograms, with the tags of each instrument as
+// labels.  Metric and label names have any characters which Prometheus does not allow replaced
+// with underscores, so db.couchbase.operations is exported as db_couchbase_operations, and the
+// retry count as db_couchbase_retries_total.  Timeouts are counted by the outcome="Timeout" label
+// of db_couchbase_requests_total.  Circuit breaker state is not exported, as gocbcore does not
+// expose it.
+// UNCOMMITTED: This API may change in the future.
+type PrometheusMeter struct {
+	buckets []uint64
+
+	lock       sync.Mutex
+	counters   map[string]*prometheusCounter
+	histograms map[string]*prometheusHistogram
+}
+
+// NewPrometheusMeter returns a new PrometheusMeter.  The meter is an http.Handler which serves
+// the metrics, and is typically registered on the path which Prometheus is configured to scrape.
+// UNCOMMITTED: This API may change in the future.
+func NewPrometheusMeter(opts *PrometheusMeterOptions) *PrometheusMeter {
+	if opts == nil {
+		opts = &PrometheusMeterOptions{}
+	}
+
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = []uint64{
+			100, 250, 500,
+			1000, 2500, 5000,
+			10000, 25000, 50000,
+			100000, 250000, 500000,
+			1000000, 2500000, 5000000,
+			10000000,
+		}
+	}
+
+	return &PrometheusMeter{
+		buckets:    buckets,
+		counters:   make(map[string]*prometheusCounter),
+		histograms: make(map[string]*prometheusHistogram),
+	}
+}
+
+// Counter returns the counter with the given name and tags.
+func (m *PrometheusMeter) Counter(name string, tags map[string]string) (Counter, error) {
+	key := aggregatingMeterKey(name, tags)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	counter, ok := m.counters[key]
+	if !ok {
+		counter = &prometheusCounter{
+			name:   prometheusName(name) + "_total",
+			labels: prometheusLabels(tags),
+		}
+		m.counters[key] = counter
+	}
+	return counter, nil
+}
+
+// ValueRecorder returns the value recorder with the given name and tags.
+func (m *PrometheusMeter) ValueRecorder(name string, tags map[string]string) (ValueRecorder, error) {
+	key := aggregatingMeterKey(name, tags)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	histogram, ok := m.histograms[key]
+	if !ok {
+		histogram = &prometheusHistogram{
+			name:    prometheusName(name),
+			labels:  prometheusLabels(tags),
+			buckets: m.buckets,
+			counts:  make([]uint64, len(m.buckets)),
+		}
+		m.histograms[key] = histogram
+	}
+	return histogram, nil
+}
+
+// ServeHTTP writes the current value of every metric in the Prometheus text exposition format.
+func (m *PrometheusMeter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	err := m.writeMetrics(w)
+	if err != nil {
+		logDebugf("Failed to write prometheus metrics: %s", err)
+	}
+}
+
+func (m *PrometheusMeter) writeMetrics(w io.Writer) error {
+	m.lock.Lock()
+	counters := make([]*prometheusCounter, 0, len(m.counters))
+	for _, counter := range m.counters {
+		counters = append(counters, counter)
+	}
+	histograms := make([]*prometheusHistogram, 0, len(m.histograms))
+	for _, histogram := range m.histograms {
+		histograms = append(histograms, histogram)
+	}
+	m.lock.Unlock()
+
+	// Prometheus requires all of the samples of a metric to be grouped together.
+	sort.Slice(counters, func(i, j int) bool {
+		return counters[i].name+counters[i].labels < counters[j].name+counters[j].labels
+	})
+	sort.Slice(histograms, func(i, j int) bool {
+		return histograms[i].name+histograms[i].labels < histograms[j].name+histograms[j].labels
+	})
+
+	bw := bufio.NewWriter(w)
+
+	lastName := ""
+	for _, counter := range counters {
+		if counter.name != lastName {
+			fmt.Fprintf(bw, "# TYPE %s counter\n", counter.name)
+			lastName = counter.name
+		}
+		fmt.Fprintf(bw, "%s%s %d\n", counter.name, prometheusLabelSet(counter.labels, ""), atomic.LoadUint64(&counter.value))
+	}
+
+	lastName = ""
+	for _, histogram := range histograms {
+		if histogram.name != lastName {
+			fmt.Fprintf(bw, "# TYPE %s histogram\n", histogram.name)
+			lastName = histogram.name
+		}
+		histogram.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+type prometheusCounter struct {
+	name   string
+	labels string
+	value  uint64
+}
+
+func (c *prometheusCounter) IncrementBy(num uint64) {
+	atomic.AddUint64(&c.value, num)
+}
+
+type prometheusHistogram struct {
+	name    string
+	labels  string
+	buckets []uint64
+
+	lock   sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    uint64
+}
+
+func (h *prometheusHistogram) RecordValue(val uint64) {
+	idx := sort.Search(len(h.buckets), func(i int) bool {
+		return h.buckets[i] >= val
+	})
+
+	h.lock.Lock()
+	if idx < len(h.counts) {
+		h.counts[idx]++
+	}
+	h.count++
+	h.sum += val
+	h.lock.Unlock()
+}
+
+func (h *prometheusHistogram) write(w io.Writer) {
+	h.lock.Lock()
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	count, sum := h.count, h.sum
+	h.lock.Unlock()
+
+	// Prometheus buckets are cumulative, whereas each value is only counted in one bucket here.
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += counts[i]
+		le := `le="` + strconv.FormatUint(bound, 10) + `"`
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, prometheusLabelSet(h.labels, le), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, prometheusLabelSet(h.labels, `le="+Inf"`), count)
+	fmt.Fprintf(w, "%s_sum%s %d\n", h.name, prometheusLabelSet(h.labels, ""), sum)
+	fmt.Fprintf(w, "%s_count%s %d\n", h.name, prometheusLabelSet(h.labels, ""), count)
+}
+
+// prometheusName replaces any characters which are not allowed in Prometheus metric and label
+// names with underscores.
+func prometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+var prometheusLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// prometheusLabels formats tags as the comma separated labels of a sample, ordered by name.
+func prometheusLabels(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labels := make([]string, len(keys))
+	for i, key := range keys {
+		labels[i] = prometheusName(key) + `="` + prometheusLabelValueReplacer.Replace(tags[key]) + `"`
+	}
+	return strings.Join(labels, ",")
+}
+
+func prometheusLabelSet(labels, extra string) string {
+	if labels != "" && extra != "" {
+		return "{" + labels + "," + extra + "}"
+	} else if labels != "" || extra != "" {
+		return "{" + labels + extra + "}"
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package gocb
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusMeterExport(t *testing.T) {
+	meter := NewPrometheusMeter(&PrometheusMeterOptions{
+		Buckets: []uint64{100, 1000},
+	})
+
+	tags := map[string]string{meterTagService: "kv", meterTagOperation: "Get"}
+	recorder, err := meter.ValueRecorder(meterNameOperations, tags)
+	if err != nil {
+		t.Fatalf("Failed to get value recorder: %v", err)
+	}
+	recorder.RecordValue(50)
+	recorder.RecordValue(500)
+	recorder.RecordValue(5000)
+
+	counter, err := meter.Counter(meterNameRequests, map[string]string{"bucket": `a"b`})
+	if err != nil {
+		t.Fatalf("Failed to get counter: %v", err)
+	}
+	counter.IncrementBy(2)
+
+	resp := httptest.NewRecorder()
+	meter.ServeHTTP(resp, httptest.NewRequest("GET", "/metrics", nil))
+
+	expected := strings.Join([]string{
+		"# TYPE db_couchbase_requests_total counter",
+		`db_couchbase_requests_total{bucket="a\"b"} 2`,
+		"# TYPE db_couchbase_operations histogram",
+		`db_couchbase_operations_bucket{db_couchbase_service="kv",db_operation="Get",le="100"} 1`,
+		`db_couchbase_operations_bucket{db_couchbase_service="kv",db_operation="Get",le="1000"} 2`,
+		`db_couchbase_operations_bucket{db_couchbase_service="kv",db_operation="Get",le="+Inf"} 3`,
+		`db_couchbase_operations_sum{db_couchbase_service="kv",db_operation="Get"} 5550`,
+		`db_couchbase_operations_count{db_couchbase_service="kv",db_operation="Get"} 3`,
+		"",
+	}, "\n")
+	if body := resp.Body.String(); body != expected {
+		t.Fatalf("Expected metrics:\n%s\nbut was:\n%s", expected, body)
+	}
+}
@@ -28,6 +28,32 @@ type clusterCapabilityProvider interface {
 	SupportsClusterCapability(capability gocbcore.ClusterCapability) bool
 }
 
+type dcpProvider interface {
+	OpenStream(vbID uint16, flags gocbcore.DcpStreamAddFlag, vbUUID gocbcore.VbUUID, startSeqNo,
+		endSeqNo, snapStartSeqNo, snapEndSeqNo gocbcore.SeqNo, evtHandler gocbcore.StreamObserver,
+		filter *gocbcore.StreamFilter, cb gocbcore.OpenStreamCallback) (gocbcore.PendingOp, error)
+	CloseStream(vbID uint16, cb gocbcore.CloseStreamCallback) (gocbcore.PendingOp, error)
+	GetFailoverLog(vbID uint16, cb gocbcore.GetFailoverLogCallback) (gocbcore.PendingOp, error)
+	GetCollectionID(scopeName string, collectionName string, opts gocbcore.GetCollectionIDOptions,
+		cb gocbcore.CollectionIDCallback) (gocbcore.PendingOp, error)
+	NumVbuckets() int
+	Close() error
+}
+
 type diagnosticsProvider interface {
 	Diagnostics() (*gocbcore.DiagnosticInfo, error)
 }
+
+type configSnapshotProvider interface {
+	Diagnostics() (*gocbcore.DiagnosticInfo, error)
+	NumReplicas() int
+	NumServers() int
+	NumVbuckets() int
+	KeyToVbucket(key []byte) uint16
+	VbucketToServer(vbID uint16, replicaIdx uint32) int
+	MgmtEps() []string
+	CapiEps() []string
+	N1qlEps() []string
+	FtsEps() []string
+	CbasEps() []string
+}
@@ -1,6 +1,9 @@
 package gocb
 
 import (
+	"context"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +21,33 @@ const (
 	QueryScanConsistencyRequestPlus = QueryScanConsistency(2)
 )
 
+// QueryContext identifies the bucket, and optionally the scope within it, against which
+// keyspaces which are not fully qualified in a query statement are resolved.
+// UNCOMMITTED: This API may change in the future.
+type QueryContext struct {
+	BucketName string
+	ScopeName  string
+}
+
+func (qc QueryContext) validate() error {
+	if qc.BucketName == "" && qc.ScopeName != "" {
+		return makeInvalidArgumentsError("a query context with a scope must also have a bucket")
+	}
+	return nil
+}
+
+// String returns the query context in the form expected by the query service, or an empty
+// string if no bucket is set.  Backticks within the names are escaped by doubling them.
+func (qc QueryContext) String() string {
+	if qc.BucketName == "" {
+		return ""
+	}
+	if qc.ScopeName == "" {
+		return "default:" + QueryIdentifier(qc.BucketName)
+	}
+	return "default:" + QueryIdentifier(qc.BucketName, qc.ScopeName)
+}
+
 // QueryOptions represents the options available when executing a query.
 type QueryOptions struct {
 	ScanConsistency      QueryScanConsistency
@@ -39,10 +69,28 @@ type QueryOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
-	parentSpan requestSpanContext
+	// Context, if set, allows the query to be cancelled.  Cancelling the context
+	// whilst rows are being read will abort the underlying stream.
+	Context context.Context
+
+	// Tags are attached to the tracing span and threshold log entry recorded for
+	// this query, allowing load to be attributed to a tenant or feature.
+	Tags map[string]string
+
+	// RetryOnStreamReset, if set, re-dispatches the query if the connection streaming its
+	// rows is lost, such as when the node serving it restarts, rather than failing the
+	// result.  The rows which were already read are skipped, so the statement must return
+	// its rows in a consistent order, such as by using ORDER BY.  It cannot be used with
+	// statements which modify data, the query is sent as readonly so that the server rejects
+	// them.
+	// UNCOMMITTED: This API may change in the future.
+	RetryOnStreamReset bool
+
+	// ParentSpan, if set, is the parent of the span recorded for this query.
+	ParentSpan RequestSpanContext
 }
 
-func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
+func (opts *QueryOptions) toMap(serializer JSONSerializer) (map[string]interface{}, error) {
 	execOpts := make(map[string]interface{})
 
 	if opts.ScanConsistency != 0 && opts.ConsistentWith != nil {
@@ -69,7 +117,7 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 	}
 
 	if opts.Readonly {
-		execOpts["readonly"] = opts.Readonly
+		execOpts["readonly"] = true
 	}
 
 	if opts.PositionalParameters != nil && opts.NamedParameters != nil {
@@ -77,7 +125,11 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 	}
 
 	if opts.PositionalParameters != nil {
-		execOpts["args"] = opts.PositionalParameters
+		args, err := serializePositionalParameters(serializer, opts.PositionalParameters)
+		if err != nil {
+			return nil, wrapError(err, "failed to serialize positional parameters")
+		}
+		execOpts["args"] = args
 	}
 
 	if opts.NamedParameters != nil {
@@ -85,7 +137,11 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 			if !strings.HasPrefix(key, "$") {
 				key = "$" + key
 			}
-			execOpts[key] = value
+			param, err := serializeParameter(serializer, value)
+			if err != nil {
+				return nil, wrapError(err, "failed to serialize named parameter "+key)
+			}
+			execOpts[key] = param
 		}
 	}
 
@@ -105,25 +161,110 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 		execOpts["scan_wait"] = opts.ScanWait.String()
 	}
 
+	if opts.MaxParallelism > 0 {
+		execOpts["max_parallelism"] = strconv.FormatUint(uint64(opts.MaxParallelism), 10)
+	}
+
+	if opts.ClientContextID != "" {
+		execOpts["client_context_id"] = opts.ClientContextID
+	}
+
 	if opts.Raw != nil {
+		err := checkRawOptions(opts.Raw, execOpts)
+		if err != nil {
+			return nil, err
+		}
 		for k, v := range opts.Raw {
 			execOpts[k] = v
 		}
 	}
 
-	if opts.MaxParallelism > 0 {
-		execOpts["max_parallelism"] = strconv.FormatUint(uint64(opts.MaxParallelism), 10)
+	// The query is only retried after ambiguous failures if it is read-only, so this is always
+	// sent to allow the request to be classified.
+	if _, ok := execOpts["readonly"]; !ok {
+		execOpts["readonly"] = false
 	}
 
-	if !opts.Metrics {
+	if _, ok := execOpts["metrics"]; !ok && !opts.Metrics {
 		execOpts["metrics"] = false
 	}
 
-	if opts.ClientContextID == "" {
-		execOpts["client_context_id"] = uuid.New()
-	} else {
-		execOpts["client_context_id"] = opts.ClientContextID
+	if _, ok := execOpts["client_context_id"]; !ok {
+		execOpts["client_context_id"] = uuid.New().String()
 	}
 
 	return execOpts, nil
 }
+
+// checkRawOptions returns an invalid argument error listing any raw options which would replace
+// options already set from the typed options, or the statement and timeout set by the SDK.
+func checkRawOptions(raw, execOpts map[string]interface{}) error {
+	var conflicts []string
+	for key := range raw {
+		if _, ok := execOpts[key]; ok || key == "statement" || key == "timeout" {
+			conflicts = append(conflicts, key)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return makeInvalidArgumentsError("raw options conflict with typed options: " + strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// NamedParametersFromStruct derives a set of named parameters, suitable for use as
+// QueryOptions.NamedParameters or AnalyticsOptions.NamedParameters, from the exported
+// fields of a struct.  The parameter name for each field is taken from its json tag
+// if one is present, otherwise the field name is used.  Fields tagged with "-" are
+// skipped, as are fields tagged with omitempty which hold their zero value.
+func NamedParametersFromStruct(value interface{}) (map[string]interface{}, error) {
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, makeInvalidArgumentsError("named parameters struct cannot be nil")
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil, makeInvalidArgumentsError("named parameters must be derived from a struct")
+	}
+
+	params := make(map[string]interface{})
+	valType := val.Type()
+	for i := 0; i < valType.NumField(); i++ {
+		field := valType.Field(i)
+		if field.PkgPath != "" {
+			// Unexported field
+			continue
+		}
+
+		name := field.Name
+		omitEmpty := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagParts := strings.Split(tag, ",")
+			if tagParts[0] == "-" && len(tagParts) == 1 {
+				continue
+			}
+			if tagParts[0] != "" {
+				name = tagParts[0]
+			}
+			for _, opt := range tagParts[1:] {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+
+		fieldVal := val.Field(i)
+		if omitEmpty && fieldVal.IsZero() {
+			continue
+		}
+
+		params[name] = fieldVal.Interface()
+	}
+
+	return params, nil
+}
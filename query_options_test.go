@@ -0,0 +1,164 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testPrefixSerializer struct {
+	DefaultJSONSerializer
+}
+
+func (s *testPrefixSerializer) Serialize(value interface{}) ([]byte, error) {
+	if str, ok := value.(string); ok {
+		return json.Marshal("serialized:" + str)
+	}
+
+	return s.DefaultJSONSerializer.Serialize(value)
+}
+
+func TestQueryOptionsToMapUsesSerializer(t *testing.T) {
+	opts := &QueryOptions{
+		PositionalParameters: []interface{}{"a", 1},
+	}
+
+	execOpts, err := opts.toMap(&testPrefixSerializer{})
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	reqBytes, err := json.Marshal(execOpts["args"])
+	if err != nil {
+		t.Fatalf("Failed to marshal args: %v", err)
+	}
+
+	if string(reqBytes) != `["serialized:a",1]` {
+		t.Fatalf("Expected args to be serialized with the serializer but were %s", reqBytes)
+	}
+}
+
+func TestQueryOptionsNamedParametersUseSerializer(t *testing.T) {
+	opts := &QueryOptions{
+		NamedParameters: map[string]interface{}{
+			"name":  "a",
+			"$size": 1,
+		},
+	}
+
+	execOpts, err := opts.toMap(&testPrefixSerializer{})
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	reqBytes, err := json.Marshal(execOpts["$name"])
+	if err != nil {
+		t.Fatalf("Failed to marshal parameter: %v", err)
+	}
+
+	if string(reqBytes) != `"serialized:a"` {
+		t.Fatalf("Expected parameter to be serialized with the serializer but was %s", reqBytes)
+	}
+
+	if _, ok := execOpts["$size"]; !ok {
+		t.Fatalf("Expected $size parameter to be present")
+	}
+}
+
+func TestNamedParametersFromStruct(t *testing.T) {
+	type params struct {
+		Name     string `json:"name"`
+		Age      int
+		Skipped  string `json:"-"`
+		Empty    string `json:"empty,omitempty"`
+		internal string
+	}
+
+	named, err := NamedParametersFromStruct(&params{
+		Name:     "barry",
+		Age:      21,
+		Skipped:  "skipped",
+		internal: "internal",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if len(named) != 2 {
+		t.Fatalf("Expected 2 parameters but had %d: %v", len(named), named)
+	}
+
+	if named["name"] != "barry" {
+		t.Fatalf("Expected name parameter to be barry but was %v", named["name"])
+	}
+
+	if named["Age"] != 21 {
+		t.Fatalf("Expected Age parameter to be 21 but was %v", named["Age"])
+	}
+}
+
+func TestNamedParametersFromStructNotStruct(t *testing.T) {
+	_, err := NamedParametersFromStruct(map[string]interface{}{})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func TestQueryOptionsReadonlyAlwaysSent(t *testing.T) {
+	opts := &QueryOptions{}
+	execOpts, err := opts.toMap(NewDefaultJSONSerializer())
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if readonly, ok := execOpts["readonly"].(bool); !ok || readonly {
+		t.Fatalf("Expected readonly to be sent as false but was %v", execOpts["readonly"])
+	}
+
+	opts.Readonly = true
+	execOpts, err = opts.toMap(NewDefaultJSONSerializer())
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if readonly, ok := execOpts["readonly"].(bool); !ok || !readonly {
+		t.Fatalf("Expected readonly to be sent as true but was %v", execOpts["readonly"])
+	}
+}
+
+func TestQueryOptionsRawConflicts(t *testing.T) {
+	opts := &QueryOptions{
+		PositionalParameters: []interface{}{1},
+		ClientContextID:      "abc",
+		Raw: map[string]interface{}{
+			"statement":         "SELECT 2",
+			"args":              []interface{}{2},
+			"client_context_id": "def",
+			"pretty":            true,
+		},
+	}
+	_, err := opts.toMap(NewDefaultJSONSerializer())
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+	if !strings.Contains(err.Error(), "args, client_context_id, statement") {
+		t.Fatalf("Expected conflicts to be listed but was %v", err)
+	}
+
+	// Options which are only defaulted by the SDK may be set using raw options.
+	opts = &QueryOptions{
+		Raw: map[string]interface{}{
+			"client_context_id": "def",
+			"metrics":           true,
+			"pretty":            true,
+		},
+	}
+	execOpts, err := opts.toMap(NewDefaultJSONSerializer())
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+	if execOpts["client_context_id"] != "def" || execOpts["metrics"] != true || execOpts["pretty"] != true {
+		t.Fatalf("Expected raw options to be sent but was %v", execOpts)
+	}
+}
@@ -0,0 +1,81 @@
+package gocb
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QueryIdentifier escapes a name, such as that of a bucket, collection or field, for use within a
+// query statement.  When more than one part is given, the parts are escaped individually and joined
+// into a path, such as bucket.scope.collection or field.subfield.
+func QueryIdentifier(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = "`" + strings.Replace(part, "`", "``", -1) + "`"
+	}
+
+	return strings.Join(escaped, ".")
+}
+
+// QueryStatement builds a query statement from fixed text, escaped identifiers and parameters.
+// Values are never placed within the statement itself, instead they are sent as positional
+// parameters, so that values which come from users cannot change the meaning of the statement.
+//
+//	stmt := gocb.NewQueryStatement().
+//		Text("SELECT * FROM ").Identifier("travel-sample").
+//		Text(" WHERE ").Identifier("country").Text(" = ").Param(country)
+//	result, err := cluster.Query(stmt.String(), stmt.Options(nil))
+//
+// UNCOMMITTED: This API may change in the future.
+type QueryStatement struct {
+	statement strings.Builder
+	params    []interface{}
+}
+
+// NewQueryStatement creates a new, empty, QueryStatement.
+func NewQueryStatement() *QueryStatement {
+	return &QueryStatement{}
+}
+
+// Text appends text to the statement as it is.  It must only be used for text which is part of
+// the application, never for values which come from users.
+func (s *QueryStatement) Text(text string) *QueryStatement {
+	s.statement.WriteString(text)
+	return s
+}
+
+// Identifier appends an escaped identifier to the statement, see QueryIdentifier.
+func (s *QueryStatement) Identifier(parts ...string) *QueryStatement {
+	s.statement.WriteString(QueryIdentifier(parts...))
+	return s
+}
+
+// Param appends a placeholder for value to the statement.
+func (s *QueryStatement) Param(value interface{}) *QueryStatement {
+	s.params = append(s.params, value)
+	s.statement.WriteString("$" + strconv.Itoa(len(s.params)))
+	return s
+}
+
+// String returns the statement.
+func (s *QueryStatement) String() string {
+	return s.statement.String()
+}
+
+// Parameters returns the values of the placeholders within the statement, in order.
+func (s *QueryStatement) Parameters() []interface{} {
+	return s.params
+}
+
+// Options returns a copy of opts with the positional parameters of the statement set.  Any
+// parameters already set on opts are replaced.
+func (s *QueryStatement) Options(opts *QueryOptions) *QueryOptions {
+	var out QueryOptions
+	if opts != nil {
+		out = *opts
+	}
+
+	out.PositionalParameters = s.params
+	out.NamedParameters = nil
+	return &out
+}
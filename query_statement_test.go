@@ -0,0 +1,40 @@
+package gocb
+
+import (
+	"testing"
+)
+
+func TestQueryIdentifier(t *testing.T) {
+	if QueryIdentifier("travel-sample") != "`travel-sample`" {
+		t.Fatalf("Unexpected identifier %s", QueryIdentifier("travel-sample"))
+	}
+
+	if QueryIdentifier("bucket", "scope", "air`line") != "`bucket`.`scope`.`air``line`" {
+		t.Fatalf("Unexpected identifier %s", QueryIdentifier("bucket", "scope", "air`line"))
+	}
+}
+
+func TestQueryStatement(t *testing.T) {
+	stmt := NewQueryStatement().
+		Text("SELECT * FROM ").Identifier("travel-sample").
+		Text(" WHERE ").Identifier("country").Text(" = ").Param("France' OR 1=1").
+		Text(" LIMIT ").Param(10)
+
+	expected := "SELECT * FROM `travel-sample` WHERE `country` = $1 LIMIT $2"
+	if stmt.String() != expected {
+		t.Fatalf("Unexpected statement %s", stmt.String())
+	}
+
+	opts := stmt.Options(&QueryOptions{
+		Adhoc:           true,
+		NamedParameters: map[string]interface{}{"country": "UK"},
+	})
+	if !opts.Adhoc || opts.NamedParameters != nil {
+		t.Fatalf("Expected options to be copied with named parameters removed")
+	}
+
+	params := opts.PositionalParameters
+	if len(params) != 2 || params[0] != "France' OR 1=1" || params[1] != 10 {
+		t.Fatalf("Unexpected parameters %v", params)
+	}
+}
@@ -0,0 +1,374 @@
+// Package querybuilder builds N1QL SELECT statements from structured parts, rather than by
+// concatenating strings.  Identifiers are always escaped and values are always sent as
+// parameters, so that values which come from users cannot change the meaning of a statement.
+//
+//	stmt := querybuilder.Select(querybuilder.Field("name")).
+//		From("travel-sample").
+//		Where(querybuilder.Eq(querybuilder.Field("country"), querybuilder.Value(country))).
+//		OrderBy(querybuilder.Asc(querybuilder.Field("name"))).
+//		Limit(10).
+//		Statement()
+//	result, err := cluster.Query(stmt.String(), stmt.Options(nil))
+//
+// UNCOMMITTED: This API may change in the future.
+package querybuilder
+
+import (
+	"github.com/couchbase/gocb/v2"
+)
+
+// Expression represents part of a statement, such as a field, a value or a condition.
+type Expression interface {
+	appendTo(stmt *gocb.QueryStatement)
+}
+
+type fieldExpr struct {
+	path []string
+}
+
+func (e fieldExpr) appendTo(stmt *gocb.QueryStatement) {
+	stmt.Identifier(e.path...)
+}
+
+// Field refers to a field, each part of the path being escaped, such as Field("address", "city").
+func Field(path ...string) Expression {
+	return fieldExpr{path: path}
+}
+
+type valueExpr struct {
+	value interface{}
+}
+
+func (e valueExpr) appendTo(stmt *gocb.QueryStatement) {
+	stmt.Param(e.value)
+}
+
+// Value refers to a value, which is sent as a parameter of the statement.
+func Value(value interface{}) Expression {
+	return valueExpr{value: value}
+}
+
+type rawExpr struct {
+	text string
+}
+
+func (e rawExpr) appendTo(stmt *gocb.QueryStatement) {
+	stmt.Text(e.text)
+}
+
+// Raw includes text in the statement as it is, such as Raw("COUNT(*)").  It must only be used
+// for text which is part of the application, never for values which come from users.
+func Raw(text string) Expression {
+	return rawExpr{text: text}
+}
+
+// MetaID refers to the ID of the document, optionally of the document with the given alias.
+func MetaID(alias ...string) Expression {
+	if len(alias) == 0 {
+		return Raw("META().id")
+	}
+
+	return funcExpr{name: "META", args: []Expression{Field(alias...)}, suffix: ".id"}
+}
+
+type funcExpr struct {
+	name   string
+	args   []Expression
+	suffix string
+}
+
+func (e funcExpr) appendTo(stmt *gocb.QueryStatement) {
+	stmt.Text(e.name + "(")
+	for i, arg := range e.args {
+		if i > 0 {
+			stmt.Text(", ")
+		}
+		arg.appendTo(stmt)
+	}
+	stmt.Text(")" + e.suffix)
+}
+
+type aliasExpr struct {
+	expr  Expression
+	alias string
+}
+
+func (e aliasExpr) appendTo(stmt *gocb.QueryStatement) {
+	e.expr.appendTo(stmt)
+	stmt.Text(" AS ").Identifier(e.alias)
+}
+
+// As names the result of an expression within the selected fields.
+func As(expr Expression, alias string) Expression {
+	return aliasExpr{expr: expr, alias: alias}
+}
+
+type binaryExpr struct {
+	left  Expression
+	op    string
+	right Expression
+}
+
+func (e binaryExpr) appendTo(stmt *gocb.QueryStatement) {
+	e.left.appendTo(stmt)
+	stmt.Text(" " + e.op + " ")
+	e.right.appendTo(stmt)
+}
+
+// Eq is true when left is equal to right.
+func Eq(left, right Expression) Expression {
+	return binaryExpr{left: left, op: "=", right: right}
+}
+
+// Ne is true when left is not equal to right.
+func Ne(left, right Expression) Expression {
+	return binaryExpr{left: left, op: "!=", right: right}
+}
+
+// Gt is true when left is greater than right.
+func Gt(left, right Expression) Expression {
+	return binaryExpr{left: left, op: ">", right: right}
+}
+
+// Gte is true when left is greater than or equal to right.
+func Gte(left, right Expression) Expression {
+	return binaryExpr{left: left, op: ">=", right: right}
+}
+
+// Lt is true when left is less than right.
+func Lt(left, right Expression) Expression {
+	return binaryExpr{left: left, op: "<", right: right}
+}
+
+// Lte is true when left is less than or equal to right.
+func Lte(left, right Expression) Expression {
+	return binaryExpr{left: left, op: "<=", right: right}
+}
+
+// Like is true when left matches the pattern right.
+func Like(left, right Expression) Expression {
+	return binaryExpr{left: left, op: "LIKE", right: right}
+}
+
+// In is true when left is an element of the array right.
+func In(left, right Expression) Expression {
+	return binaryExpr{left: left, op: "IN", right: right}
+}
+
+type postfixExpr struct {
+	expr Expression
+	op   string
+}
+
+func (e postfixExpr) appendTo(stmt *gocb.QueryStatement) {
+	e.expr.appendTo(stmt)
+	stmt.Text(" " + e.op)
+}
+
+// IsNull is true when expr is null.
+func IsNull(expr Expression) Expression {
+	return postfixExpr{expr: expr, op: "IS NULL"}
+}
+
+// IsMissing is true when expr is missing.
+func IsMissing(expr Expression) Expression {
+	return postfixExpr{expr: expr, op: "IS MISSING"}
+}
+
+// IsValued is true when expr is neither null nor missing.
+func IsValued(expr Expression) Expression {
+	return postfixExpr{expr: expr, op: "IS VALUED"}
+}
+
+type logicalExpr struct {
+	op    string
+	exprs []Expression
+}
+
+func (e logicalExpr) appendTo(stmt *gocb.QueryStatement) {
+	stmt.Text("(")
+	for i, expr := range e.exprs {
+		if i > 0 {
+			stmt.Text(" " + e.op + " ")
+		}
+		expr.appendTo(stmt)
+	}
+	stmt.Text(")")
+}
+
+// And is true when every one of exprs is true.
+func And(exprs ...Expression) Expression {
+	return logicalExpr{op: "AND", exprs: exprs}
+}
+
+// Or is true when any one of exprs is true.
+func Or(exprs ...Expression) Expression {
+	return logicalExpr{op: "OR", exprs: exprs}
+}
+
+type notExpr struct {
+	expr Expression
+}
+
+func (e notExpr) appendTo(stmt *gocb.QueryStatement) {
+	stmt.Text("NOT (")
+	e.expr.appendTo(stmt)
+	stmt.Text(")")
+}
+
+// Not is true when expr is false.
+func Not(expr Expression) Expression {
+	return notExpr{expr: expr}
+}
+
+// Ordering is a sort key of an ORDER BY clause.
+type Ordering struct {
+	expr Expression
+	desc bool
+}
+
+// Asc sorts by expr in ascending order.
+func Asc(expr Expression) Ordering {
+	return Ordering{expr: expr}
+}
+
+// Desc sorts by expr in descending order.
+func Desc(expr Expression) Ordering {
+	return Ordering{expr: expr, desc: true}
+}
+
+// SelectStatement is a SELECT statement which is being built.
+type SelectStatement struct {
+	distinct bool
+	raw      bool
+	fields   []Expression
+	keyspace []string
+	alias    string
+	useKeys  []string
+	where    Expression
+	orderBy  []Ordering
+	limit    *int
+	offset   *int
+}
+
+// Select starts a statement which selects fields.  If no fields are given then every field is
+// selected.
+func Select(fields ...Expression) *SelectStatement {
+	return &SelectStatement{fields: fields}
+}
+
+// SelectRaw starts a statement which selects the value of field, rather than an object
+// containing it.
+func SelectRaw(field Expression) *SelectStatement {
+	return &SelectStatement{fields: []Expression{field}, raw: true}
+}
+
+// Distinct removes duplicate results.
+func (s *SelectStatement) Distinct() *SelectStatement {
+	s.distinct = true
+	return s
+}
+
+// From specifies the keyspace to select from, such as From("bucket") or
+// From("bucket", "scope", "collection").
+func (s *SelectStatement) From(keyspace ...string) *SelectStatement {
+	s.keyspace = keyspace
+	return s
+}
+
+// As specifies an alias for the keyspace.
+func (s *SelectStatement) As(alias string) *SelectStatement {
+	s.alias = alias
+	return s
+}
+
+// UseKeys limits the statement to the documents with the given IDs.
+func (s *SelectStatement) UseKeys(keys ...string) *SelectStatement {
+	s.useKeys = keys
+	return s
+}
+
+// Where limits the statement to the documents which match cond.
+func (s *SelectStatement) Where(cond Expression) *SelectStatement {
+	s.where = cond
+	return s
+}
+
+// OrderBy sorts the results.
+func (s *SelectStatement) OrderBy(orderings ...Ordering) *SelectStatement {
+	s.orderBy = orderings
+	return s
+}
+
+// Limit limits the number of results.
+func (s *SelectStatement) Limit(limit int) *SelectStatement {
+	s.limit = &limit
+	return s
+}
+
+// Offset skips the first results.
+func (s *SelectStatement) Offset(offset int) *SelectStatement {
+	s.offset = &offset
+	return s
+}
+
+// Statement builds the statement, along with its parameters.
+func (s *SelectStatement) Statement() *gocb.QueryStatement {
+	stmt := gocb.NewQueryStatement().Text("SELECT ")
+
+	if s.distinct {
+		stmt.Text("DISTINCT ")
+	}
+	if s.raw {
+		stmt.Text("RAW ")
+	}
+
+	if len(s.fields) == 0 {
+		stmt.Text("*")
+	}
+	for i, field := range s.fields {
+		if i > 0 {
+			stmt.Text(", ")
+		}
+		field.appendTo(stmt)
+	}
+
+	if len(s.keyspace) > 0 {
+		stmt.Text(" FROM ").Identifier(s.keyspace...)
+		if s.alias != "" {
+			stmt.Text(" AS ").Identifier(s.alias)
+		}
+	}
+
+	if s.useKeys != nil {
+		stmt.Text(" USE KEYS ").Param(s.useKeys)
+	}
+
+	if s.where != nil {
+		stmt.Text(" WHERE ")
+		s.where.appendTo(stmt)
+	}
+
+	for i, ordering := range s.orderBy {
+		if i == 0 {
+			stmt.Text(" ORDER BY ")
+		} else {
+			stmt.Text(", ")
+		}
+		ordering.expr.appendTo(stmt)
+		if ordering.desc {
+			stmt.Text(" DESC")
+		} else {
+			stmt.Text(" ASC")
+		}
+	}
+
+	if s.limit != nil {
+		stmt.Text(" LIMIT ").Param(*s.limit)
+	}
+	if s.offset != nil {
+		stmt.Text(" OFFSET ").Param(*s.offset)
+	}
+
+	return stmt
+}
@@ -0,0 +1,53 @@
+package querybuilder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectStatement(t *testing.T) {
+	stmt := Select(Field("h", "name"), As(Raw("COUNT(*)"), "total")).
+		Distinct().
+		From("travel-sample", "inventory", "hotel").As("h").
+		Where(And(
+			Eq(Field("h", "country"), Value("France")),
+			Or(Gt(Field("h", "rating"), Value(3)), IsMissing(Field("h", "rating"))),
+			Not(Like(Field("h", "name"), Value("%Ibis%"))),
+		)).
+		OrderBy(Desc(Field("h", "rating")), Asc(MetaID("h"))).
+		Limit(10).
+		Offset(20).
+		Statement()
+
+	expected := "SELECT DISTINCT `h`.`name`, COUNT(*) AS `total` FROM `travel-sample`.`inventory`.`hotel` AS `h` " +
+		"WHERE (`h`.`country` = $1 AND (`h`.`rating` > $2 OR `h`.`rating` IS MISSING) AND NOT (`h`.`name` LIKE $3)) " +
+		"ORDER BY `h`.`rating` DESC, META(`h`).id ASC LIMIT $4 OFFSET $5"
+	if stmt.String() != expected {
+		t.Fatalf("Unexpected statement %s", stmt.String())
+	}
+
+	params := []interface{}{"France", 3, "%Ibis%", 10, 20}
+	if !reflect.DeepEqual(stmt.Parameters(), params) {
+		t.Fatalf("Unexpected parameters %v", stmt.Parameters())
+	}
+}
+
+func TestSelectUseKeys(t *testing.T) {
+	stmt := SelectRaw(MetaID()).From("default").UseKeys("a", "b").Statement()
+
+	if stmt.String() != "SELECT RAW META().id FROM `default` USE KEYS $1" {
+		t.Fatalf("Unexpected statement %s", stmt.String())
+	}
+
+	if !reflect.DeepEqual(stmt.Parameters(), []interface{}{[]string{"a", "b"}}) {
+		t.Fatalf("Unexpected parameters %v", stmt.Parameters())
+	}
+}
+
+func TestSelectAll(t *testing.T) {
+	stmt := Select().From("default").Statement()
+
+	if stmt.String() != "SELECT * FROM `default`" || len(stmt.Parameters()) != 0 {
+		t.Fatalf("Unexpected statement %s", stmt.String())
+	}
+}
@@ -0,0 +1,174 @@
+package gocb
+
+import (
+	"strings"
+	"unicode"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+// ReadOnlyError is returned when an operation which would modify data is attempted by a
+// Cluster connected with ClusterOptions.ReadOnly set.  No request is sent to the server.
+type ReadOnlyError struct {
+	Operation string
+}
+
+func (e ReadOnlyError) Error() string {
+	return "operation " + e.Operation + " is not permitted in read-only mode"
+}
+
+// Unwrap returns ErrReadOnly.
+func (e ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// readOnlyKvProvider rejects every KV operation which modifies a document, including those
+// which only modify its expiry or lock state.
+type readOnlyKvProvider struct {
+	kvProvider
+}
+
+func (p readOnlyKvProvider) AddEx(gocbcore.AddOptions, gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Add"}
+}
+
+func (p readOnlyKvProvider) SetEx(gocbcore.SetOptions, gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Set"}
+}
+
+func (p readOnlyKvProvider) ReplaceEx(gocbcore.ReplaceOptions, gocbcore.StoreExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Replace"}
+}
+
+func (p readOnlyKvProvider) SetMetaEx(gocbcore.SetMetaOptions, gocbcore.SetMetaExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "SetMeta"}
+}
+
+func (p readOnlyKvProvider) DeleteMetaEx(gocbcore.DeleteMetaOptions, gocbcore.DeleteMetaExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "DeleteMeta"}
+}
+
+func (p readOnlyKvProvider) DeleteEx(gocbcore.DeleteOptions, gocbcore.DeleteExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Delete"}
+}
+
+func (p readOnlyKvProvider) MutateInEx(gocbcore.MutateInOptions, gocbcore.MutateInExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "MutateIn"}
+}
+
+func (p readOnlyKvProvider) GetAndTouchEx(gocbcore.GetAndTouchOptions, gocbcore.GetAndTouchExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "GetAndTouch"}
+}
+
+func (p readOnlyKvProvider) GetAndLockEx(gocbcore.GetAndLockOptions, gocbcore.GetAndLockExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "GetAndLock"}
+}
+
+func (p readOnlyKvProvider) UnlockEx(gocbcore.UnlockOptions, gocbcore.UnlockExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Unlock"}
+}
+
+func (p readOnlyKvProvider) TouchEx(gocbcore.TouchOptions, gocbcore.TouchExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Touch"}
+}
+
+func (p readOnlyKvProvider) IncrementEx(gocbcore.CounterOptions, gocbcore.CounterExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Increment"}
+}
+
+func (p readOnlyKvProvider) DecrementEx(gocbcore.CounterOptions, gocbcore.CounterExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Decrement"}
+}
+
+func (p readOnlyKvProvider) AppendEx(gocbcore.AdjoinOptions, gocbcore.AdjoinExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Append"}
+}
+
+func (p readOnlyKvProvider) PrependEx(gocbcore.AdjoinOptions, gocbcore.AdjoinExCallback) (gocbcore.PendingOp, error) {
+	return nil, ReadOnlyError{Operation: "Prepend"}
+}
+
+// queryMutatingKeywords are the keywords which begin query and analytics statements that
+// modify data or definitions.
+var queryMutatingKeywords = map[string]bool{
+	"INSERT":     true,
+	"UPSERT":     true,
+	"UPDATE":     true,
+	"DELETE":     true,
+	"MERGE":      true,
+	"CREATE":     true,
+	"DROP":       true,
+	"ALTER":      true,
+	"BUILD":      true,
+	"GRANT":      true,
+	"REVOKE":     true,
+	"CONNECT":    true,
+	"DISCONNECT": true,
+	"LOAD":       true,
+}
+
+// checkReadOnlyStatement fails statements which obviously modify data, so that they fail
+// before being sent.  The server is also asked to reject any other statement which would
+// modify data, as the statement is not fully parsed here.
+func checkReadOnlyStatement(statement string) error {
+	statement = trimStatementPrefix(statement)
+	end := strings.IndexFunc(statement, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end >= 0 {
+		statement = statement[:end]
+	}
+
+	keyword := strings.ToUpper(statement)
+	if queryMutatingKeywords[keyword] {
+		return ReadOnlyError{Operation: keyword}
+	}
+
+	return nil
+}
+
+// trimStatementPrefix removes the whitespace, opening brackets and comments which may come
+// before the first keyword of a statement.
+func trimStatementPrefix(statement string) string {
+	for {
+		statement = strings.TrimLeftFunc(statement, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '('
+		})
+
+		switch {
+		case strings.HasPrefix(statement, "/*"):
+			end := strings.Index(statement[2:], "*/")
+			if end < 0 {
+				return ""
+			}
+			statement = statement[end+4:]
+		case strings.HasPrefix(statement, "--"):
+			end := strings.IndexByte(statement, '\n')
+			if end < 0 {
+				return ""
+			}
+			statement = statement[end+1:]
+		default:
+			return statement
+		}
+	}
+}
+
+// checkReadOnlyMgmtRequest fails management requests which may modify the cluster, unless they
+// are marked as being permitted in read-only mode.
+func checkReadOnlyMgmtRequest(req mgmtRequest) error {
+	if req.internal || req.readOnly {
+		return nil
+	}
+
+	return checkReadOnlyHTTPRequest(req.Method, req.Path)
+}
+
+// checkReadOnlyHTTPRequest fails HTTP requests which are not simple reads.
+func checkReadOnlyHTTPRequest(method, path string) error {
+	if method == "" || method == "GET" || method == "HEAD" {
+		return nil
+	}
+
+	return ReadOnlyError{Operation: method + " " + path}
+}
@@ -0,0 +1,201 @@
+package gocb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestReadOnlyKv(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{cas: 10, value: []byte(`{}`), flags: 2 << 24})
+	col.sb.ReadOnly = true
+
+	_, err := col.Get("doc", nil)
+	if err != nil {
+		t.Fatalf("Expected Get to succeed but was %v", err)
+	}
+
+	_, err = col.Upsert("doc", "value", nil)
+	var readOnlyErr ReadOnlyError
+	if !errors.As(err, &readOnlyErr) || readOnlyErr.Operation != "Set" {
+		t.Fatalf("Expected read-only error but was %v", err)
+	}
+
+	_, err = col.Touch("doc", time.Minute, nil)
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected read-only error but was %v", err)
+	}
+
+	ops := []BulkOp{&GetOp{ID: "doc"}, &RemoveOp{ID: "doc"}}
+	err = col.Do(ops, nil)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	if ops[0].(*GetOp).Err != nil {
+		t.Fatalf("Expected bulk get to succeed but was %v", ops[0].(*GetOp).Err)
+	}
+
+	if !errors.Is(ops[1].(*RemoveOp).Err, ErrReadOnly) {
+		t.Fatalf("Expected read-only error but was %v", ops[1].(*RemoveOp).Err)
+	}
+}
+
+func TestReadOnlyQuery(t *testing.T) {
+	provider := &mockQueryProvider{err: errors.New("no results")}
+	c := testGetQueryCluster(provider)
+	c.sb.Tracer = &noopTracer{}
+	c.sb.Serializer = NewDefaultJSONSerializer()
+	c.sb.QueryTimeout = time.Second
+	c.sb.ReadOnly = true
+
+	_, err := c.Query("  delete FROM `travel-sample`", &QueryOptions{Adhoc: true})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected read-only error but was %v", err)
+	}
+
+	if len(provider.payloads) != 0 {
+		t.Fatalf("Expected no requests to be sent but was %d", len(provider.payloads))
+	}
+
+	_, _ = c.Query("SELECT * FROM `travel-sample`", &QueryOptions{Adhoc: true})
+	if len(provider.payloads) != 1 {
+		t.Fatalf("Expected 1 request but was %d", len(provider.payloads))
+	}
+
+	var payload map[string]interface{}
+	err = json.Unmarshal(provider.payloads[0], &payload)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+
+	if payload["readonly"] != true {
+		t.Fatalf("Expected query to be sent as readonly but was %v", payload["readonly"])
+	}
+}
+
+func TestCheckReadOnlyStatement(t *testing.T) {
+	allowed := []string{"", "SELECT 1", "(SELECT 1)", "EXPLAIN DELETE FROM b", "INFER `b`", "selecting"}
+	for _, statement := range allowed {
+		if err := checkReadOnlyStatement(statement); err != nil {
+			t.Fatalf("Expected %q to be allowed but was %v", statement, err)
+		}
+	}
+
+	rejected := []string{"INSERT INTO b VALUES", "\nupsert into b", "CREATE INDEX i ON b(x)", "Drop Dataset d",
+		"CONNECT LINK Local", "BUILD INDEX ON b(i)", "/* x */ DELETE FROM b", "-- c\nUPDATE b SET x = 1",
+		"/* a */ -- b\n ( /* c */ INSERT INTO b VALUES"}
+	for _, statement := range rejected {
+		if err := checkReadOnlyStatement(statement); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("Expected %q to be rejected but was %v", statement, err)
+		}
+	}
+}
+
+func TestReadOnlyMgmt(t *testing.T) {
+	c := &Cluster{}
+	c.sb.ReadOnly = true
+
+	_, err := c.executeMgmtRequest(mgmtRequest{Method: "DELETE", Path: "/pools/default/buckets/b"})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected read-only error but was %v", err)
+	}
+}
+
+func TestReadOnlyBucketMgmt(t *testing.T) {
+	var sent []string
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			sent = append(sent, req.Method+" "+req.Path)
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(""), nil},
+			}, nil
+		},
+	}
+
+	b := &Bucket{}
+	b.sb.ManagementTimeout = time.Second
+	b.sb.ReadOnly = true
+	b.cacheClient(&mockClient{bucketName: "mock", mockHTTPProvider: provider})
+
+	_, err := b.executeMgmtRequest(mgmtRequest{Method: "DELETE", Path: "/pools/default/buckets/b"})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected read-only error but was %v", err)
+	}
+
+	_, err = b.executeMgmtRequest(mgmtRequest{Method: "DELETE", Path: "/internal", internal: true})
+	if err != nil {
+		t.Fatalf("Expected internal request to be permitted but was %v", err)
+	}
+
+	if len(sent) != 1 || sent[0] != "DELETE /internal" {
+		t.Fatalf("Expected only the internal request to be sent but was %v", sent)
+	}
+}
+
+func TestReadOnlyAnalyzeDocument(t *testing.T) {
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			if req.Method != "POST" || req.Path != "/api/index/idx/analyzeDoc" {
+				t.Fatalf("Unexpected request %s %s", req.Method, req.Path)
+			}
+
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(`{"status":"ok","analyzed":[{"name":"mike"}]}`), nil},
+			}, nil
+		},
+	}
+
+	c := &Cluster{
+		connections: map[string]client{
+			"mock": &mockClient{bucketName: "mock", mockHTTPProvider: provider},
+		},
+	}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.ManagementTimeout = time.Second
+	c.sb.ReadOnly = true
+
+	analyzed, err := c.SearchIndexes().AnalyzeDocument("idx", map[string]string{"name": "mike"}, nil)
+	if err != nil {
+		t.Fatalf("Expected AnalyzeDocument to be permitted in read-only mode but was %v", err)
+	}
+	if len(analyzed) != 1 {
+		t.Fatalf("Expected 1 analyzed field but was %v", analyzed)
+	}
+}
+
+func TestReadOnlyAnalyticsCancel(t *testing.T) {
+	var cancelled bool
+	provider := &mockHTTPProvider{
+		doFn: func(req *gocbcore.HTTPRequest) (*gocbcore.HTTPResponse, error) {
+			if req.Method == "DELETE" && req.Path == "/analytics/admin/active_requests" {
+				cancelled = true
+			}
+
+			return &gocbcore.HTTPResponse{
+				StatusCode: 200,
+				Body:       &testReadCloser{bytes.NewBufferString(""), nil},
+			}, nil
+		},
+	}
+
+	c := &Cluster{
+		connections: map[string]client{
+			"mock": &mockClient{bucketName: "mock", mockHTTPProvider: provider},
+		},
+	}
+	c.sb.Tracer = &noopTracer{}
+	c.sb.ManagementTimeout = time.Second
+	c.sb.ReadOnly = true
+
+	c.cancelAnalyticsRequest("ctx-1")
+	if !cancelled {
+		t.Fatalf("Expected the analytics request to be cancelled in read-only mode")
+	}
+}
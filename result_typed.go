@@ -0,0 +1,132 @@
+//go:build go1.21
+// +build go1.21
+
+package gocb
+
+// TypedRows iterates over the rows of a result, decoding each of them as a T.
+// UNCOMMITTED: This API may change in the future.
+type TypedRows[T any] struct {
+	next    func() bool
+	decode  func(valuePtr *T) error
+	err     func() error
+	close   func() error
+	current T
+	rowErr  error
+}
+
+// QueryRows iterates over the rows of a query result, decoding each of them as a T.
+// UNCOMMITTED: This API may change in the future.
+func QueryRows[T any](result *QueryResult) *TypedRows[T] {
+	return &TypedRows[T]{
+		next: result.Next,
+		decode: func(valuePtr *T) error {
+			return result.Row(valuePtr)
+		},
+		err:   result.Err,
+		close: result.Close,
+	}
+}
+
+// AnalyticsRows iterates over the rows of an analytics result, decoding each of them as a T.
+// UNCOMMITTED: This API may change in the future.
+func AnalyticsRows[T any](result *AnalyticsResult) *TypedRows[T] {
+	return &TypedRows[T]{
+		next: result.Next,
+		decode: func(valuePtr *T) error {
+			return result.Row(valuePtr)
+		},
+		err:   result.Err,
+		close: result.Close,
+	}
+}
+
+// SearchFieldRows iterates over the hits of a search result, decoding the fields of each of them
+// as a T.
+// UNCOMMITTED: This API may change in the future.
+func SearchFieldRows[T any](result *SearchResult) *TypedRows[T] {
+	return &TypedRows[T]{
+		next: result.Next,
+		decode: func(valuePtr *T) error {
+			row := result.Row()
+			return row.Fields(valuePtr)
+		},
+		err:   result.Err,
+		close: result.Close,
+	}
+}
+
+// ViewValueRows iterates over the rows of a view result, decoding the value of each of them as a T.
+// UNCOMMITTED: This API may change in the future.
+func ViewValueRows[T any](result *ViewResult) *TypedRows[T] {
+	return &TypedRows[T]{
+		next: result.Next,
+		decode: func(valuePtr *T) error {
+			row := result.Row()
+			return row.Value(valuePtr)
+		},
+		err:   result.Err,
+		close: result.Close,
+	}
+}
+
+// Next moves to and decodes the next row, returning false once every row has been read or an
+// error occurs.  Once Next returns false Err should be checked.
+func (r *TypedRows[T]) Next() bool {
+	if r.rowErr != nil {
+		return false
+	}
+
+	if !r.next() {
+		return false
+	}
+
+	var value T
+	err := r.decode(&value)
+	if err != nil {
+		r.rowErr = err
+		return false
+	}
+
+	r.current = value
+	return true
+}
+
+// Row returns the row decoded by the last call to Next.
+func (r *TypedRows[T]) Row() T {
+	return r.current
+}
+
+// Err returns any error which occurred whilst reading or decoding the rows.
+func (r *TypedRows[T]) Err() error {
+	if r.rowErr != nil {
+		return r.rowErr
+	}
+
+	return r.err()
+}
+
+// Close closes the underlying result, returning any error which occurred whilst reading or
+// decoding the rows.
+func (r *TypedRows[T]) Close() error {
+	err := r.close()
+	if r.rowErr != nil {
+		return r.rowErr
+	}
+
+	return err
+}
+
+// All reads and decodes every remaining row and closes the result.
+func (r *TypedRows[T]) All() ([]T, error) {
+	var rows []T
+	for r.Next() {
+		rows = append(rows, r.current)
+	}
+
+	err := r.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
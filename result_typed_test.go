@@ -0,0 +1,97 @@
+//go:build go1.21
+// +build go1.21
+
+package gocb
+
+import (
+	"reflect"
+	"testing"
+)
+
+type typedRowsTestRow struct {
+	Name string `json:"name"`
+}
+
+func TestQueryRows(t *testing.T) {
+	reader := &mockRowReader{rows: [][]byte{
+		[]byte(`{"name":"a"}`),
+		[]byte(`{"name":"b"}`),
+	}}
+	result, _ := newQueryResult(reader)
+
+	rows, err := QueryRows[typedRowsTestRow](result).All()
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+
+	expected := []typedRowsTestRow{{Name: "a"}, {Name: "b"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+
+	if reader.closeCount != 1 {
+		t.Fatalf("Expected result to be closed once but was %d", reader.closeCount)
+	}
+}
+
+func TestQueryRowsDecodeError(t *testing.T) {
+	reader := &mockRowReader{rows: [][]byte{
+		[]byte(`"a"`),
+		[]byte(`{"name":"b"}`),
+	}}
+	result, _ := newQueryResult(reader)
+
+	rows := QueryRows[int](result)
+	if rows.Next() {
+		t.Fatalf("Expected Next to fail to decode row")
+	}
+
+	if rows.Err() == nil {
+		t.Fatalf("Expected decode error")
+	}
+
+	if rows.Close() == nil {
+		t.Fatalf("Expected Close to return decode error")
+	}
+}
+
+func TestSearchFieldRows(t *testing.T) {
+	reader := &mockRowReader{rows: [][]byte{
+		[]byte(`{"id":"a","fields":{"name":"x"}}`),
+	}}
+	result, _ := newSearchResult(reader)
+
+	rows := SearchFieldRows[typedRowsTestRow](result)
+	if !rows.Next() {
+		t.Fatalf("Expected row: %v", rows.Err())
+	}
+
+	if rows.Row().Name != "x" {
+		t.Fatalf("Unexpected row %v", rows.Row())
+	}
+
+	if rows.Next() {
+		t.Fatalf("Expected no more rows")
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func TestViewValueRows(t *testing.T) {
+	reader := &mockRowReader{rows: [][]byte{
+		[]byte(`{"id":"a","key":"k","value":3}`),
+		[]byte(`{"id":"b","key":"k","value":4}`),
+	}}
+	result, _ := newViewResult(reader)
+
+	rows, err := ViewValueRows[int](result).All()
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(rows, []int{3, 4}) {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+}
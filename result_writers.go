@@ -0,0 +1,173 @@
+package gocb
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// CSVWriteOptions are the options available when writing results as CSV.
+type CSVWriteOptions struct {
+	// Columns are the fields of each row to write, in order.  If not set, the fields of the first
+	// row are used, in alphabetical order.
+	Columns []string
+
+	// OmitHeader skips writing the column names as the first line.
+	OmitHeader bool
+}
+
+// rawRowResult is the subset of the streaming result types which is needed to write them out.
+type rawRowResult interface {
+	Next() bool
+	RawBytes() []byte
+	Close() error
+}
+
+// WriteNDJSON writes each remaining row of the results to w as a line of JSON, without holding
+// the results in memory, and closes the results.  It returns the number of rows written.
+func (r *QueryResult) WriteNDJSON(w io.Writer) (int, error) {
+	return writeRowsNDJSON(r, w)
+}
+
+// WriteCSV writes each remaining row of the results to w as CSV, without holding the results in
+// memory, and closes the results.  Each row must be a JSON object.  Fields which are objects or
+// arrays are written as JSON, and null or missing fields are written as empty values.  It returns
+// the number of rows written.
+func (r *QueryResult) WriteCSV(w io.Writer, opts *CSVWriteOptions) (int, error) {
+	return writeRowsCSV(r, w, opts)
+}
+
+// WriteNDJSON writes each remaining row of the results to w as a line of JSON, without holding
+// the results in memory, and closes the results.  It returns the number of rows written.
+func (r *AnalyticsResult) WriteNDJSON(w io.Writer) (int, error) {
+	return writeRowsNDJSON(r, w)
+}
+
+// WriteCSV writes each remaining row of the results to w as CSV, without holding the results in
+// memory, and closes the results.  Each row must be a JSON object.  Fields which are objects or
+// arrays are written as JSON, and null or missing fields are written as empty values.  It returns
+// the number of rows written.
+func (r *AnalyticsResult) WriteCSV(w io.Writer, opts *CSVWriteOptions) (int, error) {
+	return writeRowsCSV(r, w, opts)
+}
+
+func closeRowsAfterError(res rawRowResult, err error) error {
+	closeErr := res.Close()
+	if closeErr != nil {
+		logDebugf("Failed to close results (%s)", closeErr)
+	}
+
+	return err
+}
+
+func writeRowsNDJSON(res rawRowResult, w io.Writer) (int, error) {
+	var line bytes.Buffer
+	rows := 0
+
+	for res.Next() {
+		line.Reset()
+		err := json.Compact(&line, res.RawBytes())
+		if err != nil {
+			return rows, closeRowsAfterError(res, err)
+		}
+		line.WriteByte('\n')
+
+		_, err = w.Write(line.Bytes())
+		if err != nil {
+			return rows, closeRowsAfterError(res, err)
+		}
+		rows++
+	}
+
+	return rows, res.Close()
+}
+
+func writeRowsCSV(res rawRowResult, w io.Writer, opts *CSVWriteOptions) (int, error) {
+	if opts == nil {
+		opts = &CSVWriteOptions{}
+	}
+
+	writer := csv.NewWriter(w)
+	columns := opts.Columns
+	record := make([]string, len(columns))
+	rows := 0
+
+	// When the columns are known up front the header is written even if there are no rows.
+	writeHeader := func() error {
+		if opts.OmitHeader {
+			return nil
+		}
+		return writer.Write(columns)
+	}
+	if len(columns) > 0 {
+		if err := writeHeader(); err != nil {
+			return rows, closeRowsAfterError(res, err)
+		}
+	}
+
+	for res.Next() {
+		var fields map[string]json.RawMessage
+		err := json.Unmarshal(res.RawBytes(), &fields)
+		if err != nil {
+			return rows, closeRowsAfterError(res, wrapError(err, "row is not a JSON object"))
+		}
+
+		if rows == 0 && len(columns) == 0 {
+			for name := range fields {
+				columns = append(columns, name)
+			}
+			sort.Strings(columns)
+			record = make([]string, len(columns))
+
+			if err := writeHeader(); err != nil {
+				return rows, closeRowsAfterError(res, err)
+			}
+		}
+
+		for i, name := range columns {
+			record[i], err = csvFieldValue(fields[name])
+			if err != nil {
+				return rows, closeRowsAfterError(res, err)
+			}
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return rows, closeRowsAfterError(res, err)
+		}
+		rows++
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return rows, closeRowsAfterError(res, err)
+	}
+
+	return rows, res.Close()
+}
+
+func csvFieldValue(value json.RawMessage) (string, error) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || bytes.Equal(value, []byte("null")) {
+		return "", nil
+	}
+
+	if value[0] == '"' {
+		var str string
+		err := json.Unmarshal(value, &str)
+		if err != nil {
+			return "", err
+		}
+		return str, nil
+	}
+
+	var compact bytes.Buffer
+	err := json.Compact(&compact, value)
+	if err != nil {
+		return "", err
+	}
+
+	return compact.String(), nil
+}
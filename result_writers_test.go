@@ -0,0 +1,91 @@
+package gocb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testQueryResultRows(rows ...string) *QueryResult {
+	var rowBytes [][]byte
+	for _, row := range rows {
+		rowBytes = append(rowBytes, []byte(row))
+	}
+
+	res, _ := newQueryResult(&mockRowReader{rows: rowBytes})
+	return res
+}
+
+func TestQueryResultWriteNDJSON(t *testing.T) {
+	res := testQueryResultRows("{\n  \"name\": \"mike\"\n}", `{"name":"bob"}`)
+
+	var out bytes.Buffer
+	rows, err := res.WriteNDJSON(&out)
+	if err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+
+	if rows != 2 || out.String() != "{\"name\":\"mike\"}\n{\"name\":\"bob\"}\n" {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+}
+
+func TestQueryResultWriteCSV(t *testing.T) {
+	res := testQueryResultRows(
+		`{"name":"mike","age":32,"tags":["a","b"],"email":null}`,
+		`{"name":"bob, jr","age":1.5}`,
+	)
+
+	var out bytes.Buffer
+	rows, err := res.WriteCSV(&out, nil)
+	if err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	expected := "age,email,name,tags\n32,,mike,\"[\"\"a\"\",\"\"b\"\"]\"\n1.5,,\"bob, jr\",\n"
+	if rows != 2 || out.String() != expected {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+}
+
+func TestQueryResultWriteCSVColumns(t *testing.T) {
+	res := testQueryResultRows(`{"name":"mike","age":32}`)
+
+	var out bytes.Buffer
+	_, err := res.WriteCSV(&out, &CSVWriteOptions{
+		Columns:    []string{"name", "missing"},
+		OmitHeader: true,
+	})
+	if err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	if out.String() != "mike,\n" {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+}
+
+func TestQueryResultWriteCSVColumnsNoRows(t *testing.T) {
+	res := testQueryResultRows()
+
+	var out bytes.Buffer
+	rows, err := res.WriteCSV(&out, &CSVWriteOptions{
+		Columns: []string{"name", "age"},
+	})
+	if err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	if rows != 0 || out.String() != "name,age\n" {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+}
+
+func TestQueryResultWriteCSVNotObject(t *testing.T) {
+	res := testQueryResultRows(`"raw"`)
+
+	var out bytes.Buffer
+	_, err := res.WriteCSV(&out, nil)
+	if err == nil {
+		t.Fatalf("Expected non-object row to fail")
+	}
+}
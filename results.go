@@ -9,7 +9,8 @@ import (
 
 // Result is the base type for the return types of operations
 type Result struct {
-	cas Cas
+	cas            Cas
+	serverDuration time.Duration
 }
 
 // Cas returns the cas of the result.
@@ -17,11 +18,19 @@ func (d *Result) Cas() Cas {
 	return d.cas
 }
 
+// ServerDuration returns the time which the server reported spending processing the operation,
+// allowing it to be distinguished from network latency.  It is zero if the server did not
+// report a duration, for instance if server durations are disabled in the IoConfig.
+func (d *Result) ServerDuration() time.Duration {
+	return d.serverDuration
+}
+
 // GetResult is the return type of Get operations.
 type GetResult struct {
 	Result
 	transcoder Transcoder
 	flags      uint32
+	datatype   DocumentDatatype
 	contents   []byte
 	expiry     *time.Duration
 }
@@ -31,6 +40,18 @@ func (d *GetResult) Content(valuePtr interface{}) error {
 	return d.transcoder.Decode(d.contents, d.flags, valuePtr)
 }
 
+// Flags returns the flags stored with the document, which the transcoder uses to determine the
+// format of the value.
+func (d *GetResult) Flags() uint32 {
+	return d.flags
+}
+
+// Datatype returns the datatype bits which the server stored with the document.  It is zero for
+// results which were built from a sub-document lookup, such as when a projection is used.
+func (d *GetResult) Datatype() DocumentDatatype {
+	return d.datatype
+}
+
 // Expiry returns the expiry value for the result if it available.  Note that a nil
 // pointer indicates that the Expiry was fetched, while a valid pointer to a zero
 // Duration indicates that the document will never expire.
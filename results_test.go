@@ -3,6 +3,7 @@ package gocb
 import (
 	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -52,6 +53,28 @@ func TestGetResultExpiry(t *testing.T) {
 	}
 }
 
+func TestGetResultFlagsAndDatatype(t *testing.T) {
+	col := testGetCollection(t, &mockKvProvider{
+		cas:      10,
+		value:    []byte(`{"name":"mike"}`),
+		flags:    2 << 24,
+		datatype: uint8(gocbcore.DatatypeFlagJSON | gocbcore.DatatypeFlagXattrs),
+	})
+
+	res, err := col.Get("doc", nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if res.Flags() != 2<<24 {
+		t.Fatalf("Flags should have been %d but was %d", 2<<24, res.Flags())
+	}
+
+	if res.Datatype() != DocumentDatatypeJSON|DocumentDatatypeXattrs {
+		t.Fatalf("Datatype should have been JSON and xattrs but was %d", res.Datatype())
+	}
+}
+
 func TestGetResultContent(t *testing.T) {
 	dataset, err := loadRawTestDataset("beer_sample_single")
 	if err != nil {
@@ -422,3 +445,193 @@ func TestMutateInResultContentAt(t *testing.T) {
 		t.Fatalf("Expected count to be %d but was %d", 1, count)
 	}
 }
+
+func TestSearchResultRawBytes(t *testing.T) {
+	rowBytes := []byte(`{"index":"idx","id":"key","score":1.5}`)
+	res, err := newSearchResult(&mockRowReader{
+		rows: [][]byte{rowBytes},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	if string(res.RawBytes()) != string(rowBytes) {
+		t.Fatalf("Expected raw bytes to be %s but was %s", rowBytes, res.RawBytes())
+	}
+
+	row := res.Row()
+	if row.ID != "key" || row.Index != "idx" || row.Score != 1.5 {
+		t.Fatalf("Row was not decoded correctly: %+v", row)
+	}
+}
+
+func TestSearchResultExplanation(t *testing.T) {
+	rowBytes := []byte(`{"index":"idx","id":"key","score":1.5,"explanation":{"value":1.5,"message":"sum of:",` +
+		`"children":[{"value":1,"message":"weight(name:mike)"},{"value":0.5,"message":"weight(name:bob)"}]}}`)
+	res, err := newSearchResult(&mockRowReader{
+		rows: [][]byte{rowBytes},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	row := res.Row()
+	explanation := row.ExplanationTree
+	if explanation == nil || explanation.Value != 1.5 || explanation.Message != "sum of:" || len(explanation.Children) != 2 {
+		t.Fatalf("Explanation was not decoded correctly: %+v", explanation)
+	}
+
+	if explanation.Children[1].Value != 0.5 || explanation.Children[1].Message != "weight(name:bob)" {
+		t.Fatalf("Explanation child was not decoded correctly: %+v", explanation.Children[1])
+	}
+
+	if _, ok := row.Explanation.(map[string]interface{}); !ok {
+		t.Fatalf("Expected raw explanation to be decoded but was %v", row.Explanation)
+	}
+}
+
+func TestSearchResultDecodeError(t *testing.T) {
+	res, err := newSearchResult(&mockRowReader{
+		rows: [][]byte{[]byte(`{"index":"idx","id":"key","score":"high"}`)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	row := res.Row()
+	if row.ID != "" {
+		t.Fatalf("Expected an empty row but was %+v", row)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(res.Err(), &typeErr) {
+		t.Fatalf("Expected the decode error to be returned but was %v", res.Err())
+	}
+}
+
+func TestViewResultReduce(t *testing.T) {
+	res, err := newViewResult(&mockRowReader{
+		rows: [][]byte{
+			[]byte(`{"key":["beer","ale"],"value":12}`),
+			[]byte(`{"key":"lager","value":{"sum":10.5,"count":3,"min":1,"max":7.5,"sumsqr":60.25}}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	row := res.Row()
+	count, err := row.ReduceCount()
+	if err != nil || count != 12 {
+		t.Fatalf("Expected count of 12 but was %d, error %v", count, err)
+	}
+
+	sum, err := row.ReduceSum()
+	if err != nil || sum != 12 {
+		t.Fatalf("Expected sum of 12 but was %f, error %v", sum, err)
+	}
+
+	key, err := row.GroupKey()
+	if err != nil || len(key) != 2 || key[0] != "beer" || key[1] != "ale" {
+		t.Fatalf("Unexpected group key %v, error %v", key, err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	row = res.Row()
+	stats, err := row.ReduceStats()
+	if err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+
+	if *stats != (ViewStats{Sum: 10.5, Count: 3, Min: 1, Max: 7.5, SumSqr: 60.25}) {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+
+	_, err = row.ReduceCount()
+	if err == nil {
+		t.Fatalf("Expected count of a stats row to fail")
+	}
+
+	key, err = row.GroupKey()
+	if err != nil || len(key) != 1 || key[0] != "lager" {
+		t.Fatalf("Unexpected group key %v, error %v", key, err)
+	}
+}
+
+func TestViewResultNextGroup(t *testing.T) {
+	res, err := newViewResult(&mockRowReader{
+		rows: [][]byte{
+			[]byte(`{"key":["ale","uk",2019],"value":2}`),
+			[]byte(`{"key":["ale","uk",2020],"value":3}`),
+			[]byte(`{"key":["ale","us",2020],"value":5}`),
+			[]byte(`{"key":["lager","de",2020],"value":7}`),
+			[]byte(`{"value":1}`),
+			[]byte(`{"key":"stout","value":11}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	type group struct {
+		key  []interface{}
+		rows int
+	}
+	expected := []group{
+		{[]interface{}{"ale", "uk"}, 2},
+		{[]interface{}{"ale", "us"}, 1},
+		{[]interface{}{"lager", "de"}, 1},
+		{[]interface{}{"stout"}, 1},
+	}
+
+	var groups []group
+	for res.NextGroup(2) {
+		g := res.Group()
+		groups = append(groups, group{g.Key, len(g.Rows)})
+	}
+
+	if !reflect.DeepEqual(groups, expected) {
+		t.Fatalf("Expected groups %v but was %v", expected, groups)
+	}
+
+	if res.Err() == nil {
+		t.Fatalf("Expected the row without a key to be reported by Err")
+	}
+}
+
+func TestQueryResultRawBytes(t *testing.T) {
+	rowBytes := []byte(`{"name":"barry"}`)
+	res, err := newQueryResult(&mockRowReader{
+		rows: [][]byte{rowBytes},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	if string(res.RawBytes()) != string(rowBytes) {
+		t.Fatalf("Expected raw bytes to be %s but was %s", rowBytes, res.RawBytes())
+	}
+}
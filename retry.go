@@ -176,6 +176,23 @@ func newRetryStrategyWrapper(strategy RetryStrategy) *retryStrategyWrapper {
 
 type retryStrategyWrapper struct {
 	wrapped RetryStrategy
+
+	// meter, if set, counts each retry which the strategy allows.
+	meter Meter
+}
+
+// withStrategy returns a wrapper for the strategy given in the options of an operation, which
+// reports to the same meter as rs, or rs itself if no strategy was given.
+func (rs *retryStrategyWrapper) withStrategy(strategy RetryStrategy) *retryStrategyWrapper {
+	if strategy == nil {
+		return rs
+	}
+
+	wrapper := newRetryStrategyWrapper(strategy)
+	if rs != nil {
+		wrapper.meter = rs.meter
+	}
+	return wrapper
 }
 
 // RetryAfter calculates and returns a RetryAction describing how long to wait before retrying an operation.
@@ -184,6 +201,13 @@ func (rs *retryStrategyWrapper) RetryAfter(req gocbcore.RetryRequest, reason goc
 		req: req,
 	}
 	wrappedAction := rs.wrapped.RetryAfter(wreq, RetryReason(reason))
+
+	if rs.meter != nil && wrappedAction != nil && wrappedAction.Duration() > 0 {
+		meterIncrement(rs.meter, meterNameRetries, map[string]string{
+			meterTagRetryReason: reason.Description(),
+		})
+	}
+
 	return gocbcore.RetryAction(wrappedAction)
 }
 
@@ -194,6 +218,12 @@ type BackoffCalculator func(retryAttempts uint32) time.Duration
 // the request).
 type BestEffortRetryStrategy struct {
 	BackoffCalculator BackoffCalculator
+
+	// RetryNonIdempotent, if set, also retries operations which are not idempotent, such as
+	// mutations and queries which are not read-only, after failures which leave it ambiguous
+	// whether they were applied, such as the connection closing whilst they were in flight.
+	// Retrying these operations may apply them more than once.
+	RetryNonIdempotent bool
 }
 
 // NewBestEffortRetryStrategy returns a new BestEffortRetryStrategy which will use the supplied calculator function
@@ -207,14 +237,35 @@ func NewBestEffortRetryStrategy(calculator BackoffCalculator) *BestEffortRetrySt
 }
 
 // RetryAfter calculates and returns a RetryAction describing how long to wait before retrying an operation.
+// Search requests which were rejected for being too many wait for at least as long as the rate limited
+// backoff.  Operations which are not idempotent are only retried if the reason guarantees that they were
+// not applied, unless RetryNonIdempotent is set.
 func (rs *BestEffortRetryStrategy) RetryAfter(req RetryRequest, reason RetryReason) RetryAction {
-	if req.Idempotent() || reason.AllowsNonIdempotentRetry() {
-		return &WithDurationRetryAction{WithDuration: rs.BackoffCalculator(req.RetryAttempts())}
+	if req.Idempotent() || reason.AllowsNonIdempotentRetry() || rs.RetryNonIdempotent {
+		duration := rs.BackoffCalculator(req.RetryAttempts())
+		if reason == SearchTooManyRequestsRetryReason {
+			if limitedDuration := rateLimitedBackoff(req.RetryAttempts()); limitedDuration > duration {
+				duration = limitedDuration
+			}
+		}
+
+		return &WithDurationRetryAction{WithDuration: duration}
 	}
 
 	return &NoRetryRetryAction{}
 }
 
+// rateLimitedBackoff calculates the backoff duration for search requests which were rate limited.  Rate limits
+// are applied over periods of up to a minute, so this backs off more quickly, and further, than the
+// controlled backoff in order to avoid using up the limit as soon as it recovers.
+func rateLimitedBackoff(retryAttempts uint32) time.Duration {
+	if retryAttempts > 6 {
+		return 10 * time.Second
+	}
+
+	return (100 * time.Millisecond) << retryAttempts
+}
+
 // failFastRetryStrategy represents a strategy that will never retry.
 type failFastRetryStrategy struct {
 }
@@ -133,6 +133,24 @@ func TestBestEffortRetryStrategy_RetryAfterDefaultCalculator(t *testing.T) {
 	}
 }
 
+func TestBestEffortRetryStrategy_RetryAfterSearchTooManyRequests(t *testing.T) {
+	strategy := NewBestEffortRetryStrategy(nil)
+	action := strategy.RetryAfter(&mockRetryRequest{}, SearchTooManyRequestsRetryReason)
+	if action.Duration() != 100*time.Millisecond {
+		t.Fatalf("Expected duration to be %d but was %d", 100*time.Millisecond, action.Duration())
+	}
+
+	action = strategy.RetryAfter(&mockRetryRequest{attempts: 3}, SearchTooManyRequestsRetryReason)
+	if action.Duration() != 800*time.Millisecond {
+		t.Fatalf("Expected duration to be %d but was %d", 800*time.Millisecond, action.Duration())
+	}
+
+	action = strategy.RetryAfter(&mockRetryRequest{attempts: 20}, SearchTooManyRequestsRetryReason)
+	if action.Duration() != 10*time.Second {
+		t.Fatalf("Expected duration to be %d but was %d", 10*time.Second, action.Duration())
+	}
+}
+
 func TestFailFastRetryStrategy_RetryAfterNoRetry(t *testing.T) {
 	strategy := newFailFastRetryStrategy()
 	action := strategy.RetryAfter(&mockRetryRequest{}, RetryReason(gocbcore.UnknownRetryReason))
@@ -156,3 +174,22 @@ func TestFailFastRetryStrategy_RetryAfterAllowsNonIdempotent(t *testing.T) {
 		t.Fatalf("Expected duration to be %d but was %d", 0, action.Duration())
 	}
 }
+
+func TestBestEffortRetryStrategy_RetryAfterAmbiguousNonIdempotent(t *testing.T) {
+	strategy := NewBestEffortRetryStrategy(mockBackoffCalculator)
+	action := strategy.RetryAfter(&mockRetryRequest{attempts: 5}, SocketCloseInFlightRetryReason)
+	if action.Duration() != 0 {
+		t.Fatalf("Expected duration to be %d but was %d", 0, action.Duration())
+	}
+
+	action = strategy.RetryAfter(&mockRetryRequest{attempts: 5, idempotent: true}, SocketCloseInFlightRetryReason)
+	if action.Duration() != 5*time.Millisecond {
+		t.Fatalf("Expected duration to be %d but was %d", 5*time.Millisecond, action.Duration())
+	}
+
+	strategy.RetryNonIdempotent = true
+	action = strategy.RetryAfter(&mockRetryRequest{attempts: 5}, SocketCloseInFlightRetryReason)
+	if action.Duration() != 5*time.Millisecond {
+		t.Fatalf("Expected duration to be %d but was %d", 5*time.Millisecond, action.Duration())
+	}
+}
@@ -0,0 +1,338 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// rowReader is the common interface implemented by the streaming row readers
+// which back the query, analytics, search and view results.
+type rowReader interface {
+	NextRow() []byte
+	Err() error
+	MetaData() ([]byte, error)
+	Close() error
+}
+
+// closeOnceRowReader wraps a rowReader to provide consistent semantics around
+// closing.  Closing releases the underlying HTTP stream even if rows remain,
+// no further rows are returned once closed and repeated calls to Close (or
+// calls to Close after a stream error) return the same result.
+type closeOnceRowReader struct {
+	reader rowReader
+
+	lock     sync.Mutex
+	closed   bool
+	closeErr error
+}
+
+func newCloseOnceRowReader(reader rowReader) *closeOnceRowReader {
+	return &closeOnceRowReader{
+		reader: reader,
+	}
+}
+
+func (r *closeOnceRowReader) isClosed() bool {
+	r.lock.Lock()
+	closed := r.closed
+	r.lock.Unlock()
+
+	return closed
+}
+
+func (r *closeOnceRowReader) NextRow() []byte {
+	if r.isClosed() {
+		return nil
+	}
+
+	return r.reader.NextRow()
+}
+
+func (r *closeOnceRowReader) Err() error {
+	return r.reader.Err()
+}
+
+func (r *closeOnceRowReader) MetaData() ([]byte, error) {
+	return r.reader.MetaData()
+}
+
+func (r *closeOnceRowReader) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return r.closeErr
+	}
+
+	r.closed = true
+	r.closeErr = r.reader.Close()
+
+	return r.closeErr
+}
+
+// ctxRowReader wraps a rowReader so that cancellation of the supplied context
+// aborts the underlying stream, with the context error then being surfaced
+// from the stream rather than blocking until the server completes.
+type ctxRowReader struct {
+	reader   rowReader
+	ctx      context.Context
+	onCancel func()
+	workers  *clusterWorkers
+
+	lock     sync.Mutex
+	ctxErr   error
+	doneCh   chan struct{}
+	doneOnce sync.Once
+}
+
+// newCtxRowReader wraps reader so that it is aborted when ctx is cancelled.  If
+// onCancel is not nil it is invoked once the stream has been aborted, allowing
+// services to also cancel the request server side.  The context is watched by a
+// worker of the Cluster, which stops watching once the Cluster is closed.
+func newCtxRowReader(ctx context.Context, workers *clusterWorkers, reader rowReader, onCancel func()) rowReader {
+	if ctx == nil || ctx.Done() == nil {
+		return reader
+	}
+
+	r := &ctxRowReader{
+		reader:   reader,
+		ctx:      ctx,
+		onCancel: onCancel,
+		workers:  workers,
+		doneCh:   make(chan struct{}),
+	}
+	workers.start(r.watch)
+
+	return r
+}
+
+func (r *ctxRowReader) watch() {
+	select {
+	case <-r.workers.closed():
+	case <-r.ctx.Done():
+		r.lock.Lock()
+		r.ctxErr = r.ctx.Err()
+		r.lock.Unlock()
+
+		// Closing the stream will unblock any reader currently waiting on
+		// the next row from the server.
+		err := r.reader.Close()
+		if err != nil {
+			logDebugf("Failed to close stream after context cancellation: %s", err)
+		}
+
+		if r.onCancel != nil {
+			r.onCancel()
+		}
+	case <-r.doneCh:
+	}
+}
+
+func (r *ctxRowReader) finish() {
+	r.doneOnce.Do(func() {
+		close(r.doneCh)
+	})
+}
+
+func (r *ctxRowReader) contextErr() error {
+	r.lock.Lock()
+	err := r.ctxErr
+	r.lock.Unlock()
+
+	return err
+}
+
+func (r *ctxRowReader) NextRow() []byte {
+	if r.contextErr() != nil {
+		return nil
+	}
+
+	rowBytes := r.reader.NextRow()
+	if rowBytes == nil {
+		r.finish()
+		return nil
+	}
+
+	// The stream may have been aborted whilst we were reading this row.
+	if r.contextErr() != nil {
+		return nil
+	}
+
+	return rowBytes
+}
+
+func (r *ctxRowReader) Err() error {
+	if err := r.contextErr(); err != nil {
+		return err
+	}
+
+	return r.reader.Err()
+}
+
+func (r *ctxRowReader) MetaData() ([]byte, error) {
+	if err := r.contextErr(); err != nil {
+		return nil, err
+	}
+
+	return r.reader.MetaData()
+}
+
+func (r *ctxRowReader) Close() error {
+	r.finish()
+
+	if err := r.contextErr(); err != nil {
+		return err
+	}
+
+	return r.reader.Close()
+}
+
+// maxStreamRedispatches is the number of times that a resumingRowReader will re-dispatch a
+// request after its stream fails.
+const maxStreamRedispatches = 3
+
+// isStreamResetError returns whether err indicates that the connection carrying a stream of
+// results was lost part way through, such as when the node serving it restarted.
+func isStreamResetError(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && !opErr.Timeout()
+}
+
+// resumingRowReader re-dispatches an idempotent request whose stream of results fails with a
+// connection error, rather than surfacing the error.  The rows which were already returned are
+// skipped in the new stream, so the request must return its rows in a deterministic order.
+type resumingRowReader struct {
+	redispatch  func() (rowReader, error)
+	numRetries  int
+	rowsRead    int
+	rowsToSkip  int
+	redispatchE error
+
+	lock   sync.Mutex
+	reader rowReader
+	closed bool
+}
+
+func newResumingRowReader(reader rowReader, redispatch func() (rowReader, error)) *resumingRowReader {
+	return &resumingRowReader{
+		reader:     reader,
+		redispatch: redispatch,
+	}
+}
+
+func (r *resumingRowReader) currentReader() rowReader {
+	r.lock.Lock()
+	reader := r.reader
+	r.lock.Unlock()
+
+	return reader
+}
+
+func (r *resumingRowReader) NextRow() []byte {
+	for {
+		reader := r.currentReader()
+
+		rowBytes := reader.NextRow()
+		if rowBytes != nil {
+			if r.rowsToSkip > 0 {
+				r.rowsToSkip--
+				continue
+			}
+
+			r.rowsRead++
+			return rowBytes
+		}
+
+		err := reader.Err()
+		if err == nil {
+			if r.rowsToSkip > 0 {
+				r.redispatchE = errors.New("re-dispatched request returned fewer rows than were already read")
+			}
+			return nil
+		}
+
+		if !isStreamResetError(err) || r.numRetries >= maxStreamRedispatches {
+			return nil
+		}
+		r.numRetries++
+
+		logDebugf("Result stream failed after %d rows, re-dispatching request (attempt %d): %s",
+			r.rowsRead, r.numRetries, err)
+
+		newReader, err := r.redispatch()
+		if err != nil {
+			r.redispatchE = err
+			return nil
+		}
+
+		r.lock.Lock()
+		if r.closed {
+			r.lock.Unlock()
+			if closeErr := newReader.Close(); closeErr != nil {
+				logDebugf("Failed to close re-dispatched stream after close: %s", closeErr)
+			}
+			return nil
+		}
+		r.reader = newReader
+		r.lock.Unlock()
+
+		if closeErr := reader.Close(); closeErr != nil {
+			logDebugf("Failed to close failed stream: %s", closeErr)
+		}
+
+		r.rowsToSkip = r.rowsRead
+	}
+}
+
+func (r *resumingRowReader) Err() error {
+	if r.redispatchE != nil {
+		return r.redispatchE
+	}
+
+	return r.currentReader().Err()
+}
+
+func (r *resumingRowReader) MetaData() ([]byte, error) {
+	if r.redispatchE != nil {
+		return nil, r.redispatchE
+	}
+
+	return r.currentReader().MetaData()
+}
+
+func (r *resumingRowReader) Close() error {
+	r.lock.Lock()
+	r.closed = true
+	reader := r.reader
+	r.lock.Unlock()
+
+	if err := reader.Close(); err != nil {
+		return err
+	}
+
+	return r.redispatchE
+}
+
+// contextDeadline returns the earlier of the deadline provided and the
+// deadline of the context, if it has one.
+func contextDeadline(ctx context.Context, deadline time.Time) time.Time {
+	if ctx == nil {
+		return deadline
+	}
+
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+
+	return deadline
+}
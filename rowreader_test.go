@@ -0,0 +1,332 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+type mockRowReader struct {
+	rows       [][]byte
+	err        error
+	closeErr   error
+	closeCount int
+}
+
+func (r *mockRowReader) NextRow() []byte {
+	if len(r.rows) == 0 {
+		return nil
+	}
+
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row
+}
+
+func (r *mockRowReader) Err() error {
+	return r.err
+}
+
+func (r *mockRowReader) MetaData() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (r *mockRowReader) Close() error {
+	r.closeCount++
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.closeErr
+}
+
+// blockingRowReader returns its rows and then blocks until it is closed, as a stream does
+// whilst waiting for the server to send more rows.
+type blockingRowReader struct {
+	rows    [][]byte
+	closeCh chan struct{}
+}
+
+func newBlockingRowReader(rows ...[]byte) *blockingRowReader {
+	return &blockingRowReader{
+		rows:    rows,
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (r *blockingRowReader) NextRow() []byte {
+	if len(r.rows) > 0 {
+		row := r.rows[0]
+		r.rows = r.rows[1:]
+		return row
+	}
+
+	<-r.closeCh
+	return nil
+}
+
+func (r *blockingRowReader) Err() error {
+	return nil
+}
+
+func (r *blockingRowReader) MetaData() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (r *blockingRowReader) Close() error {
+	select {
+	case <-r.closeCh:
+	default:
+		close(r.closeCh)
+	}
+	return nil
+}
+
+func TestQueryResultContextCancelledMidIteration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader := newBlockingRowReader([]byte(`{"id":1}`))
+	res, err := newQueryResult(newCtxRowReader(ctx, nil, reader, nil))
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	nextCh := make(chan bool)
+	go func() {
+		nextCh <- res.Next()
+	}()
+
+	select {
+	case hasNext := <-nextCh:
+		if hasNext {
+			t.Fatalf("Next should not return rows after the context is cancelled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Next did not return after the context was cancelled")
+	}
+
+	if !errors.Is(res.Err(), context.Canceled) {
+		t.Fatalf("Expected Err to wrap context.Canceled but was %v", res.Err())
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(10 * time.Second)
+
+	if d := contextDeadline(context.Background(), deadline); !d.Equal(deadline) {
+		t.Fatalf("Expected the timeout deadline without a context deadline but was %v", d)
+	}
+
+	shortCtx, cancel := context.WithDeadline(context.Background(), now.Add(time.Second))
+	defer cancel()
+	if d := contextDeadline(shortCtx, deadline); !d.Equal(now.Add(time.Second)) {
+		t.Fatalf("Expected the earlier context deadline but was %v", d)
+	}
+
+	longCtx, cancel := context.WithDeadline(context.Background(), now.Add(time.Minute))
+	defer cancel()
+	if d := contextDeadline(longCtx, deadline); !d.Equal(deadline) {
+		t.Fatalf("Expected the earlier timeout deadline but was %v", d)
+	}
+}
+
+func TestQueryResultCloseWithRemainingRows(t *testing.T) {
+	reader := &mockRowReader{
+		rows: [][]byte{[]byte(`{"id":1}`), []byte(`{"id":2}`), []byte(`{"id":3}`)},
+	}
+	res, err := newQueryResult(reader)
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	err = res.Close()
+	if err != nil {
+		t.Fatalf("Close should not have errored: %v", err)
+	}
+
+	if reader.closeCount != 1 {
+		t.Fatalf("Underlying reader should have been closed once but was closed %d times", reader.closeCount)
+	}
+
+	if res.Next() {
+		t.Fatalf("Next should not return rows after Close")
+	}
+}
+
+func TestQueryResultDoubleClose(t *testing.T) {
+	reader := &mockRowReader{}
+	res, err := newQueryResult(reader)
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	err = res.Close()
+	if err != nil {
+		t.Fatalf("Close should not have errored: %v", err)
+	}
+
+	err = res.Close()
+	if err != nil {
+		t.Fatalf("Second Close should not have errored: %v", err)
+	}
+
+	if reader.closeCount != 1 {
+		t.Fatalf("Underlying reader should have been closed once but was closed %d times", reader.closeCount)
+	}
+}
+
+func TestQueryResultCloseAfterError(t *testing.T) {
+	streamErr := errors.New("stream failed")
+	reader := &mockRowReader{
+		err: streamErr,
+	}
+	res, err := newQueryResult(reader)
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if res.Next() {
+		t.Fatalf("Next should not have returned a row")
+	}
+
+	err = res.Close()
+	if !errors.Is(err, streamErr) {
+		t.Fatalf("Close should have returned the stream error but was %v", err)
+	}
+
+	err = res.Close()
+	if !errors.Is(err, streamErr) {
+		t.Fatalf("Second Close should have returned the stream error but was %v", err)
+	}
+
+	if reader.closeCount != 1 {
+		t.Fatalf("Underlying reader should have been closed once but was closed %d times", reader.closeCount)
+	}
+}
+
+func TestSearchResultDoubleClose(t *testing.T) {
+	reader := &mockRowReader{
+		rows: [][]byte{[]byte(`{"id":"key"}`)},
+	}
+	res, err := newSearchResult(reader)
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	err = res.Close()
+	if err != nil {
+		t.Fatalf("Close should not have errored: %v", err)
+	}
+
+	err = res.Close()
+	if err != nil {
+		t.Fatalf("Second Close should not have errored: %v", err)
+	}
+
+	if reader.closeCount != 1 {
+		t.Fatalf("Underlying reader should have been closed once but was closed %d times", reader.closeCount)
+	}
+}
+
+func TestResumingRowReaderRedispatchesOnReset(t *testing.T) {
+	first := &mockRowReader{
+		rows: [][]byte{[]byte(`1`), []byte(`2`)},
+		err:  io.ErrUnexpectedEOF,
+	}
+	second := &mockRowReader{
+		rows: [][]byte{[]byte(`1`), []byte(`2`), []byte(`3`)},
+	}
+
+	dispatches := 0
+	reader := newResumingRowReader(first, func() (rowReader, error) {
+		dispatches++
+		return second, nil
+	})
+
+	var rows []string
+	for row := reader.NextRow(); row != nil; row = reader.NextRow() {
+		rows = append(rows, string(row))
+	}
+
+	if err := reader.Err(); err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+	if dispatches != 1 {
+		t.Fatalf("Expected 1 re-dispatch but was %d", dispatches)
+	}
+	if len(rows) != 3 || rows[0] != "1" || rows[1] != "2" || rows[2] != "3" {
+		t.Fatalf("Expected rows 1, 2 and 3 but was %v", rows)
+	}
+	if first.closeCount != 1 {
+		t.Fatalf("Expected failed stream to be closed once but was closed %d times", first.closeCount)
+	}
+}
+
+func TestResumingRowReaderSurfacesOtherErrors(t *testing.T) {
+	streamErr := errors.New("query failed")
+	reader := newResumingRowReader(&mockRowReader{err: streamErr}, func() (rowReader, error) {
+		t.Fatalf("Request should not have been re-dispatched")
+		return nil, nil
+	})
+
+	if reader.NextRow() != nil {
+		t.Fatalf("Expected no rows")
+	}
+	if err := reader.Err(); !errors.Is(err, streamErr) {
+		t.Fatalf("Expected stream error but was %v", err)
+	}
+}
+
+func TestResumingRowReaderFailsIfResultShrinks(t *testing.T) {
+	first := &mockRowReader{
+		rows: [][]byte{[]byte(`1`), []byte(`2`)},
+		err:  io.ErrUnexpectedEOF,
+	}
+	reader := newResumingRowReader(first, func() (rowReader, error) {
+		return &mockRowReader{rows: [][]byte{[]byte(`1`)}}, nil
+	})
+
+	rows := 0
+	for row := reader.NextRow(); row != nil; row = reader.NextRow() {
+		rows++
+	}
+
+	if rows != 2 {
+		t.Fatalf("Expected 2 rows but was %d", rows)
+	}
+	if reader.Err() == nil {
+		t.Fatalf("Expected an error when the re-dispatched result had fewer rows")
+	}
+}
+
+func TestResumingRowReaderGivesUp(t *testing.T) {
+	dispatches := 0
+	reader := newResumingRowReader(&mockRowReader{err: io.ErrUnexpectedEOF}, func() (rowReader, error) {
+		dispatches++
+		return &mockRowReader{err: io.ErrUnexpectedEOF}, nil
+	})
+
+	if reader.NextRow() != nil {
+		t.Fatalf("Expected no rows")
+	}
+	if dispatches != maxStreamRedispatches {
+		t.Fatalf("Expected %d re-dispatches but was %d", maxStreamRedispatches, dispatches)
+	}
+	if err := reader.Err(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected stream error but was %v", err)
+	}
+}
@@ -1,14 +1,21 @@
 package gocb
 
+import (
+	cbsearch "github.com/couchbase/gocb/v2/search"
+)
+
 // Scope represents a single scope within a bucket.
 // VOLATILE: This API is subject to change at any time.
 type Scope struct {
 	sb stateBlock
+
+	cluster *Cluster
 }
 
 func newScope(bucket *Bucket, scopeName string) *Scope {
 	scope := &Scope{
-		sb: bucket.stateBlock(),
+		sb:      bucket.stateBlock(),
+		cluster: bucket.cluster,
 	}
 	scope.sb.ScopeName = scopeName
 	return scope
@@ -30,6 +37,37 @@ func (s *Scope) Collection(collectionName string) *Collection {
 	return newCollection(s, collectionName)
 }
 
+// Query executes the query statement on the server.  Keyspaces which are not fully qualified in
+// the statement, such as the collection in SELECT * FROM airline, are resolved against this
+// scope.  Prepared statements share the prepared statement cache of the Cluster.
+// Scope level queries require ClusterOptions.EnableDeveloperPreview.
+// VOLATILE: This API is subject to change at any time.
+func (s *Scope) Query(statement string, opts *QueryOptions) (*QueryResult, error) {
+	if err := s.sb.checkDeveloperPreview("Scope.Query"); err != nil {
+		return nil, err
+	}
+
+	queryContext := QueryContext{
+		BucketName: s.sb.BucketName,
+		ScopeName:  s.sb.ScopeName,
+	}
+
+	return s.cluster.query(statement, queryContext.String(), opts)
+}
+
+// SearchQuery executes a search query against a search index defined within this scope.
+// SearchOptions.Collections can be used to restrict the search to some of the collections
+// within the scope.  Scope level search requires ClusterOptions.EnableDeveloperPreview.
+// VOLATILE: This API is subject to change at any time.
+func (s *Scope) SearchQuery(indexName string, query cbsearch.Query, opts *SearchOptions) (*SearchResult, error) {
+	if err := s.sb.checkDeveloperPreview("Scope.SearchQuery"); err != nil {
+		return nil, err
+	}
+
+	// Search indexes within a scope are addressed by their fully qualified name.
+	return s.cluster.SearchQuery(s.sb.BucketName+"."+s.sb.ScopeName+"."+indexName, query, opts)
+}
+
 func (s *Scope) stateBlock() stateBlock {
 	return s.sb
 }
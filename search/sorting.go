@@ -4,7 +4,8 @@ import (
 	"encoding/json"
 )
 
-// SearchSort represents an search sorting for a search query.
+// Sort represents a search sorting for a search query.  Sorts should be created
+// using one of the NewSearchSort constructors.
 type Sort interface {
 }
 
@@ -59,6 +60,42 @@ func (q *SearchSortID) Descending(descending bool) *SearchSortID {
 	return q
 }
 
+// SortFieldType specifies the type of the values of the field being sorted on.
+type SortFieldType string
+
+const (
+	// SortFieldTypeAuto indicates that the type of the field should be detected automatically.
+	SortFieldTypeAuto = SortFieldType("auto")
+	// SortFieldTypeString indicates that the field should be sorted as a string.
+	SortFieldTypeString = SortFieldType("string")
+	// SortFieldTypeNumber indicates that the field should be sorted as a number.
+	SortFieldTypeNumber = SortFieldType("number")
+	// SortFieldTypeDate indicates that the field should be sorted as a date.
+	SortFieldTypeDate = SortFieldType("date")
+)
+
+// SortFieldMode specifies which value is used for sorting when a field has multiple values.
+type SortFieldMode string
+
+const (
+	// SortFieldModeDefault indicates that the default mode should be used.
+	SortFieldModeDefault = SortFieldMode("default")
+	// SortFieldModeMin indicates that the minimum of the field values should be used.
+	SortFieldModeMin = SortFieldMode("min")
+	// SortFieldModeMax indicates that the maximum of the field values should be used.
+	SortFieldModeMax = SortFieldMode("max")
+)
+
+// SortFieldMissing specifies where hits which are missing the field being sorted on are placed.
+type SortFieldMissing string
+
+const (
+	// SortFieldMissingFirst indicates that hits missing the field should be sorted first.
+	SortFieldMissingFirst = SortFieldMissing("first")
+	// SortFieldMissingLast indicates that hits missing the field should be sorted last.
+	SortFieldMissingLast = SortFieldMissing("last")
+)
+
 // SearchSortField represents a search field sort.
 type SearchSortField struct {
 	searchSortBase
@@ -73,20 +110,20 @@ func NewSearchSortField(field string) *SearchSortField {
 }
 
 // Type allows you to specify the search field sort type.
-func (q *SearchSortField) Type(value string) *SearchSortField {
-	q.options["type"] = value
+func (q *SearchSortField) Type(value SortFieldType) *SearchSortField {
+	q.options["type"] = string(value)
 	return q
 }
 
 // Mode allows you to specify the search field sort mode.
-func (q *SearchSortField) Mode(mode string) *SearchSortField {
-	q.options["mode"] = mode
+func (q *SearchSortField) Mode(mode SortFieldMode) *SearchSortField {
+	q.options["mode"] = string(mode)
 	return q
 }
 
 // Missing allows you to specify the search field sort missing behaviour.
-func (q *SearchSortField) Missing(missing string) *SearchSortField {
-	q.options["missing"] = missing
+func (q *SearchSortField) Missing(missing SortFieldMissing) *SearchSortField {
+	q.options["missing"] = string(missing)
 	return q
 }
 
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSearchSortFieldMarshal(t *testing.T) {
+	sort := NewSearchSortField("name").
+		Type(SortFieldTypeString).
+		Mode(SortFieldModeMax).
+		Missing(SortFieldMissingFirst).
+		Descending(true)
+
+	data, err := json.Marshal(sort)
+	if err != nil {
+		t.Fatalf("Failed to marshal sort: %v", err)
+	}
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Failed to unmarshal sort: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"by":      "field",
+		"field":   "name",
+		"type":    "string",
+		"mode":    "max",
+		"missing": "first",
+		"desc":    true,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected sort to be %v but was %v", expected, actual)
+	}
+}
+
+func TestSearchSortFieldOmitsUnsetOptions(t *testing.T) {
+	data, err := json.Marshal(NewSearchSortField("name"))
+	if err != nil {
+		t.Fatalf("Failed to marshal sort: %v", err)
+	}
+
+	if string(data) != `{"by":"field","field":"name"}` {
+		t.Fatalf("Expected only by and field to be set but was %s", data)
+	}
+}
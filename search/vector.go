@@ -0,0 +1,69 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// VectorQueryCombination specifies how the hits of multiple vector queries are combined.
+type VectorQueryCombination string
+
+const (
+	// VectorQueryCombinationOr returns the hits which match any of the vector queries.
+	VectorQueryCombinationOr = VectorQueryCombination("or")
+
+	// VectorQueryCombinationAnd returns only the hits which match all of the vector queries.
+	VectorQueryCombinationAnd = VectorQueryCombination("and")
+)
+
+type vectorQueryData struct {
+	Field  string    `json:"field"`
+	Vector []float32 `json:"vector"`
+	K      uint32    `json:"k"`
+	Boost  float32   `json:"boost,omitempty"`
+}
+
+// VectorQuery represents a k-nearest-neighbour query against a vector field of a search index.
+// UNCOMMITTED: This API may change in the future.
+type VectorQuery struct {
+	data vectorQueryData
+}
+
+// NewVectorQuery creates a new VectorQuery which finds the 3 nearest neighbours of vector in
+// the vector field given.
+func NewVectorQuery(field string, vector []float32) *VectorQuery {
+	return &VectorQuery{
+		data: vectorQueryData{
+			Field:  field,
+			Vector: vector,
+			K:      3,
+		},
+	}
+}
+
+// K specifies the number of nearest neighbours to return for this query.
+func (q *VectorQuery) K(k uint32) *VectorQuery {
+	q.data.K = k
+	return q
+}
+
+// Boost specifies the boost for this query.
+func (q *VectorQuery) Boost(boost float32) *VectorQuery {
+	q.data.Boost = boost
+	return q
+}
+
+// MarshalJSON marshal's this query to JSON for the search REST API.
+func (q VectorQuery) MarshalJSON() ([]byte, error) {
+	if q.data.Field == "" {
+		return nil, errors.New("vector query field cannot be empty")
+	}
+	if len(q.data.Vector) == 0 {
+		return nil, errors.New("vector query vector cannot be empty")
+	}
+	if q.data.K == 0 {
+		return nil, errors.New("vector query k must be greater than 0")
+	}
+
+	return json.Marshal(q.data)
+}
@@ -1,6 +1,7 @@
 package gocb
 
 import (
+	"context"
 	"time"
 
 	cbsearch "github.com/couchbase/gocb/v2/search"
@@ -49,10 +50,57 @@ type SearchOptions struct {
 	ConsistentWith  *MutationState
 	Raw             map[string]interface{}
 
+	// SearchAfter, if set, returns the hits which follow the hit with these sort keys, allowing
+	// large result sets to be paged through more efficiently than with Skip.  The sort keys of
+	// a hit are available from SearchRow.SortKeys.  Sort must also be set, and should end with
+	// a sort on the document ID so that every hit has distinct sort keys.
+	// UNCOMMITTED: This API may change in the future.
+	SearchAfter []string
+
+	// SearchBefore, if set, returns the hits which precede the hit with these sort keys, for
+	// paging backwards.  See SearchAfter for more information.
+	// UNCOMMITTED: This API may change in the future.
+	SearchBefore []string
+
+	// Collections, if set, restricts the search to the documents in these collections.  It can
+	// only be used with search indexes defined within a scope, using Scope.SearchQuery.
+	// VOLATILE: This API is subject to change at any time.
+	Collections []string
+
+	// VectorQueries, if set, are k-nearest-neighbour queries against vector fields of the index,
+	// whose hits are combined with the hits of the search query.  For a search which only uses
+	// vector queries, use cbsearch.NewMatchNoneQuery as the search query.
+	// UNCOMMITTED: This API may change in the future.
+	VectorQueries []*cbsearch.VectorQuery
+
+	// VectorQueryCombination specifies how the hits of multiple vector queries are combined.
+	// The default is cbsearch.VectorQueryCombinationOr.
+	// UNCOMMITTED: This API may change in the future.
+	VectorQueryCombination cbsearch.VectorQueryCombination
+
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
-	parentSpan requestSpanContext
+	// Context, if set, allows the query to be cancelled.  Cancelling the context whilst rows
+	// are being read will abort the underlying stream.  Cancelling it before the server has
+	// responded returns the context error straight away, but the HTTP request is only
+	// abandoned rather than aborted, so it runs on in the background until the server
+	// responds or the timeout, which is also sent to the server, elapses.
+	Context context.Context
+
+	// Tags are attached to the tracing span and threshold log entry recorded for
+	// this query.
+	Tags map[string]string
+
+	// RetryOnStreamReset, if set, re-dispatches the query if the connection streaming its
+	// hits is lost, such as when the node serving it restarts, rather than failing the
+	// result.  The hits which were already read are skipped, so the query should specify
+	// Sort if hits may have equal scores.
+	// UNCOMMITTED: This API may change in the future.
+	RetryOnStreamReset bool
+
+	// ParentSpan, if set, is the parent of the span recorded for this query.
+	ParentSpan RequestSpanContext
 }
 
 func (opts *SearchOptions) toMap() (map[string]interface{}, error) {
@@ -64,6 +112,47 @@ func (opts *SearchOptions) toMap() (map[string]interface{}, error) {
 	data["fields"] = opts.Fields
 	data["sort"] = opts.Sort
 
+	if opts.SearchAfter != nil || opts.SearchBefore != nil {
+		if opts.SearchAfter != nil && opts.SearchBefore != nil {
+			return nil, makeInvalidArgumentsError("SearchAfter and SearchBefore must be used exclusively")
+		}
+		if len(opts.Sort) == 0 {
+			return nil, makeInvalidArgumentsError("Sort must be set when using SearchAfter or SearchBefore")
+		}
+		if opts.Skip != 0 {
+			return nil, makeInvalidArgumentsError("Skip cannot be used with SearchAfter or SearchBefore")
+		}
+
+		if opts.SearchAfter != nil {
+			data["search_after"] = opts.SearchAfter
+		} else {
+			data["search_before"] = opts.SearchBefore
+		}
+	}
+
+	if len(opts.Collections) > 0 {
+		for _, collection := range opts.Collections {
+			if collection == "" {
+				return nil, makeInvalidArgumentsError("collection names cannot be empty")
+			}
+		}
+		data["collections"] = opts.Collections
+	}
+
+	if len(opts.VectorQueries) > 0 {
+		data["knn"] = opts.VectorQueries
+
+		if opts.VectorQueryCombination != "" {
+			if opts.VectorQueryCombination != cbsearch.VectorQueryCombinationOr &&
+				opts.VectorQueryCombination != cbsearch.VectorQueryCombinationAnd {
+				return nil, makeInvalidArgumentsError("unexpected vector query combination option")
+			}
+			data["knn_operator"] = string(opts.VectorQueryCombination)
+		}
+	} else if opts.VectorQueryCombination != "" {
+		return nil, makeInvalidArgumentsError("VectorQueryCombination cannot be used without VectorQueries")
+	}
+
 	if opts.Highlight != nil {
 		highlight := make(map[string]interface{})
 		highlight["style"] = string(opts.Highlight.Style)
@@ -112,6 +201,10 @@ func (opts *SearchOptions) toMap() (map[string]interface{}, error) {
 		ctl["consistency"] = consistency
 	}
 
+	if ctl != nil {
+		data["ctl"] = ctl
+	}
+
 	if opts.Raw != nil {
 		for k, v := range opts.Raw {
 			data[k] = v
@@ -0,0 +1,130 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	cbsearch "github.com/couchbase/gocb/v2/search"
+)
+
+func TestSearchQueryOptionsConsistencyInCtl(t *testing.T) {
+	opts := &SearchOptions{
+		ScanConsistency: SearchScanConsistencyNotBounded,
+	}
+
+	data, err := opts.toMap()
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	ctl, ok := data["ctl"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected ctl to be present but was %v", data["ctl"])
+	}
+
+	consistency, ok := ctl["consistency"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected ctl consistency to be present but was %v", ctl["consistency"])
+	}
+
+	if consistency["level"] != "not_bounded" {
+		t.Fatalf("Expected consistency level to be not_bounded but was %v", consistency["level"])
+	}
+}
+
+func TestSearchQueryOptionsNoCtl(t *testing.T) {
+	opts := &SearchOptions{}
+
+	data, err := opts.toMap()
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if _, ok := data["ctl"]; ok {
+		t.Fatalf("Expected ctl to not be present but was %v", data["ctl"])
+	}
+}
+
+func TestSearchQueryOptionsSearchAfter(t *testing.T) {
+	opts := &SearchOptions{
+		Sort:        []cbsearch.Sort{cbsearch.NewSearchSortScore(), cbsearch.NewSearchSortID()},
+		SearchAfter: []string{"_score", "airline_10"},
+	}
+
+	data, err := opts.toMap()
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	if !reflect.DeepEqual(data["search_after"], opts.SearchAfter) {
+		t.Fatalf("Expected search_after to be %v but was %v", opts.SearchAfter, data["search_after"])
+	}
+	if _, ok := data["search_before"]; ok {
+		t.Fatalf("Expected search_before to not be present but was %v", data["search_before"])
+	}
+
+	invalidOpts := []*SearchOptions{
+		{Sort: opts.Sort, SearchAfter: []string{"a"}, SearchBefore: []string{"b"}},
+		{SearchBefore: []string{"a"}},
+		{Sort: opts.Sort, SearchAfter: []string{"a"}, Skip: 10},
+	}
+	for _, invalid := range invalidOpts {
+		_, err := invalid.toMap()
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("Expected invalid argument error for %+v but was %v", invalid, err)
+		}
+	}
+}
+
+func TestSearchRowSortKeys(t *testing.T) {
+	var row SearchRow
+	err := row.fromBytes([]byte(`{"index":"travel","id":"airline_10","score":1.5,"sort":["_score","airline_10"]}`))
+	if err != nil {
+		t.Fatalf("Failed to decode row: %v", err)
+	}
+
+	if !reflect.DeepEqual(row.SortKeys, []string{"_score", "airline_10"}) {
+		t.Fatalf("Unexpected sort keys %v", row.SortKeys)
+	}
+}
+
+func TestSearchQueryOptionsVectorQueries(t *testing.T) {
+	opts := &SearchOptions{
+		VectorQueries: []*cbsearch.VectorQuery{
+			cbsearch.NewVectorQuery("embedding", []float32{0.5, 1}).K(5).Boost(2),
+			cbsearch.NewVectorQuery("title_embedding", []float32{1, 0.5}),
+		},
+		VectorQueryCombination: cbsearch.VectorQueryCombinationAnd,
+	}
+
+	data, err := opts.toMap()
+	if err != nil {
+		t.Fatalf("Expected no error but was %v", err)
+	}
+
+	knnBytes, err := json.Marshal(data["knn"])
+	if err != nil {
+		t.Fatalf("Failed to marshal knn: %v", err)
+	}
+
+	expected := `[{"field":"embedding","vector":[0.5,1],"k":5,"boost":2},` +
+		`{"field":"title_embedding","vector":[1,0.5],"k":3}]`
+	if string(knnBytes) != expected {
+		t.Fatalf("Expected knn %s but was %s", expected, knnBytes)
+	}
+	if data["knn_operator"] != "and" {
+		t.Fatalf("Expected knn_operator to be and but was %v", data["knn_operator"])
+	}
+
+	_, err = (&SearchOptions{VectorQueryCombination: cbsearch.VectorQueryCombinationOr}).toMap()
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	_, err = json.Marshal(cbsearch.NewVectorQuery("embedding", nil))
+	if err == nil {
+		t.Fatalf("Expected an error marshalling a query without a vector")
+	}
+}
@@ -0,0 +1,56 @@
+package gocb
+
+import "encoding/json"
+
+// JSONSerializer is used to serialize and deserialize JSON values which are not handled by
+// a Transcoder, such as the parameters passed to query and analytics requests.
+type JSONSerializer interface {
+	// Serialize serializes a Go type into JSON bytes.
+	Serialize(value interface{}) ([]byte, error)
+
+	// Deserialize deserializes JSON bytes into a Go type.
+	Deserialize(bytes []byte, out interface{}) error
+}
+
+// DefaultJSONSerializer implements the JSONSerializer interface using json.Marshal/Unmarshal.
+type DefaultJSONSerializer struct {
+}
+
+// NewDefaultJSONSerializer returns a new DefaultJSONSerializer.
+func NewDefaultJSONSerializer() *DefaultJSONSerializer {
+	return &DefaultJSONSerializer{}
+}
+
+// Serialize applies the json.Marshal behaviour to serialize a Go type.
+func (s *DefaultJSONSerializer) Serialize(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Deserialize applies the json.Unmarshal behaviour to deserialize into a Go type.
+func (s *DefaultJSONSerializer) Deserialize(bytes []byte, out interface{}) error {
+	return json.Unmarshal(bytes, out)
+}
+
+// serializeParameter encodes a single query parameter using the serializer so that
+// it is embedded verbatim when the request body is later marshalled.
+func serializeParameter(serializer JSONSerializer, value interface{}) (json.RawMessage, error) {
+	bytes, err := serializer.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(bytes), nil
+}
+
+func serializePositionalParameters(serializer JSONSerializer, params []interface{}) ([]json.RawMessage, error) {
+	args := make([]json.RawMessage, len(params))
+	for i, param := range params {
+		arg, err := serializeParameter(serializer, param)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+
+	return args, nil
+}
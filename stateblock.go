@@ -34,18 +34,49 @@ type stateBlock struct {
 	ViewTimeout       time.Duration
 	ManagementTimeout time.Duration
 
+	// Timeouts holds the timeouts which have been changed at runtime, overriding those above.
+	Timeouts *Timeouts
+
 	UseMutationTokens bool
 
 	Transcoder Transcoder
+	Serializer JSONSerializer
 
 	RetryStrategyWrapper   *retryStrategyWrapper
 	OrphanLoggerEnabled    bool
 	OrphanLoggerInterval   time.Duration
 	OrphanLoggerSampleSize uint32
 
-	Tracer requestTracer
+	Tracer RequestTracer
+
+	// Meter records metrics which are not derived from the spans of operations.  It is nil
+	// if no meter has been configured.
+	Meter Meter
 
 	CircuitBreakerConfig CircuitBreakerConfig
+
+	OperationLimitsConfig OperationLimitsConfig
+	KvLimiter             *opLimiter
+	HTTPLimiter           *opLimiter
+
+	Telemetry *telemetryReporter
+
+	QueryContext string
+
+	ReadOnly bool
+
+	DeveloperPreview bool
+
+	// Workers runs the goroutines of the Cluster which outlive the operations that start them.
+	Workers *clusterWorkers
+
+	UserAgent string
+
+	// Clock is the source of time for the deadlines of operations, the backoff of the loops
+	// which poll the server and the timing of spans by the threshold logging and metering
+	// tracers.  Orphaned responses are logged by gocbcore, which always uses the system clock.
+	// The system clock is used if nil.
+	Clock clock
 }
 
 func (sb *stateBlock) getCachedClient() client {
@@ -0,0 +1,195 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// TelemetrySink receives the telemetry reports of a Cluster.
+type TelemetrySink interface {
+	// ReportTelemetry is called with each report, encoded as a single JSON document.  It is
+	// called from a background goroutine and should not block for long.
+	ReportTelemetry(report []byte)
+}
+
+// TelemetryConfig specifies options for the telemetry report, which periodically combines the
+// operations exceeding the threshold logging thresholds and the number of errors returned by
+// each service into a single report.  When a Sink is set the threshold log is delivered to the
+// sink rather than being logged.  The threshold log is only included when the default tracer is
+// in use.
+//
+// Orphaned responses are not included.  They are recorded and logged by gocbcore, which offers
+// no way to receive them, so they are still logged as configured by OrphanReporterConfig.
+// UNCOMMITTED: This API may change in the future.
+type TelemetryConfig struct {
+	// Sink receives the reports.  If nil then no reports are generated.
+	Sink TelemetrySink
+
+	// Interval is how often a report is generated.  The default is 10 seconds.  A report is only
+	// generated when there is something to report.
+	Interval time.Duration
+}
+
+type telemetryReport struct {
+	Timestamp    time.Time                    `json:"timestamp"`
+	IntervalMs   int64                        `json:"interval_ms"`
+	ThresholdLog []thresholdLogService        `json:"threshold_log,omitempty"`
+	Errors       map[string]map[string]uint64 `json:"errors,omitempty"`
+}
+
+// telemetryReporter gathers the data for the telemetry report.  A nil telemetryReporter
+// discards everything recorded to it.
+type telemetryReporter struct {
+	sink     TelemetrySink
+	interval time.Duration
+	clock    clock
+
+	lock       sync.Mutex
+	lastReport time.Time
+	thresholds []thresholdLogService
+	errors     map[string]map[string]uint64
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	workers  workerGroup
+}
+
+func newTelemetryReporter(config TelemetryConfig, clk clock) *telemetryReporter {
+	if config.Sink == nil {
+		return nil
+	}
+
+	interval := config.Interval
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+
+	clk = clockOrSystem(clk)
+	return &telemetryReporter{
+		sink:       config.Sink,
+		interval:   interval,
+		clock:      clk,
+		lastReport: clk.Now(),
+		errors:     make(map[string]map[string]uint64),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+func (r *telemetryReporter) start() {
+	if r == nil {
+		return
+	}
+
+	r.workers.start(func() {
+		for {
+			select {
+			case <-r.clock.After(r.interval):
+				r.report()
+			case <-r.stopCh:
+				r.report()
+				return
+			}
+		}
+	})
+}
+
+// stop generates a final report and stops the reporter.  It is safe to call stop more than once.
+func (r *telemetryReporter) stop() {
+	if r == nil {
+		return
+	}
+
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.workers.wait()
+}
+
+func (r *telemetryReporter) recordThreshold(service thresholdLogService) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	r.thresholds = append(r.thresholds, service)
+	r.lock.Unlock()
+}
+
+// telemetryErrorClasses are the errors which are counted separately in the telemetry report.
+// Errors which wrap more general errors, such as ErrAmbiguousTimeout, come first.
+var telemetryErrorClasses = []error{
+	ErrAmbiguousTimeout, ErrUnambiguousTimeout, ErrTimeout, ErrRequestCanceled, ErrInvalidArgument,
+	ErrServiceNotAvailable, ErrInternalServerFailure, ErrAuthenticationFailure, ErrTemporaryFailure,
+	ErrOverload, ErrParsingFailure, ErrCasMismatch, ErrBucketNotFound, ErrScopeNotFound,
+	ErrCollectionNotFound, ErrEncodingFailure, ErrDecodingFailure, ErrUnsupportedOperation,
+	ErrFeatureNotAvailable, ErrIndexNotFound, ErrIndexExists, ErrDocumentNotFound,
+	ErrDocumentUnretrievable, ErrDocumentLocked, ErrValueTooLarge, ErrDocumentExists,
+	ErrDurabilityLevelNotAvailable, ErrDurabilityImpossible, ErrDurabilityAmbiguous,
+	ErrDurableWriteInProgress, ErrDurableWriteReCommitInProgress, ErrMutationLost, ErrPathNotFound,
+	ErrPathMismatch, ErrPathInvalid, ErrPathExists, ErrPlanningFailure, ErrIndexFailure,
+	ErrPreparedStatementFailure, ErrCompilationFailure, ErrJobQueueFull, ErrDatasetNotFound,
+	ErrDataverseNotFound, ErrLinkNotFound, ErrViewNotFound, ErrDesignDocumentNotFound,
+	ErrRateLimited, ErrQuotaLimited, ErrOperationQueueFull, ErrReadOnly,
+}
+
+// telemetryErrorClass returns the name under which an error is counted in the telemetry report.
+// Errors are grouped by the SDK error value which they wrap, rather than by their messages, which
+// can include document IDs and addresses.
+func telemetryErrorClass(err error) string {
+	for _, class := range telemetryErrorClasses {
+		if errors.Is(err, class) {
+			return class.Error()
+		}
+	}
+	return "other"
+}
+
+// recordError counts an error returned by a service, by its telemetryErrorClass.
+func (r *telemetryReporter) recordError(service string, err error) {
+	if r == nil || err == nil {
+		return
+	}
+
+	class := telemetryErrorClass(err)
+
+	r.lock.Lock()
+	counts, ok := r.errors[service]
+	if !ok {
+		counts = make(map[string]uint64)
+		r.errors[service] = counts
+	}
+	counts[class]++
+	r.lock.Unlock()
+}
+
+func (r *telemetryReporter) report() {
+	now := r.clock.Now()
+
+	r.lock.Lock()
+	report := telemetryReport{
+		Timestamp:    now,
+		IntervalMs:   int64(now.Sub(r.lastReport) / time.Millisecond),
+		ThresholdLog: r.thresholds,
+	}
+	if len(r.errors) > 0 {
+		report.Errors = r.errors
+	}
+	r.lastReport = now
+	r.thresholds = nil
+	r.errors = make(map[string]map[string]uint64)
+	r.lock.Unlock()
+
+	if len(report.ThresholdLog) == 0 && len(report.Errors) == 0 {
+		return
+	}
+
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		logDebugf("Failed to generate telemetry report JSON: %s", err)
+		return
+	}
+
+	r.sink.ReportTelemetry(reportBytes)
+}
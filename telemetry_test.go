@@ -0,0 +1,198 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+type testTelemetrySink struct {
+	lock    sync.Mutex
+	reports [][]byte
+}
+
+func (s *testTelemetrySink) ReportTelemetry(report []byte) {
+	s.lock.Lock()
+	s.reports = append(s.reports, report)
+	s.lock.Unlock()
+}
+
+func (s *testTelemetrySink) decodeReports(t *testing.T) []telemetryReport {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reports := make([]telemetryReport, len(s.reports))
+	for i, reportBytes := range s.reports {
+		err := json.Unmarshal(reportBytes, &reports[i])
+		if err != nil {
+			t.Fatalf("Failed to decode report: %v", err)
+		}
+	}
+	return reports
+}
+
+func TestTelemetryReporterNil(t *testing.T) {
+	reporter := newTelemetryReporter(TelemetryConfig{}, nil)
+	if reporter != nil {
+		t.Fatalf("Expected no reporter without a sink")
+	}
+
+	// A nil reporter must discard everything.
+	reporter.start()
+	reporter.recordError("kv", ErrDocumentNotFound)
+	reporter.recordThreshold(thresholdLogService{Service: "kv"})
+	reporter.stop()
+}
+
+func TestTelemetryReporter(t *testing.T) {
+	sink := &testTelemetrySink{}
+	reporter := newTelemetryReporter(TelemetryConfig{Sink: sink, Interval: time.Hour}, nil)
+	reporter.start()
+
+	reporter.recordError("kv", KeyValueError{InnerError: ErrDocumentNotFound})
+	reporter.recordError("kv", ErrDocumentNotFound)
+	reporter.recordError("query", wrapError(ErrTimeout, "query timed out"))
+	reporter.recordError("query", errors.New("connection to 10.0.0.1:8093 refused"))
+	reporter.recordError("query", errors.New("connection to 10.0.0.2:8093 refused"))
+	reporter.recordError("kv", nil)
+	reporter.recordThreshold(thresholdLogService{Service: "kv", Count: 1})
+
+	// Stopping must generate a final report.
+	reporter.stop()
+
+	reports := sink.decodeReports(t)
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 report but was %d", len(reports))
+	}
+
+	report := reports[0]
+	if report.Errors["kv"][ErrDocumentNotFound.Error()] != 2 || report.Errors["query"][ErrTimeout.Error()] != 1 ||
+		report.Errors["query"]["other"] != 2 || len(report.Errors["query"]) != 2 {
+		t.Fatalf("Unexpected errors %v", report.Errors)
+	}
+
+	if len(report.ThresholdLog) != 1 || report.ThresholdLog[0].Service != "kv" {
+		t.Fatalf("Unexpected threshold log %v", report.ThresholdLog)
+	}
+}
+
+func TestTelemetryReporterSkipsEmptyReports(t *testing.T) {
+	sink := &testTelemetrySink{}
+	reporter := newTelemetryReporter(TelemetryConfig{Sink: sink, Interval: 10 * time.Millisecond}, nil)
+	reporter.start()
+	time.Sleep(50 * time.Millisecond)
+	reporter.stop()
+
+	if len(sink.decodeReports(t)) != 0 {
+		t.Fatalf("Expected no reports")
+	}
+}
+
+func TestTelemetryThresholdTracer(t *testing.T) {
+	sink := &testTelemetrySink{}
+	reporter := newTelemetryReporter(TelemetryConfig{Sink: sink}, nil)
+
+	tracer := newThresholdLoggingTracer(&ThresholdLoggingOptions{KVThreshold: time.Nanosecond})
+	tracer.reporter = reporter
+
+	span := tracer.StartSpan("Get", nil).SetTag("couchbase.service", "kv")
+	time.Sleep(time.Millisecond)
+	span.Finish()
+
+	tracer.logRecordedRecords()
+	reporter.report()
+
+	reports := sink.decodeReports(t)
+	if len(reports) != 1 || len(reports[0].ThresholdLog) != 1 ||
+		reports[0].ThresholdLog[0].Top[0].OperationName != "Get" {
+		t.Fatalf("Unexpected reports %v", reports)
+	}
+}
+
+func TestTelemetryKvErrors(t *testing.T) {
+	sink := &testTelemetrySink{}
+	col := testGetCollection(t, &mockKvProvider{err: gocbcore.ErrDocumentNotFound})
+	col.sb.Telemetry = newTelemetryReporter(TelemetryConfig{Sink: sink}, nil)
+
+	_, err := col.Get("missing", nil)
+	if err == nil {
+		t.Fatalf("Expected Get to fail")
+	}
+
+	col.sb.Telemetry.report()
+
+	reports := sink.decodeReports(t)
+	if len(reports) != 1 || reports[0].Errors["kv"][ErrDocumentNotFound.Error()] != 1 {
+		t.Fatalf("Unexpected reports %v", reports)
+	}
+}
+
+func TestTelemetryReporterPropagatesToBuckets(t *testing.T) {
+	reporter := newTelemetryReporter(TelemetryConfig{Sink: &testTelemetrySink{}}, nil)
+	bucket := newBucket(&stateBlock{Telemetry: reporter}, "default")
+
+	if bucket.DefaultCollection().sb.Telemetry != reporter {
+		t.Fatalf("Expected collections to use the cluster reporter")
+	}
+}
+
+func TestTelemetryReporterStopTwice(t *testing.T) {
+	sink := &testTelemetrySink{}
+	reporter := newTelemetryReporter(TelemetryConfig{Sink: sink, Interval: time.Hour}, nil)
+	reporter.start()
+
+	reporter.recordError("kv", ErrDocumentNotFound)
+	reporter.stop()
+	reporter.stop()
+
+	// Recording once stopped is safe, but is never reported.
+	reporter.recordError("kv", ErrDocumentNotFound)
+
+	if reports := sink.decodeReports(t); len(reports) != 1 {
+		t.Fatalf("Expected only the final report but was %v", reports)
+	}
+}
+
+func TestTelemetryLookupInErrors(t *testing.T) {
+	sink := &testTelemetrySink{}
+	col := testGetCollection(t, &mockKvProvider{
+		value: []gocbcore.SubDocResult{
+			{Err: gocbcore.SubDocumentError{
+				Index:      0,
+				InnerError: &gocbcore.KeyValueError{InnerError: gocbcore.ErrPathNotFound},
+			}},
+			{Err: gocbcore.SubDocumentError{
+				Index:      1,
+				InnerError: &gocbcore.KeyValueError{InnerError: gocbcore.ErrPathNotFound},
+			}},
+		},
+		resultErr: &gocbcore.KeyValueError{InnerError: gocbcore.ErrMemdSubDocBadMulti},
+	})
+	col.sb.Telemetry = newTelemetryReporter(TelemetryConfig{Sink: sink}, nil)
+
+	_, err := col.LookupIn("doc", []LookupInSpec{
+		GetSpec("a", nil),
+		GetSpec("b", nil),
+	}, nil)
+	if err != nil {
+		t.Fatalf("LookupIn failed: %v", err)
+	}
+
+	col.sb.Telemetry.report()
+
+	reports := sink.decodeReports(t)
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 report but was %v", reports)
+	}
+	var total uint64
+	for _, count := range reports[0].Errors["kv"] {
+		total += count
+	}
+	if total != 1 {
+		t.Fatalf("Expected the lookup to be counted as a single error but was %v", reports[0].Errors)
+	}
+}
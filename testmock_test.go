@@ -2,6 +2,7 @@ package gocb
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -20,6 +21,10 @@ type mockClient struct {
 	mockSearchProvider      searchProvider
 	mockHTTPProvider        httpProvider
 	mockDiagnosticsProvider diagnosticsProvider
+	mockConfigProvider      configSnapshotProvider
+	mockDcpProvider         dcpProvider
+	closeWait               time.Duration
+	closeErr                error
 }
 
 type mockKvProvider struct {
@@ -30,6 +35,17 @@ type mockKvProvider struct {
 	flags    uint32
 	datatype uint8
 	err      error
+
+	// resultErr is returned alongside the result of a lookup, as gocbcore does when one or
+	// more of the specs failed.
+	resultErr error
+
+	// numReplicas is the number of replicas once the config is known.  Replica reads beyond it
+	// fail as gocbcore fails them once it has routed them.
+	numReplicas int
+
+	// vbSeqNo is reported as the current sequence number of every vbucket observed.
+	vbSeqNo gocbcore.SeqNo
 }
 
 type mockHTTPProvider struct {
@@ -37,6 +53,78 @@ type mockHTTPProvider struct {
 	supportFn func(capability gocbcore.ClusterCapability) bool
 }
 
+// fakeClock is a clock which only moves when advanced by the test.
+type fakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+}
+
+type fakeClockWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, fakeClockWaiter{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Sleep advances the clock rather than blocking, so that loops which back off complete
+// immediately.
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(c.now) {
+			waiters = append(waiters, waiter)
+			continue
+		}
+		waiter.ch <- c.now
+	}
+	c.waiters = waiters
+}
+
+// waitForWaiters blocks until n timers are waiting on the clock.
+func (c *fakeClock) waitForWaiters(n int) {
+	for {
+		c.lock.Lock()
+		numWaiters := len(c.waiters)
+		c.lock.Unlock()
+
+		if numWaiters >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 type mockPendingOp struct {
 	handler   func(error)
 	completed uint32
@@ -251,7 +339,7 @@ func (mko *mockKvProvider) LookupInEx(opts gocbcore.LookupInOptions, cb gocbcore
 			cb(&gocbcore.LookupInResult{
 				Cas: mko.cas,
 				Ops: mko.value.([]gocbcore.SubDocResult),
-			}, nil)
+			}, mko.resultErr)
 		}
 	})
 }
@@ -288,7 +376,7 @@ func (mko *mockKvProvider) ObserveVbEx(opts gocbcore.ObserveVbOptions, cb gocbco
 		if err != nil {
 			cb(nil, err)
 		} else {
-			cb(&gocbcore.ObserveVbResult{}, nil)
+			cb(&gocbcore.ObserveVbResult{VbID: opts.VbID, CurrentSeqNo: mko.vbSeqNo}, nil)
 		}
 	})
 }
@@ -305,6 +393,32 @@ func (mko *mockKvProvider) GetMetaEx(opts gocbcore.GetMetaOptions, cb gocbcore.G
 	})
 }
 
+func (mko *mockKvProvider) SetMetaEx(opts gocbcore.SetMetaOptions, cb gocbcore.SetMetaExCallback) (gocbcore.PendingOp, error) {
+	return mko.waitForOp(func(err error) {
+		if err != nil {
+			cb(nil, err)
+		} else {
+			cb(&gocbcore.SetMetaResult{
+				Cas:           mko.cas,
+				MutationToken: mko.mt,
+			}, nil)
+		}
+	})
+}
+
+func (mko *mockKvProvider) DeleteMetaEx(opts gocbcore.DeleteMetaOptions, cb gocbcore.DeleteMetaExCallback) (gocbcore.PendingOp, error) {
+	return mko.waitForOp(func(err error) {
+		if err != nil {
+			cb(nil, err)
+		} else {
+			cb(&gocbcore.DeleteMetaResult{
+				Cas:           mko.cas,
+				MutationToken: mko.mt,
+			}, nil)
+		}
+	})
+}
+
 func (mko *mockKvProvider) GetAnyReplicaEx(opts gocbcore.GetAnyReplicaOptions, cb gocbcore.GetReplicaExCallback) (gocbcore.PendingOp, error) {
 	return mko.waitForOp(func(err error) {
 		if err != nil {
@@ -322,6 +436,9 @@ func (mko *mockKvProvider) GetAnyReplicaEx(opts gocbcore.GetAnyReplicaOptions, c
 
 func (mko *mockKvProvider) GetOneReplicaEx(opts gocbcore.GetOneReplicaOptions, cb gocbcore.GetReplicaExCallback) (gocbcore.PendingOp, error) {
 	return mko.waitForOp(func(err error) {
+		if err == nil && opts.ReplicaIdx > mko.numReplicas {
+			err = gocbcore.ErrInvalidReplica
+		}
 		if err != nil {
 			cb(nil, err)
 		} else {
@@ -345,7 +462,21 @@ func (mko *mockKvProvider) PingKvEx(opts gocbcore.PingKvOptions, cb gocbcore.Pin
 	})
 }
 
+func (mko *mockKvProvider) StatsEx(opts gocbcore.StatsOptions, cb gocbcore.StatsExCallback) (gocbcore.PendingOp, error) {
+	return mko.waitForOp(func(err error) {
+		if err != nil {
+			cb(nil, err)
+		} else {
+			cb(mko.value.(*gocbcore.StatsResult), nil)
+		}
+	})
+}
+
 func (mko *mockKvProvider) NumReplicas() int {
+	return mko.numReplicas
+}
+
+func (mko *mockKvProvider) KeyToVbucket(key []byte) uint16 {
 	return 0
 }
 
@@ -378,7 +509,8 @@ func (mc *mockClient) buildConfig() error {
 }
 
 func (mc *mockClient) close() error {
-	return nil
+	time.Sleep(mc.closeWait)
+	return mc.closeErr
 }
 
 func (mc *mockClient) selectBucket(bucketName string) error {
@@ -430,3 +562,11 @@ func (mc *mockClient) getHTTPProvider() (httpProvider, error) {
 func (mc *mockClient) getDiagnosticsProvider() (diagnosticsProvider, error) {
 	return mc.mockDiagnosticsProvider, nil
 }
+
+func (mc *mockClient) getConfigSnapshotProvider() (configSnapshotProvider, error) {
+	return mc.mockConfigProvider, nil
+}
+
+func (mc *mockClient) openDcpProvider(bucketName, streamName string, flags gocbcore.DcpOpenFlag) (dcpProvider, error) {
+	return mc.mockDcpProvider, nil
+}
@@ -3,6 +3,7 @@ package gocb
 import (
 	"encoding/json"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -69,17 +70,18 @@ func (g *thresholdLogGroup) recordOp(span *thresholdLogSpan) {
 }
 
 type thresholdLogItem struct {
-	OperationName          string `json:"operation_name,omitempty"`
-	TotalTimeUs            uint64 `json:"total_us,omitempty"`
-	EncodeDurationUs       uint64 `json:"encode_us,omitempty"`
-	DispatchDurationUs     uint64 `json:"dispatch_us,omitempty"`
-	ServerDurationUs       uint64 `json:"server_us,omitempty"`
-	LastRemoteAddress      string `json:"last_remote_address,omitempty"`
-	LastLocalAddress       string `json:"last_local_address,omitempty"`
-	LastDispatchDurationUs uint64 `json:"last_dispatch_us,omitempty"`
-	LastOperationID        string `json:"last_operation_id,omitempty"`
-	LastLocalID            string `json:"last_local_id,omitempty"`
-	DocumentKey            string `json:"document_key,omitempty"`
+	OperationName          string            `json:"operation_name,omitempty"`
+	TotalTimeUs            uint64            `json:"total_us,omitempty"`
+	EncodeDurationUs       uint64            `json:"encode_us,omitempty"`
+	DispatchDurationUs     uint64            `json:"dispatch_us,omitempty"`
+	ServerDurationUs       uint64            `json:"server_us,omitempty"`
+	LastRemoteAddress      string            `json:"last_remote_address,omitempty"`
+	LastLocalAddress       string            `json:"last_local_address,omitempty"`
+	LastDispatchDurationUs uint64            `json:"last_dispatch_us,omitempty"`
+	LastOperationID        string            `json:"last_operation_id,omitempty"`
+	LastLocalID            string            `json:"last_local_id,omitempty"`
+	DocumentKey            string            `json:"document_key,omitempty"`
+	Tags                   map[string]string `json:"tags,omitempty"`
 }
 
 type thresholdLogService struct {
@@ -88,7 +90,8 @@ type thresholdLogService struct {
 	Top     []thresholdLogItem `json:"top"`
 }
 
-func (g *thresholdLogGroup) logRecordedRecords(sampleSize uint32) {
+// takeRecordedRecords removes the recorded ops from the group, returning nil if there were none.
+func (g *thresholdLogGroup) takeRecordedRecords(sampleSize uint32) *thresholdLogService {
 	// Preallocate space to copy the ops into...
 	oldOps := make([]*thresholdLogSpan, sampleSize)
 
@@ -96,7 +99,7 @@ func (g *thresholdLogGroup) logRecordedRecords(sampleSize uint32) {
 	// Escape early if we have no ops to log...
 	if len(g.ops) == 0 {
 		g.lock.Unlock()
-		return
+		return nil
 	}
 
 	// Copy out our ops so we can cheaply print them out without blocking
@@ -127,11 +130,26 @@ func (g *thresholdLogGroup) logRecordedRecords(sampleSize uint32) {
 			LastOperationID:        op.lastOperationID,
 			LastLocalID:            op.lastLocalID,
 			DocumentKey:            op.documentKey,
+			Tags:                   op.tags,
 		})
 	}
 
 	jsonData.Count = uint64(len(jsonData.Top))
 
+	return &jsonData
+}
+
+func (g *thresholdLogGroup) logRecordedRecords(sampleSize uint32, reporter *telemetryReporter) {
+	jsonData := g.takeRecordedRecords(sampleSize)
+	if jsonData == nil {
+		return
+	}
+
+	if reporter != nil {
+		reporter.recordThreshold(*jsonData)
+		return
+	}
+
 	jsonBytes, err := json.Marshal(jsonData)
 	if err != nil {
 		logDebugf("Failed to generate threshold logging service JSON: %s", err)
@@ -168,6 +186,8 @@ type thresholdLoggingTracer struct {
 	ManagementThreshold time.Duration
 
 	killCh          chan struct{}
+	workers         workerGroup
+	clock           clock
 	refCount        int32
 	nextTick        time.Time
 	kvGroup         thresholdLogGroup
@@ -177,6 +197,9 @@ type thresholdLoggingTracer struct {
 	analyticsGroup  thresholdLogGroup
 	managementGroup thresholdLogGroup
 	mgmtGroup       thresholdLogGroup
+
+	// reporter, if set, receives the recorded ops in place of them being logged.
+	reporter *telemetryReporter
 }
 
 func newThresholdLoggingTracer(opts *ThresholdLoggingOptions) *thresholdLoggingTracer {
@@ -217,6 +240,7 @@ func newThresholdLoggingTracer(opts *ThresholdLoggingOptions) *thresholdLoggingT
 		SearchThreshold:     opts.SearchThreshold,
 		AnalyticsThreshold:  opts.AnalyticsThreshold,
 		ManagementThreshold: opts.ManagementThreshold,
+		clock:               systemClock{},
 	}
 
 	t.kvGroup.init("kv", t.KVThreshold, t.SampleSize)
@@ -231,7 +255,7 @@ func newThresholdLoggingTracer(opts *ThresholdLoggingOptions) *thresholdLoggingT
 	}
 
 	if t.nextTick.IsZero() {
-		t.nextTick = time.Now().Add(t.Interval)
+		t.nextTick = t.clock.Now().Add(t.Interval)
 	}
 
 	return t
@@ -248,32 +272,34 @@ func (t *thresholdLoggingTracer) AddRef() int32 {
 	return newRefCount
 }
 
-// DecRef is the counterpart to AddRef (see AddRef for more information).
+// DecRef is the counterpart to AddRef (see AddRef for more information).  Releasing the last
+// reference waits for the aggregation routine to log the remaining records and exit.
 func (t *thresholdLoggingTracer) DecRef() int32 {
 	newRefCount := atomic.AddInt32(&t.refCount, -1)
 	if newRefCount == 0 {
 		t.killCh <- struct{}{}
+		t.workers.wait()
 	}
 	return newRefCount
 }
 
 func (t *thresholdLoggingTracer) logRecordedRecords() {
-	t.kvGroup.logRecordedRecords(t.SampleSize)
-	t.viewsGroup.logRecordedRecords(t.SampleSize)
-	t.queryGroup.logRecordedRecords(t.SampleSize)
-	t.searchGroup.logRecordedRecords(t.SampleSize)
-	t.analyticsGroup.logRecordedRecords(t.SampleSize)
-	t.managementGroup.logRecordedRecords(t.SampleSize)
+	t.kvGroup.logRecordedRecords(t.SampleSize, t.reporter)
+	t.viewsGroup.logRecordedRecords(t.SampleSize, t.reporter)
+	t.queryGroup.logRecordedRecords(t.SampleSize, t.reporter)
+	t.searchGroup.logRecordedRecords(t.SampleSize, t.reporter)
+	t.analyticsGroup.logRecordedRecords(t.SampleSize, t.reporter)
+	t.managementGroup.logRecordedRecords(t.SampleSize, t.reporter)
 }
 
 func (t *thresholdLoggingTracer) startLoggerRoutine() {
-	go t.loggerRoutine()
+	t.workers.start(t.loggerRoutine)
 }
 
 func (t *thresholdLoggingTracer) loggerRoutine() {
 	for {
 		select {
-		case <-time.After(t.nextTick.Sub(time.Now())):
+		case <-t.clock.After(t.nextTick.Sub(t.clock.Now())):
 			t.nextTick = t.nextTick.Add(t.Interval)
 			t.logRecordedRecords()
 		case <-t.killCh:
@@ -301,11 +327,11 @@ func (t *thresholdLoggingTracer) recordOp(span *thresholdLogSpan) {
 }
 
 // StartSpan belongs to the Tracer interface.
-func (t *thresholdLoggingTracer) StartSpan(operationName string, parentContext requestSpanContext) requestSpan {
+func (t *thresholdLoggingTracer) StartSpan(operationName string, parentContext RequestSpanContext) RequestSpan {
 	span := &thresholdLogSpan{
 		tracer:    t,
 		opName:    operationName,
-		startTime: time.Now(),
+		startTime: t.clock.Now(),
 	}
 
 	if context, ok := parentContext.(*thresholdLogSpanContext); ok {
@@ -332,14 +358,15 @@ type thresholdLogSpan struct {
 	lastOperationID       string
 	lastLocalID           string
 	documentKey           string
+	tags                  map[string]string
 	lock                  sync.Mutex
 }
 
-func (n *thresholdLogSpan) Context() requestSpanContext {
+func (n *thresholdLogSpan) Context() RequestSpanContext {
 	return &thresholdLogSpanContext{n}
 }
 
-func (n *thresholdLogSpan) SetTag(key string, value interface{}) requestSpan {
+func (n *thresholdLogSpan) SetTag(key string, value interface{}) RequestSpan {
 	var ok bool
 
 	switch key {
@@ -367,12 +394,25 @@ func (n *thresholdLogSpan) SetTag(key string, value interface{}) requestSpan {
 		if n.lastLocalID, ok = value.(string); !ok {
 			logDebugf("Failed to cast span couchbase.local_id tag")
 		}
+	default:
+		if strings.HasPrefix(key, operationTagPrefix) {
+			tagValue, ok := value.(string)
+			if !ok {
+				logDebugf("Failed to cast span %s tag", key)
+				break
+			}
+
+			if n.tags == nil {
+				n.tags = make(map[string]string)
+			}
+			n.tags[strings.TrimPrefix(key, operationTagPrefix)] = tagValue
+		}
 	}
 	return n
 }
 
 func (n *thresholdLogSpan) Finish() {
-	n.duration = time.Now().Sub(n.startTime)
+	n.duration = n.tracer.clock.Now().Sub(n.startTime)
 
 	n.totalServerDuration += n.serverDuration
 	if n.opName == "dispatch" {
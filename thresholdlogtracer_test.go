@@ -36,3 +36,24 @@ func TestThresholdGroup(t *testing.T) {
 		t.Fatalf("Failed to insert in correct order (3)")
 	}
 }
+
+func TestThresholdSpanOperationTags(t *testing.T) {
+	tracer := newThresholdLoggingTracer(nil)
+
+	span := tracer.StartSpan("Get", nil).
+		SetTag("couchbase.service", "kv")
+	span = applyOperationTags(span, map[string]string{"tenant": "acme"})
+
+	logSpan, ok := span.(*thresholdLogSpan)
+	if !ok {
+		t.Fatalf("Expected span to be a threshold log span")
+	}
+
+	if len(logSpan.tags) != 1 || logSpan.tags["tenant"] != "acme" {
+		t.Fatalf("Expected operation tags to be recorded but were %v", logSpan.tags)
+	}
+
+	if logSpan.serviceName != "kv" {
+		t.Fatalf("Operation tags should not affect the service name but was %s", logSpan.serviceName)
+	}
+}
@@ -0,0 +1,99 @@
+package gocb
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Timeouts allows the timeouts used by a Cluster or Bucket to be changed whilst it is in use,
+// without reconnecting.  Timeouts set on a Bucket apply to the operations performed through it
+// and take precedence over those set on the Cluster, which in turn take precedence over
+// ClusterOptions.TimeoutsConfig.  Changing a timeout does not affect operations which are
+// already in progress.  Timeouts is safe for concurrent use.
+// UNCOMMITTED: This API may change in the future.
+type Timeouts struct {
+	parent *Timeouts
+
+	kv         int64
+	query      int64
+	analytics  int64
+	search     int64
+	view       int64
+	management int64
+}
+
+func newTimeouts(parent *Timeouts) *Timeouts {
+	return &Timeouts{
+		parent: parent,
+	}
+}
+
+// SetKVTimeout sets the timeout used for key-value operations.  A timeout of 0 removes the
+// override, restoring the timeout which applied before it was set.
+func (t *Timeouts) SetKVTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&t.kv, int64(timeout))
+}
+
+// SetQueryTimeout sets the timeout used for queries.  A timeout of 0 removes the override.
+func (t *Timeouts) SetQueryTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&t.query, int64(timeout))
+}
+
+// SetAnalyticsTimeout sets the timeout used for analytics queries.  A timeout of 0 removes the
+// override.
+func (t *Timeouts) SetAnalyticsTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&t.analytics, int64(timeout))
+}
+
+// SetSearchTimeout sets the timeout used for search queries.  A timeout of 0 removes the
+// override.
+func (t *Timeouts) SetSearchTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&t.search, int64(timeout))
+}
+
+// SetViewTimeout sets the timeout used for view queries.  A timeout of 0 removes the override.
+func (t *Timeouts) SetViewTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&t.view, int64(timeout))
+}
+
+// SetManagementTimeout sets the timeout used for management operations.  Managers which have
+// already been created continue to use the timeout which applied when they were created.  A
+// timeout of 0 removes the override.
+func (t *Timeouts) SetManagementTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&t.management, int64(timeout))
+}
+
+// lookup returns the first timeout which is set, starting from t and moving through its
+// parents, or fallback if none are set.  A nil Timeouts has no timeouts set.
+func (t *Timeouts) lookup(field func(*Timeouts) *int64, fallback time.Duration) time.Duration {
+	for ; t != nil; t = t.parent {
+		if timeout := atomic.LoadInt64(field(t)); timeout > 0 {
+			return time.Duration(timeout)
+		}
+	}
+	return fallback
+}
+
+func (sb *stateBlock) kvTimeout() time.Duration {
+	return sb.Timeouts.lookup(func(t *Timeouts) *int64 { return &t.kv }, sb.KvTimeout)
+}
+
+func (sb *stateBlock) queryTimeout() time.Duration {
+	return sb.Timeouts.lookup(func(t *Timeouts) *int64 { return &t.query }, sb.QueryTimeout)
+}
+
+func (sb *stateBlock) analyticsTimeout() time.Duration {
+	return sb.Timeouts.lookup(func(t *Timeouts) *int64 { return &t.analytics }, sb.AnalyticsTimeout)
+}
+
+func (sb *stateBlock) searchTimeout() time.Duration {
+	return sb.Timeouts.lookup(func(t *Timeouts) *int64 { return &t.search }, sb.SearchTimeout)
+}
+
+func (sb *stateBlock) viewTimeout() time.Duration {
+	return sb.Timeouts.lookup(func(t *Timeouts) *int64 { return &t.view }, sb.ViewTimeout)
+}
+
+func (sb *stateBlock) managementTimeout() time.Duration {
+	return sb.Timeouts.lookup(func(t *Timeouts) *int64 { return &t.management }, sb.ManagementTimeout)
+}
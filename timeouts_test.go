@@ -0,0 +1,42 @@
+package gocb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeoutsOverride(t *testing.T) {
+	clusterSb := &stateBlock{
+		QueryTimeout: 75 * time.Second,
+		KvTimeout:    2500 * time.Millisecond,
+		Timeouts:     newTimeouts(nil),
+	}
+	bucket := newBucket(clusterSb, "default")
+	collection := bucket.DefaultCollection()
+
+	if timeout := collection.sb.queryTimeout(); timeout != 75*time.Second {
+		t.Fatalf("Expected configured query timeout but was %s", timeout)
+	}
+
+	clusterSb.Timeouts.SetQueryTimeout(10 * time.Second)
+	if timeout := collection.sb.queryTimeout(); timeout != 10*time.Second {
+		t.Fatalf("Expected cluster query timeout but was %s", timeout)
+	}
+
+	bucket.Timeouts().SetQueryTimeout(5 * time.Second)
+	if timeout := collection.sb.queryTimeout(); timeout != 5*time.Second {
+		t.Fatalf("Expected bucket query timeout but was %s", timeout)
+	}
+	if timeout := clusterSb.queryTimeout(); timeout != 10*time.Second {
+		t.Fatalf("Expected bucket timeout to not affect the cluster but was %s", timeout)
+	}
+
+	bucket.Timeouts().SetQueryTimeout(0)
+	if timeout := collection.sb.queryTimeout(); timeout != 10*time.Second {
+		t.Fatalf("Expected cluster query timeout after clearing but was %s", timeout)
+	}
+
+	if timeout := collection.sb.kvTimeout(); timeout != 2500*time.Millisecond {
+		t.Fatalf("Expected configured kv timeout but was %s", timeout)
+	}
+}
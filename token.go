@@ -14,6 +14,21 @@ type MutationToken struct {
 	bucketName string
 }
 
+// NewMutationToken creates a MutationToken from its parts, such as those of a token which was
+// previously stored by the application.  This allows tokens to be added to a MutationState
+// for use with services other than the one which returned them.
+// UNCOMMITTED: This API may change in the future.
+func NewMutationToken(bucketName string, partitionID uint16, partitionUUID, sequenceNumber uint64) MutationToken {
+	return MutationToken{
+		token: gocbcore.MutationToken{
+			VbID:   partitionID,
+			VbUUID: gocbcore.VbUUID(partitionUUID),
+			SeqNo:  gocbcore.SeqNo(sequenceNumber),
+		},
+		bucketName: bucketName,
+	}
+}
+
 type bucketToken struct {
 	SeqNo  uint64 `json:"seqno"`
 	VbUUID string `json:"vbuuid"`
@@ -75,6 +90,14 @@ func (mt *MutationState) Add(tokens ...MutationToken) {
 	}
 }
 
+// Tokens returns a copy of the tokens which make up this mutation state.
+// UNCOMMITTED: This API may change in the future.
+func (mt *MutationState) Tokens() []MutationToken {
+	tokens := make([]MutationToken, len(mt.tokens))
+	copy(tokens, mt.tokens)
+	return tokens
+}
+
 // MarshalJSON marshal's this mutation state to JSON.
 func (mt *MutationState) MarshalJSON() ([]byte, error) {
 	var data mutationStateData
@@ -118,7 +141,7 @@ func (mt *MutationState) UnmarshalJSON(data []byte) error {
 			if err != nil {
 				return err
 			}
-			vbUUID, err := strconv.Atoi(stateToken.VbUUID)
+			vbUUID, err := strconv.ParseUint(stateToken.VbUUID, 10, 64)
 			if err != nil {
 				return err
 			}
@@ -101,3 +101,35 @@ func TestMutationState_toSeachMutationState(t *testing.T) {
 		t.Fatalf("Failed to generate correct JSON output %s", bytes)
 	}
 }
+
+func TestNewMutationToken(t *testing.T) {
+	token := NewMutationToken("frank", 7, 18446744073709551615, 42)
+
+	if token.BucketName() != "frank" || token.PartitionID() != 7 ||
+		token.PartitionUUID() != 18446744073709551615 || token.SequenceNumber() != 42 {
+		t.Fatalf("Unexpected token %v", token)
+	}
+
+	state := NewMutationState(token)
+	bytes, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Failed to marshal %v", err)
+	}
+
+	var afterState MutationState
+	err = json.Unmarshal(bytes, &afterState)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal %v", err)
+	}
+
+	tokens := afterState.Tokens()
+	if len(tokens) != 1 || tokens[0] != token {
+		t.Fatalf("Unexpected tokens %v", tokens)
+	}
+
+	// Changing the returned tokens must not change the state.
+	tokens[0] = MutationToken{}
+	if afterState.Tokens()[0] != token {
+		t.Fatalf("Expected the state to be unchanged but was %v", afterState.Tokens())
+	}
+}
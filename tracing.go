@@ -1,10 +1,13 @@
 package gocb
 
 import (
+	"sync/atomic"
+	"time"
+
 	"github.com/couchbase/gocbcore/v8"
 )
 
-func tracerAddRef(tracer requestTracer) {
+func tracerAddRef(tracer RequestTracer) {
 	if tracer == nil {
 		return
 	}
@@ -15,7 +18,7 @@ func tracerAddRef(tracer requestTracer) {
 	}
 }
 
-func tracerDecRef(tracer requestTracer) {
+func tracerDecRef(tracer RequestTracer) {
 	if tracer == nil {
 		return
 	}
@@ -26,34 +29,54 @@ func tracerDecRef(tracer requestTracer) {
 	}
 }
 
-// requestTracer describes the tracing abstraction in the SDK.
-type requestTracer interface {
-	StartSpan(operationName string, parentContext requestSpanContext) requestSpan
+// RequestTracer describes the tracing abstraction in the SDK.  It can be implemented to bridge
+// the spans recorded by the SDK into another tracing system, and is set using
+// ClusterOptions.Tracer.  StartSpan is called with a nil parent context for operations which
+// were not given a parent, and otherwise with either the ParentSpan from the options of the
+// operation or the Context of a span previously returned by the tracer.  RequestTracers which
+// record spans with OpenTelemetry and OpenTracing are provided by the
+// github.com/couchbase/gocb/v2/tracing/otel and github.com/couchbase/gocb/v2/tracing/opentracing
+// modules.
+// VOLATILE: This API is subject to change at any time.
+type RequestTracer interface {
+	StartSpan(operationName string, parentContext RequestSpanContext) RequestSpan
 }
 
-// requestSpan is the interface for spans that are created by a requestTracer.
-type requestSpan interface {
+// RequestSpan is the interface for spans that are created by a RequestTracer.
+// VOLATILE: This API is subject to change at any time.
+type RequestSpan interface {
 	Finish()
-	Context() requestSpanContext
-	SetTag(key string, value interface{}) requestSpan
+	Context() RequestSpanContext
+	SetTag(key string, value interface{}) RequestSpan
 }
 
-// requestSpanContext is the interface for for external span contexts that can be passed in into the SDK option blocks.
-type requestSpanContext interface {
+// RequestSpanContext is the interface for for external span contexts that can be passed in into the SDK option blocks.
+// The ParentSpan of an operation is passed to the RequestTracer unchanged, so it should be a span
+// context understood by that tracer.
+// VOLATILE: This API is subject to change at any time.
+type RequestSpanContext interface {
 }
 
 type requestTracerWrapper struct {
-	tracer requestTracer
+	tracer RequestTracer
 }
 
 func (tracer *requestTracerWrapper) StartSpan(operationName string, parentContext gocbcore.RequestSpanContext) gocbcore.RequestSpan {
+	if ctx, ok := parentContext.(*serverDurationContext); ok {
+		return requestSpanWrapper{
+			span:     tracer.tracer.StartSpan(operationName, ctx.parent),
+			recorder: ctx.recorder,
+		}
+	}
+
 	return requestSpanWrapper{
 		span: tracer.tracer.StartSpan(operationName, parentContext),
 	}
 }
 
 type requestSpanWrapper struct {
-	span requestSpan
+	span     RequestSpan
+	recorder *serverDurationRecorder
 }
 
 func (span requestSpanWrapper) Finish() {
@@ -61,14 +84,50 @@ func (span requestSpanWrapper) Finish() {
 }
 
 func (span requestSpanWrapper) Context() gocbcore.RequestSpanContext {
+	if span.recorder != nil {
+		return &serverDurationContext{
+			parent:   span.span.Context(),
+			recorder: span.recorder,
+		}
+	}
+
 	return span.span.Context()
 }
 
 func (span requestSpanWrapper) SetTag(key string, value interface{}) gocbcore.RequestSpan {
+	if key == "server_duration" && span.recorder != nil {
+		if duration, ok := value.(time.Duration); ok {
+			span.recorder.record(duration)
+		}
+	}
+
 	span.span = span.span.SetTag(key, value)
 	return span
 }
 
+// serverDurationRecorder records the server duration which gocbcore reports, as a tag, on the
+// spans which it starts for an operation.  If the operation is retried then the duration of the
+// last attempt is kept.
+type serverDurationRecorder struct {
+	duration int64
+}
+
+func (r *serverDurationRecorder) record(duration time.Duration) {
+	atomic.StoreInt64(&r.duration, int64(duration))
+}
+
+func (r *serverDurationRecorder) Duration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&r.duration))
+}
+
+// serverDurationContext is passed to gocbcore as the parent of the spans which it starts, so
+// that the server duration reported on them can be recorded.  The tracer only ever sees the
+// wrapped parent context.
+type serverDurationContext struct {
+	parent   RequestSpanContext
+	recorder *serverDurationRecorder
+}
+
 type noopSpan struct{}
 type noopSpanContext struct{}
 
@@ -80,17 +139,29 @@ var (
 type noopTracer struct {
 }
 
-func (tracer *noopTracer) StartSpan(operationName string, parentContext requestSpanContext) requestSpan {
+func (tracer *noopTracer) StartSpan(operationName string, parentContext RequestSpanContext) RequestSpan {
 	return defaultNoopSpan
 }
 
 func (span noopSpan) Finish() {
 }
 
-func (span noopSpan) Context() requestSpanContext {
+func (span noopSpan) Context() RequestSpanContext {
 	return defaultNoopSpanContext
 }
 
-func (span noopSpan) SetTag(key string, value interface{}) requestSpan {
+func (span noopSpan) SetTag(key string, value interface{}) RequestSpan {
 	return defaultNoopSpan
 }
+
+// operationTagPrefix namespaces user-defined operation tags within the tags of a span, so
+// that they cannot collide with the tags which are set by the SDK itself.
+const operationTagPrefix = "couchbase.tag."
+
+func applyOperationTags(span RequestSpan, tags map[string]string) RequestSpan {
+	for key, value := range tags {
+		span = span.SetTag(operationTagPrefix+key, value)
+	}
+
+	return span
+}
@@ -0,0 +1,114 @@
+// Package opentracing provides a gocb RequestTracer which records the spans of the SDK with
+// OpenTracing, for applications whose tracing pipeline, such as Jaeger or Zipkin, is still
+// built on opentracing-go.  It is a separate module so that applications which do not use
+// OpenTracing do not depend upon it.
+//
+//	tracer := opentracing.NewOpenTracingRequestTracer(ot.GlobalTracer())
+//	cluster, err := gocb.Connect("couchbase://localhost", gocb.ClusterOptions{
+//		Tracer: tracer,
+//	})
+//
+// The spans of an operation are nested under an application span by passing that span, its
+// SpanContext, or a context.Context holding it as the ParentSpan of the operation:
+//
+//	span := ot.StartSpan("handle-request")
+//	defer span.Finish()
+//	res, err := collection.Get("doc", &gocb.GetOptions{ParentSpan: span.Context()})
+//
+// This module builds against the gocb source tree that it lives in.  It cannot yet be used as a
+// dependency of another module, since no tagged release of gocb exports RequestTracer.
+//
+// VOLATILE: This API is subject to change at any time.
+package opentracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	ot "github.com/opentracing/opentracing-go"
+)
+
+// OpenTracingRequestTracer is a gocb RequestTracer which records spans with an OpenTracing
+// tracer.
+type OpenTracingRequestTracer struct {
+	wrapped ot.Tracer
+}
+
+// NewOpenTracingRequestTracer returns a new OpenTracingRequestTracer which records spans with
+// the tracer.
+func NewOpenTracingRequestTracer(tracer ot.Tracer) *OpenTracingRequestTracer {
+	return &OpenTracingRequestTracer{
+		wrapped: tracer,
+	}
+}
+
+// StartSpan starts a new span.  The parent context is either the Context of a span started by
+// this tracer, or an OpenTracing span, SpanContext or a context.Context holding a span, as given
+// as the ParentSpan of an operation.  Any other parent context, including nil, starts a span
+// with no parent.
+func (tracer *OpenTracingRequestTracer) StartSpan(operationName string,
+	parentContext gocb.RequestSpanContext) gocb.RequestSpan {
+	var parent ot.SpanContext
+	switch p := parentContext.(type) {
+	case *OpenTracingRequestSpanContext:
+		parent = p.ctx
+	case ot.SpanContext:
+		parent = p
+	case ot.Span:
+		parent = p.Context()
+	case context.Context:
+		if span := ot.SpanFromContext(p); span != nil {
+			parent = span.Context()
+		}
+	}
+
+	var opts []ot.StartSpanOption
+	if parent != nil {
+		opts = append(opts, ot.ChildOf(parent))
+	}
+
+	return &OpenTracingRequestSpan{
+		span: tracer.wrapped.StartSpan(operationName, opts...),
+	}
+}
+
+// OpenTracingRequestSpan is a gocb RequestSpan which wraps an OpenTracing span.
+type OpenTracingRequestSpan struct {
+	span ot.Span
+}
+
+// Finish finishes the span.
+func (span *OpenTracingRequestSpan) Finish() {
+	span.span.Finish()
+}
+
+// Context returns the context of the span, which is used as the parent of the spans started
+// for the requests made by an operation.
+func (span *OpenTracingRequestSpan) Context() gocb.RequestSpanContext {
+	return &OpenTracingRequestSpanContext{
+		ctx: span.span.Context(),
+	}
+}
+
+// SetTag sets a tag on the span.  Durations are recorded in microseconds, as the threshold
+// logging tracer reports them.
+func (span *OpenTracingRequestSpan) SetTag(key string, value interface{}) gocb.RequestSpan {
+	if d, ok := value.(time.Duration); ok {
+		value = int64(d / time.Microsecond)
+	}
+
+	span.span.SetTag(key, value)
+	return span
+}
+
+// OpenTracingRequestSpanContext is the context of an OpenTracingRequestSpan.
+type OpenTracingRequestSpanContext struct {
+	ctx ot.SpanContext
+}
+
+// SpanContext returns the OpenTracing SpanContext of the span, which can be used to start
+// OpenTracing spans as its children.
+func (sc *OpenTracingRequestSpanContext) SpanContext() ot.SpanContext {
+	return sc.ctx
+}
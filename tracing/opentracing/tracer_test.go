@@ -0,0 +1,91 @@
+package opentracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ot "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestOpenTracingRequestTracerParents(t *testing.T) {
+	mock := mocktracer.New()
+	tracer := NewOpenTracingRequestTracer(mock)
+
+	appSpan := mock.StartSpan("handle-request")
+
+	opSpan := tracer.StartSpan("Get", appSpan.Context())
+	reqSpan := tracer.StartSpan("get", opSpan.Context())
+	reqSpan.Finish()
+	opSpan.Finish()
+	appSpan.Finish()
+
+	spans := mock.FinishedSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans but was %d", len(spans))
+	}
+
+	reqRecord, opRecord, appRecord := spans[0], spans[1], spans[2]
+	if opRecord.OperationName != "Get" || opRecord.ParentID != appRecord.SpanContext.SpanID {
+		t.Fatalf("Expected operation span to be a child of the application span")
+	}
+	if reqRecord.OperationName != "get" || reqRecord.ParentID != opRecord.SpanContext.SpanID {
+		t.Fatalf("Expected request span to be a child of the operation span")
+	}
+	if opRecord.SpanContext.TraceID != appRecord.SpanContext.TraceID {
+		t.Fatalf("Expected operation span to be part of the application trace")
+	}
+}
+
+func TestOpenTracingRequestTracerContextParent(t *testing.T) {
+	mock := mocktracer.New()
+	tracer := NewOpenTracingRequestTracer(mock)
+
+	appSpan := mock.StartSpan("handle-request")
+	ctx := ot.ContextWithSpan(context.Background(), appSpan)
+
+	tracer.StartSpan("Get", ctx).Finish()
+	tracer.StartSpan("Get", appSpan).Finish()
+	appSpan.Finish()
+
+	spans := mock.FinishedSpans()
+	appID := spans[2].SpanContext.SpanID
+	if spans[0].ParentID != appID || spans[1].ParentID != appID {
+		t.Fatalf("Expected operation spans to be children of the application span")
+	}
+}
+
+func TestOpenTracingRequestTracerNoParent(t *testing.T) {
+	mock := mocktracer.New()
+	tracer := NewOpenTracingRequestTracer(mock)
+
+	tracer.StartSpan("Query", nil).Finish()
+
+	spans := mock.FinishedSpans()
+	if len(spans) != 1 || spans[0].ParentID != 0 {
+		t.Fatalf("Expected a single root span but was %v", spans)
+	}
+}
+
+func TestOpenTracingRequestTracerTags(t *testing.T) {
+	mock := mocktracer.New()
+	tracer := NewOpenTracingRequestTracer(mock)
+
+	span := tracer.StartSpan("Get", nil)
+	span.SetTag("couchbase.service", "kv")
+	span.SetTag("server_duration", 1500*time.Microsecond)
+	span.SetTag("retries", uint32(2))
+	span.Finish()
+
+	tags := mock.FinishedSpans()[0].Tags()
+	if tags["couchbase.service"] != "kv" {
+		t.Errorf("Expected service tag to be kv but was %v", tags["couchbase.service"])
+	}
+	if tags["server_duration"] != int64(1500) {
+		t.Errorf("Expected server duration of 1500us but was %v", tags["server_duration"])
+	}
+	if tags["retries"] != uint32(2) {
+		t.Errorf("Expected retries tag to be 2 but was %v", tags["retries"])
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/couchbase/gocb/v2/tracing/otel
+
+// No tagged release of gocb exports RequestTracer yet, so the requirement below only
+// builds through the replace directive, which is ignored when this module is used as a
+// dependency.  Bump it to the first release which exports the tracing API once it is tagged.
+require (
+	github.com/couchbase/gocb/v2 v2.0.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+)
+
+replace github.com/couchbase/gocb/v2 => ../../
+
+go 1.15
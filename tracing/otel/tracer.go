@@ -0,0 +1,125 @@
+// Package otel provides a gocb RequestTracer which records the spans of the SDK with
+// OpenTelemetry.  It is a separate module so that applications which do not use OpenTelemetry
+// do not depend upon it.
+//
+//	tracer := otel.NewOpenTelemetryRequestTracer(otelglobal.GetTracerProvider())
+//	cluster, err := gocb.Connect("couchbase://localhost", gocb.ClusterOptions{
+//		Tracer: tracer,
+//	})
+//
+// The spans of an operation are nested under an application span by passing the context
+// holding that span as the ParentSpan of the operation:
+//
+//	ctx, span := appTracer.Start(ctx, "handle-request")
+//	defer span.End()
+//	res, err := collection.Get("doc", &gocb.GetOptions{ParentSpan: ctx})
+//
+// This module builds against the gocb source tree that it lives in.  It cannot yet be used as a
+// dependency of another module, since no tagged release of gocb exports RequestTracer.
+//
+// VOLATILE: This API is subject to change at any time.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "com.couchbase.client/go"
+
+// OpenTelemetryRequestTracer is a gocb RequestTracer which records spans with an
+// OpenTelemetry tracer.
+type OpenTelemetryRequestTracer struct {
+	wrapped trace.Tracer
+}
+
+// NewOpenTelemetryRequestTracer returns a new OpenTelemetryRequestTracer which records spans
+// with a tracer from the provider.
+func NewOpenTelemetryRequestTracer(provider trace.TracerProvider) *OpenTelemetryRequestTracer {
+	return &OpenTelemetryRequestTracer{
+		wrapped: provider.Tracer(instrumentationName),
+	}
+}
+
+// StartSpan starts a new span.  The parent context is either the Context of a span started by
+// this tracer, or a context.Context holding an OpenTelemetry span, as given as the ParentSpan
+// of an operation.  Any other parent context, including nil, starts a span with no parent.
+func (tracer *OpenTelemetryRequestTracer) StartSpan(operationName string,
+	parentContext gocb.RequestSpanContext) gocb.RequestSpan {
+	ctx := context.Background()
+	switch parent := parentContext.(type) {
+	case *OpenTelemetryRequestSpanContext:
+		ctx = parent.ctx
+	case context.Context:
+		ctx = parent
+	}
+
+	ctx, span := tracer.wrapped.Start(ctx, operationName)
+	return &OpenTelemetryRequestSpan{
+		ctx:  ctx,
+		span: span,
+	}
+}
+
+// OpenTelemetryRequestSpan is a gocb RequestSpan which wraps an OpenTelemetry span.
+type OpenTelemetryRequestSpan struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+// Finish ends the span.
+func (span *OpenTelemetryRequestSpan) Finish() {
+	span.span.End()
+}
+
+// Context returns the context of the span, which is used as the parent of the spans started
+// for the requests made by an operation.
+func (span *OpenTelemetryRequestSpan) Context() gocb.RequestSpanContext {
+	return &OpenTelemetryRequestSpanContext{
+		ctx: span.ctx,
+	}
+}
+
+// SetTag sets an attribute on the span.
+func (span *OpenTelemetryRequestSpan) SetTag(key string, value interface{}) gocb.RequestSpan {
+	span.span.SetAttributes(tagAttribute(key, value))
+	return span
+}
+
+// OpenTelemetryRequestSpanContext is the context of an OpenTelemetryRequestSpan.
+type OpenTelemetryRequestSpanContext struct {
+	ctx context.Context
+}
+
+// Context returns a context.Context holding the span, which can be used to start
+// OpenTelemetry spans as its children.
+func (sc *OpenTelemetryRequestSpanContext) Context() context.Context {
+	return sc.ctx
+}
+
+func tagAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case time.Duration:
+		// Durations are recorded in microseconds, as the threshold logging tracer reports them.
+		return attribute.Int64(key, int64(v/time.Microsecond))
+	default:
+		return attribute.String(key, fmt.Sprintf("%v", v))
+	}
+}
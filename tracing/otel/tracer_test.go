@@ -0,0 +1,82 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func testGetTracer() (*OpenTelemetryRequestTracer, *tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	return NewOpenTelemetryRequestTracer(provider), recorder, provider
+}
+
+func TestOpenTelemetryRequestTracerParents(t *testing.T) {
+	tracer, recorder, provider := testGetTracer()
+
+	appCtx, appSpan := provider.Tracer("app").Start(context.Background(), "handle-request")
+
+	opSpan := tracer.StartSpan("Get", appCtx)
+	reqSpan := tracer.StartSpan("get", opSpan.Context())
+	reqSpan.Finish()
+	opSpan.Finish()
+	appSpan.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans but was %d", len(spans))
+	}
+
+	reqRecord, opRecord := spans[0], spans[1]
+	if opRecord.Name() != "Get" || opRecord.Parent().SpanID() != appSpan.SpanContext().SpanID() {
+		t.Fatalf("Expected operation span to be a child of the application span")
+	}
+	if reqRecord.Name() != "get" || reqRecord.Parent().SpanID() != opRecord.SpanContext().SpanID() {
+		t.Fatalf("Expected request span to be a child of the operation span")
+	}
+	if opRecord.SpanContext().TraceID() != appSpan.SpanContext().TraceID() {
+		t.Fatalf("Expected operation span to be part of the application trace")
+	}
+}
+
+func TestOpenTelemetryRequestTracerNoParent(t *testing.T) {
+	tracer, recorder, _ := testGetTracer()
+
+	tracer.StartSpan("Query", nil).Finish()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Parent().IsValid() {
+		t.Fatalf("Expected a single root span but was %v", spans)
+	}
+}
+
+func TestOpenTelemetryRequestTracerTags(t *testing.T) {
+	tracer, recorder, _ := testGetTracer()
+
+	span := tracer.StartSpan("Get", nil)
+	span.SetTag("couchbase.service", "kv")
+	span.SetTag("server_duration", 1500*time.Microsecond)
+	span.SetTag("retries", uint32(2))
+	span.Finish()
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range recorder.Ended()[0].Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+
+	if attrs["couchbase.service"].AsString() != "kv" {
+		t.Errorf("Expected service tag to be kv but was %v", attrs["couchbase.service"])
+	}
+	if attrs["server_duration"].AsInt64() != 1500 {
+		t.Errorf("Expected server duration of 1500us but was %v", attrs["server_duration"])
+	}
+	if attrs["retries"].AsInt64() != 2 {
+		t.Errorf("Expected retries tag to be 2 but was %v", attrs["retries"])
+	}
+}
@@ -0,0 +1,170 @@
+package gocb
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
+)
+
+func TestServerDurationRecorded(t *testing.T) {
+	tracer := &requestTracerWrapper{tracer: &noopTracer{}}
+	recorder := &serverDurationRecorder{}
+
+	cmdSpan := tracer.StartSpan("Get", &serverDurationContext{recorder: recorder})
+	netSpan := tracer.StartSpan("rpc", cmdSpan.Context())
+	netSpan.SetTag("server_duration", 150*time.Microsecond)
+	netSpan.Finish()
+	cmdSpan.Finish()
+
+	if recorder.Duration() != 150*time.Microsecond {
+		t.Fatalf("Expected server duration of 150us but was %s", recorder.Duration())
+	}
+
+	// Spans started without a recorder must not be affected.
+	span := tracer.StartSpan("rpc", nil)
+	span.SetTag("server_duration", time.Second)
+	if _, ok := span.Context().(*serverDurationContext); ok {
+		t.Fatalf("Expected span without a recorder to return the tracer context")
+	}
+	if recorder.Duration() != 150*time.Microsecond {
+		t.Fatalf("Expected server duration to be unchanged but was %s", recorder.Duration())
+	}
+}
+
+type recordedSpanStart struct {
+	operationName string
+	parent        RequestSpanContext
+}
+
+// recordingTracer records the spans which are started, to check how they are parented.
+type recordingTracer struct {
+	lock   sync.Mutex
+	starts []recordedSpanStart
+}
+
+func (tracer *recordingTracer) StartSpan(operationName string, parentContext RequestSpanContext) RequestSpan {
+	tracer.lock.Lock()
+	tracer.starts = append(tracer.starts, recordedSpanStart{operationName, parentContext})
+	tracer.lock.Unlock()
+
+	return defaultNoopSpan
+}
+
+func TestKvOperationUsesParentSpan(t *testing.T) {
+	tracer := &recordingTracer{}
+	col := testGetCollection(t, &mockKvProvider{value: []byte(`{}`)})
+	col.sb.Tracer = tracer
+
+	parent := "user-span"
+	_, err := col.Get("key", &GetOptions{ParentSpan: parent})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if len(tracer.starts) == 0 || tracer.starts[0].operationName != "Get" {
+		t.Fatalf("Expected a Get span to be started but was %v", tracer.starts)
+	}
+	if tracer.starts[0].parent != parent {
+		t.Fatalf("Expected Get span to have the user parent but was %v", tracer.starts[0].parent)
+	}
+}
+
+func TestKvEncodeSpansUseSpanContext(t *testing.T) {
+	tracer := &recordingTracer{}
+	col := testGetCollection(t, &mockKvProvider{value: []byte(`{}`), mt: gocbcore.MutationToken{VbUUID: 1}})
+	col.sb.Tracer = tracer
+	col.sb.UseMutationTokens = true
+
+	_, err := col.Upsert("key", map[string]string{"a": "b"}, &UpsertOptions{PersistTo: 1})
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	col = testGetCollection(t, &mockKvProvider{value: []gocbcore.SubDocResult{{}}})
+	col.sb.Tracer = tracer
+	_, err = col.MutateIn("key", []MutateInSpec{UpsertSpec("a", "b", nil)}, nil)
+	if err != nil {
+		t.Fatalf("MutateIn failed: %v", err)
+	}
+
+	var encodes, observes int
+	for _, start := range tracer.starts {
+		if start.operationName == "observeOnceSeqNo" {
+			observes++
+		}
+		if _, ok := start.parent.(RequestSpan); ok {
+			t.Fatalf("Expected %s span to be given a span context but was given a span", start.operationName)
+		}
+		if start.operationName == "encode" {
+			encodes++
+			if start.parent != defaultNoopSpanContext {
+				t.Fatalf("Expected encode span to have the operation as its parent but was %v", start.parent)
+			}
+		}
+	}
+	if encodes != 2 || observes == 0 {
+		t.Fatalf("Expected 2 encode spans and an observe span but was %d and %d", encodes, observes)
+	}
+}
+
+// failingAnalyticsProvider fails every query, for tests which only care about how the
+// query was dispatched.
+type failingAnalyticsProvider struct{}
+
+func (p *failingAnalyticsProvider) AnalyticsQuery(opts gocbcore.AnalyticsQueryOptions) (*gocbcore.AnalyticsRowReader, error) {
+	return nil, errors.New("query failed")
+}
+
+func TestInnerOperationSpansUseSpanContext(t *testing.T) {
+	tracer := &recordingTracer{}
+
+	c := &Cluster{
+		connections: map[string]client{
+			"mock": &mockClient{
+				bucketName:            "mock",
+				mockAnalyticsProvider: &failingAnalyticsProvider{},
+			},
+		},
+	}
+	c.sb.Tracer = tracer
+	c.sb.AnalyticsTimeout = time.Second
+	c.sb.ManagementTimeout = time.Second
+
+	mgr := &AnalyticsIndexManager{
+		cluster: c,
+		tracer:  tracer,
+	}
+	err := mgr.CreateDataverse("travel", nil)
+	if err == nil {
+		t.Fatalf("Expected CreateDataverse to fail")
+	}
+
+	col := testGetCollection(t, &mockKvProvider{value: []byte(`{}`)})
+	col.sb.Tracer = tracer
+	_, err = col.GetAnyReplica("key", nil)
+	if err != nil {
+		t.Fatalf("GetAnyReplica failed: %v", err)
+	}
+
+	inner := map[string]bool{"Query": false, "GetAllReplicas": false}
+	for _, start := range tracer.starts {
+		if _, ok := start.parent.(RequestSpan); ok {
+			t.Fatalf("Expected %s span to be given a span context but was given a span", start.operationName)
+		}
+		if _, ok := inner[start.operationName]; ok {
+			inner[start.operationName] = true
+			if start.parent != defaultNoopSpanContext {
+				t.Fatalf("Expected %s span to have the outer operation as its parent but was %v",
+					start.operationName, start.parent)
+			}
+		}
+	}
+	for name, started := range inner {
+		if !started {
+			t.Fatalf("Expected a %s span to be started", name)
+		}
+	}
+}
@@ -18,6 +18,8 @@ type Transcoder interface {
 }
 
 // JSONTranscoder implements the default transcoding behavior and applies JSON transcoding to all values.
+// Decoding a document whose flags indicate binary or string content returns an error wrapping
+// ErrValueNotJSON, CompositeTranscoder can be used to decode such documents.
 //
 // This will apply the following behavior to the value:
 // binary ([]byte) -> error.
@@ -41,9 +43,9 @@ func (t *JSONTranscoder) Decode(bytes []byte, flags uint32, out interface{}) err
 
 	// Normal types of decoding
 	if valueType == gocbcore.BinaryType {
-		return errors.New("binary datatype is not supported by JSONTranscoder")
+		return wrapError(ErrValueNotJSON, "binary datatype is not supported by JSONTranscoder")
 	} else if valueType == gocbcore.StringType {
-		return errors.New("string datatype is not supported by JSONTranscoder")
+		return wrapError(ErrValueNotJSON, "string datatype is not supported by JSONTranscoder")
 	} else if valueType == gocbcore.JSONType {
 		err := json.Unmarshal(bytes, &out)
 		if err != nil {
@@ -113,9 +115,9 @@ func (t *RawJSONTranscoder) Decode(bytes []byte, flags uint32, out interface{})
 
 	// Normal types of decoding
 	if valueType == gocbcore.BinaryType {
-		return errors.New("binary datatype is not supported by RawJSONTranscoder")
+		return wrapError(ErrValueNotJSON, "binary datatype is not supported by RawJSONTranscoder")
 	} else if valueType == gocbcore.StringType {
-		return errors.New("string datatype is not supported by RawJSONTranscoder")
+		return wrapError(ErrValueNotJSON, "string datatype is not supported by RawJSONTranscoder")
 	} else if valueType == gocbcore.JSONType {
 		switch typedOut := out.(type) {
 		case *[]byte:
@@ -396,3 +398,69 @@ func (t *LegacyTranscoder) Encode(value interface{}) ([]byte, uint32, error) {
 
 	return bytes, flags, nil
 }
+
+// CompositeTranscoder decodes each document using the transcoder which matches the content type
+// indicated by the common flags of the document, and encodes values using Encoder.  Any nil field
+// is replaced by the default for that content type.
+//
+// This will apply the following behavior to the value:
+// JSON flags -> JSON, decoded by JSONDecoder (JSONTranscoder by default).
+// string flags -> string, decoded by StringDecoder (RawStringTranscoder by default).
+// binary flags -> binary, decoded by BinaryDecoder (RawBinaryTranscoder by default).
+// encode -> encoded by Encoder (JSONTranscoder by default).
+//
+// UNCOMMITTED: This API may change in the future.
+type CompositeTranscoder struct {
+	JSONDecoder   Transcoder
+	StringDecoder Transcoder
+	BinaryDecoder Transcoder
+	Encoder       Transcoder
+}
+
+// NewCompositeTranscoder returns a new CompositeTranscoder using the default transcoders.
+func NewCompositeTranscoder() *CompositeTranscoder {
+	return &CompositeTranscoder{
+		JSONDecoder:   NewJSONTranscoder(),
+		StringDecoder: NewRawStringTranscoder(),
+		BinaryDecoder: NewRawBinaryTranscoder(),
+		Encoder:       NewJSONTranscoder(),
+	}
+}
+
+// Decode dispatches to the transcoder matching the content type indicated by flags.
+func (t *CompositeTranscoder) Decode(bytes []byte, flags uint32, out interface{}) error {
+	valueType, _ := gocbcore.DecodeCommonFlags(flags)
+
+	var decoder Transcoder
+	switch valueType {
+	case gocbcore.JSONType:
+		decoder = t.JSONDecoder
+		if decoder == nil {
+			decoder = NewJSONTranscoder()
+		}
+	case gocbcore.StringType:
+		decoder = t.StringDecoder
+		if decoder == nil {
+			decoder = NewRawStringTranscoder()
+		}
+	case gocbcore.BinaryType:
+		decoder = t.BinaryDecoder
+		if decoder == nil {
+			decoder = NewRawBinaryTranscoder()
+		}
+	default:
+		return errors.New("unexpected expectedFlags value")
+	}
+
+	return decoder.Decode(bytes, flags, out)
+}
+
+// Encode encodes a Go type using Encoder.
+func (t *CompositeTranscoder) Encode(value interface{}) ([]byte, uint32, error) {
+	encoder := t.Encoder
+	if encoder == nil {
+		encoder = NewJSONTranscoder()
+	}
+
+	return encoder.Encode(value)
+}
@@ -2,6 +2,7 @@ package gocb
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -859,3 +860,45 @@ func TestDecodeString(t *testing.T) {
 		}
 	}
 }
+
+func TestJSONTranscoderDecodeNotJSON(t *testing.T) {
+	var out interface{}
+
+	err := NewJSONTranscoder().Decode([]byte("hello"), gocbcore.EncodeCommonFlags(gocbcore.StringType, gocbcore.NoCompression), &out)
+	if !errors.Is(err, ErrValueNotJSON) {
+		t.Fatalf("Expected value not JSON but was %v", err)
+	}
+
+	err = NewJSONTranscoder().Decode([]byte{0x1}, gocbcore.EncodeCommonFlags(gocbcore.BinaryType, gocbcore.NoCompression), &out)
+	if !errors.Is(err, ErrValueNotJSON) {
+		t.Fatalf("Expected value not JSON but was %v", err)
+	}
+}
+
+func TestCompositeTranscoderDecode(t *testing.T) {
+	transcoder := NewCompositeTranscoder()
+
+	var jsonOut map[string]string
+	err := transcoder.Decode([]byte(`{"name":"something"}`), gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression), &jsonOut)
+	if err != nil || jsonOut["name"] != "something" {
+		t.Fatalf("Unexpected JSON decode %v, error %v", jsonOut, err)
+	}
+
+	var stringOut interface{}
+	err = transcoder.Decode([]byte("hello"), gocbcore.EncodeCommonFlags(gocbcore.StringType, gocbcore.NoCompression), &stringOut)
+	if err != nil || stringOut != "hello" {
+		t.Fatalf("Unexpected string decode %v, error %v", stringOut, err)
+	}
+
+	var binaryOut []byte
+	err = transcoder.Decode([]byte{0x1, 0x2}, gocbcore.EncodeCommonFlags(gocbcore.BinaryType, gocbcore.NoCompression), &binaryOut)
+	if err != nil || !reflect.DeepEqual(binaryOut, []byte{0x1, 0x2}) {
+		t.Fatalf("Unexpected binary decode %v, error %v", binaryOut, err)
+	}
+
+	bytes, flags, err := (&CompositeTranscoder{}).Encode(map[string]string{"name": "something"})
+	if err != nil || string(bytes) != `{"name":"something"}` ||
+		flags != gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression) {
+		t.Fatalf("Unexpected encode %s, %d, error %v", bytes, flags, err)
+	}
+}
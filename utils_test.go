@@ -136,6 +136,12 @@ func testGetCollection(t *testing.T, provider *mockKvProvider) *Collection {
 			Tracer:           &noopTracer{},
 		},
 	}
+	b.cluster = &Cluster{
+		connections: clients,
+		sb:          b.sb,
+		queryCache:  make(map[string]*queryCacheEntry),
+	}
+	b.cluster.sb.Serializer = NewDefaultJSONSerializer()
 	col := b.DefaultCollection()
 	return col
 }
@@ -1,10 +1,38 @@
 package gocb
 
+import (
+	"strings"
+	"unicode"
+)
+
 // Version returns a string representation of the current SDK version.
 func Version() string {
 	return goCbVersionStr
 }
 
+// buildUserAgent returns the SDK identifier followed by the application name and version, if
+// they are set.
+func buildUserAgent(appName, appVersion string) (string, error) {
+	if appName == "" {
+		if appVersion != "" {
+			return "", makeInvalidArgumentsError("application version cannot be set without an application name")
+		}
+
+		return Identifier(), nil
+	}
+
+	if strings.IndexFunc(appName+appVersion, unicode.IsSpace) >= 0 {
+		return "", makeInvalidArgumentsError("application name and version cannot contain whitespace")
+	}
+
+	userAgent := Identifier() + " " + appName
+	if appVersion != "" {
+		userAgent += "/" + appVersion
+	}
+
+	return userAgent, nil
+}
+
 // Identifier returns a string representation of the current SDK identifier.
 func Identifier() string {
 	return "gocb/" + goCbVersionStr
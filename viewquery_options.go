@@ -2,6 +2,7 @@ package gocb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -67,7 +68,16 @@ type ViewOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
-	parentSpan requestSpanContext
+	// Context, if set, allows the query to be cancelled.  Cancelling the context
+	// whilst rows are being read will abort the underlying stream.
+	Context context.Context
+
+	// Tags are attached to the tracing span and threshold log entry recorded for
+	// this query.
+	Tags map[string]string
+
+	// ParentSpan, if set, is the parent of the span recorded for this query.
+	ParentSpan RequestSpanContext
 }
 
 func (opts *ViewOptions) toURLValues() (*url.Values, error) {
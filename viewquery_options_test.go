@@ -1,10 +1,15 @@
 package gocb
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/url"
 	"testing"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v8"
 )
 
 func TestViewQueryOptionsToURLValues(t *testing.T) {
@@ -97,6 +102,97 @@ func TestViewQueryOptionsToURLValues(t *testing.T) {
 	}
 }
 
+type mockViewProvider struct {
+	deadlines []time.Time
+	err       error
+}
+
+func (p *mockViewProvider) ViewQuery(opts gocbcore.ViewQueryOptions) (*gocbcore.ViewQueryRowReader, error) {
+	p.deadlines = append(p.deadlines, opts.Deadline)
+	return nil, p.err
+}
+
+func testGetViewBucket(provider viewProvider) *Bucket {
+	b := &Bucket{}
+	b.sb.Tracer = &noopTracer{}
+	b.sb.ViewTimeout = time.Minute
+	b.cacheClient(&mockClient{bucketName: "mock", mockViewProvider: provider})
+	return b
+}
+
+func TestViewQueryContextAlreadyCancelled(t *testing.T) {
+	provider := &mockViewProvider{err: errors.New("no results")}
+	b := testGetViewBucket(provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := b.ViewQuery("ddoc", "view", &ViewOptions{Context: ctx})
+	var viewErr ViewError
+	if !errors.As(err, &viewErr) {
+		t.Fatalf("Expected view error but was %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled but was %v", err)
+	}
+	if len(provider.deadlines) != 0 {
+		t.Fatalf("Expected no requests to be sent but was %d", len(provider.deadlines))
+	}
+}
+
+func TestViewQueryContextDeadlineShorterThanTimeout(t *testing.T) {
+	provider := &mockViewProvider{err: errors.New("no results")}
+	b := testGetViewBucket(provider)
+
+	ctxDeadline := time.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), ctxDeadline)
+	defer cancel()
+
+	_, _ = b.ViewQuery("ddoc", "view", &ViewOptions{Context: ctx, Timeout: 30 * time.Second})
+
+	if len(provider.deadlines) != 1 {
+		t.Fatalf("Expected 1 request but was %d", len(provider.deadlines))
+	}
+	if !provider.deadlines[0].Equal(ctxDeadline) {
+		t.Fatalf("Expected the context deadline %v to be used but was %v", ctxDeadline, provider.deadlines[0])
+	}
+}
+
+func TestViewResultContextCancelledMidIteration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader := newBlockingRowReader([]byte(`{"id":"a","key":"a","value":1}`))
+	res, err := newViewResult(newCtxRowReader(ctx, nil, reader, nil))
+	if err != nil {
+		t.Fatalf("Failed to create result: %v", err)
+	}
+
+	if !res.Next() {
+		t.Fatalf("Expected a row to be available")
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	nextCh := make(chan bool)
+	go func() {
+		nextCh <- res.Next()
+	}()
+
+	select {
+	case hasNext := <-nextCh:
+		if hasNext {
+			t.Fatalf("Next should not return rows after the context is cancelled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Next did not return after the context was cancelled")
+	}
+
+	if !errors.Is(res.Err(), context.Canceled) {
+		t.Fatalf("Expected Err to wrap context.Canceled but was %v", res.Err())
+	}
+}
+
 func testAssertViewOption(t *testing.T, expected string, key string, optValues *url.Values) {
 	val := optValues.Get(key)
 	if val != expected {
@@ -0,0 +1,97 @@
+package gocb
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// activeWorkers counts the long-running goroutines started by startWorker which have not exited.
+var activeWorkers int64
+
+// workerGroup starts long-running goroutines and allows their owner to wait for them to exit.
+type workerGroup struct {
+	wg sync.WaitGroup
+}
+
+func (g *workerGroup) start(fn func()) {
+	g.wg.Add(1)
+	atomic.AddInt64(&activeWorkers, 1)
+	go func() {
+		defer g.wg.Done()
+		defer atomic.AddInt64(&activeWorkers, -1)
+		fn()
+	}()
+}
+
+// wait blocks until every goroutine started by the group has exited.
+func (g *workerGroup) wait() {
+	g.wg.Wait()
+}
+
+// detachedWorkers runs the goroutines of a clusterWorkers which does not belong to a Cluster, so
+// that they are still counted by ActiveWorkers.
+var detachedWorkers workerGroup
+
+// clusterWorkers runs the goroutines which belong to a Cluster but outlive the operation which
+// started them, such as those watching documents or the contexts of streaming results.  They
+// must exit once closed is signalled, which Cluster.Close does before waiting for them.
+type clusterWorkers struct {
+	group     workerGroup
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func newClusterWorkers() *clusterWorkers {
+	return &clusterWorkers{
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (w *clusterWorkers) start(fn func()) {
+	if w == nil {
+		detachedWorkers.start(fn)
+		return
+	}
+
+	w.group.start(fn)
+}
+
+// closed returns a channel which is closed once the Cluster starts closing.
+func (w *clusterWorkers) closed() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+
+	return w.closeCh
+}
+
+// signalClose tells the goroutines of the Cluster to exit.
+func (w *clusterWorkers) signalClose() {
+	if w == nil {
+		return
+	}
+
+	w.closeOnce.Do(func() {
+		close(w.closeCh)
+	})
+}
+
+// wait blocks until every goroutine of the Cluster has exited.
+func (w *clusterWorkers) wait() {
+	if w == nil {
+		return
+	}
+
+	w.group.wait()
+}
+
+// ActiveWorkers returns the number of long-running goroutines which the SDK has started and
+// which have not yet exited, such as those used by the threshold logging tracer, telemetry
+// reporting, document watchers and the watching of contexts passed to queries.  Once every
+// Cluster has been closed this is zero, which tests can assert to check that cycling clusters
+// does not leak goroutines.  The goroutines used by the connections to
+// the cluster, such as the config pollers, are waited for by Cluster.Close and are not counted.
+// UNCOMMITTED: This API may change in the future.
+func ActiveWorkers() int {
+	return int(atomic.LoadInt64(&activeWorkers))
+}
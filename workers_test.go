@@ -0,0 +1,95 @@
+package gocb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testSettledWorkers waits for workers started by earlier tests to finish exiting, returning
+// the number which remain running.  The tracer of the global test cluster, for example, is
+// never stopped.
+func testSettledWorkers() int {
+	workers := ActiveWorkers()
+	for stable := 0; stable < 5; {
+		time.Sleep(10 * time.Millisecond)
+
+		current := ActiveWorkers()
+		if current == workers {
+			stable++
+		} else {
+			workers = current
+			stable = 0
+		}
+	}
+
+	return workers
+}
+
+// testWaitForWorkers waits for the number of active workers to reach expected, as workers
+// exit asynchronously.
+func testWaitForWorkers(t *testing.T, expected int) {
+	deadline := time.Now().Add(time.Second)
+	for ActiveWorkers() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d active workers but was %d", expected, ActiveWorkers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClusterCloseWaitsForWorkers(t *testing.T) {
+	baseline := testSettledWorkers()
+
+	for i := 0; i < 3; i++ {
+		tracer := newThresholdLoggingTracer(nil)
+		tracerAddRef(tracer)
+
+		telemetry := newTelemetryReporter(TelemetryConfig{Sink: &testTelemetrySink{}}, nil)
+		telemetry.start()
+
+		testWaitForWorkers(t, baseline+2)
+
+		c := &Cluster{
+			connections: make(map[string]client),
+		}
+		c.sb.Tracer = tracer
+		c.sb.Telemetry = telemetry
+		c.sb.Workers = newClusterWorkers()
+
+		// A watcher, and contexts which are never cancelled, keep their workers running until
+		// the cluster is closed.
+		col, _ := testWatchCollection(t, 10, nil)
+		col.sb.Workers = c.sb.Workers
+		w, err := col.Watch("config", &WatchOptions{PollInterval: time.Hour})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		newCtxRowReader(ctx, c.sb.Workers, newBlockingRowReader(), nil)
+		watchContext(ctx, c.sb.Workers, func() {})
+
+		testWaitForWorkers(t, baseline+5)
+
+		err = c.Close(nil)
+		if err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		// Close waits for the workers of the cluster, so they must have exited already.
+		if workers := ActiveWorkers(); workers != baseline {
+			t.Fatalf("Expected %d active workers after close but was %d", baseline, workers)
+		}
+
+		// The initial state of the document may not have been read, but the watcher must
+		// have stopped without an error.
+		for range w.Changes() {
+		}
+		if w.Err() != nil {
+			t.Fatalf("Expected watcher to stop without an error but was %v", w.Err())
+		}
+
+		cancel()
+	}
+}